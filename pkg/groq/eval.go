@@ -0,0 +1,614 @@
+package groq
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+type node interface {
+	eval(s *scope) (any, error)
+}
+
+// scope holds the value of @ (this), the parent scope used by ^ and the dataset used by *
+type scope struct {
+	this   any
+	parent *scope
+	root   any
+	// projected records the keys of the object expressions in the order they are written in the query
+	projected *keyOrder
+}
+
+func (s *scope) child(this any) *scope {
+	return &scope{this: this, parent: s, root: s.root, projected: s.projected}
+}
+
+type everythingNode struct{}
+
+func (n *everythingNode) eval(s *scope) (any, error) { return s.root, nil }
+
+type thisNode struct{}
+
+func (n *thisNode) eval(s *scope) (any, error) { return s.this, nil }
+
+type parentNode struct{ levels int }
+
+func (n *parentNode) eval(s *scope) (any, error) {
+	current := s
+	for i := 0; i < n.levels && current != nil; i++ {
+		current = current.parent
+	}
+	if current == nil {
+		return nil, nil
+	}
+	return current.this, nil
+}
+
+type literalNode struct{ value any }
+
+func (n *literalNode) eval(_ *scope) (any, error) { return n.value, nil }
+
+// paramNode always returns null as the queries don't accept parameters. Use grafana variables instead
+type paramNode struct{ name string }
+
+func (n *paramNode) eval(_ *scope) (any, error) { return nil, nil }
+
+type arrayItem struct {
+	value  node
+	spread bool
+}
+
+type arrayNode struct{ items []arrayItem }
+
+func (n *arrayNode) eval(s *scope) (any, error) {
+	out := []any{}
+	for _, item := range n.items {
+		v, err := item.value.eval(s)
+		if err != nil {
+			return nil, err
+		}
+		if arr, ok := v.([]any); ok && item.spread {
+			out = append(out, arr...)
+			continue
+		}
+		out = append(out, v)
+	}
+	return out, nil
+}
+
+type projectionItem struct {
+	key    string
+	value  node
+	spread bool
+}
+
+type objectNode struct{ items []projectionItem }
+
+func (n *objectNode) eval(s *scope) (any, error) {
+	out := map[string]any{}
+	for _, item := range n.items {
+		v, err := item.value.eval(s)
+		if err != nil {
+			return nil, err
+		}
+		if item.spread {
+			if obj, ok := v.(map[string]any); ok {
+				for k, value := range obj {
+					out[k] = value
+				}
+			}
+			continue
+		}
+		if s.projected != nil {
+			s.projected.add(item.key)
+		}
+		out[item.key] = v
+	}
+	return out, nil
+}
+
+// projectionNode evaluates the object expression for the base value or for each item when the base is an array
+type projectionNode struct {
+	base  node
+	items []projectionItem
+}
+
+func (n *projectionNode) eval(s *scope) (any, error) {
+	base, err := n.base.eval(s)
+	if err != nil {
+		return nil, err
+	}
+	obj := &objectNode{items: n.items}
+	return mapValue(base, func(item any) (any, error) {
+		if item == nil {
+			return nil, nil
+		}
+		return obj.eval(s.child(item))
+	})
+}
+
+// attributeNode reads an attribute. When the base is an array, the attribute is read from each item
+type attributeNode struct {
+	base node
+	name string
+}
+
+func (n *attributeNode) eval(s *scope) (any, error) {
+	base, err := n.base.eval(s)
+	if err != nil {
+		return nil, err
+	}
+	return mapValue(base, func(item any) (any, error) {
+		if obj, ok := item.(map[string]any); ok {
+			return obj[n.name], nil
+		}
+		return nil, nil
+	})
+}
+
+type elementNode struct {
+	base  node
+	index int
+}
+
+func (n *elementNode) eval(s *scope) (any, error) {
+	base, err := n.base.eval(s)
+	if err != nil {
+		return nil, err
+	}
+	arr, ok := base.([]any)
+	if !ok {
+		return nil, nil
+	}
+	idx := n.index
+	if idx < 0 {
+		idx += len(arr)
+	}
+	if idx < 0 || idx >= len(arr) {
+		return nil, nil
+	}
+	return arr[idx], nil
+}
+
+type rangeNode struct {
+	start, end node
+	exclusive  bool
+}
+
+type rangeValue struct {
+	start, end any
+	exclusive  bool
+}
+
+func (n *rangeNode) eval(s *scope) (any, error) {
+	start, err := n.start.eval(s)
+	if err != nil {
+		return nil, err
+	}
+	end, err := n.end.eval(s)
+	if err != nil {
+		return nil, err
+	}
+	return rangeValue{start: start, end: end, exclusive: n.exclusive}, nil
+}
+
+type sliceNode struct {
+	base node
+	rng  *rangeNode
+}
+
+func (n *sliceNode) eval(s *scope) (any, error) {
+	base, err := n.base.eval(s)
+	if err != nil {
+		return nil, err
+	}
+	v, err := n.rng.eval(s)
+	if err != nil {
+		return nil, err
+	}
+	rng := v.(rangeValue)
+	start, ok1 := rng.start.(float64)
+	end, ok2 := rng.end.(float64)
+	arr, ok3 := base.([]any)
+	if !ok1 || !ok2 || !ok3 {
+		return nil, nil
+	}
+	from, to := int(start), int(end)
+	if from < 0 {
+		from += len(arr)
+	}
+	if to < 0 {
+		to += len(arr)
+	}
+	if !rng.exclusive {
+		to++
+	}
+	from, to = max(from, 0), min(to, len(arr))
+	if from >= to {
+		return []any{}, nil
+	}
+	return arr[from:to], nil
+}
+
+type filterNode struct {
+	base      node
+	condition node
+}
+
+func (n *filterNode) eval(s *scope) (any, error) {
+	base, err := n.base.eval(s)
+	if err != nil {
+		return nil, err
+	}
+	arr, ok := base.([]any)
+	if !ok {
+		return nil, nil
+	}
+	out := []any{}
+	for _, item := range arr {
+		v, err := n.condition.eval(s.child(item))
+		if err != nil {
+			return nil, err
+		}
+		if v == true {
+			out = append(out, item)
+		}
+	}
+	return out, nil
+}
+
+// mapNode evaluates the inner expression for each item of the base array. The items don't introduce a new parent scope
+type mapNode struct {
+	base   node
+	inner  node
+	closed bool
+}
+
+func (n *mapNode) eval(s *scope) (any, error) {
+	base, err := n.base.eval(s)
+	if err != nil {
+		return nil, err
+	}
+	return mapValue(base, func(item any) (any, error) {
+		return n.inner.eval(&scope{this: item, parent: s.parent, root: s.root, projected: s.projected})
+	})
+}
+
+type flattenNode struct{ base node }
+
+func (n *flattenNode) eval(s *scope) (any, error) {
+	base, err := n.base.eval(s)
+	if err != nil {
+		return nil, err
+	}
+	arr, ok := base.([]any)
+	if !ok {
+		return nil, nil
+	}
+	out := []any{}
+	for _, item := range arr {
+		if inner, ok := item.([]any); ok {
+			out = append(out, inner...)
+			continue
+		}
+		out = append(out, item)
+	}
+	return out, nil
+}
+
+// dereferenceNode always returns null as the responses don't have a document store to resolve references from
+type dereferenceNode struct{ base node }
+
+func (n *dereferenceNode) eval(_ *scope) (any, error) { return nil, nil }
+
+type orderNode struct {
+	value node
+	desc  bool
+}
+
+func (n *orderNode) eval(s *scope) (any, error) { return n.value.eval(s) }
+
+type unaryNode struct {
+	op      string
+	operand node
+}
+
+func (n *unaryNode) eval(s *scope) (any, error) {
+	v, err := n.operand.eval(s)
+	if err != nil {
+		return nil, err
+	}
+	switch n.op {
+	case "!":
+		if b, ok := v.(bool); ok {
+			return !b, nil
+		}
+		return nil, nil
+	case "-":
+		if f, ok := v.(float64); ok {
+			return -f, nil
+		}
+		return nil, nil
+	}
+	if f, ok := v.(float64); ok {
+		return f, nil
+	}
+	return nil, nil
+}
+
+type binaryNode struct {
+	op          string
+	left, right node
+}
+
+func (n *binaryNode) eval(s *scope) (any, error) {
+	l, err := n.left.eval(s)
+	if err != nil {
+		return nil, err
+	}
+	// logical operators follow the three-valued logic of GROQ
+	switch n.op {
+	case "=>":
+		if l != true {
+			return nil, nil
+		}
+		return n.right.eval(s)
+	case "&&":
+		if l == false {
+			return false, nil
+		}
+		r, err := n.right.eval(s)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case r == false:
+			return false, nil
+		case l == true && r == true:
+			return true, nil
+		}
+		return nil, nil
+	case "||":
+		if l == true {
+			return true, nil
+		}
+		r, err := n.right.eval(s)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case r == true:
+			return true, nil
+		case l == false && r == false:
+			return false, nil
+		}
+		return nil, nil
+	}
+	r, err := n.right.eval(s)
+	if err != nil {
+		return nil, err
+	}
+	switch n.op {
+	case "==":
+		return equals(l, r), nil
+	case "!=":
+		return !equals(l, r), nil
+	case "<", "<=", ">", ">=":
+		c, ok := compare(l, r)
+		if !ok {
+			return nil, nil
+		}
+		switch n.op {
+		case "<":
+			return c < 0, nil
+		case "<=":
+			return c <= 0, nil
+		case ">":
+			return c > 0, nil
+		}
+		return c >= 0, nil
+	case "in":
+		return in(l, r), nil
+	case "match":
+		return match(l, r), nil
+	case "+":
+		return add(l, r), nil
+	}
+	lf, lok := l.(float64)
+	rf, rok := r.(float64)
+	if !lok || !rok {
+		return nil, nil
+	}
+	switch n.op {
+	case "-":
+		return lf - rf, nil
+	case "*":
+		return lf * rf, nil
+	case "/":
+		if rf == 0 {
+			return nil, nil
+		}
+		return lf / rf, nil
+	case "%":
+		if rf == 0 {
+			return nil, nil
+		}
+		return math.Mod(lf, rf), nil
+	case "**":
+		return math.Pow(lf, rf), nil
+	}
+	return nil, fmt.Errorf("%w: unsupported operator %s", ErrInvalidQuery, n.op)
+}
+
+// mapValue applies the function to the value or to each item when the value is an array
+func mapValue(v any, fn func(item any) (any, error)) (any, error) {
+	arr, ok := v.([]any)
+	if !ok {
+		return fn(v)
+	}
+	out := make([]any, 0, len(arr))
+	for _, item := range arr {
+		value, err := fn(item)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, value)
+	}
+	return out, nil
+}
+
+func equals(l, r any) bool {
+	switch lv := l.(type) {
+	case nil:
+		return r == nil
+	case float64, string, bool:
+		return l == r
+	case map[string]any, []any:
+		return reflect.DeepEqual(lv, r)
+	}
+	return false
+}
+
+// compare orders numbers, strings and booleans. The second return value is false when the values are not comparable
+func compare(l, r any) (int, bool) {
+	switch lv := l.(type) {
+	case float64:
+		if rv, ok := r.(float64); ok {
+			switch {
+			case lv < rv:
+				return -1, true
+			case lv > rv:
+				return 1, true
+			}
+			return 0, true
+		}
+	case string:
+		if rv, ok := r.(string); ok {
+			return strings.Compare(lv, rv), true
+		}
+	case bool:
+		if rv, ok := r.(bool); ok {
+			switch {
+			case lv == rv:
+				return 0, true
+			case !lv:
+				return -1, true
+			}
+			return 1, true
+		}
+	}
+	return 0, false
+}
+
+func in(l, r any) any {
+	switch rv := r.(type) {
+	case []any:
+		return slices.ContainsFunc(rv, func(item any) bool { return equals(l, item) })
+	case rangeValue:
+		lower, ok1 := compare(l, rv.start)
+		upper, ok2 := compare(l, rv.end)
+		if !ok1 || !ok2 {
+			return nil
+		}
+		if rv.exclusive {
+			return lower >= 0 && upper < 0
+		}
+		return lower >= 0 && upper <= 0
+	}
+	return nil
+}
+
+func add(l, r any) any {
+	switch lv := l.(type) {
+	case float64:
+		if rv, ok := r.(float64); ok {
+			return lv + rv
+		}
+	case string:
+		if rv, ok := r.(string); ok {
+			return lv + rv
+		}
+	case []any:
+		if rv, ok := r.([]any); ok {
+			return append(slices.Clone(lv), rv...)
+		}
+	case map[string]any:
+		if rv, ok := r.(map[string]any); ok {
+			out := map[string]any{}
+			for k, v := range lv {
+				out[k] = v
+			}
+			for k, v := range rv {
+				out[k] = v
+			}
+			return out
+		}
+	}
+	return nil
+}
+
+// match implements the full text match operator. Every pattern term must be present in the text and * acts as a wildcard
+func match(text, pattern any) any {
+	words := []string{}
+	for _, t := range stringValues(text) {
+		words = append(words, splitWords(t)...)
+	}
+	terms := []string{}
+	for _, p := range stringValues(pattern) {
+		terms = append(terms, splitWords(p)...)
+	}
+	if len(terms) == 0 {
+		return false
+	}
+	for _, term := range terms {
+		found := slices.ContainsFunc(words, func(word string) bool { return wildcardMatch(term, word) })
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func stringValues(v any) []string {
+	switch x := v.(type) {
+	case string:
+		return []string{x}
+	case []any:
+		out := []string{}
+		for _, item := range x {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+func splitWords(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return r != '*' && !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func wildcardMatch(pattern, word string) bool {
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return pattern == word
+	}
+	if !strings.HasPrefix(word, parts[0]) {
+		return false
+	}
+	word = word[len(parts[0]):]
+	for _, part := range parts[1 : len(parts)-1] {
+		idx := strings.Index(word, part)
+		if idx < 0 {
+			return false
+		}
+		word = word[idx+len(part):]
+	}
+	return strings.HasSuffix(word, parts[len(parts)-1])
+}
@@ -0,0 +1,364 @@
+package groq
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type function func(s *scope, args []node) (any, error)
+
+var functions map[string]function
+
+func init() {
+	functions = map[string]function{
+		"count":    valueFn(1, fnCount),
+		"defined":  valueFn(1, func(args []any) (any, error) { return args[0] != nil, nil }),
+		"length":   valueFn(1, fnLength),
+		"lower":    stringFn(strings.ToLower),
+		"upper":    stringFn(strings.ToUpper),
+		"string":   valueFn(1, fnString),
+		"round":    fnRound,
+		"coalesce": fnCoalesce,
+		"select":   fnSelect,
+		"now":      valueFn(0, func(_ []any) (any, error) { return time.Now().UTC().Format(time.RFC3339), nil }),
+		"dateTime": valueFn(1, fnDateTime),
+		// namespaced functions
+		"array::compact":     valueFn(1, fnArrayCompact),
+		"array::join":        valueFn(2, fnArrayJoin),
+		"array::unique":      valueFn(1, fnArrayUnique),
+		"math::sum":          numbersFn(func(nums []float64) any { return sum(nums) }),
+		"math::avg":          numbersFn(fnAvg),
+		"math::min":          numbersFn(func(nums []float64) any { return extreme(nums, -1) }),
+		"math::max":          numbersFn(func(nums []float64) any { return extreme(nums, 1) }),
+		"string::split":      valueFn(2, fnSplit),
+		"string::startsWith": valueFn(2, fnStartsWith),
+	}
+}
+
+type callNode struct {
+	name string
+	args []node
+}
+
+func (n *callNode) eval(s *scope) (any, error) {
+	fn, ok := functions[strings.TrimPrefix(n.name, "global::")]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownFunction, n.name)
+	}
+	return fn(s, n.args)
+}
+
+// pipeNode applies a pipe function such as order() to the base value
+type pipeNode struct {
+	base node
+	name string
+	args []node
+}
+
+func (n *pipeNode) eval(s *scope) (any, error) {
+	base, err := n.base.eval(s)
+	if err != nil {
+		return nil, err
+	}
+	if n.name != "order" {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownFunction, n.name)
+	}
+	arr, ok := base.([]any)
+	if !ok {
+		return nil, nil
+	}
+	type sortKey struct {
+		values []any
+		item   any
+	}
+	keys := make([]sortKey, len(arr))
+	for i, item := range arr {
+		keys[i] = sortKey{item: item}
+		for _, arg := range n.args {
+			v, err := arg.eval(s.child(item))
+			if err != nil {
+				return nil, err
+			}
+			keys[i].values = append(keys[i].values, v)
+		}
+	}
+	slices.SortStableFunc(keys, func(a, b sortKey) int {
+		for i, arg := range n.args {
+			c := orderCompare(a.values[i], b.values[i])
+			if o, ok := arg.(*orderNode); ok && o.desc {
+				c = -c
+			}
+			if c != 0 {
+				return c
+			}
+		}
+		return 0
+	})
+	out := make([]any, len(keys))
+	for i, k := range keys {
+		out[i] = k.item
+	}
+	return out, nil
+}
+
+// orderCompare orders the values by type first (booleans, numbers, strings) and keeps nulls at the end
+func orderCompare(a, b any) int {
+	rank := func(v any) int {
+		switch v.(type) {
+		case bool:
+			return 0
+		case float64:
+			return 1
+		case string:
+			return 2
+		case nil:
+			return 4
+		}
+		return 3
+	}
+	if ra, rb := rank(a), rank(b); ra != rb {
+		return ra - rb
+	}
+	c, _ := compare(a, b)
+	return c
+}
+
+// valueFn evaluates the arguments before calling the function and validates the number of arguments
+func valueFn(count int, fn func(args []any) (any, error)) function {
+	return func(s *scope, args []node) (any, error) {
+		if len(args) != count {
+			return nil, fmt.Errorf("%w: expected %d arguments but got %d", ErrInvalidQuery, count, len(args))
+		}
+		values, err := evalArgs(s, args)
+		if err != nil {
+			return nil, err
+		}
+		return fn(values)
+	}
+}
+
+func evalArgs(s *scope, args []node) ([]any, error) {
+	values := make([]any, len(args))
+	for i, arg := range args {
+		v, err := arg.eval(s)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = v
+	}
+	return values, nil
+}
+
+func stringFn(fn func(string) string) function {
+	return valueFn(1, func(args []any) (any, error) {
+		if str, ok := args[0].(string); ok {
+			return fn(str), nil
+		}
+		return nil, nil
+	})
+}
+
+func numbersFn(fn func(nums []float64) any) function {
+	return valueFn(1, func(args []any) (any, error) {
+		arr, ok := args[0].([]any)
+		if !ok {
+			return nil, nil
+		}
+		nums := []float64{}
+		for _, item := range arr {
+			switch v := item.(type) {
+			case float64:
+				nums = append(nums, v)
+			case nil:
+			default:
+				return nil, nil
+			}
+		}
+		return fn(nums), nil
+	})
+}
+
+func fnCount(args []any) (any, error) {
+	if arr, ok := args[0].([]any); ok {
+		return float64(len(arr)), nil
+	}
+	return nil, nil
+}
+
+func fnLength(args []any) (any, error) {
+	switch v := args[0].(type) {
+	case []any:
+		return float64(len(v)), nil
+	case string:
+		return float64(len([]rune(v))), nil
+	}
+	return nil, nil
+}
+
+func fnString(args []any) (any, error) {
+	switch v := args[0].(type) {
+	case string:
+		return v, nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	}
+	return nil, nil
+}
+
+func fnRound(s *scope, args []node) (any, error) {
+	if len(args) < 1 || len(args) > 2 {
+		return nil, fmt.Errorf("%w: round expects 1 or 2 arguments", ErrInvalidQuery)
+	}
+	values, err := evalArgs(s, args)
+	if err != nil {
+		return nil, err
+	}
+	num, ok := values[0].(float64)
+	if !ok {
+		return nil, nil
+	}
+	precision := 0.0
+	if len(values) == 2 {
+		if precision, ok = values[1].(float64); !ok {
+			return nil, nil
+		}
+	}
+	factor := math.Pow(10, precision)
+	return math.Round(num*factor) / factor, nil
+}
+
+func fnCoalesce(s *scope, args []node) (any, error) {
+	for _, arg := range args {
+		v, err := arg.eval(s)
+		if err != nil {
+			return nil, err
+		}
+		if v != nil {
+			return v, nil
+		}
+	}
+	return nil, nil
+}
+
+// fnSelect returns the first value whose condition is true. Conditions are written as pairs using => and the last
+// argument without a condition is used as the fallback value
+func fnSelect(s *scope, args []node) (any, error) {
+	for _, arg := range args {
+		pair, ok := arg.(*binaryNode)
+		if !ok || pair.op != "=>" {
+			return arg.eval(s)
+		}
+		condition, err := pair.left.eval(s)
+		if err != nil {
+			return nil, err
+		}
+		if condition == true {
+			return pair.right.eval(s)
+		}
+	}
+	return nil, nil
+}
+
+func fnDateTime(args []any) (any, error) {
+	str, ok := args[0].(string)
+	if !ok {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, str)
+	if err != nil {
+		return nil, nil
+	}
+	return t.UTC().Format(time.RFC3339Nano), nil
+}
+
+func fnArrayCompact(args []any) (any, error) {
+	arr, ok := args[0].([]any)
+	if !ok {
+		return nil, nil
+	}
+	return slices.DeleteFunc(slices.Clone(arr), func(item any) bool { return item == nil }), nil
+}
+
+func fnArrayJoin(args []any) (any, error) {
+	arr, ok1 := args[0].([]any)
+	sep, ok2 := args[1].(string)
+	if !ok1 || !ok2 {
+		return nil, nil
+	}
+	parts := []string{}
+	for _, item := range arr {
+		str, err := fnString([]any{item})
+		if err != nil || str == nil {
+			return nil, err
+		}
+		parts = append(parts, str.(string))
+	}
+	return strings.Join(parts, sep), nil
+}
+
+func fnArrayUnique(args []any) (any, error) {
+	arr, ok := args[0].([]any)
+	if !ok {
+		return nil, nil
+	}
+	out := []any{}
+	for _, item := range arr {
+		if !slices.ContainsFunc(out, func(existing any) bool { return equals(existing, item) }) {
+			out = append(out, item)
+		}
+	}
+	return out, nil
+}
+
+func sum(nums []float64) float64 {
+	total := 0.0
+	for _, n := range nums {
+		total += n
+	}
+	return total
+}
+
+func fnAvg(nums []float64) any {
+	if len(nums) == 0 {
+		return nil
+	}
+	return sum(nums) / float64(len(nums))
+}
+
+func extreme(nums []float64, direction int) any {
+	if len(nums) == 0 {
+		return nil
+	}
+	if direction < 0 {
+		return slices.Min(nums)
+	}
+	return slices.Max(nums)
+}
+
+func fnSplit(args []any) (any, error) {
+	str, ok1 := args[0].(string)
+	sep, ok2 := args[1].(string)
+	if !ok1 || !ok2 {
+		return nil, nil
+	}
+	out := []any{}
+	for _, part := range strings.Split(str, sep) {
+		out = append(out, part)
+	}
+	return out, nil
+}
+
+func fnStartsWith(args []any) (any, error) {
+	str, ok1 := args[0].(string)
+	prefix, ok2 := args[1].(string)
+	if !ok1 || !ok2 {
+		return nil, nil
+	}
+	return strings.HasPrefix(str, prefix), nil
+}
@@ -0,0 +1,147 @@
+// Package groq evaluates GROQ (Graph-Relational Object Queries) expressions against decoded JSON documents.
+//
+// The response is treated as the dataset, so * refers to the whole response.
+//
+//	*[age >= 20 && country == "US"]{name, "city": address.city} | order(name asc)[0...10]
+//
+// References (->) can't be resolved as there is no document store and always return null.
+package groq
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+var (
+	ErrInvalidQuery    = errors.New("invalid groq query")
+	ErrUnknownFunction = errors.New("unknown groq function")
+	ErrParsingInput    = errors.New("error parsing the input data")
+)
+
+// Result is the output of a GROQ query
+type Result struct {
+	Data any
+	// Columns holds the preferred column order. The projected keys come first in the order they are written in the
+	// query, followed by the keys of the input document in the order they first appear
+	Columns []string
+}
+
+// Evaluate runs the GROQ query against the input. Input can be a json string/byte slice or an already decoded json value.
+// An empty query returns the input as is
+func Evaluate(query string, input any) (any, error) {
+	result, err := Run(query, input)
+	if err != nil {
+		return nil, err
+	}
+	return result.Data, nil
+}
+
+// Run is same as Evaluate but also returns the preferred column order. The key order of the document is only known
+// when the input is json string/byte slice or json.RawMessage
+func Run(query string, input any) (*Result, error) {
+	document := &keyOrder{seen: map[string]bool{}}
+	switch v := input.(type) {
+	case []byte:
+		input = string(v)
+	case json.RawMessage:
+		input = string(v)
+	}
+	if str, ok := input.(string); ok {
+		decoded, err := decodeJSON(str, document)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrParsingInput, err)
+		}
+		input = decoded
+	}
+	if strings.TrimSpace(query) == "" {
+		return &Result{Data: input, Columns: document.keys}, nil
+	}
+	n, err := parse(query)
+	if err != nil {
+		return nil, err
+	}
+	projected := &keyOrder{seen: map[string]bool{}}
+	result, err := n.eval(&scope{this: input, root: input, projected: projected})
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := result.(rangeValue); ok {
+		return nil, fmt.Errorf("%w: range can only be used for slicing or with the in operator", ErrInvalidQuery)
+	}
+	for _, key := range document.keys {
+		projected.add(key)
+	}
+	return &Result{Data: result, Columns: projected.keys}, nil
+}
+
+// keyOrder records the object keys in the order they first appear
+type keyOrder struct {
+	keys []string
+	seen map[string]bool
+}
+
+func (k *keyOrder) add(key string) {
+	if !k.seen[key] {
+		k.seen[key] = true
+		k.keys = append(k.keys, key)
+	}
+}
+
+// decodeJSON decodes the input same as json.Unmarshal while recording the object keys in the document order
+func decodeJSON(input string, order *keyOrder) (any, error) {
+	dec := json.NewDecoder(strings.NewReader(input))
+	value, err := decodeJSONValue(dec, order)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, errors.New("unexpected data after top-level value")
+	}
+	return value, nil
+}
+
+// decodeJSONValue decodes the next json value recording the object keys
+func decodeJSONValue(dec *json.Decoder, order *keyOrder) (any, error) {
+	t, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	delim, ok := t.(json.Delim)
+	if !ok {
+		return t, nil
+	}
+	switch delim {
+	case '{':
+		obj := map[string]any{}
+		for dec.More() {
+			kt, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			key, _ := kt.(string)
+			value, err := decodeJSONValue(dec, order)
+			if err != nil {
+				return nil, err
+			}
+			order.add(key)
+			obj[key] = value
+		}
+		_, err := dec.Token()
+		return obj, err
+	case '[':
+		arr := []any{}
+		for dec.More() {
+			value, err := decodeJSONValue(dec, order)
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, value)
+		}
+		_, err := dec.Token()
+		return arr, err
+	}
+	return nil, fmt.Errorf("unexpected delimiter %s", delim)
+}
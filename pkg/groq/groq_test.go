@@ -0,0 +1,110 @@
+package groq
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvaluate(t *testing.T) {
+	users := `[
+		{ "name": "foo", "age": 20, "country": "US", "address": { "city": "new york", "zip": "10001" }, "tags": ["a","b"] },
+		{ "name": "bar", "age": 40, "country": "UK", "address": { "city": "london" }, "tags": ["c"] },
+		{ "name": "baz", "age": 12, "country": "US", "tags": [] }
+	]`
+	tests := []struct {
+		name    string
+		query   string
+		input   any
+		want    any
+		wantErr error
+	}{
+		{name: "everything", query: `*`, input: `[1,2]`, want: []any{1.0, 2.0}},
+		{name: "empty query", query: ``, input: `{"a":1}`, want: map[string]any{"a": 1.0}},
+		{
+			name:  "filter",
+			query: `*[age >= 20 && country == "US"]{name}`,
+			input: users,
+			want:  []any{map[string]any{"name": "foo"}},
+		},
+		{
+			name:  "projection with alias, nested projection and spread",
+			query: `*[name == "foo"]{name, "city": address.city, address{zip}, "count": count(tags), ...address}`,
+			input: users,
+			want: []any{map[string]any{
+				"name":    "foo",
+				"count":   2.0,
+				"city":    "new york",
+				"zip":     "10001",
+				"address": map[string]any{"zip": "10001"},
+			}},
+		},
+		{
+			name:  "order and slice",
+			query: `* | order(age desc)[0...2]{name}`,
+			input: users,
+			want:  []any{map[string]any{"name": "bar"}, map[string]any{"name": "foo"}},
+		},
+		{
+			name:  "order with multiple fields",
+			query: `* | order(country asc, name asc).name`,
+			input: users,
+			want:  []any{"bar", "baz", "foo"},
+		},
+		{name: "inclusive slice", query: `*[0..1].name`, input: users, want: []any{"foo", "bar"}},
+		{name: "element", query: `*[-1].name`, input: users, want: "baz"},
+		{name: "attribute traversal", query: `*.address.city`, input: users, want: []any{"new york", "london", nil}},
+		{name: "flatten", query: `*.tags[]`, input: users, want: []any{"a", "b", "c"}},
+		{name: "in array", query: `*[country in ["UK", "FR"]].name`, input: users, want: []any{"bar"}},
+		{name: "in range", query: `*[age in 10..20].name`, input: users, want: []any{"foo", "baz"}},
+		{name: "defined", query: `*[!defined(address)].name`, input: users, want: []any{"baz"}},
+		{name: "match", query: `*[address.city match "lon*"].name`, input: users, want: []any{"bar"}},
+		{name: "parent", query: `*[name == "foo"].tags[@ != "a"]{"tag": @, "owner": ^.name}`, input: users, want: []any{[]any{map[string]any{"tag": "b", "owner": "foo"}}}},
+		{name: "arithmetic", query: `{"total": math::sum(*.age), "avg": round(math::avg(*.age), 1), "pow": 2 ** 3 ** 2, "text": "a" + "b"}`, input: users, want: map[string]any{"total": 72.0, "avg": 24.0, "pow": 512.0, "text": "ab"}},
+		{name: "coalesce and select", query: `*{"city": coalesce(address.city, "unknown"), "group": select(age < 18 => "kid", "adult")}[2]`, input: users, want: map[string]any{"city": "unknown", "group": "kid"}},
+		{name: "conditional projection", query: `*[0]{name, age > 18 => {"adult": true}}`, input: users, want: map[string]any{"name": "foo", "adult": true}},
+		{name: "comments and strings", query: "// comment\n*[name == 'foo'].address[\"city\"]", input: users, want: []any{"new york"}},
+		{name: "already decoded input", query: `count(*)`, input: []any{1.0, 2.0}, want: 2.0},
+		{name: "filter on object", query: `*[a == 1]`, input: `{"a":1}`, want: nil},
+		{name: "null comparison", query: `*[age > "10"]`, input: users, want: []any{}},
+		{name: "invalid query", query: `*[age >`, input: users, wantErr: ErrInvalidQuery},
+		{name: "chained comparison", query: `1 < 2 < 3`, input: users, wantErr: ErrInvalidQuery},
+		{name: "unknown function", query: `foo(*)`, input: users, wantErr: ErrUnknownFunction},
+		{name: "invalid json", query: `*`, input: `{`, wantErr: ErrParsingInput},
+		{name: "trailing data after json", query: `*`, input: `{} 1`, wantErr: ErrParsingInput},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Evaluate(tt.query, tt.input)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRunColumns(t *testing.T) {
+	input := `[{ "name": "foo", "age": 20, "address": { "city": "london" } }]`
+	tests := []struct {
+		name    string
+		query   string
+		input   any
+		columns []string
+	}{
+		{name: "document order", query: `*`, input: input, columns: []string{"name", "age", "city", "address"}},
+		{name: "raw json input", query: ``, input: json.RawMessage(input), columns: []string{"name", "age", "city", "address"}},
+		{name: "projected keys first", query: `*{age, "town": address.city, name}`, input: input, columns: []string{"age", "town", "name", "city", "address"}},
+		{name: "already decoded input", query: `*{name}`, input: []any{map[string]any{"name": "foo"}}, columns: []string{"name"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Run(tt.query, tt.input)
+			require.NoError(t, err)
+			require.Equal(t, tt.columns, got.Columns)
+		})
+	}
+}
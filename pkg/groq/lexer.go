@@ -0,0 +1,161 @@
+package groq
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenParam
+	tokenString
+	tokenNumber
+	tokenPunct
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+	pos   int
+}
+
+// punctuations are ordered so that the longest match wins
+var punctuations = []string{"...", "..", "->", "=>", "==", "!=", "<=", ">=", "&&", "||", "**", "*", "@", "^", ".", "[", "]", "{", "}", "(", ")", ",", ":", "|", "<", ">", "!", "+", "-", "/", "%"}
+
+func tokenize(query string) ([]token, error) {
+	tokens := []token{}
+	for i := 0; i < len(query); {
+		r, size := utf8.DecodeRuneInString(query[i:])
+		switch {
+		case unicode.IsSpace(r):
+			i += size
+		case strings.HasPrefix(query[i:], "//"):
+			end := strings.IndexByte(query[i:], '\n')
+			if end < 0 {
+				i = len(query)
+				continue
+			}
+			i += end
+		case r == '"' || r == '\'':
+			value, next, err := readString(query, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokenString, value: value, pos: i})
+			i = next
+		case unicode.IsDigit(r):
+			start := i
+			for i < len(query) && isDigit(query[i]) {
+				i++
+			}
+			// a dot is only part of the number when followed by a digit, otherwise it is a range operator
+			if i+1 < len(query) && query[i] == '.' && isDigit(query[i+1]) {
+				i++
+				for i < len(query) && isDigit(query[i]) {
+					i++
+				}
+			}
+			if i < len(query) && (query[i] == 'e' || query[i] == 'E') {
+				j := i + 1
+				if j < len(query) && (query[j] == '+' || query[j] == '-') {
+					j++
+				}
+				if j < len(query) && isDigit(query[j]) {
+					i = j
+					for i < len(query) && isDigit(query[i]) {
+						i++
+					}
+				}
+			}
+			tokens = append(tokens, token{kind: tokenNumber, value: query[start:i], pos: start})
+		case r == '$' || r == '_' || unicode.IsLetter(r):
+			start := i
+			kind := tokenIdent
+			if r == '$' {
+				kind = tokenParam
+				i++
+			}
+			i = readIdent(query, i)
+			// namespaced functions such as math::sum
+			for strings.HasPrefix(query[i:], "::") {
+				i = readIdent(query, i+2)
+			}
+			tokens = append(tokens, token{kind: kind, value: strings.TrimPrefix(query[start:i], "$"), pos: start})
+		default:
+			matched := false
+			for _, p := range punctuations {
+				if strings.HasPrefix(query[i:], p) {
+					tokens = append(tokens, token{kind: tokenPunct, value: p, pos: i})
+					i += len(p)
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, fmt.Errorf("%w: unexpected character %q at position %d", ErrInvalidQuery, r, i)
+			}
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, pos: len(query)}), nil
+}
+
+func isDigit(b byte) bool {
+	return b >= '0' && b <= '9'
+}
+
+func readIdent(query string, i int) int {
+	for i < len(query) {
+		r, size := utf8.DecodeRuneInString(query[i:])
+		if r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			break
+		}
+		i += size
+	}
+	return i
+}
+
+func readString(query string, start int) (string, int, error) {
+	quote := query[start]
+	var sb strings.Builder
+	for i := start + 1; i < len(query); i++ {
+		c := query[i]
+		if c == quote {
+			return sb.String(), i + 1, nil
+		}
+		if c != '\\' || i+1 >= len(query) {
+			sb.WriteByte(c)
+			continue
+		}
+		i++
+		switch query[i] {
+		case 'n':
+			sb.WriteByte('\n')
+		case 't':
+			sb.WriteByte('\t')
+		case 'r':
+			sb.WriteByte('\r')
+		case 'b':
+			sb.WriteByte('\b')
+		case 'f':
+			sb.WriteByte('\f')
+		case 'u':
+			if i+4 < len(query) {
+				if code, err := strconv.ParseUint(query[i+1:i+5], 16, 32); err == nil {
+					sb.WriteRune(rune(code))
+					i += 4
+					continue
+				}
+			}
+			return "", 0, fmt.Errorf("%w: invalid unicode escape at position %d", ErrInvalidQuery, i)
+		default:
+			sb.WriteByte(query[i])
+		}
+	}
+	return "", 0, fmt.Errorf("%w: unterminated string at position %d", ErrInvalidQuery, start)
+}
@@ -0,0 +1,435 @@
+package groq
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// binding powers of the operators, from the loosest to the tightest
+const (
+	bpPipe = iota + 1
+	bpPair
+	bpOr
+	bpAnd
+	bpCompare
+	bpRange
+	bpAdd
+	bpMul
+	bpUnaryMinus
+	bpPow
+	bpNot
+	bpPostfix
+)
+
+var infixBindingPowers = map[string]int{
+	"|": bpPipe, "=>": bpPair, "||": bpOr, "&&": bpAnd,
+	"==": bpCompare, "!=": bpCompare, "<": bpCompare, "<=": bpCompare, ">": bpCompare, ">=": bpCompare,
+	"..": bpRange, "...": bpRange,
+	"+": bpAdd, "-": bpAdd,
+	"*": bpMul, "/": bpMul, "%": bpMul,
+	"**": bpPow,
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func parse(query string) (node, error) {
+	tokens, err := tokenize(query)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	n, err := p.parseExpr(0)
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, p.errorf(t, "unexpected token %q", t.value)
+	}
+	return n, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) isPunct(value string) bool {
+	t := p.peek()
+	return t.kind == tokenPunct && t.value == value
+}
+
+func (p *parser) isIdent(value string) bool {
+	t := p.peek()
+	return t.kind == tokenIdent && t.value == value
+}
+
+func (p *parser) expect(value string) error {
+	t := p.next()
+	if t.kind != tokenPunct || t.value != value {
+		return p.errorf(t, "expected %q", value)
+	}
+	return nil
+}
+
+func (p *parser) errorf(t token, format string, args ...any) error {
+	return fmt.Errorf("%w: %s at position %d", ErrInvalidQuery, fmt.Sprintf(format, args...), t.pos)
+}
+
+func (p *parser) parseExpr(minBP int) (node, error) {
+	left, err := p.parsePrefix()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		if t.kind == tokenPunct && (t.value == "." || t.value == "[" || t.value == "{" || t.value == "->") {
+			if left, err = p.parsePostfix(left); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		op := t.value
+		bp := 0
+		switch {
+		case t.kind == tokenPunct:
+			bp = infixBindingPowers[op]
+		case t.kind == tokenIdent && (op == "in" || op == "match"):
+			bp = bpCompare
+		}
+		if bp == 0 || bp <= minBP {
+			return left, nil
+		}
+		p.next()
+		switch op {
+		case "|":
+			if left, err = p.parsePipe(left); err != nil {
+				return nil, err
+			}
+			continue
+		case "..", "...":
+			right, err := p.parseExpr(bp)
+			if err != nil {
+				return nil, err
+			}
+			left = &rangeNode{start: left, end: right, exclusive: op == "..."}
+			continue
+		}
+		rightBP := bp
+		if op == "**" {
+			// right associative
+			rightBP = bp - 1
+		}
+		right, err := p.parseExpr(rightBP)
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: op, left: left, right: right}
+		if bp == bpCompare {
+			if next := p.peek(); (next.kind == tokenPunct && infixBindingPowers[next.value] == bpCompare) || (next.kind == tokenIdent && (next.value == "in" || next.value == "match")) {
+				return nil, p.errorf(next, "comparison operators cannot be chained")
+			}
+		}
+	}
+}
+
+func (p *parser) parsePrefix() (node, error) {
+	t := p.next()
+	switch t.kind {
+	case tokenNumber:
+		v, err := strconv.ParseFloat(t.value, 64)
+		if err != nil {
+			return nil, p.errorf(t, "invalid number %s", t.value)
+		}
+		return &literalNode{value: v}, nil
+	case tokenString:
+		return &literalNode{value: t.value}, nil
+	case tokenParam:
+		return &paramNode{name: t.value}, nil
+	case tokenIdent:
+		switch t.value {
+		case "true":
+			return &literalNode{value: true}, nil
+		case "false":
+			return &literalNode{value: false}, nil
+		case "null":
+			return &literalNode{value: nil}, nil
+		}
+		if p.isPunct("(") {
+			args, err := p.parseArgs()
+			if err != nil {
+				return nil, err
+			}
+			return &callNode{name: t.value, args: args}, nil
+		}
+		return &attributeNode{base: &thisNode{}, name: t.value}, nil
+	case tokenPunct:
+		switch t.value {
+		case "*":
+			return &everythingNode{}, nil
+		case "@":
+			return &thisNode{}, nil
+		case "^":
+			levels := 1
+			for p.isPunct(".") && p.tokens[p.pos+1].kind == tokenPunct && p.tokens[p.pos+1].value == "^" {
+				p.pos += 2
+				levels++
+			}
+			return &parentNode{levels: levels}, nil
+		case "(":
+			inner, err := p.parseExpr(0)
+			if err != nil {
+				return nil, err
+			}
+			return inner, p.expect(")")
+		case "[":
+			return p.parseArrayLiteral()
+		case "{":
+			items, err := p.parseProjectionItems()
+			if err != nil {
+				return nil, err
+			}
+			return &objectNode{items: items}, nil
+		case "-", "+":
+			operand, err := p.parseExpr(bpUnaryMinus)
+			if err != nil {
+				return nil, err
+			}
+			return &unaryNode{op: t.value, operand: operand}, nil
+		case "!":
+			operand, err := p.parseExpr(bpNot)
+			if err != nil {
+				return nil, err
+			}
+			return &unaryNode{op: "!", operand: operand}, nil
+		}
+	}
+	if t.kind == tokenEOF {
+		return nil, p.errorf(t, "unexpected end of query")
+	}
+	return nil, p.errorf(t, "unexpected token %q", t.value)
+}
+
+func (p *parser) parsePostfix(base node) (node, error) {
+	t := p.next()
+	switch t.value {
+	case ".":
+		name := p.next()
+		if name.kind != tokenIdent {
+			return nil, p.errorf(name, "expected attribute name")
+		}
+		return traverse(base, true, func(b node) node { return &attributeNode{base: b, name: name.value} }), nil
+	case "->":
+		if name := p.peek(); name.kind == tokenIdent {
+			p.next()
+			return traverse(base, true, func(b node) node { return &attributeNode{base: &dereferenceNode{base: b}, name: name.value} }), nil
+		}
+		return traverse(base, true, func(b node) node { return &dereferenceNode{base: b} }), nil
+	case "{":
+		items, err := p.parseProjectionItems()
+		if err != nil {
+			return nil, err
+		}
+		// a projection completes the traversal, *[a > 1]{b}[0] returns the first projected item
+		if m, ok := base.(*mapNode); ok && !m.closed {
+			return &mapNode{base: m.base, inner: &projectionNode{base: m.inner, items: items}, closed: true}, nil
+		}
+		return &projectionNode{base: base, items: items}, nil
+	}
+	// [
+	if p.isPunct("]") {
+		p.next()
+		return &flattenNode{base: base}, nil
+	}
+	inner, err := p.parseExpr(0)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect("]"); err != nil {
+		return nil, err
+	}
+	switch n := inner.(type) {
+	case *rangeNode:
+		return traverse(base, false, func(b node) node { return &sliceNode{base: b, rng: n} }), nil
+	case *literalNode:
+		switch v := n.value.(type) {
+		case float64:
+			return traverse(base, false, func(b node) node { return &elementNode{base: b, index: int(v)} }), nil
+		case string:
+			return traverse(base, true, func(b node) node { return &attributeNode{base: b, name: v} }), nil
+		}
+	case *unaryNode:
+		if lit, ok := n.operand.(*literalNode); ok && n.op == "-" {
+			if v, ok := lit.value.(float64); ok {
+				return traverse(base, false, func(b node) node { return &elementNode{base: b, index: -int(v)} }), nil
+			}
+		}
+	}
+	return traverse(base, false, func(b node) node { return &filterNode{base: b, condition: inner} }), nil
+}
+
+// traverse applies the postfix operator to each item once the base is an array traversal, so that *[a > 1].b[0]
+// returns the first b of each item instead of the b of the first item. Attribute access starts a new
+// traversal on arrays, filters and slices apply to the whole array unless a traversal is already in progress
+func traverse(base node, startsTraversal bool, build func(base node) node) node {
+	if m, ok := base.(*mapNode); ok && !m.closed {
+		return &mapNode{base: m.base, inner: build(m.inner)}
+	}
+	if startsTraversal {
+		switch base.(type) {
+		case *everythingNode, *filterNode, *sliceNode, *flattenNode:
+			return &mapNode{base: base, inner: build(&thisNode{})}
+		}
+	}
+	return build(base)
+}
+
+func (p *parser) parsePipe(base node) (node, error) {
+	if p.isPunct("{") {
+		p.next()
+		items, err := p.parseProjectionItems()
+		if err != nil {
+			return nil, err
+		}
+		return &projectionNode{base: base, items: items}, nil
+	}
+	name := p.next()
+	if name.kind != tokenIdent || !p.isPunct("(") {
+		return nil, p.errorf(name, "expected pipe function")
+	}
+	args, err := p.parseArgs()
+	if err != nil {
+		return nil, err
+	}
+	return &pipeNode{base: base, name: name.value, args: args}, nil
+}
+
+func (p *parser) parseArgs() ([]node, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	args := []node{}
+	for !p.isPunct(")") {
+		arg, err := p.parseExpr(0)
+		if err != nil {
+			return nil, err
+		}
+		// sort direction used by order()
+		if p.isIdent("asc") || p.isIdent("desc") {
+			arg = &orderNode{value: arg, desc: p.next().value == "desc"}
+		}
+		args = append(args, arg)
+		if !p.isPunct(",") {
+			break
+		}
+		p.next()
+	}
+	return args, p.expect(")")
+}
+
+func (p *parser) parseArrayLiteral() (node, error) {
+	items := []arrayItem{}
+	for !p.isPunct("]") {
+		spread := false
+		if p.isPunct("...") {
+			p.next()
+			spread = true
+		}
+		value, err := p.parseExpr(0)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, arrayItem{value: value, spread: spread})
+		if !p.isPunct(",") {
+			break
+		}
+		p.next()
+	}
+	return &arrayNode{items: items}, p.expect("]")
+}
+
+// parseProjectionItems parses the items of {...}. The opening brace is already consumed
+func (p *parser) parseProjectionItems() ([]projectionItem, error) {
+	items := []projectionItem{}
+	for !p.isPunct("}") {
+		if p.isPunct("...") {
+			p.next()
+			var value node = &thisNode{}
+			if !p.isPunct(",") && !p.isPunct("}") {
+				v, err := p.parseExpr(0)
+				if err != nil {
+					return nil, err
+				}
+				value = v
+			}
+			items = append(items, projectionItem{value: value, spread: true})
+		} else {
+			start := p.peek()
+			value, err := p.parseExpr(0)
+			if err != nil {
+				return nil, err
+			}
+			if p.isPunct(":") {
+				p.next()
+				name, isString := "", false
+				if key, ok := value.(*literalNode); ok {
+					name, isString = key.value.(string)
+				}
+				if !isString {
+					return nil, p.errorf(start, "object keys must be strings")
+				}
+				if value, err = p.parseExpr(0); err != nil {
+					return nil, err
+				}
+				items = append(items, projectionItem{key: name, value: value})
+			} else if pair, ok := value.(*binaryNode); ok && pair.op == "=>" {
+				// conditional projection such as {defined(a) => {a}}
+				items = append(items, projectionItem{value: value, spread: true})
+			} else {
+				name := implicitKey(value)
+				if name == "" {
+					return nil, p.errorf(start, "object expression requires a key")
+				}
+				items = append(items, projectionItem{key: name, value: value})
+			}
+		}
+		if !p.isPunct(",") {
+			break
+		}
+		p.next()
+	}
+	return items, p.expect("}")
+}
+
+// implicitKey returns the key used for the projection items without explicit keys such as {name} or {address{city}}
+func implicitKey(n node) string {
+	switch v := n.(type) {
+	case *attributeNode:
+		if _, ok := v.base.(*thisNode); ok {
+			return v.name
+		}
+		return implicitKey(v.base)
+	case *projectionNode:
+		return implicitKey(v.base)
+	case *filterNode:
+		return implicitKey(v.base)
+	case *elementNode:
+		return implicitKey(v.base)
+	case *sliceNode:
+		return implicitKey(v.base)
+	case *flattenNode:
+		return implicitKey(v.base)
+	case *dereferenceNode:
+		return implicitKey(v.base)
+	}
+	return ""
+}
@@ -0,0 +1,143 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/grafana/grafana-infinity-datasource/pkg/models"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/tracing"
+	"github.com/grafana/grafana-plugin-sdk-go/config"
+)
+
+// azure settings of grafana which are forwarded to the plugin when the plugin is listed in forward_settings_to_plugins
+const (
+	GrafanaAzureManagedIdentityEnabled  = "GFAZPL_MANAGED_IDENTITY_ENABLED"
+	GrafanaAzureManagedIdentityClientID = "GFAZPL_MANAGED_IDENTITY_CLIENT_ID"
+	GrafanaAzureWorkloadIdentityEnabled = "GFAZPL_WORKLOAD_IDENTITY_ENABLED"
+	GrafanaAzureWorkloadIdentityTenant  = "GFAZPL_WORKLOAD_IDENTITY_TENANT_ID"
+	GrafanaAzureWorkloadIdentityClient  = "GFAZPL_WORKLOAD_IDENTITY_CLIENT_ID"
+	GrafanaAzureWorkloadIdentityToken   = "GFAZPL_WORKLOAD_IDENTITY_TOKEN_FILE"
+)
+
+func isAzureADConfigured(settings models.InfinitySettings) bool {
+	return settings.AuthenticationMethod == models.AuthenticationMethodAzureAD
+}
+
+// applyAzureADAuth signs the requests with the Microsoft Entra ID token. Scope of the token is derived from the host of the request
+func applyAzureADAuth(ctx context.Context, httpClient *http.Client, settings models.InfinitySettings) (*http.Client, error) {
+	ctx, span := tracing.DefaultTracer().Start(ctx, "ApplyAzureADAuth")
+	defer span.End()
+	if isAzureADConfigured(settings) {
+		credentials := settings.AzureADCredentials()
+		cred, err := GetAzureTokenCredential(ctx, credentials, azcore.ClientOptions{Cloud: GetAzureCloudConfiguration(credentials.CloudType)})
+		if err != nil {
+			return httpClient, err
+		}
+		httpClient = &http.Client{
+			Transport: &azureADTransport{Base: httpClient.Transport, credential: cred, settings: settings},
+			Timeout:   httpClient.Timeout,
+		}
+	}
+	return httpClient, nil
+}
+
+// azureADTransport sets the Entra ID token of the request host as the bearer token. Tokens are cached per scope by the credential
+type azureADTransport struct {
+	Base       http.RoundTripper
+	credential azcore.TokenCredential
+	settings   models.InfinitySettings
+}
+
+func (t *azureADTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	scope := t.settings.AzureADScope(req.URL.Hostname())
+	token, err := t.credential.GetToken(req.Context(), policy.TokenRequestOptions{Scopes: []string{scope}})
+	if err != nil {
+		backend.Logger.FromContext(req.Context()).Error("error getting the Entra ID token", "scope", scope, "error", err.Error())
+		return nil, backend.DownstreamError(errors.Join(models.ErrAzureADToken, err))
+	}
+	req2 := req.Clone(req.Context())
+	req2.Header.Set("Authorization", "Bearer "+token.Token)
+	return t.Base.RoundTrip(req2)
+}
+
+// GetAzureTokenCredential returns the Entra ID credential. Managed identity and workload identity of the grafana host
+// are used only when enabled in the azure section of the grafana configuration
+func GetAzureTokenCredential(ctx context.Context, credentials models.AzureCredentials, clientOptions azcore.ClientOptions) (azcore.TokenCredential, error) {
+	switch credentials.AuthType {
+	case models.AzureAuthTypeClientSecret:
+		return azidentity.NewClientSecretCredential(credentials.TenantID, credentials.ClientID, credentials.ClientSecret, &azidentity.ClientSecretCredentialOptions{ClientOptions: clientOptions})
+	case models.AzureAuthTypeClientCertificate:
+		certs, key, err := azidentity.ParseCertificates([]byte(credentials.ClientCertificate), nil)
+		if err != nil {
+			return nil, fmt.Errorf("%w. %s", models.ErrInvalidConfigAzureClientCert, err.Error())
+		}
+		return azidentity.NewClientCertificateCredential(credentials.TenantID, credentials.ClientID, certs, key, &azidentity.ClientCertificateCredentialOptions{ClientOptions: clientOptions})
+	case models.AzureAuthTypeManagedIdentity:
+		// identity of the grafana host must not be used by the data sources unless the grafana admin allowed it
+		if !isGrafanaAzureSettingEnabled(ctx, GrafanaAzureManagedIdentityEnabled) {
+			return nil, models.ErrAzureIdentityDisabled
+		}
+		options := &azidentity.ManagedIdentityCredentialOptions{ClientOptions: clientOptions}
+		clientID := strings.TrimSpace(credentials.ClientID)
+		if clientID == "" {
+			clientID = getGrafanaAzureSetting(ctx, GrafanaAzureManagedIdentityClientID)
+		}
+		if clientID != "" {
+			options.ID = azidentity.ClientID(clientID)
+		}
+		return azidentity.NewManagedIdentityCredential(options)
+	case models.AzureAuthTypeWorkloadIdentity:
+		if !isGrafanaAzureSettingEnabled(ctx, GrafanaAzureWorkloadIdentityEnabled) {
+			return nil, models.ErrAzureIdentityDisabled
+		}
+		options := &azidentity.WorkloadIdentityCredentialOptions{
+			ClientOptions: clientOptions,
+			TenantID:      getGrafanaAzureSetting(ctx, GrafanaAzureWorkloadIdentityTenant),
+			ClientID:      getGrafanaAzureSetting(ctx, GrafanaAzureWorkloadIdentityClient),
+			TokenFilePath: getGrafanaAzureSetting(ctx, GrafanaAzureWorkloadIdentityToken),
+		}
+		if tenantID := strings.TrimSpace(credentials.TenantID); tenantID != "" {
+			options.TenantID = tenantID
+		}
+		if clientID := strings.TrimSpace(credentials.ClientID); clientID != "" {
+			options.ClientID = clientID
+		}
+		return azidentity.NewWorkloadIdentityCredential(options)
+	}
+	return nil, fmt.Errorf("%w. %s", models.ErrInvalidConfigAzureAuthType, credentials.AuthType)
+}
+
+// GetAzureCloudConfiguration returns the Entra ID authority of the sovereign clouds
+func GetAzureCloudConfiguration(cloudType string) cloud.Configuration {
+	switch cloudType {
+	case models.AzureCloudUSGovernment:
+		return cloud.AzureGovernment
+	case models.AzureCloudChina:
+		return cloud.AzureChina
+	default:
+		return cloud.AzurePublic
+	}
+}
+
+func getGrafanaAzureSetting(ctx context.Context, key string) string {
+	if value := config.GrafanaConfigFromContext(ctx).Get(key); value != "" {
+		return value
+	}
+	// older grafana versions pass the azure settings as environment variables
+	return os.Getenv(key)
+}
+
+func isGrafanaAzureSettingEnabled(ctx context.Context, key string) bool {
+	enabled, _ := strconv.ParseBool(getGrafanaAzureSetting(ctx, key))
+	return enabled
+}
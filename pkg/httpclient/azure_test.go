@@ -0,0 +1,108 @@
+package httpclient_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+	"github.com/grafana/grafana-infinity-datasource/pkg/httpclient"
+	"github.com/grafana/grafana-infinity-datasource/pkg/models"
+	"github.com/grafana/grafana-plugin-sdk-go/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAzureADAuth(t *testing.T) {
+	getData := func(t *testing.T, client *http.Client, url string) string {
+		t.Helper()
+		res, err := client.Get(url)
+		require.NoError(t, err)
+		defer func() { _ = res.Body.Close() }()
+		body, _ := io.ReadAll(res.Body)
+		return string(body)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/msi/token" {
+			// app service managed identity endpoint
+			require.Equal(t, "secret1", r.Header.Get("X-IDENTITY-HEADER"))
+			resource := r.URL.Query().Get("resource")
+			_, _ = fmt.Fprintf(w, `{"access_token":"token-%s","expires_on":"%d","resource":%q,"token_type":"Bearer"}`, resource, time.Now().Add(time.Hour).Unix(), resource)
+			return
+		}
+		_, _ = fmt.Fprintf(w, `{"authorization":%q}`, r.Header.Get("Authorization"))
+	}))
+	defer server.Close()
+	t.Setenv("IDENTITY_ENDPOINT", server.URL+"/msi/token")
+	t.Setenv("IDENTITY_HEADER", "secret1")
+	ctx := config.WithGrafanaConfig(context.Background(), config.NewGrafanaCfg(map[string]string{httpclient.GrafanaAzureManagedIdentityEnabled: "true"}))
+	t.Run("should request the token for the scope of the host", func(t *testing.T) {
+		client, err := httpclient.GetHTTPClient(ctx, models.InfinitySettings{
+			AuthenticationMethod: models.AuthenticationMethodAzureAD,
+			AzureADSettings:      models.AzureADSettings{AuthType: models.AzureAuthTypeManagedIdentity},
+		})
+		require.NoError(t, err)
+		require.Equal(t, `{"authorization":"Bearer token-https://127.0.0.1"}`, getData(t, client, server.URL+"/data"))
+	})
+	t.Run("should request the token for the scope configured for the host", func(t *testing.T) {
+		client, err := httpclient.GetHTTPClient(ctx, models.InfinitySettings{
+			AuthenticationMethod: models.AuthenticationMethodAzureAD,
+			AzureADSettings: models.AzureADSettings{
+				AuthType: models.AzureAuthTypeManagedIdentity,
+				Scopes:   map[string]string{server.URL: "api://app1/.default"},
+			},
+		})
+		require.NoError(t, err)
+		require.Equal(t, `{"authorization":"Bearer token-api://app1"}`, getData(t, client, server.URL+"/data"))
+	})
+	t.Run("managed identity should be enabled in grafana", func(t *testing.T) {
+		_, err := httpclient.GetHTTPClient(context.Background(), models.InfinitySettings{
+			AuthenticationMethod: models.AuthenticationMethodAzureAD,
+			AzureADSettings:      models.AzureADSettings{AuthType: models.AzureAuthTypeManagedIdentity},
+		})
+		require.ErrorIs(t, err, models.ErrAzureIdentityDisabled)
+	})
+	t.Run("should return error for the invalid client certificate", func(t *testing.T) {
+		_, err := httpclient.GetHTTPClient(context.Background(), models.InfinitySettings{
+			AuthenticationMethod:     models.AuthenticationMethodAzureAD,
+			AzureADSettings:          models.AzureADSettings{AuthType: models.AzureAuthTypeClientCertificate, TenantID: "00000000-0000-0000-0000-000000000000", ClientID: "client1"},
+			AzureADClientCertificate: "foo",
+		})
+		require.ErrorIs(t, err, models.ErrInvalidConfigAzureClientCert)
+	})
+	t.Run("should create the client with the client certificate", func(t *testing.T) {
+		// entra id supports only the RSA certificates
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		require.NoError(t, err)
+		template := &x509.Certificate{SerialNumber: big.NewInt(1), Subject: pkix.Name{CommonName: "client1"}, NotBefore: time.Now(), NotAfter: time.Now().Add(time.Hour)}
+		certBytes, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+		require.NoError(t, err)
+		keyBytes, err := x509.MarshalPKCS8PrivateKey(key)
+		require.NoError(t, err)
+		client, err := httpclient.GetHTTPClient(context.Background(), models.InfinitySettings{
+			AuthenticationMethod: models.AuthenticationMethodAzureAD,
+			AzureADSettings:      models.AzureADSettings{AuthType: models.AzureAuthTypeClientCertificate, TenantID: "00000000-0000-0000-0000-000000000000", ClientID: "client1"},
+			AzureADClientCertificate: string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certBytes})) +
+				string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyBytes})),
+		})
+		require.NoError(t, err)
+		require.NotNil(t, client)
+	})
+}
+
+func TestGetAzureCloudConfiguration(t *testing.T) {
+	require.Equal(t, cloud.AzurePublic, httpclient.GetAzureCloudConfiguration(""))
+	require.Equal(t, cloud.AzurePublic, httpclient.GetAzureCloudConfiguration(models.AzureCloudPublic))
+	require.Equal(t, cloud.AzureGovernment, httpclient.GetAzureCloudConfiguration(models.AzureCloudUSGovernment))
+	require.Equal(t, cloud.AzureChina, httpclient.GetAzureCloudConfiguration(models.AzureCloudChina))
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"strings"
 	"time"
 
 	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
@@ -18,7 +19,6 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/backend/tracing"
 	"github.com/icholy/digest"
 	"golang.org/x/oauth2"
-	"golang.org/x/oauth2/clientcredentials"
 	"golang.org/x/oauth2/jwt"
 )
 
@@ -39,14 +39,44 @@ func GetHTTPClient(ctx context.Context, settings models.InfinitySettings) (*http
 	if err != nil {
 		return httpClient, errors.Join(models.ErrCreatingHTTPClient, err)
 	}
+	httpClient, err = applyOAuthAuthorizationCode(ctx, httpClient, settings)
+	if err != nil {
+		return httpClient, errors.Join(models.ErrCreatingHTTPClient, err)
+	}
 	httpClient, err = applyAWSAuth(ctx, httpClient, settings)
 	if err != nil {
 		return httpClient, errors.Join(models.ErrCreatingHTTPClient, err)
 	}
+	httpClient, err = applyGoogleServiceAccountAuth(ctx, httpClient, settings)
+	if err != nil {
+		return httpClient, errors.Join(models.ErrCreatingHTTPClient, err)
+	}
+	httpClient, err = applyAzureADAuth(ctx, httpClient, settings)
+	if err != nil {
+		return httpClient, errors.Join(models.ErrCreatingHTTPClient, err)
+	}
 	httpClient, err = applySecureSocksProxyConfiguration(ctx, httpClient, settings)
 	if err != nil {
 		return httpClient, errors.Join(models.ErrCreatingHTTPClient, err)
 	}
+	// token exchange wraps the transport already configured with the secure socks proxy
+	httpClient, err = applyTokenExchange(ctx, httpClient, settings)
+	if err != nil {
+		return httpClient, errors.Join(models.ErrCreatingHTTPClient, err)
+	}
+	return httpClient, nil
+}
+
+// GetBaseHTTPClient returns the http client with the TLS, proxy and secure socks proxy settings but without any authentication.
+// Used by the SDK clients such as s3 which authenticate the requests themselves
+func GetBaseHTTPClient(ctx context.Context, settings models.InfinitySettings) (*http.Client, error) {
+	httpClient, err := getBaseHTTPClient(ctx, settings)
+	if err != nil {
+		return nil, errors.Join(models.ErrCreatingHTTPClient, err)
+	}
+	if err := proxy.New(settings.ProxyOpts.ProxyOptions).ConfigureSecureSocksHTTPProxy(httpClient.Transport.(*http.Transport)); err != nil {
+		return nil, errors.Join(models.ErrCreatingHTTPClient, fmt.Errorf("error configuring secure socks proxy. %w", err))
+	}
 	return httpClient, nil
 }
 
@@ -113,29 +143,13 @@ func applyOAuthClientCredentials(ctx context.Context, httpClient *http.Client, s
 	_, span := tracing.DefaultTracer().Start(ctx, "ApplyOAuthClientCredentials")
 	defer span.End()
 	if isOAuthCredentialsConfigured(settings) {
-		oauthConfig := clientcredentials.Config{
-			ClientID:       settings.OAuth2Settings.ClientID,
-			ClientSecret:   settings.OAuth2Settings.ClientSecret,
-			TokenURL:       settings.OAuth2Settings.TokenURL,
-			Scopes:         []string{},
-			EndpointParams: url.Values{},
-			AuthStyle:      settings.OAuth2Settings.AuthStyle,
-		}
-		for _, scope := range settings.OAuth2Settings.Scopes {
-			if scope != "" {
-				oauthConfig.Scopes = append(oauthConfig.Scopes, scope)
-			}
-		}
-		for k, v := range settings.OAuth2Settings.EndpointParams {
-			if k != "" && v != "" {
-				oauthConfig.EndpointParams.Set(k, v)
-			}
-		}
-
 		tokenClient := getOAuthTokenClient(httpClient, settings.OAuth2Settings.TokenHeaders)
 		tokenCtx := context.WithValue(context.Background(), oauth2.HTTPClient, tokenClient)
 
-		tokenSource := oauthConfig.TokenSource(tokenCtx)
+		tokenSource, err := getOAuth2ClientCredentialsTokenSource(tokenCtx, settings)
+		if err != nil {
+			return httpClient, err
+		}
 
 		httpClient = &http.Client{
 			Transport: &oauth2.Transport{
@@ -188,10 +202,76 @@ func applyOAuthJWT(ctx context.Context, httpClient *http.Client, settings models
 	return httpClient, nil
 }
 
+func isGoogleServiceAccountConfigured(settings models.InfinitySettings) bool {
+	return settings.AuthenticationMethod == models.AuthenticationMethodGoogle && settings.GoogleSettings.AuthType != models.GoogleAuthTypeAPIKey
+}
+
+// applyGoogleServiceAccountAuth signs the requests with the access token of the service account. Scopes are derived from the allowed hosts
+func applyGoogleServiceAccountAuth(ctx context.Context, httpClient *http.Client, settings models.InfinitySettings) (*http.Client, error) {
+	_, span := tracing.DefaultTracer().Start(ctx, "ApplyGoogleServiceAccountAuth")
+	defer span.End()
+	if isGoogleServiceAccountConfigured(settings) {
+		key, err := models.ParseGoogleServiceAccountKey(settings.GoogleServiceAccountKey)
+		if err != nil {
+			// invalid keys are reported by the settings validation. so the client is created without the authentication
+			backend.Logger.FromContext(ctx).Warn("invalid google service account key", "err", err.Error())
+			return httpClient, nil
+		}
+		httpClient = withGoogleServiceAccountAuth(httpClient, key, settings.GoogleScopes())
+	}
+	return httpClient, nil
+}
+
+// GetGoogleServiceAccountHTTPClient returns the http client authenticated with the google service account key for the given scopes.
+// Used by the google cloud storage client which doesn't depend on the allowed hosts
+func GetGoogleServiceAccountHTTPClient(ctx context.Context, settings models.InfinitySettings, scopes []string) (*http.Client, error) {
+	key, err := models.ParseGoogleServiceAccountKey(settings.GoogleServiceAccountKey)
+	if err != nil {
+		return nil, err
+	}
+	httpClient, err := GetBaseHTTPClient(ctx, settings)
+	if err != nil {
+		return nil, err
+	}
+	return withGoogleServiceAccountAuth(httpClient, key, scopes), nil
+}
+
+func withGoogleServiceAccountAuth(httpClient *http.Client, key models.GoogleServiceAccountKey, scopes []string) *http.Client {
+	jwtConfig := jwt.Config{
+		Email:        key.ClientEmail,
+		TokenURL:     key.TokenURI,
+		PrivateKey:   []byte(key.PrivateKey),
+		PrivateKeyID: key.PrivateKeyID,
+		Scopes:       scopes,
+	}
+	tokenCtx := context.WithValue(context.Background(), oauth2.HTTPClient, httpClient)
+	return &http.Client{
+		Transport: &oauth2.Transport{
+			Source: jwtConfig.TokenSource(tokenCtx),
+			Base:   httpClient.Transport,
+		},
+		Timeout: httpClient.Timeout,
+	}
+}
+
 func isAwsAuthConfigured(settings models.InfinitySettings) bool {
 	return settings.AuthenticationMethod == models.AuthenticationMethodAWS
 }
 
+// GetAWSRegion returns the region of the AWS settings. When not configured, the region of the grafana host
+// environment (AWS_REGION / AWS_DEFAULT_REGION) is used. This is the case for EKS pods using IRSA
+func GetAWSRegion(ctx context.Context, settings models.InfinitySettings) (string, error) {
+	if region := settings.AWSSettings.ResolveRegion(); region != "" {
+		return region, nil
+	}
+	// access and secret keys and the data sources saved before the auth type was introduced are signed for us-east-2
+	if settings.AWSSettings.UsesLegacyDefaults() {
+		backend.Logger.FromContext(ctx).Warn("AWS region is not configured. signing the requests for the legacy default region", "default_region", models.AWSLegacyDefaultRegion)
+		return models.AWSLegacyDefaultRegion, nil
+	}
+	return "", models.ErrInvalidConfigAWSRegion
+}
+
 func applyAWSAuth(ctx context.Context, httpClient *http.Client, settings models.InfinitySettings) (*http.Client, error) {
 	ctx, span := tracing.DefaultTracer().Start(ctx, "ApplyAWSAuth")
 	defer span.End()
@@ -204,21 +284,28 @@ func applyAWSAuth(ctx context.Context, httpClient *http.Client, settings models.
 		if authType == "" {
 			authType = models.AWSAuthTypeKeys
 		}
-		region := settings.AWSSettings.Region
-		if region == "" {
-			region = "us-east-2"
+		region, err := GetAWSRegion(ctx, settings)
+		if err != nil {
+			return httpClient, backend.DownstreamError(err)
 		}
-		service := settings.AWSSettings.Service
+		service := strings.TrimSpace(settings.AWSSettings.Service)
 		if service == "" {
-			service = "monitoring"
+			if !settings.AWSSettings.UsesLegacyDefaults() {
+				return httpClient, backend.DownstreamError(models.ErrInvalidConfigAWSService)
+			}
+			backend.Logger.FromContext(ctx).Warn("AWS service is not configured. signing the requests for the legacy default service", "default_service", models.AWSLegacyDefaultService)
+			service = models.AWSLegacyDefaultService
 		}
 		httpOptions := httpclient.Options{
 			SigV4: &httpclient.SigV4Config{
-				AccessKey: settings.AWSAccessKey,
-				SecretKey: settings.AWSSecretKey,
-				AuthType:  string(authType),
-				Region:    region,
-				Service:   service,
+				AccessKey:     settings.AWSAccessKey,
+				SecretKey:     settings.AWSSecretKey,
+				AuthType:      string(authType),
+				Profile:       strings.TrimSpace(settings.AWSSettings.Profile),
+				AssumeRoleARN: strings.TrimSpace(settings.AWSSettings.AssumeRoleARN),
+				ExternalID:    settings.AWSSettings.ExternalID,
+				Region:        region,
+				Service:       service,
 			},
 		}
 		acceptHeaderMiddleware := func(req *http.Request) (*http.Response, error) {
@@ -239,12 +326,16 @@ func applySecureSocksProxyConfiguration(ctx context.Context, httpClient *http.Cl
 	if isDigestAuthConfigured(settings) {
 		// if we are using Digest, the Transport is 'digest.Transport' that wraps 'http.Transport'
 		t = t.(*digest.Transport).Transport
-	} else if isOAuthCredentialsConfigured(settings) || isOAuthJWTConfigured(settings) {
+	} else if isOAuthCredentialsConfigured(settings) || isOAuthJWTConfigured(settings) || isOAuthAuthorizationCodeConfigured(settings) {
 		if cht, ok := t.(*oauth2CustomTokenTransport); ok {
 			t = cht.Transport.(*oauth2.Transport).Base
 		} else {
 			t = t.(*oauth2.Transport).Base
 		}
+	} else if ot, ok := t.(*oauth2.Transport); ok && isGoogleServiceAccountConfigured(settings) {
+		t = ot.Base
+	} else if at, ok := t.(*azureADTransport); ok {
+		t = at.Base
 	}
 	// secure socks proxy configuration - checks if enabled inside the function
 	err := proxy.New(settings.ProxyOpts.ProxyOptions).ConfigureSecureSocksHTTPProxy(t.(*http.Transport))
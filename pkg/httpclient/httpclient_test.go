@@ -1,6 +1,7 @@
 package httpclient_test
 
 import (
+	"context"
 	"testing"
 
 	"github.com/grafana/grafana-infinity-datasource/pkg/httpclient"
@@ -46,3 +47,56 @@ func TestGetProxyUrl(t *testing.T) {
 		})
 	}
 }
+
+func TestGetAWSRegion(t *testing.T) {
+	t.Run("should use the configured region", func(t *testing.T) {
+		t.Setenv("AWS_REGION", "eu-west-1")
+		region, err := httpclient.GetAWSRegion(context.Background(), models.InfinitySettings{AWSSettings: models.AWSSettings{AuthType: models.AWSAuthTypeDefault, Region: "us-west-2"}})
+		require.NoError(t, err)
+		require.Equal(t, "us-west-2", region)
+	})
+	t.Run("should use the region of the environment when not configured", func(t *testing.T) {
+		t.Setenv("AWS_REGION", "")
+		t.Setenv("AWS_DEFAULT_REGION", "eu-west-1")
+		region, err := httpclient.GetAWSRegion(context.Background(), models.InfinitySettings{AWSSettings: models.AWSSettings{AuthType: models.AWSAuthTypeDefault}})
+		require.NoError(t, err)
+		require.Equal(t, "eu-west-1", region)
+	})
+	t.Run("should use the legacy default region when the auth type is not configured", func(t *testing.T) {
+		t.Setenv("AWS_REGION", "")
+		t.Setenv("AWS_DEFAULT_REGION", "")
+		region, err := httpclient.GetAWSRegion(context.Background(), models.InfinitySettings{})
+		require.NoError(t, err)
+		require.Equal(t, "us-east-2", region)
+	})
+	t.Run("should use the legacy default region for the keys auth type", func(t *testing.T) {
+		t.Setenv("AWS_REGION", "")
+		t.Setenv("AWS_DEFAULT_REGION", "")
+		region, err := httpclient.GetAWSRegion(context.Background(), models.InfinitySettings{AWSSettings: models.AWSSettings{AuthType: models.AWSAuthTypeKeys}})
+		require.NoError(t, err)
+		require.Equal(t, "us-east-2", region)
+	})
+	t.Run("should return error when the region can't be resolved", func(t *testing.T) {
+		t.Setenv("AWS_REGION", "")
+		t.Setenv("AWS_DEFAULT_REGION", "")
+		_, err := httpclient.GetAWSRegion(context.Background(), models.InfinitySettings{AWSSettings: models.AWSSettings{AuthType: models.AWSAuthTypeDefault}})
+		require.ErrorIs(t, err, models.ErrInvalidConfigAWSRegion)
+	})
+}
+
+func TestGetHTTPClientAWS(t *testing.T) {
+	t.Setenv("AWS_REGION", "")
+	t.Setenv("AWS_DEFAULT_REGION", "")
+	t.Run("should return error when the region is not configured", func(t *testing.T) {
+		_, err := httpclient.GetHTTPClient(context.Background(), models.InfinitySettings{AuthenticationMethod: models.AuthenticationMethodAWS, AWSSettings: models.AWSSettings{AuthType: models.AWSAuthTypeDefault, Service: "execute-api"}})
+		require.ErrorIs(t, err, models.ErrInvalidConfigAWSRegion)
+	})
+	t.Run("should return error when the service is not configured", func(t *testing.T) {
+		_, err := httpclient.GetHTTPClient(context.Background(), models.InfinitySettings{AuthenticationMethod: models.AuthenticationMethodAWS, AWSSettings: models.AWSSettings{AuthType: models.AWSAuthTypeDefault, Region: "us-east-1"}})
+		require.ErrorIs(t, err, models.ErrInvalidConfigAWSService)
+	})
+	t.Run("should sign the requests of the access and secret keys for the default region and service", func(t *testing.T) {
+		_, err := httpclient.GetHTTPClient(context.Background(), models.InfinitySettings{AuthenticationMethod: models.AuthenticationMethodAWS, AWSSettings: models.AWSSettings{AuthType: models.AWSAuthTypeKeys}, AWSAccessKey: "foo", AWSSecretKey: "bar"})
+		require.NoError(t, err)
+	})
+}
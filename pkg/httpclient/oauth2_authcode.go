@@ -0,0 +1,270 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-infinity-datasource/pkg/models"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/httpclient"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/tracing"
+	"github.com/grafana/grafana-plugin-sdk-go/config"
+	"golang.org/x/oauth2"
+)
+
+// OAuth2CallbackPath is the resource path of the data source which receives the authorization code
+const OAuth2CallbackPath = "oauth2/callback"
+
+// OAuth2RefreshTokenSecureKey is the secure json data key of the refresh token
+const OAuth2RefreshTokenSecureKey = "oauth2RefreshToken"
+
+func isOAuthAuthorizationCodeConfigured(settings models.InfinitySettings) bool {
+	return settings.AuthenticationMethod == models.AuthenticationMethodOAuth && settings.OAuth2Settings.OAuth2Type == models.AuthOAuthAuthorizationCode
+}
+
+// GetOAuth2RedirectURL returns the url of the data source resource which receives the authorization code.
+// This url needs to be registered as the redirect url of the client in the authorization server
+func GetOAuth2RedirectURL(appURL string, uid string) string {
+	return fmt.Sprintf("%s/api/datasources/uid/%s/resources/%s", strings.TrimSuffix(appURL, "/"), uid, OAuth2CallbackPath)
+}
+
+func getOAuth2AuthorizationCodeConfig(settings models.InfinitySettings, redirectURL string) *oauth2.Config {
+	oauthConfig := &oauth2.Config{
+		ClientID:     settings.OAuth2Settings.ClientID,
+		ClientSecret: settings.OAuth2Settings.ClientSecret,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:   settings.OAuth2Settings.AuthURL,
+			TokenURL:  settings.OAuth2Settings.TokenURL,
+			AuthStyle: settings.OAuth2Settings.AuthStyle,
+		},
+		RedirectURL: redirectURL,
+		Scopes:      []string{},
+	}
+	for _, scope := range settings.OAuth2Settings.Scopes {
+		if scope != "" {
+			oauthConfig.Scopes = append(oauthConfig.Scopes, scope)
+		}
+	}
+	return oauthConfig
+}
+
+// GetOAuth2AuthorizationCodeURL returns the url of the consent page of the authorization server. The code challenge of
+// the verifier is sent along (PKCE) and offline access is requested to get the refresh token
+func GetOAuth2AuthorizationCodeURL(settings models.InfinitySettings, redirectURL string, state string, verifier string) string {
+	return getOAuth2AuthorizationCodeConfig(settings, redirectURL).AuthCodeURL(state, oauth2.AccessTypeOffline, oauth2.S256ChallengeOption(verifier))
+}
+
+// ExchangeOAuth2AuthorizationCode exchanges the authorization code received by the callback for the tokens
+func ExchangeOAuth2AuthorizationCode(ctx context.Context, settings models.InfinitySettings, redirectURL string, code string, verifier string) (*oauth2.Token, error) {
+	httpClient, err := GetBaseHTTPClient(ctx, settings)
+	if err != nil {
+		return nil, err
+	}
+	tokenCtx := context.WithValue(ctx, oauth2.HTTPClient, getOAuthTokenClient(httpClient, settings.OAuth2Settings.TokenHeaders))
+	return getOAuth2AuthorizationCodeConfig(settings, redirectURL).Exchange(tokenCtx, code, oauth2.VerifierOption(verifier))
+}
+
+func applyOAuthAuthorizationCode(ctx context.Context, httpClient *http.Client, settings models.InfinitySettings) (*http.Client, error) {
+	ctx, span := tracing.DefaultTracer().Start(ctx, "ApplyOAuthAuthorizationCode")
+	defer span.End()
+	if isOAuthAuthorizationCodeConfigured(settings) {
+		tokenClient := getOAuthTokenClient(httpClient, settings.OAuth2Settings.TokenHeaders)
+		tokenCtx := context.WithValue(context.Background(), oauth2.HTTPClient, tokenClient)
+
+		tokenSource := &oauth2RefreshTokenSource{
+			ctx:      tokenCtx,
+			config:   getOAuth2AuthorizationCodeConfig(settings, ""),
+			settings: settings,
+			persist:  getGrafanaRefreshTokenPersister(ctx, settings),
+		}
+
+		httpClient = &http.Client{
+			Transport: &oauth2.Transport{
+				Source: oauth2.ReuseTokenSource(nil, tokenSource),
+				Base:   httpClient.Transport,
+			},
+			Timeout: httpClient.Timeout,
+		}
+		httpClient.Transport = getCustomOAuth2Transport(settings, httpClient)
+	}
+	return httpClient, nil
+}
+
+// oauth2RefreshTokens holds the refresh tokens rotated by the authorization servers until the settings of the data
+// source are updated with the rotated refresh token
+var oauth2RefreshTokens = &oauth2RefreshTokenStore{tokens: map[string]oauth2RotatedRefreshToken{}}
+
+type oauth2RotatedRefreshToken struct {
+	configured string
+	current    string
+	persistErr error
+}
+
+type oauth2RefreshTokenStore struct {
+	mu     sync.Mutex
+	tokens map[string]oauth2RotatedRefreshToken
+}
+
+// get returns the latest refresh token of the data source. The rotated token is ignored once the configured
+// refresh token changes. i.e. after a new authorization or after the rotated token is persisted
+func (s *oauth2RefreshTokenStore) get(uid string, configured string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if token, ok := s.tokens[uid]; ok && token.configured == configured {
+		return token.current
+	}
+	return configured
+}
+
+func (s *oauth2RefreshTokenStore) set(uid string, configured string, current string, persistErr error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokens[uid] = oauth2RotatedRefreshToken{configured: configured, current: current, persistErr: persistErr}
+}
+
+// getPersistError returns the error of saving the latest rotated refresh token of the data source
+func (s *oauth2RefreshTokenStore) getPersistError(uid string, configured string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if token, ok := s.tokens[uid]; ok && token.configured == configured {
+		return token.persistErr
+	}
+	return nil
+}
+
+// oauth2RefreshTokenSource gets the access tokens using the refresh token of the data source and keeps track of
+// the refresh tokens rotated by the authorization server
+type oauth2RefreshTokenSource struct {
+	ctx      context.Context
+	config   *oauth2.Config
+	settings models.InfinitySettings
+	persist  func(ctx context.Context, refreshToken string) error
+}
+
+func (s *oauth2RefreshTokenSource) Token() (*oauth2.Token, error) {
+	logger := backend.Logger.FromContext(s.ctx)
+	configured := s.settings.OAuth2Settings.RefreshToken
+	refreshToken := oauth2RefreshTokens.get(s.settings.UID, configured)
+	if strings.TrimSpace(refreshToken) == "" {
+		return nil, backend.DownstreamError(models.ErrOAuth2AuthorizationRequired)
+	}
+	token, err := s.config.TokenSource(s.ctx, &oauth2.Token{RefreshToken: refreshToken}).Token()
+	if err != nil {
+		return nil, err
+	}
+	if token.RefreshToken != "" && token.RefreshToken != refreshToken {
+		var persistErr error
+		if s.persist == nil {
+			logger.Warn("OAuth2 refresh token rotated by the authorization server is kept in memory only. enable the externalServiceAccounts feature toggle of grafana to persist the refresh token", "datasource_uid", s.settings.UID)
+			persistErr = models.ErrOAuth2RefreshTokenNoSaving
+		} else if err := s.persist(s.ctx, token.RefreshToken); err != nil {
+			logger.Error("error persisting the rotated OAuth2 refresh token", "datasource_uid", s.settings.UID, "error", err.Error())
+			persistErr = err
+		}
+		oauth2RefreshTokens.set(s.settings.UID, configured, token.RefreshToken, persistErr)
+	}
+	return token, nil
+}
+
+// GetOAuth2RefreshTokenPersistError returns the error when the refresh token rotated by the authorization server
+// couldn't be saved in the data source. The rotated refresh token is lost when grafana restarts
+func GetOAuth2RefreshTokenPersistError(settings models.InfinitySettings) error {
+	if !isOAuthAuthorizationCodeConfigured(settings) {
+		return nil
+	}
+	if err := oauth2RefreshTokens.getPersistError(settings.UID, settings.OAuth2Settings.RefreshToken); err != nil {
+		return errors.Join(models.ErrOAuth2RefreshTokenNotSaved, err)
+	}
+	return nil
+}
+
+// GetOAuth2RefreshTokenWarnings returns the warnings about the refresh tokens of the data source which are not saved.
+// This is the case when the latest rotated refresh token couldn't be saved or when the rotated refresh tokens can't
+// be saved at all
+func GetOAuth2RefreshTokenWarnings(ctx context.Context, settings models.InfinitySettings) []string {
+	warnings := []string{}
+	if !isOAuthAuthorizationCodeConfigured(settings) {
+		return warnings
+	}
+	if err := GetOAuth2RefreshTokenPersistError(settings); err != nil {
+		return append(warnings, err.Error())
+	}
+	if getGrafanaRefreshTokenPersister(ctx, settings) == nil {
+		warnings = append(warnings, models.ErrOAuth2RefreshTokenNoSaving.Error())
+	}
+	return warnings
+}
+
+// getGrafanaRefreshTokenPersister returns the function which updates the refresh token in the secure json data of the
+// data source using the grafana API. The service account of the plugin is required. i.e. the externalServiceAccounts
+// feature toggle of grafana. Returns nil when not available
+func getGrafanaRefreshTokenPersister(ctx context.Context, settings models.InfinitySettings) func(ctx context.Context, refreshToken string) error {
+	cfg := config.GrafanaConfigFromContext(ctx)
+	appURL, err := cfg.AppURL()
+	if err != nil || settings.UID == "" {
+		return nil
+	}
+	serviceAccountToken, err := cfg.PluginAppClientSecret()
+	if err != nil {
+		return nil
+	}
+	return func(ctx context.Context, refreshToken string) error {
+		return updateGrafanaDataSourceSecureJSONData(ctx, appURL, serviceAccountToken, settings.UID, map[string]string{OAuth2RefreshTokenSecureKey: refreshToken})
+	}
+}
+
+// updateGrafanaDataSourceSecureJSONData updates the secure json data of the data source. Other settings and secure
+// json data values of the data source are kept as is. The SDK http client is used so that the grafana API is reached
+// with the TLS and the proxy configuration of the grafana host
+func updateGrafanaDataSourceSecureJSONData(ctx context.Context, appURL string, serviceAccountToken string, uid string, secureJSONData map[string]string) error {
+	dataSourceURL := fmt.Sprintf("%s/api/datasources/uid/%s", strings.TrimSuffix(appURL, "/"), uid)
+	timeouts := httpclient.DefaultTimeoutOptions
+	timeouts.Timeout = 30 * time.Second
+	client, err := httpclient.New(httpclient.Options{Timeouts: &timeouts})
+	if err != nil {
+		return err
+	}
+	doRequest := func(method string, body []byte) ([]byte, error) {
+		req, err := http.NewRequestWithContext(ctx, method, dataSourceURL, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+serviceAccountToken)
+		req.Header.Set("Content-Type", "application/json")
+		res, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer func() { _ = res.Body.Close() }()
+		bodyBytes, err := io.ReadAll(res.Body)
+		if err != nil {
+			return nil, err
+		}
+		if res.StatusCode >= http.StatusBadRequest {
+			return nil, fmt.Errorf("grafana API responded with status code %d for %s %s", res.StatusCode, method, dataSourceURL)
+		}
+		return bodyBytes, nil
+	}
+	bodyBytes, err := doRequest(http.MethodGet, nil)
+	if err != nil {
+		return err
+	}
+	dataSource := map[string]any{}
+	if err := json.Unmarshal(bodyBytes, &dataSource); err != nil {
+		return fmt.Errorf("error parsing the data source. %w", err)
+	}
+	dataSource["secureJsonData"] = secureJSONData
+	if bodyBytes, err = json.Marshal(dataSource); err != nil {
+		return err
+	}
+	_, err = doRequest(http.MethodPut, bodyBytes)
+	return err
+}
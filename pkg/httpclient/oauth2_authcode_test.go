@@ -0,0 +1,155 @@
+package httpclient_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/grafana/grafana-infinity-datasource/pkg/httpclient"
+	"github.com/grafana/grafana-infinity-datasource/pkg/models"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOAuth2AuthorizationCode(t *testing.T) {
+	// getServer returns the authorization server which rotates the refresh token on every refresh and the grafana API
+	// which receives the rotated refresh tokens
+	getServer := func(t *testing.T, persisted *[]string) (*httptest.Server, *[]string) {
+		t.Helper()
+		var mu sync.Mutex
+		refreshTokens := []string{}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+			w.Header().Set("Content-Type", "application/json")
+			switch r.URL.Path {
+			case "/token":
+				require.Nil(t, r.ParseForm())
+				require.Equal(t, "refresh_token", r.Form.Get("grant_type"))
+				refreshTokens = append(refreshTokens, r.Form.Get("refresh_token"))
+				_, _ = fmt.Fprintf(w, `{"access_token":"access%d","token_type":"Bearer","expires_in":1,"refresh_token":"refresh%d"}`, len(refreshTokens), len(refreshTokens))
+			case "/api/datasources/uid/ds-rotation", "/api/datasources/uid/ds-rotation-failure":
+				if r.Header.Get("Authorization") != "Bearer sa-token" {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				if r.Method == http.MethodGet {
+					_, _ = w.Write([]byte(`{"uid":"ds-rotation","name":"infinity","type":"yesoreyeram-infinity-datasource","version":3,"jsonData":{"auth_method":"oauth2"}}`))
+					return
+				}
+				body, _ := io.ReadAll(r.Body)
+				dataSource := map[string]any{}
+				require.Nil(t, json.Unmarshal(body, &dataSource))
+				require.Equal(t, "infinity", dataSource["name"])
+				require.Equal(t, float64(3), dataSource["version"])
+				*persisted = append(*persisted, dataSource["secureJsonData"].(map[string]any)["oauth2RefreshToken"].(string))
+			default:
+				_, _ = fmt.Fprintf(w, `{"authorization":%q}`, r.Header.Get("Authorization"))
+			}
+		}))
+		return server, &refreshTokens
+	}
+	getSettings := func(uid string, tokenURL string) models.InfinitySettings {
+		return models.InfinitySettings{
+			UID:                  uid,
+			AuthenticationMethod: models.AuthenticationMethodOAuth,
+			OAuth2Settings: models.OAuth2Settings{
+				OAuth2Type:   models.AuthOAuthAuthorizationCode,
+				ClientID:     "client",
+				ClientSecret: "secret",
+				AuthURL:      tokenURL + "/authorize",
+				TokenURL:     tokenURL + "/token",
+				RefreshToken: "refresh0",
+			},
+		}
+	}
+	t.Run("should refresh the access token and use the rotated refresh tokens", func(t *testing.T) {
+		persisted := []string{}
+		server, refreshTokens := getServer(t, &persisted)
+		defer server.Close()
+		ctx := config.WithGrafanaConfig(context.Background(), config.NewGrafanaCfg(map[string]string{
+			config.AppURL:          server.URL,
+			config.AppClientSecret: "sa-token",
+		}))
+		client, err := httpclient.GetHTTPClient(ctx, getSettings("ds-rotation", server.URL))
+		require.NoError(t, err)
+		for i := 1; i <= 2; i++ {
+			res, err := client.Get(server.URL + "/data")
+			require.NoError(t, err)
+			body, _ := io.ReadAll(res.Body)
+			_ = res.Body.Close()
+			require.Equal(t, fmt.Sprintf(`{"authorization":"Bearer access%d"}`, i), string(body))
+		}
+		require.Equal(t, []string{"refresh0", "refresh1"}, *refreshTokens)
+		require.Equal(t, []string{"refresh1", "refresh2"}, persisted)
+		require.NoError(t, httpclient.GetOAuth2RefreshTokenPersistError(getSettings("ds-rotation", server.URL)))
+		require.Empty(t, httpclient.GetOAuth2RefreshTokenWarnings(ctx, getSettings("ds-rotation", server.URL)))
+	})
+	t.Run("should report the rotated refresh token which couldn't be saved", func(t *testing.T) {
+		server, _ := getServer(t, nil)
+		defer server.Close()
+		// the grafana API rejects the service account token
+		ctx := config.WithGrafanaConfig(context.Background(), config.NewGrafanaCfg(map[string]string{
+			config.AppURL:          server.URL,
+			config.AppClientSecret: "invalid-token",
+		}))
+		settings := getSettings("ds-rotation-failure", server.URL)
+		client, err := httpclient.GetHTTPClient(ctx, settings)
+		require.NoError(t, err)
+		res, err := client.Get(server.URL + "/data")
+		require.NoError(t, err)
+		_ = res.Body.Close()
+		err = httpclient.GetOAuth2RefreshTokenPersistError(settings)
+		require.ErrorIs(t, err, models.ErrOAuth2RefreshTokenNotSaved)
+		require.Equal(t, []string{err.Error()}, httpclient.GetOAuth2RefreshTokenWarnings(ctx, settings))
+		// authorizing the data source again replaces the refresh token which couldn't be saved
+		settings.OAuth2Settings.RefreshToken = "refresh-new"
+		require.NoError(t, httpclient.GetOAuth2RefreshTokenPersistError(settings))
+	})
+	t.Run("should keep the rotated refresh tokens in memory when the grafana API is not available", func(t *testing.T) {
+		server, refreshTokens := getServer(t, nil)
+		defer server.Close()
+		client, err := httpclient.GetHTTPClient(context.Background(), getSettings("ds-memory", server.URL))
+		require.NoError(t, err)
+		for range 2 {
+			res, err := client.Get(server.URL + "/data")
+			require.NoError(t, err)
+			_ = res.Body.Close()
+		}
+		// the instance created again with the same settings continues with the rotated refresh token
+		client, err = httpclient.GetHTTPClient(context.Background(), getSettings("ds-memory", server.URL))
+		require.NoError(t, err)
+		res, err := client.Get(server.URL + "/data")
+		require.NoError(t, err)
+		_ = res.Body.Close()
+		require.Equal(t, []string{"refresh0", "refresh1", "refresh2"}, *refreshTokens)
+		require.ErrorIs(t, httpclient.GetOAuth2RefreshTokenPersistError(getSettings("ds-memory", server.URL)), models.ErrOAuth2RefreshTokenNoSaving)
+	})
+	t.Run("should warn when the rotated refresh tokens can't be saved", func(t *testing.T) {
+		require.Equal(t, []string{models.ErrOAuth2RefreshTokenNoSaving.Error()}, httpclient.GetOAuth2RefreshTokenWarnings(context.Background(), getSettings("ds-not-rotated", "http://localhost")))
+	})
+	t.Run("should return error when the authorization is not completed", func(t *testing.T) {
+		settings := getSettings("ds-unauthorized", "http://localhost")
+		settings.OAuth2Settings.RefreshToken = ""
+		client, err := httpclient.GetHTTPClient(context.Background(), settings)
+		require.NoError(t, err)
+		_, err = client.Get("http://localhost/data")
+		require.ErrorIs(t, err, models.ErrOAuth2AuthorizationRequired)
+		require.True(t, backend.IsDownstreamError(err))
+	})
+}
+
+func TestGetOAuth2AuthorizationCodeURL(t *testing.T) {
+	settings := models.InfinitySettings{OAuth2Settings: models.OAuth2Settings{ClientID: "client", AuthURL: "https://foo.com/authorize", Scopes: []string{"read", "offline_access", ""}}}
+	redirectURL := httpclient.GetOAuth2RedirectURL("https://grafana.foo.com/", "ds1")
+	require.Equal(t, "https://grafana.foo.com/api/datasources/uid/ds1/resources/oauth2/callback", redirectURL)
+	got := httpclient.GetOAuth2AuthorizationCodeURL(settings, redirectURL, "state1", "verifier1")
+	require.Contains(t, got, "https://foo.com/authorize?access_type=offline&client_id=client&code_challenge=")
+	require.Contains(t, got, "&code_challenge_method=S256&redirect_uri=https%3A%2F%2Fgrafana.foo.com%2Fapi%2Fdatasources%2Fuid%2Fds1%2Fresources%2Foauth2%2Fcallback&response_type=code&scope=read+offline_access&state=state1")
+}
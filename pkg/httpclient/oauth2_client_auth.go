@@ -0,0 +1,138 @@
+package httpclient
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/grafana/grafana-infinity-datasource/pkg/models"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+const (
+	clientAssertionTypeJWTBearer = "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+	// clientAssertionLifetime is the validity of the private_key_jwt client assertion. A new assertion is signed for every token request
+	clientAssertionLifetime = 5 * time.Minute
+)
+
+func getOAuth2ClientCredentialsConfig(settings models.InfinitySettings) *clientcredentials.Config {
+	oauthConfig := &clientcredentials.Config{
+		ClientID:       settings.OAuth2Settings.ClientID,
+		ClientSecret:   settings.OAuth2Settings.ClientSecret,
+		TokenURL:       settings.OAuth2Settings.TokenURL,
+		Scopes:         []string{},
+		EndpointParams: url.Values{},
+		AuthStyle:      settings.OAuth2Settings.AuthStyle,
+	}
+	for _, scope := range settings.OAuth2Settings.Scopes {
+		if scope != "" {
+			oauthConfig.Scopes = append(oauthConfig.Scopes, scope)
+		}
+	}
+	for k, v := range settings.OAuth2Settings.EndpointParams {
+		if k != "" && v != "" {
+			oauthConfig.EndpointParams.Set(k, v)
+		}
+	}
+	if settings.OAuth2Settings.ClientAuthMethod == models.OAuth2ClientAuthPrivateKeyJWT || settings.OAuth2Settings.ClientAuthMethod == models.OAuth2ClientAuthTLS {
+		// the client is authenticated by the client assertion or the TLS client certificate. client id is sent in the params
+		oauthConfig.ClientSecret = ""
+		oauthConfig.AuthStyle = oauth2.AuthStyleInParams
+	}
+	return oauthConfig
+}
+
+// getOAuth2ClientCredentialsTokenSource returns the token source of the client credentials grant type using the
+// client authentication method of the settings
+func getOAuth2ClientCredentialsTokenSource(tokenCtx context.Context, settings models.InfinitySettings) (oauth2.TokenSource, error) {
+	switch settings.OAuth2Settings.ClientAuthMethod {
+	case models.OAuth2ClientAuthPrivateKeyJWT:
+		key, err := models.ParsePEMPrivateKey(settings.OAuth2Settings.PrivateKey)
+		if err != nil {
+			return nil, err
+		}
+		signingMethod, err := getClientAssertionSigningMethod(key)
+		if err != nil {
+			return nil, err
+		}
+		return oauth2.ReuseTokenSource(nil, &privateKeyJWTTokenSource{ctx: tokenCtx, settings: settings, key: key, signingMethod: signingMethod}), nil
+	case models.OAuth2ClientAuthTLS:
+		// the TLS client certificate is presented by the transport of the token client. See GetTLSConfigFromSettings
+		if !settings.TLSClientAuth || settings.TLSClientCert == "" || settings.TLSClientKey == "" {
+			return nil, models.ErrInvalidConfigOAuth2ClientCert
+		}
+	}
+	return getOAuth2ClientCredentialsConfig(settings).TokenSource(tokenCtx), nil
+}
+
+func getClientAssertionSigningMethod(key crypto.Signer) (jwt.SigningMethod, error) {
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		return jwt.SigningMethodRS256, nil
+	case *ecdsa.PrivateKey:
+		switch k.Curve.Params().BitSize {
+		case 256:
+			return jwt.SigningMethodES256, nil
+		case 384:
+			return jwt.SigningMethodES384, nil
+		case 521:
+			return jwt.SigningMethodES512, nil
+		}
+	case ed25519.PrivateKey:
+		return jwt.SigningMethodEdDSA, nil
+	}
+	return nil, fmt.Errorf("%w. unsupported private key type %T", models.ErrInvalidConfigOAuth2PrivateKey, key)
+}
+
+// privateKeyJWTTokenSource gets the access tokens using the client credentials grant type and authenticates the client
+// using the JWT signed by the private key of the client (RFC 7523)
+type privateKeyJWTTokenSource struct {
+	ctx           context.Context
+	settings      models.InfinitySettings
+	key           crypto.Signer
+	signingMethod jwt.SigningMethod
+}
+
+func (s *privateKeyJWTTokenSource) Token() (*oauth2.Token, error) {
+	assertion, err := s.getClientAssertion()
+	if err != nil {
+		return nil, err
+	}
+	oauthConfig := getOAuth2ClientCredentialsConfig(s.settings)
+	oauthConfig.EndpointParams.Set("client_assertion_type", clientAssertionTypeJWTBearer)
+	oauthConfig.EndpointParams.Set("client_assertion", assertion)
+	return oauthConfig.Token(s.ctx)
+}
+
+func (s *privateKeyJWTTokenSource) getClientAssertion() (string, error) {
+	audience := s.settings.OAuth2Settings.ClientAssertionAudience
+	if audience == "" {
+		audience = s.settings.OAuth2Settings.TokenURL
+	}
+	now := time.Now()
+	token := jwt.NewWithClaims(s.signingMethod, jwt.RegisteredClaims{
+		Issuer:    s.settings.OAuth2Settings.ClientID,
+		Subject:   s.settings.OAuth2Settings.ClientID,
+		Audience:  jwt.ClaimStrings{audience},
+		ID:        rand.Text(),
+		IssuedAt:  jwt.NewNumericDate(now),
+		NotBefore: jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(clientAssertionLifetime)),
+	})
+	if s.settings.OAuth2Settings.PrivateKeyID != "" {
+		token.Header["kid"] = s.settings.OAuth2Settings.PrivateKeyID
+	}
+	assertion, err := token.SignedString(s.key)
+	if err != nil {
+		return "", fmt.Errorf("error signing the OAuth2 client assertion. %w", err)
+	}
+	return assertion, nil
+}
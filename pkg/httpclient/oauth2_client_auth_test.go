@@ -0,0 +1,180 @@
+package httpclient_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/grafana/grafana-infinity-datasource/pkg/httpclient"
+	"github.com/grafana/grafana-infinity-datasource/pkg/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOAuth2ClientCredentialsClientAuth(t *testing.T) {
+	getData := func(t *testing.T, client *http.Client, url string) string {
+		t.Helper()
+		res, err := client.Get(url)
+		require.NoError(t, err)
+		defer func() { _ = res.Body.Close() }()
+		body, _ := io.ReadAll(res.Body)
+		return string(body)
+	}
+	t.Run("private_key_jwt should authenticate the client using the signed client assertion", func(t *testing.T) {
+		rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		require.NoError(t, err)
+		ecKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+		require.NoError(t, err)
+		ecKeyBytes, err := x509.MarshalPKCS8PrivateKey(ecKey)
+		require.NoError(t, err)
+		tests := []struct {
+			name       string
+			privateKey string
+			publicKey  any
+			audience   string
+			alg        string
+		}{
+			{
+				name:       "RSA key",
+				privateKey: string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)})),
+				publicKey:  &rsaKey.PublicKey,
+				alg:        "RS256",
+			},
+			{
+				name:       "EC key with custom audience",
+				privateKey: string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: ecKeyBytes})),
+				publicKey:  &ecKey.PublicKey,
+				audience:   "https://idp.foo.com",
+				alg:        "ES384",
+			},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				var server *httptest.Server
+				server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.Header().Set("Content-Type", "application/json")
+					if r.URL.Path != "/token" {
+						_, _ = fmt.Fprintf(w, `{"authorization":%q}`, r.Header.Get("Authorization"))
+						return
+					}
+					require.Nil(t, r.ParseForm())
+					require.Empty(t, r.Header.Get("Authorization"))
+					require.Equal(t, "client_credentials", r.Form.Get("grant_type"))
+					require.Equal(t, "client1", r.Form.Get("client_id"))
+					require.Empty(t, r.Form.Get("client_secret"))
+					require.Equal(t, "urn:ietf:params:oauth:client-assertion-type:jwt-bearer", r.Form.Get("client_assertion_type"))
+					wantAudience := tt.audience
+					if wantAudience == "" {
+						wantAudience = server.URL + "/token"
+					}
+					claims := &jwt.RegisteredClaims{}
+					token, err := jwt.ParseWithClaims(r.Form.Get("client_assertion"), claims, func(token *jwt.Token) (any, error) {
+						require.Equal(t, tt.alg, token.Method.Alg())
+						require.Equal(t, "key1", token.Header["kid"])
+						return tt.publicKey, nil
+					}, jwt.WithAudience(wantAudience), jwt.WithIssuer("client1"), jwt.WithSubject("client1"), jwt.WithExpirationRequired())
+					require.NoError(t, err)
+					require.True(t, token.Valid)
+					require.NotEmpty(t, claims.ID)
+					_, _ = w.Write([]byte(`{"access_token":"access1","token_type":"Bearer","expires_in":3600}`))
+				}))
+				defer server.Close()
+				client, err := httpclient.GetHTTPClient(context.Background(), models.InfinitySettings{
+					AuthenticationMethod: models.AuthenticationMethodOAuth,
+					OAuth2Settings: models.OAuth2Settings{
+						OAuth2Type:              models.AuthOAuthTypeClientCredentials,
+						ClientAuthMethod:        models.OAuth2ClientAuthPrivateKeyJWT,
+						ClientID:                "client1",
+						ClientSecret:            "ignored",
+						TokenURL:                server.URL + "/token",
+						PrivateKey:              tt.privateKey,
+						PrivateKeyID:            "key1",
+						ClientAssertionAudience: tt.audience,
+					},
+				})
+				require.NoError(t, err)
+				require.Equal(t, `{"authorization":"Bearer access1"}`, getData(t, client, server.URL+"/data"))
+			})
+		}
+	})
+	t.Run("tls_client_auth should authenticate the client using the TLS client certificate", func(t *testing.T) {
+		clientCert, clientKey := getTestClientCertificate(t)
+		server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			require.Len(t, r.TLS.PeerCertificates, 1)
+			require.Equal(t, "client1", r.TLS.PeerCertificates[0].Subject.CommonName)
+			if r.URL.Path != "/token" {
+				_, _ = fmt.Fprintf(w, `{"authorization":%q}`, r.Header.Get("Authorization"))
+				return
+			}
+			require.Nil(t, r.ParseForm())
+			require.Empty(t, r.Header.Get("Authorization"))
+			require.Equal(t, "client1", r.Form.Get("client_id"))
+			require.Empty(t, r.Form.Get("client_secret"))
+			_, _ = w.Write([]byte(`{"access_token":"access1","token_type":"Bearer","expires_in":3600}`))
+		}))
+		server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+		server.StartTLS()
+		defer server.Close()
+		client, err := httpclient.GetHTTPClient(context.Background(), models.InfinitySettings{
+			AuthenticationMethod: models.AuthenticationMethodOAuth,
+			InsecureSkipVerify:   true,
+			TLSClientAuth:        true,
+			TLSClientCert:        clientCert,
+			TLSClientKey:         clientKey,
+			OAuth2Settings: models.OAuth2Settings{
+				OAuth2Type:       models.AuthOAuthTypeClientCredentials,
+				ClientAuthMethod: models.OAuth2ClientAuthTLS,
+				ClientID:         "client1",
+				TokenURL:         server.URL + "/token",
+			},
+		})
+		require.NoError(t, err)
+		require.Equal(t, `{"authorization":"Bearer access1"}`, getData(t, client, server.URL+"/data"))
+	})
+	t.Run("should return error when the client authentication is not configured", func(t *testing.T) {
+		_, err := httpclient.GetHTTPClient(context.Background(), models.InfinitySettings{
+			AuthenticationMethod: models.AuthenticationMethodOAuth,
+			OAuth2Settings:       models.OAuth2Settings{OAuth2Type: models.AuthOAuthTypeClientCredentials, ClientAuthMethod: models.OAuth2ClientAuthTLS},
+		})
+		require.ErrorIs(t, err, models.ErrInvalidConfigOAuth2ClientCert)
+		_, err = httpclient.GetHTTPClient(context.Background(), models.InfinitySettings{
+			AuthenticationMethod: models.AuthenticationMethodOAuth,
+			OAuth2Settings:       models.OAuth2Settings{OAuth2Type: models.AuthOAuthTypeClientCredentials, ClientAuthMethod: models.OAuth2ClientAuthPrivateKeyJWT},
+		})
+		require.ErrorIs(t, err, models.ErrInvalidConfigOAuth2PrivateKey)
+	})
+}
+
+// getTestClientCertificate returns the PEM encoded self signed client certificate and the key
+func getTestClientCertificate(t *testing.T) (string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "client1"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	certBytes, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyBytes, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certBytes})), string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyBytes}))
+}
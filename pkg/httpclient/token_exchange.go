@@ -0,0 +1,155 @@
+package httpclient
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-infinity-datasource/pkg/models"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/tracing"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+const (
+	grantTypeTokenExchange = "urn:ietf:params:oauth:grant-type:token-exchange"
+	grantTypeJWTBearer     = "urn:ietf:params:oauth:grant-type:jwt-bearer"
+	// tokenExchangeDefaultTTL is used when the token endpoint doesn't return the expiry of the exchanged token
+	tokenExchangeDefaultTTL = 5 * time.Minute
+)
+
+func isTokenExchangeConfigured(settings models.InfinitySettings) bool {
+	return settings.ForwardOauthIdentity && settings.TokenExchangeSettings.Type != ""
+}
+
+func applyTokenExchange(ctx context.Context, httpClient *http.Client, settings models.InfinitySettings) (*http.Client, error) {
+	_, span := tracing.DefaultTracer().Start(ctx, "ApplyTokenExchange")
+	defer span.End()
+	if isTokenExchangeConfigured(settings) {
+		httpClient = &http.Client{
+			Transport: &tokenExchangeTransport{
+				Base:        httpClient.Transport,
+				settings:    settings.TokenExchangeSettings,
+				tokenClient: httpClient,
+				tokens:      map[string]*oauth2.Token{},
+			},
+			Timeout: httpClient.Timeout,
+		}
+	}
+	return httpClient, nil
+}
+
+// tokenExchangeTransport replaces the forwarded OAuth identity of the user with the token of the downstream API.
+// The exchanged tokens are cached per forwarded token, i.e. per user, until they expire
+type tokenExchangeTransport struct {
+	Base        http.RoundTripper
+	settings    models.TokenExchangeSettings
+	tokenClient *http.Client
+	mu          sync.Mutex
+	tokens      map[string]*oauth2.Token
+}
+
+func (t *tokenExchangeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	subjectToken := t.getSubjectToken(req)
+	if subjectToken == "" {
+		return nil, backend.DownstreamError(models.ErrTokenExchangeSubjectTokenRequired)
+	}
+	token, err := t.getToken(req.Context(), subjectToken)
+	if err != nil {
+		backend.Logger.FromContext(req.Context()).Error("error exchanging the forwarded OAuth identity", "error", err.Error())
+		return nil, backend.DownstreamError(errors.Join(models.ErrTokenExchangeFailed, err))
+	}
+	req2 := req.Clone(req.Context())
+	// the id token of the user is meant for grafana and not for the downstream API
+	req2.Header.Del("X-Id-Token")
+	token.SetAuthHeader(req2)
+	return t.Base.RoundTrip(req2)
+}
+
+// getSubjectToken returns the forwarded token exchanged for the token of the downstream API
+func (t *tokenExchangeTransport) getSubjectToken(req *http.Request) string {
+	if t.settings.Type == models.TokenExchangeTypeRFC8693 && t.settings.SubjectTokenType == models.TokenTypeIDToken {
+		return strings.TrimSpace(req.Header.Get("X-Id-Token"))
+	}
+	scheme, token, ok := strings.Cut(strings.TrimSpace(req.Header.Get("Authorization")), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
+func (t *tokenExchangeTransport) getToken(ctx context.Context, subjectToken string) (*oauth2.Token, error) {
+	hash := sha256.Sum256([]byte(subjectToken))
+	key := hex.EncodeToString(hash[:])
+	t.mu.Lock()
+	token, ok := t.tokens[key]
+	t.mu.Unlock()
+	if ok && token.Valid() {
+		return token, nil
+	}
+	token, err := t.exchange(ctx, subjectToken)
+	if err != nil {
+		return nil, err
+	}
+	if token.Expiry.IsZero() {
+		token.Expiry = time.Now().Add(tokenExchangeDefaultTTL)
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for k, v := range t.tokens {
+		if !v.Valid() {
+			delete(t.tokens, k)
+		}
+	}
+	t.tokens[key] = token
+	return token, nil
+}
+
+// exchange requests the token of the downstream API from the token endpoint. Both RFC 8693 and the Microsoft Entra ID
+// on-behalf-of flow are client credentials requests with a different grant type and the forwarded token as parameter
+func (t *tokenExchangeTransport) exchange(ctx context.Context, subjectToken string) (*oauth2.Token, error) {
+	oauthConfig := clientcredentials.Config{
+		ClientID:       t.settings.ClientID,
+		ClientSecret:   t.settings.ClientSecret,
+		TokenURL:       t.settings.TokenURL,
+		Scopes:         []string{},
+		EndpointParams: url.Values{},
+		AuthStyle:      t.settings.AuthStyle,
+	}
+	for _, scope := range t.settings.Scopes {
+		if scope != "" {
+			oauthConfig.Scopes = append(oauthConfig.Scopes, scope)
+		}
+	}
+	switch t.settings.Type {
+	case models.TokenExchangeTypeRFC8693:
+		subjectTokenType := t.settings.SubjectTokenType
+		if subjectTokenType == "" {
+			subjectTokenType = models.TokenTypeAccessToken
+		}
+		oauthConfig.EndpointParams.Set("grant_type", grantTypeTokenExchange)
+		oauthConfig.EndpointParams.Set("subject_token", subjectToken)
+		oauthConfig.EndpointParams.Set("subject_token_type", subjectTokenType)
+		if t.settings.Audience != "" {
+			oauthConfig.EndpointParams.Set("audience", t.settings.Audience)
+		}
+		if t.settings.Resource != "" {
+			oauthConfig.EndpointParams.Set("resource", t.settings.Resource)
+		}
+	case models.TokenExchangeTypeOnBehalfOf:
+		oauthConfig.EndpointParams.Set("grant_type", grantTypeJWTBearer)
+		oauthConfig.EndpointParams.Set("assertion", subjectToken)
+		oauthConfig.EndpointParams.Set("requested_token_use", "on_behalf_of")
+	default:
+		return nil, fmt.Errorf("%w %s", models.ErrInvalidConfigTokenExchangeType, t.settings.Type)
+	}
+	return oauthConfig.Token(context.WithValue(ctx, oauth2.HTTPClient, t.tokenClient))
+}
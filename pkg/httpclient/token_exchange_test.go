@@ -0,0 +1,141 @@
+package httpclient_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/grafana/grafana-infinity-datasource/pkg/httpclient"
+	"github.com/grafana/grafana-infinity-datasource/pkg/models"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+func TestTokenExchange(t *testing.T) {
+	getServer := func(t *testing.T, checkTokenRequest func(t *testing.T, r *http.Request) string) (*httptest.Server, *[]string) {
+		t.Helper()
+		var mu sync.Mutex
+		exchanged := []string{}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+			w.Header().Set("Content-Type", "application/json")
+			if r.URL.Path == "/token" {
+				require.Nil(t, r.ParseForm())
+				subjectToken := checkTokenRequest(t, r)
+				if subjectToken == "invalid" {
+					w.WriteHeader(http.StatusBadRequest)
+					_, _ = w.Write([]byte(`{"error":"invalid_grant"}`))
+					return
+				}
+				exchanged = append(exchanged, subjectToken)
+				_, _ = fmt.Fprintf(w, `{"access_token":"api-%s","issued_token_type":"urn:ietf:params:oauth:token-type:access_token","token_type":"Bearer","expires_in":3600}`, subjectToken)
+				return
+			}
+			_, _ = fmt.Fprintf(w, `{"authorization":%q,"id_token":%q}`, r.Header.Get("Authorization"), r.Header.Get("X-Id-Token"))
+		}))
+		return server, &exchanged
+	}
+	doRequest := func(t *testing.T, client *http.Client, url string, headers map[string]string) (string, error) {
+		t.Helper()
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, url, nil)
+		require.NoError(t, err)
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		res, err := client.Do(req)
+		if err != nil {
+			return "", err
+		}
+		defer func() { _ = res.Body.Close() }()
+		body, _ := io.ReadAll(res.Body)
+		return string(body), nil
+	}
+	t.Run("should exchange the forwarded token using RFC 8693 and cache the token per user", func(t *testing.T) {
+		server, exchanged := getServer(t, func(t *testing.T, r *http.Request) string {
+			require.Equal(t, "urn:ietf:params:oauth:grant-type:token-exchange", r.Form.Get("grant_type"))
+			require.Equal(t, "urn:ietf:params:oauth:token-type:access_token", r.Form.Get("subject_token_type"))
+			require.Equal(t, "api://downstream", r.Form.Get("audience"))
+			require.Equal(t, "read", r.Form.Get("scope"))
+			require.Equal(t, "client1", r.Form.Get("client_id"))
+			require.Equal(t, "secret1", r.Form.Get("client_secret"))
+			return r.Form.Get("subject_token")
+		})
+		defer server.Close()
+		client, err := httpclient.GetHTTPClient(context.Background(), models.InfinitySettings{
+			AuthenticationMethod: models.AuthenticationMethodForwardOauth,
+			ForwardOauthIdentity: true,
+			TokenExchangeSettings: models.TokenExchangeSettings{
+				Type:         models.TokenExchangeTypeRFC8693,
+				TokenURL:     server.URL + "/token",
+				ClientID:     "client1",
+				ClientSecret: "secret1",
+				AuthStyle:    oauth2.AuthStyleInParams,
+				Audience:     "api://downstream",
+				Scopes:       []string{"read", ""},
+			},
+		})
+		require.NoError(t, err)
+		for range 2 {
+			body, err := doRequest(t, client, server.URL+"/data", map[string]string{"Authorization": "Bearer user1", "X-Id-Token": "id1"})
+			require.NoError(t, err)
+			require.Equal(t, `{"authorization":"Bearer api-user1","id_token":""}`, body)
+		}
+		body, err := doRequest(t, client, server.URL+"/data", map[string]string{"Authorization": "Bearer user2"})
+		require.NoError(t, err)
+		require.Equal(t, `{"authorization":"Bearer api-user2","id_token":""}`, body)
+		require.Equal(t, []string{"user1", "user2"}, *exchanged)
+	})
+	t.Run("should exchange the forwarded token using the on-behalf-of flow", func(t *testing.T) {
+		server, exchanged := getServer(t, func(t *testing.T, r *http.Request) string {
+			require.Equal(t, "urn:ietf:params:oauth:grant-type:jwt-bearer", r.Form.Get("grant_type"))
+			require.Equal(t, "on_behalf_of", r.Form.Get("requested_token_use"))
+			require.Equal(t, "api://downstream/.default", r.Form.Get("scope"))
+			username, password, ok := r.BasicAuth()
+			require.True(t, ok)
+			require.Equal(t, "client1", username)
+			require.Equal(t, "secret1", password)
+			return r.Form.Get("assertion")
+		})
+		defer server.Close()
+		client, err := httpclient.GetHTTPClient(context.Background(), models.InfinitySettings{
+			AuthenticationMethod: models.AuthenticationMethodForwardOauth,
+			ForwardOauthIdentity: true,
+			TokenExchangeSettings: models.TokenExchangeSettings{
+				Type:         models.TokenExchangeTypeOnBehalfOf,
+				TokenURL:     server.URL + "/token",
+				ClientID:     "client1",
+				ClientSecret: "secret1",
+				AuthStyle:    oauth2.AuthStyleInHeader,
+				Scopes:       []string{"api://downstream/.default"},
+			},
+		})
+		require.NoError(t, err)
+		body, err := doRequest(t, client, server.URL+"/data", map[string]string{"Authorization": "Bearer user1"})
+		require.NoError(t, err)
+		require.Equal(t, `{"authorization":"Bearer api-user1","id_token":""}`, body)
+		require.Equal(t, []string{"user1"}, *exchanged)
+	})
+	t.Run("should return error when the token is not forwarded or the exchange fails", func(t *testing.T) {
+		server, _ := getServer(t, func(t *testing.T, r *http.Request) string { return r.Form.Get("subject_token") })
+		defer server.Close()
+		client, err := httpclient.GetHTTPClient(context.Background(), models.InfinitySettings{
+			AuthenticationMethod:  models.AuthenticationMethodForwardOauth,
+			ForwardOauthIdentity:  true,
+			TokenExchangeSettings: models.TokenExchangeSettings{Type: models.TokenExchangeTypeRFC8693, TokenURL: server.URL + "/token"},
+		})
+		require.NoError(t, err)
+		_, err = doRequest(t, client, server.URL+"/data", nil)
+		require.ErrorIs(t, err, models.ErrTokenExchangeSubjectTokenRequired)
+		require.True(t, backend.IsDownstreamError(err))
+		_, err = doRequest(t, client, server.URL+"/data", map[string]string{"Authorization": "Bearer invalid"})
+		require.ErrorIs(t, err, models.ErrTokenExchangeFailed)
+		require.ErrorContains(t, err, "invalid_grant")
+		require.True(t, backend.IsDownstreamError(err))
+	})
+}
@@ -0,0 +1,32 @@
+package infinity
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/grafana/grafana-infinity-datasource/pkg/httpclient"
+	"github.com/grafana/grafana-infinity-datasource/pkg/models"
+)
+
+// NewAzureBlobClient creates the azure blob storage client using the shared key, SAS token or the Entra ID credentials of the settings
+func NewAzureBlobClient(ctx context.Context, settings models.InfinitySettings) (*azblob.Client, error) {
+	clientOptions := azcore.ClientOptions{Cloud: httpclient.GetAzureCloudConfiguration(settings.AzureBlobCloudType)}
+	switch settings.AzureBlobAuthType {
+	case models.AzureBlobAuthTypeSASToken:
+		return azblob.NewClientWithNoCredential(settings.AzureBlobSASServiceURL(), &azblob.ClientOptions{ClientOptions: clientOptions})
+	case models.AzureBlobAuthTypeClientSecret, models.AzureBlobAuthTypeManagedIdentity, models.AzureBlobAuthTypeWorkloadIdentity:
+		cred, err := httpclient.GetAzureTokenCredential(ctx, settings.AzureBlobCredentials(), clientOptions)
+		if err != nil {
+			return nil, err
+		}
+		return azblob.NewClient(settings.AzureBlobServiceURL(), cred, &azblob.ClientOptions{ClientOptions: clientOptions})
+	default:
+		cred, err := azblob.NewSharedKeyCredential(settings.AzureBlobAccountName, settings.AzureBlobAccountKey)
+		if err != nil {
+			return nil, errors.New("invalid azure blob credentials")
+		}
+		return azblob.NewClientWithSharedKeyCredential(settings.AzureBlobServiceURL(), cred, &azblob.ClientOptions{ClientOptions: clientOptions})
+	}
+}
@@ -0,0 +1,76 @@
+package infinity
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/grafana/grafana-infinity-datasource/pkg/httpclient"
+	"github.com/grafana/grafana-infinity-datasource/pkg/models"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewAzureBlobClient(t *testing.T) {
+	t.Run("should send the SAS token with the blob requests", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "/my-container/users.csv", r.URL.Path)
+			require.Equal(t, "foo", r.URL.Query().Get("sig"))
+			require.Empty(t, r.Header.Get("Authorization"))
+			w.Header().Set("Content-Length", "8")
+			_, _ = w.Write([]byte("name\nfoo"))
+		}))
+		defer server.Close()
+		settings := models.InfinitySettings{
+			AuthenticationMethod: models.AuthenticationMethodAzureBlob,
+			AzureBlobAuthType:    models.AzureBlobAuthTypeSASToken,
+			AzureBlobSASToken:    server.URL + "/my-container?sv=2022-11-02&sr=c&sp=rl&sig=foo",
+		}
+		azClient, err := NewAzureBlobClient(context.Background(), settings)
+		require.NoError(t, err)
+		client := Client{Settings: settings, AzureBlobClient: azClient}
+		out, _, err := client.GetResultsWithInfo(context.Background(), &backend.PluginContext{}, models.Query{Type: models.QueryTypeCSV, Source: "azure-blob", AzBlobContainerName: "my-container", AzBlobName: "users.csv"}, nil)
+		require.NoError(t, err)
+		require.Equal(t, "name\nfoo", out)
+	})
+	t.Run("should create the client with the service principal", func(t *testing.T) {
+		azClient, err := NewAzureBlobClient(context.Background(), models.InfinitySettings{
+			AzureBlobAuthType:     models.AzureBlobAuthTypeClientSecret,
+			AzureBlobAccountName:  "foo",
+			AzureBlobTenantID:     "00000000-0000-0000-0000-000000000000",
+			AzureBlobClientID:     "client1",
+			AzureBlobClientSecret: "secret1",
+		})
+		require.NoError(t, err)
+		require.Equal(t, "https://foo.blob.core.windows.net/", azClient.URL())
+	})
+	t.Run("managed identity should be enabled in grafana", func(t *testing.T) {
+		settings := models.InfinitySettings{AzureBlobAuthType: models.AzureBlobAuthTypeManagedIdentity, AzureBlobAccountName: "foo"}
+		_, err := NewAzureBlobClient(context.Background(), settings)
+		require.ErrorIs(t, err, models.ErrAzureIdentityDisabled)
+		ctx := config.WithGrafanaConfig(context.Background(), config.NewGrafanaCfg(map[string]string{httpclient.GrafanaAzureManagedIdentityEnabled: "true"}))
+		azClient, err := NewAzureBlobClient(ctx, settings)
+		require.NoError(t, err)
+		require.NotNil(t, azClient)
+	})
+	t.Run("workload identity should be enabled in grafana", func(t *testing.T) {
+		tokenFile := filepath.Join(t.TempDir(), "token")
+		require.NoError(t, os.WriteFile(tokenFile, []byte("token"), 0600))
+		settings := models.InfinitySettings{AzureBlobAuthType: models.AzureBlobAuthTypeWorkloadIdentity, AzureBlobAccountName: "foo"}
+		_, err := NewAzureBlobClient(context.Background(), settings)
+		require.ErrorIs(t, err, models.ErrAzureIdentityDisabled)
+		ctx := config.WithGrafanaConfig(context.Background(), config.NewGrafanaCfg(map[string]string{
+			httpclient.GrafanaAzureWorkloadIdentityEnabled: "true",
+			httpclient.GrafanaAzureWorkloadIdentityTenant:  "00000000-0000-0000-0000-000000000000",
+			httpclient.GrafanaAzureWorkloadIdentityClient:  "client1",
+			httpclient.GrafanaAzureWorkloadIdentityToken:   tokenFile,
+		}))
+		azClient, err := NewAzureBlobClient(ctx, settings)
+		require.NoError(t, err)
+		require.NotNil(t, azClient)
+	})
+}
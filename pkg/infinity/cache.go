@@ -0,0 +1,241 @@
+package infinity
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/textproto"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-infinity-datasource/pkg/models"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+)
+
+const (
+	defaultResponseCacheMaxEntries = 100
+	// responses larger than this are not cached to keep the memory usage of the cache predictable
+	maxCacheableResponseSize = 10 * 1024 * 1024
+)
+
+// responseCache is an in-memory LRU cache of the upstream responses. Entries are stored per datasource instance
+// and expire based on the configured TTL and the Cache-Control header of the response
+type responseCache struct {
+	mu         sync.Mutex
+	ttl        time.Duration
+	maxEntries int
+	entries    map[string]*list.Element
+	lru        *list.List
+}
+
+type cachedResponse struct {
+	key          string
+	body         []byte
+	headers      http.Header
+	statusCode   int
+	expiresAt    time.Time
+	etag         string
+	lastModified string
+}
+
+func (r *cachedResponse) isFresh() bool {
+	return time.Now().Before(r.expiresAt)
+}
+
+func (r *cachedResponse) canRevalidate() bool {
+	return r.etag != "" || r.lastModified != ""
+}
+
+func newResponseCache(settings models.InfinitySettings) *responseCache {
+	if settings.ResponseCacheTTLInSeconds <= 0 {
+		return nil
+	}
+	maxEntries := settings.ResponseCacheMaxEntries
+	if maxEntries <= 0 {
+		maxEntries = defaultResponseCacheMaxEntries
+	}
+	return &responseCache{
+		ttl:        time.Duration(settings.ResponseCacheTTLInSeconds) * time.Second,
+		maxEntries: maxEntries,
+		entries:    map[string]*list.Element{},
+		lru:        list.New(),
+	}
+}
+
+func (c *responseCache) get(key string) (*cachedResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := element.Value.(*cachedResponse)
+	if !entry.isFresh() && !entry.canRevalidate() {
+		c.lru.Remove(element)
+		delete(c.entries, key)
+		return nil, false
+	}
+	c.lru.MoveToFront(element)
+	return entry, true
+}
+
+// store adds the response to the cache if the Cache-Control header of the response allows it
+func (c *responseCache) store(key string, body []byte, headers http.Header, statusCode int, perUser bool) {
+	if len(body) > maxCacheableResponseSize {
+		return
+	}
+	ttl, ok := c.getTTL(headers, perUser)
+	if !ok {
+		return
+	}
+	entry := &cachedResponse{
+		key:          key,
+		body:         body,
+		headers:      headers.Clone(),
+		statusCode:   statusCode,
+		expiresAt:    time.Now().Add(ttl),
+		etag:         headers.Get("ETag"),
+		lastModified: headers.Get("Last-Modified"),
+	}
+	if ttl <= 0 && !entry.canRevalidate() {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if element, ok := c.entries[key]; ok {
+		element.Value = entry
+		c.lru.MoveToFront(element)
+		return
+	}
+	c.entries[key] = c.lru.PushFront(entry)
+	for c.lru.Len() > c.maxEntries {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cachedResponse).key)
+	}
+}
+
+// revalidated extends the expiry of the entry after the upstream confirmed with 304 Not Modified that the entry is still valid
+func (c *responseCache) revalidated(entry *cachedResponse, headers http.Header, perUser bool) {
+	ttl, ok := c.getTTL(headers, perUser)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	element, exists := c.entries[entry.key]
+	if !exists {
+		return
+	}
+	if !ok {
+		c.lru.Remove(element)
+		delete(c.entries, entry.key)
+		return
+	}
+	updated := *entry
+	updated.expiresAt = time.Now().Add(ttl)
+	if etag := headers.Get("ETag"); etag != "" {
+		updated.etag = etag
+	}
+	element.Value = &updated
+}
+
+// getTTL returns how long the response can be served from the cache. Configured TTL is the upper limit
+// and the max-age from the Cache-Control header can only reduce it. Returns false if the response must not be stored
+func (c *responseCache) getTTL(headers http.Header, perUser bool) (time.Duration, bool) {
+	ttl := c.ttl
+	for _, directive := range strings.Split(strings.ToLower(strings.Join(headers.Values("Cache-Control"), ",")), ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(directive), "=")
+		switch name {
+		case "no-store":
+			return 0, false
+		case "private":
+			// private responses are only stored when the cache key is specific to the user
+			if !perUser {
+				return 0, false
+			}
+		case "no-cache":
+			ttl = 0
+		case "max-age":
+			if maxAge, err := strconv.Atoi(strings.Trim(value, `"`)); err == nil {
+				ttl = min(ttl, time.Duration(maxAge)*time.Second)
+			}
+		}
+	}
+	return ttl, true
+}
+
+type responseCacheDisabledKey struct{}
+
+// WithoutResponseCache returns a context which skips the response cache. This is used for the requests such as
+// health check where the upstream must be always called
+func WithoutResponseCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, responseCacheDisabledKey{}, true)
+}
+
+// getResponseCacheKey returns the cache key for the request. The key is based on the method, fully resolved url, body and
+// the headers of the request. Headers containing credentials are not part of the key as they are same for all the requests
+// of the datasource. When the user identity is forwarded, the user is added to the key so that responses are not shared between
+// the users. Returns false when the request can't be cached
+func (client *Client) getResponseCacheKey(ctx context.Context, pCtx *backend.PluginContext, req *http.Request) (key string, perUser bool, ok bool) {
+	if client.cache == nil || req == nil || ctx.Value(responseCacheDisabledKey{}) != nil {
+		return "", false, false
+	}
+	if req.Method != http.MethodGet && req.Method != http.MethodPost {
+		return "", false, false
+	}
+	hash := sha256.New()
+	// req.URL is constructed from GetQueryURL and already includes the query params, secure query fields and the api key
+	writeCacheKeyPart(hash, req.Method, req.URL.String())
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		// body can't be read without consuming it
+		return "", false, false
+	}
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return "", false, false
+		}
+		bodyBytes, err := io.ReadAll(body)
+		if err != nil {
+			return "", false, false
+		}
+		writeCacheKeyPart(hash, string(bodyBytes))
+	}
+	secretHeaders := []string{HeaderKeyAuthorization, HeaderKeyIdToken, "Cookie", "Traceparent", "Tracestate", "Baggage"}
+	for key := range client.Settings.CustomHeaders {
+		secretHeaders = append(secretHeaders, textproto.CanonicalMIMEHeaderKey(key))
+	}
+	if client.Settings.AuthenticationMethod == models.AuthenticationMethodApiKey && client.Settings.ApiKeyType == models.ApiKeyTypeHeader {
+		secretHeaders = append(secretHeaders, textproto.CanonicalMIMEHeaderKey(client.Settings.ApiKeyKey))
+	}
+	headerKeys := []string{}
+	for key := range req.Header {
+		if !slices.Contains(secretHeaders, key) {
+			headerKeys = append(headerKeys, key)
+		}
+	}
+	slices.Sort(headerKeys)
+	for _, key := range headerKeys {
+		writeCacheKeyPart(hash, key, strings.Join(req.Header.Values(key), ","))
+	}
+	if client.Settings.ForwardOauthIdentity || client.Settings.AuthenticationMethod == models.AuthenticationMethodForwardOauth || len(client.Settings.KeepCookies) > 0 {
+		if pCtx == nil || pCtx.User == nil || (pCtx.User.Login == "" && pCtx.User.Email == "") {
+			// response depends on the user but the user is unknown
+			return "", false, false
+		}
+		writeCacheKeyPart(hash, strconv.FormatInt(pCtx.OrgID, 10), pCtx.User.Login, pCtx.User.Email)
+		perUser = true
+	}
+	return hex.EncodeToString(hash.Sum(nil)), perUser, true
+}
+
+func writeCacheKeyPart(w io.Writer, parts ...string) {
+	for _, part := range parts {
+		// length prefix avoids collisions between the parts such as ("ab","c") and ("a","bc")
+		_, _ = io.WriteString(w, strconv.Itoa(len(part))+":"+part+";")
+	}
+}
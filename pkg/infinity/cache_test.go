@@ -0,0 +1,141 @@
+package infinity
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/grafana/grafana-infinity-datasource/pkg/models"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponseCache(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/no-store":
+			w.Header().Set("Cache-Control", "no-store")
+		case "/private":
+			w.Header().Set("Cache-Control", "private, max-age=60")
+		case "/etag":
+			w.Header().Set("Cache-Control", "no-cache")
+			w.Header().Set("ETag", `"v1"`)
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		case "/error":
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		_, _ = w.Write([]byte(`{"path":"` + r.URL.Path + `","user":"` + r.Header.Get("Authorization") + `"}`))
+	}))
+	defer server.Close()
+	getClient := func(settings models.InfinitySettings) *Client {
+		if settings.ResponseCacheTTLInSeconds == 0 {
+			settings.ResponseCacheTTLInSeconds = 60
+		}
+		return &Client{Settings: settings, HttpClient: server.Client(), cache: newResponseCache(settings)}
+	}
+	getQuery := func(path string) models.Query {
+		return models.Query{Type: models.QueryTypeJSON, Source: "url", URL: server.URL + path, URLOptions: models.URLOptions{Method: http.MethodGet}}
+	}
+	run := func(t *testing.T, client *Client, pCtx *backend.PluginContext, query models.Query, requestHeaders map[string]string) any {
+		t.Helper()
+		o, _, _, err := client.GetResults(context.Background(), pCtx, query, requestHeaders)
+		require.NoError(t, err)
+		return o
+	}
+	t.Run("should serve identical requests from the cache", func(t *testing.T) {
+		hits.Store(0)
+		client := getClient(models.InfinitySettings{})
+		first := run(t, client, &backend.PluginContext{}, getQuery("/data"), nil)
+		second := run(t, client, &backend.PluginContext{}, getQuery("/data"), nil)
+		require.Equal(t, first, second)
+		require.Equal(t, int32(1), hits.Load())
+	})
+	t.Run("should not cache when disabled", func(t *testing.T) {
+		hits.Store(0)
+		client := &Client{Settings: models.InfinitySettings{}, HttpClient: server.Client(), cache: newResponseCache(models.InfinitySettings{})}
+		run(t, client, &backend.PluginContext{}, getQuery("/data"), nil)
+		run(t, client, &backend.PluginContext{}, getQuery("/data"), nil)
+		require.Equal(t, int32(2), hits.Load())
+	})
+	t.Run("should use the url, method, body and headers as the cache key", func(t *testing.T) {
+		hits.Store(0)
+		client := getClient(models.InfinitySettings{AllowDangerousHTTPMethods: true})
+		run(t, client, &backend.PluginContext{}, getQuery("/data"), nil)
+		withParams := getQuery("/data")
+		withParams.URLOptions.Params = []models.URLOptionKeyValuePair{{Key: "foo", Value: "bar"}}
+		run(t, client, &backend.PluginContext{}, withParams, nil)
+		withHeaders := getQuery("/data")
+		withHeaders.URLOptions.Headers = []models.URLOptionKeyValuePair{{Key: "X-Foo", Value: "bar"}}
+		run(t, client, &backend.PluginContext{}, withHeaders, nil)
+		post := getQuery("/data")
+		post.URLOptions.Method = http.MethodPost
+		post.URLOptions.Body = `{"page":1}`
+		run(t, client, &backend.PluginContext{}, post, nil)
+		post.URLOptions.Body = `{"page":2}`
+		run(t, client, &backend.PluginContext{}, post, nil)
+		run(t, client, &backend.PluginContext{}, post, nil)
+		put := post
+		put.URLOptions.Method = http.MethodPut
+		run(t, client, &backend.PluginContext{}, put, nil)
+		run(t, client, &backend.PluginContext{}, put, nil)
+		require.Equal(t, int32(7), hits.Load())
+	})
+	t.Run("should not cache when the response does not allow it", func(t *testing.T) {
+		hits.Store(0)
+		client := getClient(models.InfinitySettings{IgnoreStatusCodeCheck: true})
+		for _, path := range []string{"/no-store", "/private", "/error"} {
+			run(t, client, &backend.PluginContext{}, getQuery(path), nil)
+			run(t, client, &backend.PluginContext{}, getQuery(path), nil)
+		}
+		require.Equal(t, int32(6), hits.Load())
+	})
+	t.Run("should revalidate the response using etag", func(t *testing.T) {
+		hits.Store(0)
+		client := getClient(models.InfinitySettings{})
+		first := run(t, client, &backend.PluginContext{}, getQuery("/etag"), nil)
+		second := run(t, client, &backend.PluginContext{}, getQuery("/etag"), nil)
+		require.Equal(t, map[string]any{"path": "/etag", "user": ""}, first)
+		require.Equal(t, first, second)
+		require.Equal(t, int32(2), hits.Load())
+	})
+	t.Run("should cache the responses per user when forwarding the identity", func(t *testing.T) {
+		hits.Store(0)
+		client := getClient(models.InfinitySettings{ForwardOauthIdentity: true})
+		userA := &backend.PluginContext{OrgID: 1, User: &backend.User{Login: "a"}}
+		userB := &backend.PluginContext{OrgID: 1, User: &backend.User{Login: "b"}}
+		a1 := run(t, client, userA, getQuery("/private"), map[string]string{"Authorization": "Bearer a"})
+		b1 := run(t, client, userB, getQuery("/private"), map[string]string{"Authorization": "Bearer b"})
+		a2 := run(t, client, userA, getQuery("/private"), map[string]string{"Authorization": "Bearer a"})
+		require.Equal(t, a1, a2)
+		require.NotEqual(t, a1, b1)
+		require.Equal(t, int32(2), hits.Load())
+		run(t, client, &backend.PluginContext{}, getQuery("/private"), nil)
+		run(t, client, &backend.PluginContext{}, getQuery("/private"), nil)
+		require.Equal(t, int32(4), hits.Load())
+	})
+	t.Run("should evict the least recently used responses", func(t *testing.T) {
+		hits.Store(0)
+		client := getClient(models.InfinitySettings{ResponseCacheMaxEntries: 2})
+		for _, path := range []string{"/a", "/b", "/a", "/c", "/a", "/b"} {
+			run(t, client, &backend.PluginContext{}, getQuery(path), nil)
+		}
+		require.Equal(t, int32(4), hits.Load())
+	})
+	t.Run("should skip the cache when requested", func(t *testing.T) {
+		hits.Store(0)
+		client := getClient(models.InfinitySettings{})
+		for range 2 {
+			_, _, _, err := client.GetResults(WithoutResponseCache(context.Background()), &backend.PluginContext{}, getQuery("/data"), nil)
+			require.NoError(t, err)
+		}
+		require.Equal(t, int32(2), hits.Load())
+	})
+}
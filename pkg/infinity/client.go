@@ -8,13 +8,16 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"mime"
 	"mime/multipart"
 	"net/http"
 	"net/url"
+	"path"
 	"strings"
 	"time"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/grafana/grafana-infinity-datasource/pkg/httpclient"
 	"github.com/grafana/grafana-infinity-datasource/pkg/models"
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
@@ -26,7 +29,11 @@ type Client struct {
 	Settings        models.InfinitySettings
 	HttpClient      *http.Client
 	AzureBlobClient *azblob.Client
+	S3Client        *s3.Client
+	GCSClient       *GCSClient
 	IsMock          bool
+	cache           *responseCache
+	rateLimiter     *rateLimiter
 }
 
 func NewClient(ctx context.Context, settings models.InfinitySettings) (client *Client, err error) {
@@ -49,30 +56,18 @@ func NewClient(ctx context.Context, settings models.InfinitySettings) (client *C
 		return client, err
 	}
 	client = &Client{
-		Settings:   settings,
-		HttpClient: httpClient,
+		Settings:    settings,
+		HttpClient:  httpClient,
+		cache:       newResponseCache(settings),
+		rateLimiter: newRateLimiter(settings),
 	}
 	if settings.AuthenticationMethod == models.AuthenticationMethodAzureBlob {
-		cred, err := azblob.NewSharedKeyCredential(settings.AzureBlobAccountName, settings.AzureBlobAccountKey)
-		if err != nil {
-			span.RecordError(err)
-			span.SetStatus(500, err.Error())
-			logger.Error("invalid azure blob credentials", "datasource uid", settings.UID, "datasource name", settings.Name)
-			return client, errors.New("invalid azure blob credentials")
-		}
-		clientUrl := "https://%s.blob.core.windows.net/"
-		if settings.AzureBlobAccountUrl != "" {
-			clientUrl = settings.AzureBlobAccountUrl
-		}
-		if strings.Contains(clientUrl, "%s") {
-			clientUrl = fmt.Sprintf(clientUrl, settings.AzureBlobAccountName)
-		}
-		azClient, err := azblob.NewClientWithSharedKeyCredential(clientUrl, cred, nil)
+		azClient, err := NewAzureBlobClient(ctx, settings)
 		if err != nil {
 			span.RecordError(err)
 			span.SetStatus(500, err.Error())
 			logger.Error("error creating azure blob client", "datasource uid", settings.UID, "datasource name", settings.Name)
-			return client, fmt.Errorf("error creating azure blob client. %s", err)
+			return client, fmt.Errorf("error creating azure blob client. %w", err)
 		}
 		if azClient == nil {
 			span.RecordError(errors.New("invalid/empty azure blob client"))
@@ -82,6 +77,24 @@ func NewClient(ctx context.Context, settings models.InfinitySettings) (client *C
 		}
 		client.AzureBlobClient = azClient
 	}
+	if settings.AuthenticationMethod == models.AuthenticationMethodAWS {
+		s3Client, err := NewS3Client(ctx, settings)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(500, err.Error())
+			logger.Error("error creating s3 client", "datasource uid", settings.UID, "datasource name", settings.Name)
+			return client, fmt.Errorf("error creating s3 client. %w", err)
+		}
+		client.S3Client = s3Client
+	}
+	if settings.AuthenticationMethod == models.AuthenticationMethodGoogle && settings.GoogleSettings.AuthType != models.GoogleAuthTypeAPIKey {
+		gcsClient, err := NewGCSClient(ctx, settings)
+		if err != nil {
+			// invalid keys are reported by the settings validation. so the client is created without the google cloud storage client
+			logger.Warn("error creating google cloud storage client", "err", err.Error(), "datasource uid", settings.UID, "datasource name", settings.Name)
+		}
+		client.GCSClient = gcsClient
+	}
 	if settings.IsMock {
 		client.IsMock = true
 	}
@@ -100,27 +113,46 @@ func replaceSect(input string, settings models.InfinitySettings, includeSect boo
 	return input
 }
 
-func (client *Client) req(ctx context.Context, pCtx *backend.PluginContext, url string, body io.Reader, settings models.InfinitySettings, query models.Query, requestHeaders map[string]string) (obj any, statusCode int, duration time.Duration, err error) {
+func (client *Client) req(ctx context.Context, pCtx *backend.PluginContext, url string, body io.Reader, settings models.InfinitySettings, query models.Query, requestHeaders map[string]string) (obj any, info ResponseInfo, err error) {
 	ctx, span := tracing.DefaultTracer().Start(ctx, "client.req")
 	logger := backend.Logger.FromContext(ctx)
 	defer span.End()
 	req, err := GetRequest(ctx, pCtx, settings, body, query, requestHeaders, true)
 	if err != nil {
-		return nil, http.StatusInternalServerError, 0, backend.DownstreamError(fmt.Errorf("error preparing request. %w", err))
+		return nil, ResponseInfo{StatusCode: http.StatusInternalServerError}, backend.DownstreamError(fmt.Errorf("error preparing request. %w", err))
 	}
 	if req == nil {
-		return nil, http.StatusInternalServerError, 0, backend.DownstreamError(errors.New("error preparing request. invalid request constructed"))
+		return nil, ResponseInfo{StatusCode: http.StatusInternalServerError}, backend.DownstreamError(errors.New("error preparing request. invalid request constructed"))
 	}
 	startTime := time.Now()
 	if !CanAllowURL(req.URL.String(), settings.AllowedHosts) {
 		logger.Debug("url is not in the allowed list. make sure to match the base URL with the settings", "url", req.URL.String())
-		return nil, http.StatusUnauthorized, 0, backend.DownstreamError(models.ErrInvalidConfigHostNotAllowed)
+		return nil, ResponseInfo{StatusCode: http.StatusUnauthorized}, backend.DownstreamError(models.ErrInvalidConfigHostNotAllowed)
+	}
+	cacheKey, perUser, cacheable := client.getResponseCacheKey(ctx, pCtx, req)
+	var cachedRes *cachedResponse
+	if cacheable {
+		if entry, ok := client.cache.get(cacheKey); ok {
+			if entry.isFresh() {
+				logger.Debug("serving response from cache", "host", req.URL.Hostname(), "url_path", req.URL.Path, "method", req.Method, "type", query.Type)
+				obj, err = decodeResponseBody(entry.body, entry.headers, query, url, logger)
+				return obj, ResponseInfo{Headers: entry.headers, StatusCode: entry.statusCode, Duration: time.Since(startTime)}, err
+			}
+			cachedRes = entry
+			if entry.etag != "" {
+				req.Header.Set("If-None-Match", entry.etag)
+			}
+			if entry.lastModified != "" {
+				req.Header.Set("If-Modified-Since", entry.lastModified)
+			}
+		}
 	}
 	logger.Debug("requesting URL", "host", req.URL.Hostname(), "url_path", req.URL.Path, "method", req.Method, "type", query.Type)
-	res, err := client.HttpClient.Do(req)
-	duration = time.Since(startTime)
-	logger.Debug("received response", "host", req.URL.Hostname(), "url_path", req.URL.Path, "method", req.Method, "type", query.Type, "duration_ms", duration.Milliseconds())
+	res, attempts, rateLimitWait, err := client.do(ctx, req, logger)
+	info = ResponseInfo{StatusCode: http.StatusInternalServerError, Duration: time.Since(startTime), Attempts: attempts, RateLimitWait: rateLimitWait}
+	logger.Debug("received response", "host", req.URL.Hostname(), "url_path", req.URL.Path, "method", req.Method, "type", query.Type, "duration_ms", info.Duration.Milliseconds(), "attempts", attempts)
 	if res != nil {
+		info.StatusCode = res.StatusCode
 		defer func() {
 			if err := res.Body.Close(); err != nil {
 				logger.Warn("error closing response body", "error", err.Error())
@@ -132,65 +164,139 @@ func (client *Client) req(ctx context.Context, pCtx *backend.PluginContext, url
 			logger.Debug("error getting response from server", "url", url, "method", req.Method, "error", err.Error(), "status code", res.StatusCode)
 			// Infinity can query anything and users are responsible for ensuring that endpoint/auth is correct
 			// therefore any incoming error is considered downstream
-			return nil, res.StatusCode, duration, backend.DownstreamError(fmt.Errorf("error getting response from %s", url))
+			return nil, info, backend.DownstreamError(fmt.Errorf("error getting response from %s", url))
 		}
 		if errors.Is(err, context.Canceled) {
 			logger.Debug("request cancelled", "url", url, "method", req.Method)
-			return nil, http.StatusInternalServerError, duration, backend.DownstreamError(err)
+			return nil, info, backend.DownstreamError(err)
 		}
 		logger.Debug("error getting response from server. no response received", "url", url, "error", err.Error())
-		return nil, http.StatusInternalServerError, duration, backend.DownstreamError(fmt.Errorf("error getting response from url %s. no response received. Error: %w", url, err))
+		return nil, info, backend.DownstreamError(fmt.Errorf("error getting response from url %s. no response received. Error: %w", url, err))
 	}
 	if res == nil {
 		logger.Debug("invalid response from server and also no error", "url", url, "method", req.Method)
-		return nil, http.StatusInternalServerError, duration, backend.DownstreamError(fmt.Errorf("invalid response received for the URL %s", url))
+		return nil, info, backend.DownstreamError(fmt.Errorf("invalid response received for the URL %s", url))
+	}
+	if cachedRes != nil && res.StatusCode == http.StatusNotModified {
+		logger.Debug("cached response revalidated", "host", req.URL.Hostname(), "url_path", req.URL.Path, "method", req.Method, "type", query.Type)
+		client.cache.revalidated(cachedRes, res.Header, perUser)
+		obj, err = decodeResponseBody(cachedRes.body, cachedRes.headers, query, url, logger)
+		info.Headers, info.StatusCode = cachedRes.headers, cachedRes.statusCode
+		return obj, info, err
 	}
 	if res.StatusCode >= http.StatusBadRequest && !settings.IgnoreStatusCodeCheck {
 		err = fmt.Errorf("%w\nstatus code : %s", models.ErrUnsuccessfulHTTPResponseStatus, res.Status)
 		// Infinity can query anything and users are responsible for ensuring that endpoint/auth is correct
 		// therefore any incoming error is considered downstream
-		return nil, res.StatusCode, duration, backend.DownstreamError(err)
+		return nil, info, backend.DownstreamError(err)
+	}
+	if isNDJSONBackendQuery(query) {
+		// ndjson is parsed line by line while reading the body, so the response is only buffered when it needs to be cached
+		var cacheBody bytes.Buffer
+		var parsed *ndjsonResponse
+		err := readBody(res, logger, func(reader io.Reader) (err error) {
+			if cacheable && res.StatusCode == http.StatusOK {
+				reader = io.TeeReader(reader, &cacheBody)
+			}
+			parsed, err = parseNDJSON(reader)
+			return err
+		})
+		if err != nil {
+			logger.Debug("error reading ndjson response body", "url", url, "error", err.Error())
+			return nil, info, backend.DownstreamError(err)
+		}
+		if len(parsed.records) == 0 && len(parsed.malformedLines) == 0 {
+			logger.Debug("empty response body received", "url", url)
+			return nil, info, backend.DownstreamError(fmt.Errorf("empty response body received for the URL %s", url))
+		}
+		if cacheable && res.StatusCode == http.StatusOK {
+			client.cache.store(cacheKey, cacheBody.Bytes(), res.Header, res.StatusCode, perUser)
+		}
+		info.Headers = res.Header
+		return parsed, info, nil
 	}
 	bodyBytes, err := getBodyBytes(res, logger)
 	if err != nil {
 		logger.Debug("error reading response body", "url", url, "error", err.Error())
-		return nil, res.StatusCode, duration, backend.DownstreamError(err)
+		return nil, info, backend.DownstreamError(err)
 	}
 	if len(bodyBytes) == 0 {
 		logger.Debug("empty response body received", "url", url)
-		return nil, res.StatusCode, duration, backend.DownstreamError(fmt.Errorf("empty response body received for the URL %s", url))
+		return nil, info, backend.DownstreamError(fmt.Errorf("empty response body received for the URL %s", url))
 	}
 	bodyBytes = removeBOMContent(bodyBytes)
-	if CanParseAsJSON(query.Type, res.Header) {
-		var out any
-		err := json.Unmarshal(bodyBytes, &out)
+	if cacheable && res.StatusCode == http.StatusOK {
+		client.cache.store(cacheKey, bodyBytes, res.Header, res.StatusCode, perUser)
+	}
+	obj, err = decodeResponseBody(bodyBytes, res.Header, query, url, logger)
+	info.Headers = res.Header
+	return obj, info, err
+}
+
+func decodeResponseBody(bodyBytes []byte, headers http.Header, query models.Query, url string, logger log.Logger) (any, error) {
+	if isBinaryQuery(query) {
+		return bodyBytes, nil
+	}
+	if isNDJSONBackendQuery(query) {
+		out, err := parseNDJSON(bytes.NewReader(bodyBytes))
+		if err != nil {
+			return nil, backend.DownstreamError(err)
+		}
+		return out, nil
+	}
+	if CanParseAsJSON(query.Type, headers) {
+		out, err := unmarshalJSONBody(bodyBytes, query)
 		if err != nil {
 			err = fmt.Errorf("%w. %w", models.ErrParsingResponseBodyAsJson, err)
 			err = backend.DownstreamError(err)
 			logger.Debug("error un-marshaling JSON response", "url", url, "error", err.Error())
 		}
-		return out, res.StatusCode, duration, err
+		return out, err
+	}
+	return string(bodyBytes), nil
+}
+
+// unmarshalJSONBody decodes the json response body. UQL and GROQ queries get the validated raw json instead, so that the
+// engines decode it keeping the key order of the document same as the inline data
+func unmarshalJSONBody(bodyBytes []byte, query models.Query) (any, error) {
+	if isUQLQuery(query) || isGROQQuery(query) {
+		var raw json.RawMessage
+		if err := json.Unmarshal(bodyBytes, &raw); err != nil {
+			return nil, err
+		}
+		return raw, nil
 	}
-	return string(bodyBytes), res.StatusCode, duration, err
+	var out any
+	err := json.Unmarshal(bodyBytes, &out)
+	return out, err
+}
+
+func getBodyBytes(res *http.Response, logger log.Logger) (bodyBytes []byte, err error) {
+	err = readBody(res, logger, func(reader io.Reader) (err error) {
+		bodyBytes, err = io.ReadAll(reader)
+		return err
+	})
+	return bodyBytes, err
 }
 
-func getBodyBytes(res *http.Response, logger log.Logger) ([]byte, error) {
+// readBody calls read with the response body. Gzip encoded bodies are decompressed
+func readBody(res *http.Response, logger log.Logger, read func(reader io.Reader) error) error {
 	if res == nil || res.Body == nil {
-		return nil, errors.New("invalid/empty response received from underlying API")
+		return errors.New("invalid/empty response received from underlying API")
 	}
 	if strings.EqualFold(res.Header.Get("Content-Encoding"), "gzip") {
 		reader, err := gzip.NewReader(res.Body)
 		if err != nil {
-			return nil, err
+			return err
 		}
 		defer func() {
 			if err := reader.Close(); err != nil {
 				logger.Warn("error closing gzip reader", "error", err.Error())
 			}
 		}()
-		return io.ReadAll(reader)
+		return read(reader)
 	}
-	return io.ReadAll(res.Body)
+	return read(res.Body)
 }
 
 // https://stackoverflow.com/questions/31398044/got-error-invalid-character-%C3%AF-looking-for-beginning-of-value-from-json-unmar
@@ -198,35 +304,61 @@ func removeBOMContent(input []byte) []byte {
 	return bytes.TrimPrefix(input, []byte("\xef\xbb\xbf"))
 }
 
+// ResponseInfo holds the details of the upstream response other than the body
+type ResponseInfo struct {
+	// Headers of the response. Only available for url sources
+	Headers    http.Header
+	StatusCode int
+	Duration   time.Duration
+	// Attempts is the number of requests made including the retries. Zero when the response is served from the cache
+	Attempts int
+	// RateLimitWait is the time the request was queued by the rate limiter
+	RateLimitWait time.Duration
+}
+
 func (client *Client) GetResults(ctx context.Context, pCtx *backend.PluginContext, query models.Query, requestHeaders map[string]string) (o any, statusCode int, duration time.Duration, err error) {
-	logger := backend.Logger.FromContext(ctx)
+	o, info, err := client.GetResultsWithInfo(ctx, pCtx, query, requestHeaders)
+	return o, info.StatusCode, info.Duration, err
+}
+
+// GetResultsWithInfo is same as GetResults but also returns the response details such as headers and the number of attempts
+func (client *Client) GetResultsWithInfo(ctx context.Context, pCtx *backend.PluginContext, query models.Query, requestHeaders map[string]string) (o any, info ResponseInfo, err error) {
 	if query.Source == "azure-blob" {
 		if strings.TrimSpace(query.AzBlobContainerName) == "" || strings.TrimSpace(query.AzBlobName) == "" {
-			return nil, http.StatusBadRequest, 0, backend.DownstreamError(errors.New("invalid/empty container name/blob name"))
+			return nil, ResponseInfo{StatusCode: http.StatusBadRequest}, backend.DownstreamError(errors.New("invalid/empty container name/blob name"))
 		}
 		if client.AzureBlobClient == nil {
-			return nil, http.StatusInternalServerError, 0, backend.PluginError(errors.New("invalid azure blob client"))
+			return nil, ResponseInfo{StatusCode: http.StatusInternalServerError}, backend.PluginError(errors.New("invalid azure blob client"))
+		}
+		if isColumnarQuery(query) {
+			// parquet and arrow files are read in ranges when converting to frame
+			blobFile, err := client.getAzureBlobFile(ctx, strings.TrimSpace(query.AzBlobContainerName), strings.TrimSpace(query.AzBlobName))
+			if err != nil {
+				return nil, ResponseInfo{StatusCode: http.StatusInternalServerError}, backend.DownstreamError(err)
+			}
+			return blobFile, ResponseInfo{StatusCode: http.StatusOK}, nil
 		}
 		blobDownloadResponse, err := client.AzureBlobClient.DownloadStream(ctx, strings.TrimSpace(query.AzBlobContainerName), strings.TrimSpace(query.AzBlobName), nil)
 		if err != nil {
-			return nil, http.StatusInternalServerError, 0, backend.DownstreamError(err)
+			return nil, ResponseInfo{StatusCode: http.StatusInternalServerError}, backend.DownstreamError(err)
 		}
 		reader := blobDownloadResponse.Body
 		bodyBytes, err := io.ReadAll(reader)
 		if err != nil {
-			return nil, http.StatusInternalServerError, 0, backend.PluginError(fmt.Errorf("error reading blob content. %w", err))
+			return nil, ResponseInfo{StatusCode: http.StatusInternalServerError}, backend.PluginError(fmt.Errorf("error reading blob content. %w", err))
 		}
-		bodyBytes = removeBOMContent(bodyBytes)
-		if CanParseAsJSON(query.Type, http.Header{}) {
-			var out any
-			err := json.Unmarshal(bodyBytes, &out)
-			if err != nil {
-				logger.Error("error un-marshaling blob content", "error", err.Error())
-				err = backend.PluginError(err)
-			}
-			return out, http.StatusOK, duration, err
+		contentType := ""
+		if blobDownloadResponse.ContentType != nil {
+			contentType = *blobDownloadResponse.ContentType
 		}
-		return string(bodyBytes), http.StatusOK, 0, nil
+		out, err := parseObjectContent(ctx, query, bodyBytes, contentType, strings.TrimSpace(query.AzBlobName))
+		return out, ResponseInfo{StatusCode: http.StatusOK}, err
+	}
+	if query.Source == "s3" {
+		return client.getS3Object(ctx, query)
+	}
+	if query.Source == "gcs" {
+		return client.getGCSObject(ctx, query)
 	}
 	switch strings.ToUpper(query.URLOptions.Method) {
 	case http.MethodGet:
@@ -237,6 +369,38 @@ func (client *Client) GetResults(ctx context.Context, pCtx *backend.PluginContex
 	}
 }
 
+// parseObjectContent converts the content of the object storage files such as azure blobs and s3 objects into the response
+// object. JSON content is un-marshaled and the binary files are returned as is. Same as the url sources, the content type
+// of the object decides whether uql and groq queries get the json content. When the content type is not set or generic,
+// it is guessed from the extension of the object name
+func parseObjectContent(ctx context.Context, query models.Query, bodyBytes []byte, contentType string, name string) (any, error) {
+	if isBinaryQuery(query) {
+		return bodyBytes, nil
+	}
+	bodyBytes = removeBOMContent(bodyBytes)
+	if CanParseAsJSON(query.Type, http.Header{headerKeyContentType: []string{getObjectContentType(contentType, name)}}) {
+		out, err := unmarshalJSONBody(bodyBytes, query)
+		if err != nil {
+			backend.Logger.FromContext(ctx).Error("error un-marshaling object content", "error", err.Error())
+			err = backend.PluginError(err)
+		}
+		return out, err
+	}
+	return string(bodyBytes), nil
+}
+
+// getObjectContentType returns the content type of the object. Generic content types such as application/octet-stream
+// are replaced by the content type of the file extension
+func getObjectContentType(contentType string, name string) string {
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil && mediaType != "application/octet-stream" && mediaType != "binary/octet-stream" {
+		return contentType
+	}
+	if extensionContentType := mime.TypeByExtension(path.Ext(name)); extensionContentType != "" {
+		return extensionContentType
+	}
+	return contentType
+}
+
 func CanParseAsJSON(queryType models.QueryType, responseHeaders http.Header) bool {
 	if queryType == models.QueryTypeJSON || queryType == models.QueryTypeGraphQL {
 		return true
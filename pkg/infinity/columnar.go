@@ -0,0 +1,360 @@
+package infinity
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/ipc"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/apache/arrow-go/v18/parquet/file"
+	"github.com/apache/arrow-go/v18/parquet/pqarrow"
+	"github.com/grafana/grafana-infinity-datasource/pkg/models"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/tracing"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+const (
+	columnarBatchSize = 64 * 1024
+	// arrow ipc streams are read sequentially. reads are buffered to reduce the number of range requests for azure blobs
+	arrowStreamBufferSize = 1024 * 1024
+)
+
+var (
+	arrowFileMagic = []byte("ARROW1")
+
+	errColumnNotFound = errors.New("column not found in the file")
+)
+
+// columnarFile is the content of a parquet or arrow file. Files from url sources are downloaded fully
+// whereas the azure blobs are read in ranges so that only the required parts are downloaded
+type columnarFile interface {
+	io.ReaderAt
+	Size() int64
+}
+
+func isColumnarQuery(query models.Query) bool {
+	return query.Type == models.QueryTypeParquet || query.Type == models.QueryTypeArrow
+}
+
+// GetColumnarBackendResponse converts the parquet or arrow file into a frame. Columns of the query are used for projection
+// and only the columns listed are read. Reading stops once the max rows of the query are read
+func GetColumnarBackendResponse(ctx context.Context, response any, query models.Query) (*data.Frame, error) {
+	ctx, span := tracing.DefaultTracer().Start(ctx, "GetColumnarBackendResponse")
+	defer span.End()
+	var f columnarFile
+	switch r := response.(type) {
+	case []byte:
+		f = bytes.NewReader(r)
+	case columnarFile:
+		f = r
+	default:
+		return GetDummyFrame(query), backend.PluginError(fmt.Errorf("unsupported response type %T for %s query", response, query.Type))
+	}
+	var frame *data.Frame
+	var err error
+	if query.Type == models.QueryTypeParquet {
+		frame, err = getFrameFromParquet(ctx, f, query)
+	} else {
+		frame, err = getFrameFromArrow(f, query)
+	}
+	if err != nil {
+		span.RecordError(err)
+		if errors.Is(err, context.Canceled) {
+			return GetDummyFrame(query), backend.DownstreamError(err)
+		}
+		return GetDummyFrame(query), backend.DownstreamError(fmt.Errorf("error reading %s file. %w", query.Type, err))
+	}
+	return frame, nil
+}
+
+func getFrameFromParquet(ctx context.Context, f columnarFile, query models.Query) (*data.Frame, error) {
+	parquetReader, err := file.NewParquetReader(io.NewSectionReader(f, 0, f.Size()))
+	if err != nil {
+		return nil, err
+	}
+	defer parquetReader.Close()
+	fileReader, err := pqarrow.NewFileReader(parquetReader, pqarrow.ArrowReadProperties{BatchSize: columnarBatchSize}, memory.DefaultAllocator)
+	if err != nil {
+		return nil, err
+	}
+	schema, err := fileReader.Schema()
+	if err != nil {
+		return nil, err
+	}
+	fieldIndices, err := getProjectedFieldIndices(schema, query)
+	if err != nil {
+		return nil, err
+	}
+	// record reader expects the leaf column indices. nested fields have multiple leaf columns
+	metadata := parquetReader.MetaData()
+	leafIndices := []int{}
+	for i := 0; i < metadata.Schema.NumColumns(); i++ {
+		if slices.Contains(fieldIndices, metadata.Schema.Root().FieldIndexByField(metadata.Schema.ColumnRoot(i))) {
+			leafIndices = append(leafIndices, i)
+		}
+	}
+	// row groups after the max rows are never read
+	rowGroups := []int{}
+	rowCount := int64(0)
+	for i := 0; i < parquetReader.NumRowGroups() && (query.MaxRows <= 0 || rowCount < query.MaxRows); i++ {
+		rowGroups = append(rowGroups, i)
+		rowCount += metadata.RowGroup(i).NumRows()
+	}
+	if len(rowGroups) == 0 || len(leafIndices) == 0 {
+		return newColumnarFrame(query, schema, fieldIndices), nil
+	}
+	recordReader, err := fileReader.GetRecordReader(ctx, leafIndices, rowGroups)
+	if err != nil {
+		return nil, err
+	}
+	defer recordReader.Release()
+	// record reader only has the projected fields in the file order
+	recordSchema := recordReader.Schema()
+	recordIndices := []int{}
+	for _, i := range fieldIndices {
+		recordIndices = append(recordIndices, recordSchema.FieldIndices(schema.Field(i).Name)[0])
+	}
+	frame := newColumnarFrame(query, recordSchema, recordIndices)
+	for recordReader.Next() {
+		if !appendColumnarRecord(frame, recordReader.RecordBatch(), recordIndices, query.MaxRows) {
+			break
+		}
+	}
+	if err := recordReader.Err(); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	return frame, nil
+}
+
+// getFrameFromArrow reads both the arrow ipc file format and the stream format
+func getFrameFromArrow(f columnarFile, query models.Query) (*data.Frame, error) {
+	header := make([]byte, len(arrowFileMagic))
+	if _, err := f.ReadAt(header, 0); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	if bytes.Equal(header, arrowFileMagic) {
+		fileReader, err := ipc.NewFileReader(io.NewSectionReader(f, 0, f.Size()))
+		if err != nil {
+			return nil, err
+		}
+		defer func() { _ = fileReader.Close() }()
+		fieldIndices, err := getProjectedFieldIndices(fileReader.Schema(), query)
+		if err != nil {
+			return nil, err
+		}
+		frame := newColumnarFrame(query, fileReader.Schema(), fieldIndices)
+		for i := 0; i < fileReader.NumRecords(); i++ {
+			record, err := fileReader.RecordBatchAt(i)
+			if err != nil {
+				return nil, err
+			}
+			more := appendColumnarRecord(frame, record, fieldIndices, query.MaxRows)
+			record.Release()
+			if !more {
+				break
+			}
+		}
+		return frame, nil
+	}
+	streamReader, err := ipc.NewReader(bufio.NewReaderSize(io.NewSectionReader(f, 0, f.Size()), arrowStreamBufferSize))
+	if err != nil {
+		return nil, err
+	}
+	defer streamReader.Release()
+	fieldIndices, err := getProjectedFieldIndices(streamReader.Schema(), query)
+	if err != nil {
+		return nil, err
+	}
+	frame := newColumnarFrame(query, streamReader.Schema(), fieldIndices)
+	for streamReader.Next() {
+		if !appendColumnarRecord(frame, streamReader.RecordBatch(), fieldIndices, query.MaxRows) {
+			break
+		}
+	}
+	if err := streamReader.Err(); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	return frame, nil
+}
+
+// getProjectedFieldIndices returns the indices of the fields selected by the query columns. All the fields are returned when the query has no columns
+func getProjectedFieldIndices(schema *arrow.Schema, query models.Query) ([]int, error) {
+	if len(query.Columns) == 0 {
+		indices := make([]int, schema.NumFields())
+		for i := range indices {
+			indices[i] = i
+		}
+		return indices, nil
+	}
+	indices := []int{}
+	for _, column := range query.Columns {
+		fieldIndices := schema.FieldIndices(column.Selector)
+		if len(fieldIndices) == 0 {
+			return nil, fmt.Errorf("%w. column: %s", errColumnNotFound, column.Selector)
+		}
+		indices = append(indices, fieldIndices[0])
+	}
+	return indices, nil
+}
+
+// newColumnarFrame creates the empty frame with a field for each projected arrow field. Column text of the query is used as the field name when provided
+func newColumnarFrame(query models.Query, schema *arrow.Schema, fieldIndices []int) *data.Frame {
+	frame := GetDummyFrame(query)
+	for i, fieldIndex := range fieldIndices {
+		arrowField := schema.Field(fieldIndex)
+		name := arrowField.Name
+		if i < len(query.Columns) && query.Columns[i].Text != "" {
+			name = query.Columns[i].Text
+		}
+		frame.Fields = append(frame.Fields, data.NewFieldFromFieldType(getColumnarFieldType(arrowField.Type), 0))
+		frame.Fields[i].Name = name
+	}
+	return frame
+}
+
+// appendColumnarRecord appends the rows of the record to the frame. Returns false once the max rows are reached
+func appendColumnarRecord(frame *data.Frame, record arrow.RecordBatch, fieldIndices []int, maxRows int64) bool {
+	rowCount := int64(0)
+	if len(frame.Fields) > 0 {
+		rowCount = int64(frame.Fields[0].Len())
+	}
+	rows := record.NumRows()
+	if maxRows > 0 {
+		rows = min(rows, maxRows-rowCount)
+	}
+	for i, fieldIndex := range fieldIndices {
+		column := record.Column(fieldIndex)
+		for row := 0; row < int(rows); row++ {
+			if column.IsNull(row) {
+				frame.Fields[i].Append(nil)
+				continue
+			}
+			frame.Fields[i].Append(getColumnarValue(column, row))
+		}
+	}
+	return maxRows <= 0 || rowCount+rows < maxRows
+}
+
+// getColumnarFieldType returns the nullable field type for the arrow type. Types without a matching field type are converted to string
+func getColumnarFieldType(dataType arrow.DataType) data.FieldType {
+	switch dataType.ID() {
+	case arrow.BOOL:
+		return data.FieldTypeNullableBool
+	case arrow.INT8:
+		return data.FieldTypeNullableInt8
+	case arrow.INT16:
+		return data.FieldTypeNullableInt16
+	case arrow.INT32:
+		return data.FieldTypeNullableInt32
+	case arrow.INT64:
+		return data.FieldTypeNullableInt64
+	case arrow.UINT8:
+		return data.FieldTypeNullableUint8
+	case arrow.UINT16:
+		return data.FieldTypeNullableUint16
+	case arrow.UINT32:
+		return data.FieldTypeNullableUint32
+	case arrow.UINT64:
+		return data.FieldTypeNullableUint64
+	case arrow.FLOAT16, arrow.FLOAT32:
+		return data.FieldTypeNullableFloat32
+	case arrow.FLOAT64:
+		return data.FieldTypeNullableFloat64
+	case arrow.TIMESTAMP, arrow.DATE32, arrow.DATE64:
+		return data.FieldTypeNullableTime
+	default:
+		return data.FieldTypeNullableString
+	}
+}
+
+// getColumnarValue returns the value of the row as a pointer of the type returned by getColumnarFieldType
+func getColumnarValue(column arrow.Array, row int) any {
+	switch c := column.(type) {
+	case *array.Boolean:
+		return toPointer(c.Value(row))
+	case *array.Int8:
+		return toPointer(c.Value(row))
+	case *array.Int16:
+		return toPointer(c.Value(row))
+	case *array.Int32:
+		return toPointer(c.Value(row))
+	case *array.Int64:
+		return toPointer(c.Value(row))
+	case *array.Uint8:
+		return toPointer(c.Value(row))
+	case *array.Uint16:
+		return toPointer(c.Value(row))
+	case *array.Uint32:
+		return toPointer(c.Value(row))
+	case *array.Uint64:
+		return toPointer(c.Value(row))
+	case *array.Float16:
+		return toPointer(c.Value(row).Float32())
+	case *array.Float32:
+		return toPointer(c.Value(row))
+	case *array.Float64:
+		return toPointer(c.Value(row))
+	case *array.Timestamp:
+		return toPointer(c.Value(row).ToTime(c.DataType().(*arrow.TimestampType).Unit))
+	case *array.Date32:
+		return toPointer(c.Value(row).ToTime())
+	case *array.Date64:
+		return toPointer(c.Value(row).ToTime())
+	default:
+		return toPointer(column.ValueStr(row))
+	}
+}
+
+func toPointer[T any](v T) *T {
+	return &v
+}
+
+// azureBlobFile reads the blob in ranges
+type azureBlobFile struct {
+	ctx           context.Context
+	client        *azblob.Client
+	containerName string
+	blobName      string
+	size          int64
+}
+
+func (client *Client) getAzureBlobFile(ctx context.Context, containerName, blobName string) (*azureBlobFile, error) {
+	properties, err := client.AzureBlobClient.ServiceClient().NewContainerClient(containerName).NewBlobClient(blobName).GetProperties(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	if properties.ContentLength == nil {
+		return nil, errors.New("unable to get the blob size")
+	}
+	return &azureBlobFile{ctx: ctx, client: client.AzureBlobClient, containerName: containerName, blobName: blobName, size: *properties.ContentLength}, nil
+}
+
+func (f *azureBlobFile) Size() int64 {
+	return f.size
+}
+
+func (f *azureBlobFile) ReadAt(p []byte, off int64) (int, error) {
+	if off >= f.size {
+		return 0, io.EOF
+	}
+	count := min(int64(len(p)), f.size-off)
+	res, err := f.client.DownloadStream(f.ctx, f.containerName, f.blobName, &azblob.DownloadStreamOptions{Range: azblob.HTTPRange{Offset: off, Count: count}})
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = res.Body.Close() }()
+	n, err := io.ReadFull(res.Body, p[:count])
+	if err == nil && int(count) < len(p) {
+		err = io.EOF
+	}
+	return n, err
+}
@@ -0,0 +1,188 @@
+package infinity
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/ipc"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/apache/arrow-go/v18/parquet"
+	"github.com/apache/arrow-go/v18/parquet/pqarrow"
+	"github.com/grafana/grafana-infinity-datasource/pkg/models"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+var columnarTestSchema = arrow.NewSchema([]arrow.Field{
+	{Name: "time", Type: &arrow.TimestampType{Unit: arrow.Millisecond}, Nullable: true},
+	{Name: "name", Type: arrow.BinaryTypes.String, Nullable: true},
+	{Name: "value", Type: arrow.PrimitiveTypes.Float64, Nullable: true},
+	{Name: "count", Type: arrow.PrimitiveTypes.Int32, Nullable: true},
+	{Name: "day", Type: arrow.FixedWidthTypes.Date32, Nullable: true},
+}, nil)
+
+// getColumnarTestRecords returns the given number of records with 2 rows each
+func getColumnarTestRecords(t *testing.T, count int) []arrow.RecordBatch {
+	t.Helper()
+	records := []arrow.RecordBatch{}
+	for i := range count {
+		builder := array.NewRecordBuilder(memory.DefaultAllocator, columnarTestSchema)
+		builder.Field(0).(*array.TimestampBuilder).AppendValues([]arrow.Timestamp{arrow.Timestamp(i * 2000), arrow.Timestamp(i*2000 + 1000)}, nil)
+		builder.Field(1).(*array.StringBuilder).AppendValues([]string{fmt.Sprintf("foo%d", i), ""}, []bool{true, false})
+		builder.Field(2).(*array.Float64Builder).AppendValues([]float64{float64(i) + 0.5, float64(i) + 1.5}, nil)
+		builder.Field(3).(*array.Int32Builder).AppendValues([]int32{int32(i * 2), int32(i*2 + 1)}, nil)
+		builder.Field(4).(*array.Date32Builder).AppendValues([]arrow.Date32{arrow.Date32(i), arrow.Date32(i)}, nil)
+		records = append(records, builder.NewRecordBatch())
+		builder.Release()
+	}
+	return records
+}
+
+func getParquetTestFile(t *testing.T, rowGroups int) []byte {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	writer, err := pqarrow.NewFileWriter(columnarTestSchema, buf, parquet.NewWriterProperties(), pqarrow.DefaultWriterProps())
+	require.NoError(t, err)
+	for _, record := range getColumnarTestRecords(t, rowGroups) {
+		// each record is written as a separate row group
+		require.NoError(t, writer.Write(record))
+	}
+	require.NoError(t, writer.Close())
+	return buf.Bytes()
+}
+
+func getArrowTestFile(t *testing.T, records int, stream bool) []byte {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	var writer interface {
+		Write(arrow.RecordBatch) error
+		Close() error
+	}
+	if stream {
+		writer = ipc.NewWriter(buf, ipc.WithSchema(columnarTestSchema))
+	} else {
+		fileWriter, err := ipc.NewFileWriter(buf, ipc.WithSchema(columnarTestSchema))
+		require.NoError(t, err)
+		writer = fileWriter
+	}
+	for _, record := range getColumnarTestRecords(t, records) {
+		require.NoError(t, writer.Write(record))
+	}
+	require.NoError(t, writer.Close())
+	return buf.Bytes()
+}
+
+func TestGetColumnarBackendResponse(t *testing.T) {
+	files := map[string]struct {
+		queryType models.QueryType
+		content   []byte
+	}{
+		"parquet":      {queryType: models.QueryTypeParquet, content: getParquetTestFile(t, 3)},
+		"arrow file":   {queryType: models.QueryTypeArrow, content: getArrowTestFile(t, 3, false)},
+		"arrow stream": {queryType: models.QueryTypeArrow, content: getArrowTestFile(t, 3, true)},
+	}
+	for name, file := range files {
+		t.Run(name, func(t *testing.T) {
+			t.Run("should read all the columns with native types", func(t *testing.T) {
+				frame, err := GetColumnarBackendResponse(context.Background(), file.content, models.Query{RefID: "A", Type: file.queryType})
+				require.NoError(t, err)
+				require.Len(t, frame.Fields, 5)
+				require.Equal(t, 6, frame.Rows())
+				require.Equal(t, data.FieldTypeNullableTime, frame.Fields[0].Type())
+				require.Equal(t, time.UnixMilli(5000).UTC(), *frame.Fields[0].At(5).(*time.Time))
+				require.Equal(t, "foo1", *frame.Fields[1].At(2).(*string))
+				require.Nil(t, frame.Fields[1].At(3))
+				require.Equal(t, 3.5, *frame.Fields[2].At(5).(*float64))
+				require.Equal(t, int32(4), *frame.Fields[3].At(4).(*int32))
+				require.Equal(t, time.Date(1970, 1, 3, 0, 0, 0, 0, time.UTC), *frame.Fields[4].At(4).(*time.Time))
+			})
+			t.Run("should project the columns and apply the alias", func(t *testing.T) {
+				query := models.Query{RefID: "A", Type: file.queryType, Columns: []models.InfinityColumn{{Selector: "value"}, {Selector: "name", Text: "Name"}}}
+				frame, err := GetColumnarBackendResponse(context.Background(), file.content, query)
+				require.NoError(t, err)
+				require.Len(t, frame.Fields, 2)
+				require.Equal(t, "value", frame.Fields[0].Name)
+				require.Equal(t, "Name", frame.Fields[1].Name)
+				require.Equal(t, 0.5, *frame.Fields[0].At(0).(*float64))
+				require.Equal(t, "foo0", *frame.Fields[1].At(0).(*string))
+			})
+			t.Run("should stop reading at the max rows", func(t *testing.T) {
+				frame, err := GetColumnarBackendResponse(context.Background(), file.content, models.Query{RefID: "A", Type: file.queryType, MaxRows: 3})
+				require.NoError(t, err)
+				require.Equal(t, 3, frame.Rows())
+				require.Equal(t, int32(2), *frame.Fields[3].At(2).(*int32))
+			})
+			t.Run("should return error for unknown columns", func(t *testing.T) {
+				query := models.Query{RefID: "A", Type: file.queryType, Columns: []models.InfinityColumn{{Selector: "foo"}}}
+				_, err := GetColumnarBackendResponse(context.Background(), file.content, query)
+				require.ErrorIs(t, err, errColumnNotFound)
+				require.True(t, backend.IsDownstreamError(err))
+			})
+		})
+	}
+	t.Run("should return error for invalid files", func(t *testing.T) {
+		_, err := GetColumnarBackendResponse(context.Background(), []byte("foo"), models.Query{RefID: "A", Type: models.QueryTypeParquet})
+		require.Error(t, err)
+		require.True(t, backend.IsDownstreamError(err))
+	})
+}
+
+func TestColumnarQuery(t *testing.T) {
+	content := getParquetTestFile(t, 2)
+	t.Run("url source", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Contains(t, r.Header.Get("Accept"), "application/vnd.apache.parquet")
+			w.Header().Set("Content-Type", "application/vnd.apache.parquet")
+			_, _ = w.Write(content)
+		}))
+		defer server.Close()
+		query := models.Query{RefID: "A", Type: models.QueryTypeParquet, Source: "url", Parser: models.InfinityParserBackend, URL: server.URL, URLOptions: models.URLOptions{Method: http.MethodGet}}
+		frame, err := GetFrameForURLSources(context.Background(), &backend.PluginContext{}, query, Client{HttpClient: server.Client()}, nil)
+		require.NoError(t, err)
+		require.Equal(t, 4, frame.Rows())
+		require.Nil(t, frame.Meta.Custom.(*CustomMeta).Data)
+	})
+	t.Run("azure blob source should only download the required ranges", func(t *testing.T) {
+		content := getParquetTestFile(t, 50)
+		var downloaded atomic.Int64
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "/container/blob.parquet", r.URL.Path)
+			if r.Method == http.MethodHead {
+				w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+				return
+			}
+			start, end := 0, len(content)-1
+			if byteRange := r.Header.Get("x-ms-range"); byteRange != "" {
+				from, to, _ := strings.Cut(strings.TrimPrefix(byteRange, "bytes="), "-")
+				start, _ = strconv.Atoi(from)
+				end, _ = strconv.Atoi(to)
+			}
+			downloaded.Add(int64(end - start + 1))
+			w.Header().Set("Content-Length", strconv.Itoa(end-start+1))
+			w.WriteHeader(http.StatusPartialContent)
+			_, _ = w.Write(content[start : end+1])
+		}))
+		defer server.Close()
+		azClient, err := azblob.NewClientWithNoCredential(server.URL, nil)
+		require.NoError(t, err)
+		client := Client{AzureBlobClient: azClient}
+		query := models.Query{RefID: "A", Type: models.QueryTypeParquet, Source: "azure-blob", Parser: models.InfinityParserBackend, AzBlobContainerName: "container", AzBlobName: "blob.parquet", MaxRows: 2, Columns: []models.InfinityColumn{{Selector: "value"}}}
+		frame, err := GetFrameForURLSources(context.Background(), &backend.PluginContext{}, query, client, nil)
+		require.NoError(t, err)
+		require.Equal(t, 2, frame.Rows())
+		require.Equal(t, 1.5, *frame.Fields[0].At(1).(*float64))
+		require.Less(t, downloaded.Load(), int64(len(content)))
+	})
+}
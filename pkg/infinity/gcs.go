@@ -0,0 +1,236 @@
+package infinity
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/grafana-infinity-datasource/pkg/httpclient"
+	"github.com/grafana/grafana-infinity-datasource/pkg/models"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+)
+
+// gcsMaxListObjects limits the number of objects returned by the object listing
+const gcsMaxListObjects = 10000
+
+// GCSClient reads the objects of the google cloud storage buckets using the JSON API
+type GCSClient struct {
+	Endpoint   string
+	HttpClient *http.Client
+}
+
+// GCSObject is the metadata of the google cloud storage object returned by the object listing
+type GCSObject struct {
+	Name        string `json:"name"`
+	Size        string `json:"size"`
+	ContentType string `json:"contentType"`
+	Updated     string `json:"updated"`
+}
+
+// GCSError is the error response of the google cloud storage JSON API
+type GCSError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *GCSError) Error() string {
+	return fmt.Sprintf("google cloud storage responded with status code %d. %s", e.StatusCode, e.Message)
+}
+
+// NewGCSClient creates the google cloud storage client authenticated with the service account key of the settings
+func NewGCSClient(ctx context.Context, settings models.InfinitySettings) (*GCSClient, error) {
+	httpClient, err := httpclient.GetGoogleServiceAccountHTTPClient(ctx, settings, []string{models.GoogleStorageScope})
+	if err != nil {
+		return nil, err
+	}
+	return &GCSClient{Endpoint: models.GoogleStorageHost, HttpClient: httpClient}, nil
+}
+
+func (c *GCSClient) objectURL(bucket, object string) string {
+	return fmt.Sprintf("%s/storage/v1/b/%s/o/%s", strings.TrimSuffix(c.Endpoint, "/"), url.PathEscape(bucket), url.PathEscape(object))
+}
+
+func (c *GCSClient) get(ctx context.Context, requestURL string, headers map[string]string) ([]byte, error) {
+	bodyBytes, _, err := c.getWithHeaders(ctx, requestURL, headers)
+	return bodyBytes, err
+}
+
+// getWithHeaders is same as get but also returns the response headers
+func (c *GCSClient) getWithHeaders(ctx context.Context, requestURL string, headers map[string]string) ([]byte, http.Header, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	res, err := c.HttpClient.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() { _ = res.Body.Close() }()
+	bodyBytes, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error reading google cloud storage response. %w", err)
+	}
+	if res.StatusCode >= http.StatusBadRequest {
+		gcsErr := &GCSError{StatusCode: res.StatusCode, Message: http.StatusText(res.StatusCode)}
+		var errorResponse struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if json.Unmarshal(bodyBytes, &errorResponse) == nil && errorResponse.Error.Message != "" {
+			gcsErr.Message = errorResponse.Error.Message
+		}
+		return nil, nil, gcsErr
+	}
+	return bodyBytes, res.Header, nil
+}
+
+// GetObject downloads the content of the object. Returns the content type of the object along with the content
+func (c *GCSClient) GetObject(ctx context.Context, bucket, object string) ([]byte, string, error) {
+	bodyBytes, headers, err := c.getWithHeaders(ctx, c.objectURL(bucket, object)+"?alt=media", nil)
+	if err != nil {
+		return nil, "", err
+	}
+	return bodyBytes, headers.Get(headerKeyContentType), nil
+}
+
+// ListObjects lists the objects of the bucket which are starting with the prefix. Stops once max results reached
+func (c *GCSClient) ListObjects(ctx context.Context, bucket, prefix string, maxResults int) ([]GCSObject, error) {
+	objects := []GCSObject{}
+	pageToken := ""
+	for {
+		params := url.Values{}
+		params.Set("fields", "items(name,size,contentType,updated),nextPageToken")
+		params.Set("maxResults", strconv.Itoa(min(maxResults-len(objects), 1000)))
+		if prefix != "" {
+			params.Set("prefix", prefix)
+		}
+		if pageToken != "" {
+			params.Set("pageToken", pageToken)
+		}
+		bodyBytes, err := c.get(ctx, fmt.Sprintf("%s/storage/v1/b/%s/o?%s", strings.TrimSuffix(c.Endpoint, "/"), url.PathEscape(bucket), params.Encode()), nil)
+		if err != nil {
+			return nil, err
+		}
+		var page struct {
+			Items         []GCSObject `json:"items"`
+			NextPageToken string      `json:"nextPageToken"`
+		}
+		if err := json.Unmarshal(bodyBytes, &page); err != nil {
+			return nil, fmt.Errorf("error parsing google cloud storage object listing. %w", err)
+		}
+		objects = append(objects, page.Items...)
+		pageToken = page.NextPageToken
+		if pageToken == "" || len(objects) >= maxResults {
+			return objects, nil
+		}
+	}
+}
+
+// GetGCSErrorStatusCode returns the http status code of the google cloud storage error response
+func GetGCSErrorStatusCode(err error) int {
+	var gcsErr *GCSError
+	if errors.As(err, &gcsErr) {
+		return gcsErr.StatusCode
+	}
+	return http.StatusInternalServerError
+}
+
+// getGCSObject downloads the object of the query. When the object name is empty or ends with /, the objects starting
+// with the name are listed instead. parquet and arrow files are read in ranges
+func (client *Client) getGCSObject(ctx context.Context, query models.Query) (any, ResponseInfo, error) {
+	bucket, object := strings.TrimSpace(query.GCSBucket), strings.TrimSpace(query.GCSObject)
+	if bucket == "" {
+		return nil, ResponseInfo{StatusCode: http.StatusBadRequest}, backend.DownstreamError(errors.New("invalid/empty bucket name"))
+	}
+	if client.GCSClient == nil {
+		return nil, ResponseInfo{StatusCode: http.StatusInternalServerError}, backend.DownstreamError(models.ErrInvalidGCSClient)
+	}
+	if object == "" || strings.HasSuffix(object, "/") {
+		if query.Type != models.QueryTypeJSON {
+			return nil, ResponseInfo{StatusCode: http.StatusBadRequest}, backend.DownstreamError(models.ErrGCSInvalidListType)
+		}
+		objects, err := client.GCSClient.ListObjects(ctx, bucket, object, gcsMaxListObjects)
+		if err != nil {
+			return nil, ResponseInfo{StatusCode: GetGCSErrorStatusCode(err)}, backend.DownstreamError(err)
+		}
+		out := []any{}
+		for _, o := range objects {
+			size, _ := strconv.ParseFloat(o.Size, 64)
+			out = append(out, map[string]any{"name": o.Name, "size": size, "contentType": o.ContentType, "updated": o.Updated})
+		}
+		return out, ResponseInfo{StatusCode: http.StatusOK}, nil
+	}
+	if isColumnarQuery(query) {
+		gcsFile, err := client.getGCSFile(ctx, bucket, object)
+		if err != nil {
+			return nil, ResponseInfo{StatusCode: GetGCSErrorStatusCode(err)}, backend.DownstreamError(err)
+		}
+		return gcsFile, ResponseInfo{StatusCode: http.StatusOK}, nil
+	}
+	bodyBytes, contentType, err := client.GCSClient.GetObject(ctx, bucket, object)
+	if err != nil {
+		return nil, ResponseInfo{StatusCode: GetGCSErrorStatusCode(err)}, backend.DownstreamError(err)
+	}
+	out, err := parseObjectContent(ctx, query, bodyBytes, contentType, object)
+	return out, ResponseInfo{StatusCode: http.StatusOK}, err
+}
+
+// gcsFile reads the google cloud storage object in ranges
+type gcsFile struct {
+	ctx    context.Context
+	client *GCSClient
+	bucket string
+	object string
+	size   int64
+}
+
+func (client *Client) getGCSFile(ctx context.Context, bucket, object string) (*gcsFile, error) {
+	bodyBytes, err := client.GCSClient.get(ctx, client.GCSClient.objectURL(bucket, object)+"?fields=size", nil)
+	if err != nil {
+		return nil, err
+	}
+	var metadata GCSObject
+	if err := json.Unmarshal(bodyBytes, &metadata); err != nil {
+		return nil, fmt.Errorf("error parsing google cloud storage object metadata. %w", err)
+	}
+	size, err := strconv.ParseInt(metadata.Size, 10, 64)
+	if err != nil {
+		return nil, errors.New("unable to get the object size")
+	}
+	return &gcsFile{ctx: ctx, client: client.GCSClient, bucket: bucket, object: object, size: size}, nil
+}
+
+func (f *gcsFile) Size() int64 {
+	return f.size
+}
+
+func (f *gcsFile) ReadAt(p []byte, off int64) (int, error) {
+	if off >= f.size {
+		return 0, io.EOF
+	}
+	count := min(int64(len(p)), f.size-off)
+	bodyBytes, err := f.client.get(f.ctx, f.client.objectURL(f.bucket, f.object)+"?alt=media", map[string]string{
+		"Range": fmt.Sprintf("bytes=%d-%d", off, off+count-1),
+	})
+	if err != nil {
+		return 0, err
+	}
+	n := copy(p[:count], bodyBytes)
+	if n < int(count) {
+		return n, io.ErrUnexpectedEOF
+	}
+	if int(count) < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
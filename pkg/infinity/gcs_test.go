@@ -0,0 +1,192 @@
+package infinity
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/grafana/grafana-infinity-datasource/pkg/models"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/require"
+)
+
+// getGCSTestServer returns a server which implements the object download, metadata and listing endpoints of the
+// google cloud storage JSON API
+func getGCSTestServer(t *testing.T, bucket string, objects map[string][]byte, downloaded *atomic.Int64) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		prefix := "/storage/v1/b/" + bucket + "/o"
+		if !strings.HasPrefix(r.URL.Path, prefix) {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":{"code":404,"message":"The specified bucket does not exist."}}`))
+			return
+		}
+		if r.URL.Path == prefix {
+			items := []map[string]string{}
+			for name, content := range objects {
+				if strings.HasPrefix(name, r.URL.Query().Get("prefix")) {
+					items = append(items, map[string]string{"name": name, "size": strconv.Itoa(len(content)), "contentType": "text/csv", "updated": "2026-10-18T10:00:00.000Z"})
+				}
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"items": items})
+			return
+		}
+		content, ok := objects[strings.TrimPrefix(r.URL.Path, prefix+"/")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":{"code":404,"message":"No such object"}}`))
+			return
+		}
+		if r.URL.Query().Get("alt") != "media" {
+			_, _ = w.Write([]byte(`{"size":"` + strconv.Itoa(len(content)) + `"}`))
+			return
+		}
+		start, end := 0, len(content)-1
+		if byteRange := r.Header.Get("Range"); byteRange != "" {
+			from, to, _ := strings.Cut(strings.TrimPrefix(byteRange, "bytes="), "-")
+			start, _ = strconv.Atoi(from)
+			end, _ = strconv.Atoi(to)
+			w.WriteHeader(http.StatusPartialContent)
+		}
+		if downloaded != nil {
+			downloaded.Add(int64(end - start + 1))
+		}
+		// objects uploaded without the content type are served as octet-stream
+		w.Header().Set("Content-Type", "application/octet-stream")
+		_, _ = w.Write(content[start : end+1])
+	}))
+}
+
+func getGCSTestClient(endpoint string) Client {
+	return Client{GCSClient: &GCSClient{Endpoint: endpoint, HttpClient: http.DefaultClient}}
+}
+
+func TestGCSQuery(t *testing.T) {
+	t.Run("should download the object and parse the json content", func(t *testing.T) {
+		server := getGCSTestServer(t, "my-bucket", map[string][]byte{"data/users.json": []byte(`[{"name":"foo"}]`)}, nil)
+		defer server.Close()
+		client := getGCSTestClient(server.URL)
+		out, info, err := client.GetResultsWithInfo(context.Background(), &backend.PluginContext{}, models.Query{Type: models.QueryTypeJSON, Source: "gcs", GCSBucket: "my-bucket", GCSObject: "data/users.json"}, nil)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, info.StatusCode)
+		require.Equal(t, []any{map[string]any{"name": "foo"}}, out)
+	})
+	t.Run("should use the content type of the object for uql and groq queries", func(t *testing.T) {
+		server := getGCSTestServer(t, "my-bucket", map[string][]byte{"users.json": []byte(`[{"name":"foo"}]`), "users.txt": []byte(`[{"name":"foo"}]`)}, nil)
+		defer server.Close()
+		client := getGCSTestClient(server.URL)
+		for _, queryType := range []models.QueryType{models.QueryTypeUQL, models.QueryTypeGROQ} {
+			out, _, err := client.GetResultsWithInfo(context.Background(), &backend.PluginContext{}, models.Query{Type: queryType, Source: "gcs", GCSBucket: "my-bucket", GCSObject: "users.json"}, nil)
+			require.NoError(t, err)
+			require.Equal(t, json.RawMessage(`[{"name":"foo"}]`), out)
+			out, _, err = client.GetResultsWithInfo(context.Background(), &backend.PluginContext{}, models.Query{Type: queryType, Source: "gcs", GCSBucket: "my-bucket", GCSObject: "users.txt"}, nil)
+			require.NoError(t, err)
+			require.Equal(t, `[{"name":"foo"}]`, out)
+		}
+	})
+	t.Run("should return the text content as string", func(t *testing.T) {
+		server := getGCSTestServer(t, "my-bucket", map[string][]byte{"users.csv": []byte("name\nfoo")}, nil)
+		defer server.Close()
+		client := getGCSTestClient(server.URL)
+		out, _, err := client.GetResultsWithInfo(context.Background(), &backend.PluginContext{}, models.Query{Type: models.QueryTypeCSV, Source: "gcs", GCSBucket: "my-bucket", GCSObject: "users.csv"}, nil)
+		require.NoError(t, err)
+		require.Equal(t, "name\nfoo", out)
+	})
+	t.Run("should list the objects when the object name is empty or ends with /", func(t *testing.T) {
+		server := getGCSTestServer(t, "my-bucket", map[string][]byte{"reports/users.csv": []byte("name\nfoo"), "other.csv": []byte("a")}, nil)
+		defer server.Close()
+		client := getGCSTestClient(server.URL)
+		out, _, err := client.GetResultsWithInfo(context.Background(), &backend.PluginContext{}, models.Query{Type: models.QueryTypeJSON, Source: "gcs", GCSBucket: "my-bucket", GCSObject: "reports/"}, nil)
+		require.NoError(t, err)
+		require.Equal(t, []any{map[string]any{"name": "reports/users.csv", "size": float64(8), "contentType": "text/csv", "updated": "2026-10-18T10:00:00.000Z"}}, out)
+		_, info, err := client.GetResultsWithInfo(context.Background(), &backend.PluginContext{}, models.Query{Type: models.QueryTypeCSV, Source: "gcs", GCSBucket: "my-bucket"}, nil)
+		require.ErrorIs(t, err, models.ErrGCSInvalidListType)
+		require.Equal(t, http.StatusBadRequest, info.StatusCode)
+	})
+	t.Run("should return downstream error for missing objects", func(t *testing.T) {
+		server := getGCSTestServer(t, "my-bucket", map[string][]byte{}, nil)
+		defer server.Close()
+		client := getGCSTestClient(server.URL)
+		_, info, err := client.GetResultsWithInfo(context.Background(), &backend.PluginContext{}, models.Query{Type: models.QueryTypeCSV, Source: "gcs", GCSBucket: "my-bucket", GCSObject: "users.csv"}, nil)
+		require.Error(t, err)
+		require.True(t, backend.IsDownstreamError(err))
+		require.Equal(t, http.StatusNotFound, info.StatusCode)
+		require.Contains(t, err.Error(), "No such object")
+	})
+	t.Run("should return error for empty bucket", func(t *testing.T) {
+		client := getGCSTestClient("http://localhost")
+		_, info, err := client.GetResultsWithInfo(context.Background(), &backend.PluginContext{}, models.Query{Type: models.QueryTypeCSV, Source: "gcs", GCSObject: "users.csv"}, nil)
+		require.Error(t, err)
+		require.Equal(t, http.StatusBadRequest, info.StatusCode)
+	})
+	t.Run("should return error when the gcs client is not configured", func(t *testing.T) {
+		_, _, err := (&Client{}).GetResultsWithInfo(context.Background(), &backend.PluginContext{}, models.Query{Type: models.QueryTypeCSV, Source: "gcs", GCSBucket: "my-bucket", GCSObject: "users.csv"}, nil)
+		require.ErrorIs(t, err, models.ErrInvalidGCSClient)
+	})
+	t.Run("parquet files should only download the required ranges", func(t *testing.T) {
+		content := getParquetTestFile(t, 50)
+		var downloaded atomic.Int64
+		server := getGCSTestServer(t, "my-bucket", map[string][]byte{"blob.parquet": content}, &downloaded)
+		defer server.Close()
+		query := models.Query{RefID: "A", Type: models.QueryTypeParquet, Source: "gcs", Parser: models.InfinityParserBackend, GCSBucket: "my-bucket", GCSObject: "blob.parquet", MaxRows: 2, Columns: []models.InfinityColumn{{Selector: "value"}}}
+		frame, err := GetFrameForURLSources(context.Background(), &backend.PluginContext{}, query, getGCSTestClient(server.URL), nil)
+		require.NoError(t, err)
+		require.Equal(t, 2, frame.Rows())
+		require.Equal(t, 1.5, *frame.Fields[0].At(1).(*float64))
+		require.Less(t, downloaded.Load(), int64(len(content)))
+	})
+}
+
+func TestGCSClientListObjects(t *testing.T) {
+	var requests atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("pageToken") == "" {
+			_, _ = w.Write([]byte(`{"items":[{"name":"a.csv","size":"1"},{"name":"b.csv","size":"1"}],"nextPageToken":"page2"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"items":[{"name":"c.csv","size":"1"}]}`))
+	}))
+	defer server.Close()
+	client := &GCSClient{Endpoint: server.URL, HttpClient: http.DefaultClient}
+	t.Run("should follow the next page token", func(t *testing.T) {
+		objects, err := client.ListObjects(context.Background(), "my-bucket", "", 100)
+		require.NoError(t, err)
+		require.Len(t, objects, 3)
+		require.Equal(t, int64(2), requests.Swap(0))
+	})
+	t.Run("should stop once max results reached", func(t *testing.T) {
+		objects, err := client.ListObjects(context.Background(), "my-bucket", "", 2)
+		require.NoError(t, err)
+		require.Len(t, objects, 2)
+		require.Equal(t, int64(1), requests.Swap(0))
+	})
+}
+
+func TestGetObjectContentType(t *testing.T) {
+	tests := []struct {
+		contentType string
+		name        string
+		want        string
+	}{
+		{contentType: "application/json; charset=utf-8", name: "users.txt", want: "application/json; charset=utf-8"},
+		{contentType: "text/csv", name: "users.json", want: "text/csv"},
+		{contentType: "application/octet-stream", name: "data/users.JSON", want: "application/json"},
+		{contentType: "binary/octet-stream", name: "users.json", want: "application/json"},
+		{contentType: "", name: "users.json", want: "application/json"},
+		{contentType: "", name: "users", want: ""},
+		{contentType: "application/octet-stream", name: "users", want: "application/octet-stream"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.contentType+" "+tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, getObjectContentType(tt.contentType, tt.name))
+		})
+	}
+}
@@ -5,27 +5,64 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
+	"net/url"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/grafana/grafana-infinity-datasource/pkg/models"
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/tracing"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
-	"github.com/grafana/infinity-libs/lib/go/gframer"
+	"github.com/xuri/excelize/v2"
 )
 
+const googleSheetsBaseURL = "https://sheets.googleapis.com/v4/spreadsheets/"
+
 type Spreadsheet struct {
-	Sheets     []*Sheet `json:"sheets,omitempty"`
+	Properties *SpreadsheetProperties `json:"properties,omitempty"`
+	Sheets     []*Sheet               `json:"sheets,omitempty"`
+	NullFields []string               `json:"-"`
+}
+
+type SpreadsheetProperties struct {
+	TimeZone   string   `json:"timeZone,omitempty"`
 	NullFields []string `json:"-"`
 }
 
 type Sheet struct {
-	Data       []*GridData `json:"data,omitempty"`
-	NullFields []string    `json:"-"`
+	Properties *SheetProperties `json:"properties,omitempty"`
+	Data       []*GridData      `json:"data,omitempty"`
+	Merges     []*GridRange     `json:"merges,omitempty"`
+	NullFields []string         `json:"-"`
+}
+
+type SheetProperties struct {
+	Title          string          `json:"title,omitempty"`
+	GridProperties *GridProperties `json:"gridProperties,omitempty"`
+	NullFields     []string        `json:"-"`
+}
+
+type GridProperties struct {
+	FrozenRowCount int64    `json:"frozenRowCount,omitempty"`
+	NullFields     []string `json:"-"`
+}
+
+// GridRange is the range of the merged cells. Start indexes are inclusive and end indexes are exclusive
+type GridRange struct {
+	StartRowIndex    int64    `json:"startRowIndex,omitempty"`
+	EndRowIndex      int64    `json:"endRowIndex,omitempty"`
+	StartColumnIndex int64    `json:"startColumnIndex,omitempty"`
+	EndColumnIndex   int64    `json:"endColumnIndex,omitempty"`
+	NullFields       []string `json:"-"`
 }
 
 type GridData struct {
-	RowData    []*RowData `json:"rowData,omitempty"`
-	NullFields []string   `json:"-"`
+	StartRow    int64      `json:"startRow,omitempty"`
+	StartColumn int64      `json:"startColumn,omitempty"`
+	RowData     []*RowData `json:"rowData,omitempty"`
+	NullFields  []string   `json:"-"`
 }
 
 type RowData struct {
@@ -34,87 +71,304 @@ type RowData struct {
 }
 
 type CellData struct {
-	FormattedValue string   `json:"formattedValue,omitempty"`
-	NullFields     []string `json:"-"`
+	FormattedValue    string         `json:"formattedValue,omitempty"`
+	EffectiveValue    *ExtendedValue `json:"effectiveValue,omitempty"`
+	UserEnteredFormat *CellFormat    `json:"userEnteredFormat,omitempty"`
+	EffectiveFormat   *CellFormat    `json:"effectiveFormat,omitempty"`
+	NullFields        []string       `json:"-"`
 }
 
-func GetGoogleSheetsResponse(ctx context.Context, urlResponseObject any, query models.Query) (*data.Frame, error) {
+type ExtendedValue struct {
+	NumberValue *float64    `json:"numberValue,omitempty"`
+	StringValue *string     `json:"stringValue,omitempty"`
+	BoolValue   *bool       `json:"boolValue,omitempty"`
+	ErrorValue  *ErrorValue `json:"errorValue,omitempty"`
+	NullFields  []string    `json:"-"`
+}
+
+type ErrorValue struct {
+	Type       string   `json:"type,omitempty"`
+	Message    string   `json:"message,omitempty"`
+	NullFields []string `json:"-"`
+}
+
+type CellFormat struct {
+	NumberFormat *NumberFormat `json:"numberFormat,omitempty"`
+	NullFields   []string      `json:"-"`
+}
+
+type NumberFormat struct {
+	Type       string   `json:"type,omitempty"` // 'TEXT' | 'NUMBER' | 'PERCENT' | 'CURRENCY' | 'DATE' | 'TIME' | 'DATE_TIME' | 'SCIENTIFIC'
+	Pattern    string   `json:"pattern,omitempty"`
+	NullFields []string `json:"-"`
+}
+
+// GetGoogleSheetsURL returns the url of the spreadsheet including the grid data of the ranges. Multiple ranges can be
+// separated by comma. Ranges without sheet name are prefixed with the sheet name of the query
+func GetGoogleSheetsURL(query models.Query) string {
+	params := url.Values{"includeGridData": []string{"true"}}
+	sheetName := strings.TrimSpace(query.SheetName)
+	sheetRanges := splitGoogleSheetsRanges(query.SheetRange)
+	if len(sheetRanges) == 0 && sheetName != "" {
+		sheetRanges = []string{""}
+	}
+	for _, sheetRange := range sheetRanges {
+		if sheetName != "" && !strings.Contains(sheetRange, "!") {
+			if sheetRange == "" {
+				sheetRange = sheetName
+			} else {
+				sheetRange = sheetName + "!" + sheetRange
+			}
+		}
+		params.Add("ranges", sheetRange)
+	}
+	return googleSheetsBaseURL + url.PathEscape(strings.TrimSpace(query.Spreadsheet)) + "?" + params.Encode()
+}
+
+// splitGoogleSheetsRanges splits the comma separated ranges. Commas in the quoted sheet names are not considered as separators
+func splitGoogleSheetsRanges(input string) []string {
+	out := []string{}
+	quoted := false
+	start := 0
+	for i := 0; i <= len(input); i++ {
+		if i < len(input) && input[i] == '\'' {
+			quoted = !quoted
+		}
+		if i == len(input) || (input[i] == ',' && !quoted) {
+			if item := strings.TrimSpace(input[start:i]); item != "" {
+				out = append(out, item)
+			}
+			start = i + 1
+		}
+	}
+	return out
+}
+
+// GetFramesForGoogleSheets queries the spreadsheet and returns a frame for each of the ranges
+func GetFramesForGoogleSheets(ctx context.Context, pCtx *backend.PluginContext, query models.Query, infClient Client, requestHeaders map[string]string) (data.Frames, error) {
+	ctx, span := tracing.DefaultTracer().Start(ctx, "GetFramesForGoogleSheets")
+	defer span.End()
+	query.URL = GetGoogleSheetsURL(query)
+	urlResponseObject, responseInfo, err := infClient.GetResultsWithInfo(ctx, pCtx, query, requestHeaders)
+	duration, attempts := responseInfo.Duration, 0
+	if infClient.Settings.RetryMaxAttempts > 1 {
+		attempts = responseInfo.Attempts
+	}
+	if infClient.IsMock {
+		duration = 123
+	}
+	customMeta := &CustomMeta{
+		Query:                  query,
+		Data:                   urlResponseObject,
+		ResponseCodeFromServer: responseInfo.StatusCode,
+		Duration:               duration,
+		Attempts:               attempts,
+		RateLimitWait:          responseInfo.RateLimitWait,
+	}
+	frames := data.Frames{}
+	if err == nil {
+		frames, err = GetGoogleSheetsResponse(ctx, urlResponseObject, query)
+	}
+	if err != nil {
+		span.RecordError(err)
+		customMeta.Error = err.Error()
+		frames = data.Frames{GetDummyFrame(query)}
+	}
+	for i, frame := range frames {
+		if frame.Meta == nil {
+			frame.Meta = &data.FrameMeta{}
+		}
+		frame.Meta.ExecutedQueryString = infClient.GetExecutedURL(ctx, query)
+		frameMeta := *customMeta
+		if i > 0 {
+			// response is only attached to the first frame to avoid sending it multiple times
+			frameMeta.Data = nil
+		}
+		frame.Meta.Custom = &frameMeta
+	}
+	return frames, err
+}
+
+// GetGoogleSheetsResponse converts the grid data of each range into a frame. Cell types are read from the effective values
+// and the number formats. Frozen rows of the sheet are used as header rows when the range starts from the first row
+func GetGoogleSheetsResponse(ctx context.Context, urlResponseObject any, query models.Query) (data.Frames, error) {
 	logger := backend.Logger.FromContext(ctx)
-	frame := GetDummyFrame(query)
 	sheetsString, ok := urlResponseObject.(string)
 	if !ok {
 		logger.Debug("error getting response for query", "error", "invalid response received from google sheets")
-		frame.Meta.Custom = &CustomMeta{
-			Query: query,
-			Error: "invalid response received from google sheets",
-		}
-		return frame, backend.DownstreamError(errors.New("invalid response received from google sheets"))
+		return nil, backend.DownstreamError(errors.New("invalid response received from google sheets"))
 	}
-	sheet := &Spreadsheet{}
-	if err := json.Unmarshal([]byte(sheetsString), &sheet); err != nil {
+	spreadsheet := &Spreadsheet{}
+	if err := json.Unmarshal([]byte(sheetsString), &spreadsheet); err != nil {
 		logger.Debug("error getting response for query", "error", "invalid response received from google sheets")
-		frame.Meta.Custom = &CustomMeta{
-			Query: query,
-			Error: "invalid response received from google sheets",
-		}
-		return frame, backend.DownstreamError(errors.New("invalid response received from google sheets"))
-	}
-	if sheet != nil && len(sheet.Sheets) > 0 && len(sheet.Sheets[0].Data) > 0 {
-		parsedCSV := [][]string{}
-		for _, d := range sheet.Sheets[0].Data {
-			for _, r := range d.RowData {
-				isValidRow := false
-				rowItem := []string{}
-				for _, v := range r.Values {
-					if v.FormattedValue != "" {
-						isValidRow = true
-					}
-					rowItem = append(rowItem, v.FormattedValue)
-				}
-				if isValidRow {
-					parsedCSV = append(parsedCSV, rowItem)
-				}
+		return nil, backend.DownstreamError(errors.New("invalid response received from google sheets"))
+	}
+	location := time.UTC
+	if spreadsheet.Properties != nil && spreadsheet.Properties.TimeZone != "" {
+		if loc, err := time.LoadLocation(spreadsheet.Properties.TimeZone); err == nil {
+			location = loc
+		}
+	}
+	gridCount := 0
+	for _, sheet := range spreadsheet.Sheets {
+		gridCount += len(sheet.Data)
+	}
+	frames := data.Frames{}
+	for _, sheet := range spreadsheet.Sheets {
+		for _, grid := range sheet.Data {
+			frame := GetDummyFrame(query)
+			if gridCount > 1 {
+				frame.Name = getGoogleSheetsGridName(sheet, grid)
+			}
+			headerRows := 1
+			if grid.StartRow == 0 && sheet.Properties != nil && sheet.Properties.GridProperties != nil && sheet.Properties.GridProperties.FrozenRowCount > 1 {
+				headerRows = int(sheet.Properties.GridProperties.FrozenRowCount)
 			}
+			rows := getGoogleSheetsCells(sheet, grid, location)
+			if len(rows) > 0 {
+				headerRows = min(headerRows, len(rows))
+				appendSheetFields(frame, getGoogleSheetsHeaders(rows[:headerRows]), rows[headerRows:], query)
+			}
+			frames = append(frames, frame)
 		}
-		if len(parsedCSV) > 0 {
-			records := parsedCSV[1:]
-			header := parsedCSV[0]
-			framerOptions := gframer.FramerOptions{}
-			for idx, h := range header {
-				column := gframer.ColumnSelector{Selector: h, Alias: h}
-				newHeader := strings.TrimSpace(h)
-				if h == "" {
-					newHeader = fmt.Sprintf("Field %d", idx+1)
-					header[idx] = newHeader
-					column.Selector = newHeader
-					column.Alias = newHeader
-				}
-				for _, col := range query.Columns {
-					if col.Selector == strings.TrimSpace(newHeader) {
-						column.Type = col.Type
-						column.TimeFormat = col.TimeStampFormat
-						if col.Text != "" {
-							header[idx] = col.Text
-							column.Alias = col.Text
-						}
-					}
+	}
+	if len(frames) == 0 {
+		frames = append(frames, GetDummyFrame(query))
+	}
+	return frames, nil
+}
+
+// getGoogleSheetsCells converts the grid data into cells. Cells of the merged ranges get the value of the top left cell
+// and the rows without values are skipped
+func getGoogleSheetsCells(sheet *Sheet, grid *GridData, location *time.Location) [][]sheetCell {
+	rows := make([][]sheetCell, len(grid.RowData))
+	for i, row := range grid.RowData {
+		if row == nil {
+			continue
+		}
+		rows[i] = make([]sheetCell, len(row.Values))
+		for j, value := range row.Values {
+			rows[i][j] = getGoogleSheetsCell(value, location)
+		}
+	}
+	for _, merge := range sheet.Merges {
+		if merge == nil {
+			continue
+		}
+		topRow, leftCol := merge.StartRowIndex-grid.StartRow, merge.StartColumnIndex-grid.StartColumn
+		if topRow < 0 || leftCol < 0 || topRow >= int64(len(rows)) || leftCol >= int64(len(rows[topRow])) {
+			continue
+		}
+		cell := rows[topRow][leftCol]
+		for r := topRow; r < merge.EndRowIndex-grid.StartRow && r < int64(len(rows)); r++ {
+			for c := leftCol; c < merge.EndColumnIndex-grid.StartColumn; c++ {
+				for int64(len(rows[r])) <= c {
+					rows[r] = append(rows[r], sheetCell{})
 				}
-				framerOptions.Columns = append(framerOptions.Columns, column)
+				rows[r][c] = cell
 			}
-			out := []any{}
-			for _, row := range records {
-				item := map[string]any{}
-				for colId, col := range header {
-					if colId < len(row) {
-						item[col] = row[colId]
-					}
-				}
-				out = append(out, item)
+		}
+	}
+	out := [][]sheetCell{}
+	for _, row := range rows {
+		for _, cell := range row {
+			if cell.kind != sheetCellEmpty {
+				out = append(out, row)
+				break
 			}
-			// We are not adding error source here as errors from ToDataFrame will be considered
-			// plugin errors, as the issue is with the plugin's handling of the data, not the data itself.
-			return gframer.ToDataFrame(out, framerOptions)
 		}
 	}
-	return frame, nil
+	return out
+}
+
+func getGoogleSheetsCell(value *CellData, location *time.Location) sheetCell {
+	if value == nil || value.EffectiveValue == nil {
+		return sheetCell{}
+	}
+	cell := sheetCell{formatted: value.FormattedValue}
+	switch v := value.EffectiveValue; {
+	case v.ErrorValue != nil:
+		return sheetCell{}
+	case v.BoolValue != nil:
+		cell.kind, cell.raw = sheetCellBool, strconv.FormatBool(*v.BoolValue)
+	case v.StringValue != nil:
+		cell.kind, cell.raw = sheetCellString, *v.StringValue
+	case v.NumberValue != nil:
+		cell.kind, cell.raw, cell.number = sheetCellNumber, strconv.FormatFloat(*v.NumberValue, 'f', -1, 64), *v.NumberValue
+		if formatType := getGoogleSheetsNumberFormatType(value); formatType == "DATE" || formatType == "DATE_TIME" {
+			cell.kind, cell.time = sheetCellTime, getGoogleSheetsTime(*v.NumberValue, location)
+		}
+	default:
+		return sheetCell{}
+	}
+	if cell.formatted == "" {
+		cell.formatted = cell.raw
+	}
+	return cell
+}
+
+// getGoogleSheetsNumberFormatType returns the number format type set by the user. Effective format is used
+// when the user didn't set any format such as for the dates identified by google sheets automatically
+func getGoogleSheetsNumberFormatType(value *CellData) string {
+	for _, format := range []*CellFormat{value.UserEnteredFormat, value.EffectiveFormat} {
+		if format != nil && format.NumberFormat != nil && format.NumberFormat.Type != "" {
+			return format.NumberFormat.Type
+		}
+	}
+	return ""
+}
+
+// getGoogleSheetsTime converts the serial number into time. Serial numbers are the days since 30 December 1899
+// in the time zone of the spreadsheet
+func getGoogleSheetsTime(serial float64, location *time.Location) time.Time {
+	days := math.Floor(serial)
+	seconds := math.Round((serial - days) * 86400)
+	return time.Date(1899, 12, 30+int(days), 0, 0, int(seconds), 0, location).UTC()
+}
+
+// getGoogleSheetsHeaders returns the header of each column. Values of multiple header rows are joined with space and
+// the repeated values of vertically merged header cells are only added once
+func getGoogleSheetsHeaders(rows [][]sheetCell) []string {
+	columns := 0
+	for _, row := range rows {
+		columns = max(columns, len(row))
+	}
+	headers := make([]string, columns)
+	for i := range headers {
+		parts := []string{}
+		for _, row := range rows {
+			if i >= len(row) {
+				continue
+			}
+			part := strings.TrimSpace(row[i].formatted)
+			if part != "" && (len(parts) == 0 || parts[len(parts)-1] != part) {
+				parts = append(parts, part)
+			}
+		}
+		headers[i] = strings.Join(parts, " ")
+	}
+	return headers
+}
+
+// getGoogleSheetsGridName returns the range of the grid data in A1 notation such as Sheet1!A1:D10
+func getGoogleSheetsGridName(sheet *Sheet, grid *GridData) string {
+	title := ""
+	if sheet.Properties != nil {
+		title = sheet.Properties.Title
+	}
+	columns := 0
+	for _, row := range grid.RowData {
+		if row != nil {
+			columns = max(columns, len(row.Values))
+		}
+	}
+	from, err := excelize.CoordinatesToCellName(int(grid.StartColumn)+1, int(grid.StartRow)+1)
+	if err != nil || len(grid.RowData) == 0 || columns == 0 {
+		return title
+	}
+	to, err := excelize.CoordinatesToCellName(int(grid.StartColumn)+columns, int(grid.StartRow)+len(grid.RowData))
+	if err != nil {
+		return title
+	}
+	return fmt.Sprintf("%s!%s:%s", title, from, to)
 }
@@ -0,0 +1,123 @@
+package infinity
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-infinity-datasource/pkg/models"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetGoogleSheetsURL(t *testing.T) {
+	tests := []struct {
+		name  string
+		query models.Query
+		want  string
+	}{
+		{name: "without range", query: models.Query{Spreadsheet: "foo"}, want: "https://sheets.googleapis.com/v4/spreadsheets/foo?includeGridData=true"},
+		{name: "sheet name without range", query: models.Query{Spreadsheet: "foo", SheetName: "Sheet1"}, want: "https://sheets.googleapis.com/v4/spreadsheets/foo?includeGridData=true&ranges=Sheet1"},
+		{name: "sheet name and range", query: models.Query{Spreadsheet: "foo", SheetName: "Sheet1", SheetRange: "A1:D10"}, want: "https://sheets.googleapis.com/v4/spreadsheets/foo?includeGridData=true&ranges=Sheet1%21A1%3AD10"},
+		{
+			name:  "multiple ranges",
+			query: models.Query{Spreadsheet: "foo", SheetName: "Sheet1", SheetRange: "A1:B2, 'Q1, Q2'!C:D"},
+			want:  "https://sheets.googleapis.com/v4/spreadsheets/foo?includeGridData=true&ranges=Sheet1%21A1%3AB2&ranges=%27Q1%2C+Q2%27%21C%3AD",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, GetGoogleSheetsURL(tt.query))
+		})
+	}
+}
+
+func TestGetGoogleSheetsResponse(t *testing.T) {
+	t.Run("should read the typed values and merged cells", func(t *testing.T) {
+		response := `{
+			"properties": { "timeZone": "Europe/London" },
+			"sheets": [{
+				"properties": { "title": "KPIs", "gridProperties": { "frozenRowCount": 2 } },
+				"merges": [
+					{ "startRowIndex": 0, "endRowIndex": 2, "startColumnIndex": 0, "endColumnIndex": 1 },
+					{ "startRowIndex": 0, "endRowIndex": 1, "startColumnIndex": 1, "endColumnIndex": 3 },
+					{ "startRowIndex": 3, "endRowIndex": 5, "startColumnIndex": 3, "endColumnIndex": 4 }
+				],
+				"data": [{
+					"rowData": [
+						{ "values": [
+							{ "formattedValue": "Day", "effectiveValue": { "stringValue": "Day" } },
+							{ "formattedValue": "Revenue", "effectiveValue": { "stringValue": "Revenue" } },
+							{},
+							{ "formattedValue": "Region", "effectiveValue": { "stringValue": "Region" } }
+						] },
+						{ "values": [
+							{},
+							{ "formattedValue": "EUR", "effectiveValue": { "stringValue": "EUR" } },
+							{ "formattedValue": "Met", "effectiveValue": { "stringValue": "Met" } }
+						] },
+						{},
+						{ "values": [
+							{ "formattedValue": "1/7/2024", "effectiveValue": { "numberValue": 45474.5 }, "userEnteredFormat": { "numberFormat": { "type": "DATE" } } },
+							{ "formattedValue": "€1,250.50", "effectiveValue": { "numberValue": 1250.5 }, "userEnteredFormat": { "numberFormat": { "type": "CURRENCY" } } },
+							{ "formattedValue": "TRUE", "effectiveValue": { "boolValue": true } },
+							{ "formattedValue": "EMEA", "effectiveValue": { "stringValue": "EMEA" } }
+						] },
+						{ "values": [
+							{ "formattedValue": "2/7/2024", "effectiveValue": { "numberValue": 45475 }, "effectiveFormat": { "numberFormat": { "type": "DATE" } } },
+							{ "formattedValue": "#DIV/0!", "effectiveValue": { "errorValue": { "type": "DIVIDE_BY_ZERO" } } },
+							{ "formattedValue": "FALSE", "effectiveValue": { "boolValue": false } }
+						] }
+					]
+				}]
+			}]
+		}`
+		frames, err := GetGoogleSheetsResponse(context.Background(), response, models.Query{RefID: "A"})
+		require.NoError(t, err)
+		require.Len(t, frames, 1)
+		require.Equal(t, "A", frames[0].Name)
+		// dates are in the time zone of the spreadsheet. 1 July 2024 is in BST
+		day1, day2 := time.Date(2024, 7, 1, 11, 0, 0, 0, time.UTC), time.Date(2024, 7, 1, 23, 0, 0, 0, time.UTC)
+		want := data.NewFrame("A",
+			data.NewField("Day", nil, []*time.Time{&day1, &day2}),
+			data.NewField("Revenue EUR", nil, []*float64{toPointer(1250.5), nil}),
+			data.NewField("Revenue Met", nil, []*bool{toPointer(true), toPointer(false)}),
+			data.NewField("Region", nil, []*string{toPointer("EMEA"), toPointer("EMEA")}),
+		)
+		require.Equal(t, want.Fields, frames[0].Fields)
+	})
+	t.Run("should return a frame for each range", func(t *testing.T) {
+		response := `{
+			"sheets": [
+				{ "properties": { "title": "Sheet1", "gridProperties": { "frozenRowCount": 1 } }, "data": [
+					{ "rowData": [
+						{ "values": [{ "formattedValue": "name", "effectiveValue": { "stringValue": "name" } }] },
+						{ "values": [{ "formattedValue": "foo", "effectiveValue": { "stringValue": "foo" } }] }
+					] },
+					{ "startRow": 4, "startColumn": 2, "rowData": [
+						{ "values": [{ "formattedValue": "value", "effectiveValue": { "stringValue": "value" } }, { "formattedValue": "mixed", "effectiveValue": { "stringValue": "mixed" } }] },
+						{ "values": [{ "formattedValue": "1", "effectiveValue": { "numberValue": 1 } }, { "formattedValue": "1.0", "effectiveValue": { "numberValue": 1 } }] },
+						{ "values": [{ "formattedValue": "2", "effectiveValue": { "numberValue": 2 } }, { "formattedValue": "bar", "effectiveValue": { "stringValue": "bar" } }] }
+					] }
+				] },
+				{ "properties": { "title": "Sheet2" }, "data": [ {} ] }
+			]
+		}`
+		frames, err := GetGoogleSheetsResponse(context.Background(), response, models.Query{RefID: "A", Columns: []models.InfinityColumn{{Selector: "value", Text: "Value", Type: "string"}}})
+		require.NoError(t, err)
+		require.Len(t, frames, 3)
+		require.Equal(t, "Sheet1!A1:A2", frames[0].Name)
+		require.Equal(t, data.NewField("name", nil, []*string{toPointer("foo")}), frames[0].Fields[0])
+		require.Equal(t, "Sheet1!C5:D7", frames[1].Name)
+		require.Equal(t, data.NewField("Value", nil, []*string{toPointer("1"), toPointer("2")}), frames[1].Fields[0])
+		require.Equal(t, data.NewField("mixed", nil, []*string{toPointer("1.0"), toPointer("bar")}), frames[1].Fields[1])
+		require.Equal(t, "Sheet2", frames[2].Name)
+		require.Empty(t, frames[2].Fields)
+	})
+	t.Run("should return error for invalid response", func(t *testing.T) {
+		_, err := GetGoogleSheetsResponse(context.Background(), "foo", models.Query{RefID: "A"})
+		require.Error(t, err)
+		require.True(t, backend.IsDownstreamError(err))
+	})
+}
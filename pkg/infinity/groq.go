@@ -0,0 +1,69 @@
+package infinity
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/grafana/grafana-infinity-datasource/pkg/groq"
+	"github.com/grafana/grafana-infinity-datasource/pkg/models"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/tracing"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// isGROQQuery reports whether the query should be evaluated using GROQ. Same as the frontend, the groq parser is only applied to json and graphql queries
+func isGROQQuery(query models.Query) bool {
+	if query.Type == models.QueryTypeGROQ {
+		return true
+	}
+	return query.Parser == models.InfinityParserGROQ && (query.Type == models.QueryTypeJSON || query.Type == models.QueryTypeGraphQL)
+}
+
+// GetGROQBackendResponse evaluates the GROQ query against the response and converts the result into a frame using the backend json parser
+func GetGROQBackendResponse(ctx context.Context, response any, query models.Query) (*data.Frame, error) {
+	ctx, span := tracing.DefaultTracer().Start(ctx, "GetGROQBackendResponse")
+	defer span.End()
+	result, err := groq.Run(query.GROQ, response)
+	if err != nil {
+		span.RecordError(err)
+		frame := GetDummyFrame(query)
+		if errors.Is(err, groq.ErrInvalidQuery) || errors.Is(err, groq.ErrUnknownFunction) || errors.Is(err, groq.ErrParsingInput) {
+			return frame, backend.DownstreamError(fmt.Errorf("error evaluating groq query: %w", err))
+		}
+		return frame, backend.PluginError(fmt.Errorf("error evaluating groq query: %w", err))
+	}
+	// the groq query already shapes the data, so the json parser selectors are not applied
+	jsonQuery := query
+	jsonQuery.RootSelector = ""
+	jsonQuery.Columns = []models.InfinityColumn{}
+	frame, err := GetJSONBackendResponse(ctx, result.Data, jsonQuery)
+	if frame != nil {
+		sortFields(frame, result.Columns)
+	}
+	return frame, err
+}
+
+// sortFields orders the frame fields by their position in columns. Fields not listed in columns are kept at the end in their current order
+func sortFields(frame *data.Frame, columns []string) {
+	position := map[string]int{}
+	for i, column := range columns {
+		if _, ok := position[column]; !ok {
+			position[column] = i
+		}
+	}
+	slices.SortStableFunc(frame.Fields, func(a, b *data.Field) int {
+		pa, aok := position[a.Name]
+		pb, bok := position[b.Name]
+		switch {
+		case aok && bok:
+			return pa - pb
+		case aok:
+			return -1
+		case bok:
+			return 1
+		}
+		return 0
+	})
+}
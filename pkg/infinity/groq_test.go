@@ -0,0 +1,23 @@
+package infinity
+
+import (
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSortFields(t *testing.T) {
+	frame := data.NewFrame("response",
+		data.NewField("address.city", nil, []string{}),
+		data.NewField("age", nil, []float64{}),
+		data.NewField("extra", nil, []string{}),
+		data.NewField("name", nil, []string{}),
+	)
+	sortFields(frame, []string{"name", "age", "address"})
+	names := []string{}
+	for _, field := range frame.Fields {
+		names = append(names, field.Name)
+	}
+	require.Equal(t, []string{"name", "age", "address.city", "extra"}, names)
+}
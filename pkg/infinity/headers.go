@@ -28,6 +28,8 @@ const (
 	headerKeyAcceptEncoding = "Accept-Encoding"
 	HeaderKeyAuthorization  = "Authorization"
 	HeaderKeyIdToken        = "X-Id-Token"
+	HeaderKeyGoogleAPIKey   = "X-Goog-Api-Key"
+	headerKeyLink           = "Link"
 )
 
 func ApplyAcceptHeader(_ context.Context, query models.Query, settings models.InfinitySettings, req *http.Request, includeSect bool) *http.Request {
@@ -40,6 +42,24 @@ func ApplyAcceptHeader(_ context.Context, query models.Query, settings models.In
 	if query.Type == models.QueryTypeXML {
 		req.Header.Set(headerKeyAccept, `text/xml;q=0.9,text/plain`)
 	}
+	if query.Type == models.QueryTypeYAML {
+		req.Header.Set(headerKeyAccept, `application/yaml,application/x-yaml;q=0.9,text/yaml;q=0.9,text/plain;q=0.8`)
+	}
+	if query.Type == models.QueryTypeParquet {
+		req.Header.Set(headerKeyAccept, `application/vnd.apache.parquet,application/octet-stream;q=0.9`)
+	}
+	if query.Type == models.QueryTypeArrow {
+		req.Header.Set(headerKeyAccept, `application/vnd.apache.arrow.file,application/vnd.apache.arrow.stream,application/octet-stream;q=0.9`)
+	}
+	if query.Type == models.QueryTypeXLSX {
+		req.Header.Set(headerKeyAccept, `application/vnd.openxmlformats-officedocument.spreadsheetml.sheet,application/octet-stream;q=0.9`)
+	}
+	if query.Type == models.QueryTypePrometheus {
+		req.Header.Set(headerKeyAccept, `application/openmetrics-text;version=1.0.0;q=0.9,text/plain;version=0.0.4;q=0.8,*/*;q=0.1`)
+	}
+	if query.Type == models.QueryTypeNDJSON {
+		req.Header.Set(headerKeyAccept, `application/x-ndjson,application/jsonl;q=0.9,text/plain;q=0.8`)
+	}
 	return req
 }
 
@@ -140,6 +160,17 @@ func ApplyApiKeyAuth(_ context.Context, settings models.InfinitySettings, req *h
 	return req
 }
 
+func ApplyGoogleAPIKeyAuth(_ context.Context, settings models.InfinitySettings, req *http.Request, includeSect bool) *http.Request {
+	if settings.AuthenticationMethod == models.AuthenticationMethodGoogle && settings.GoogleSettings.AuthType == models.GoogleAuthTypeAPIKey {
+		apiKeyHeader := dummyHeader
+		if includeSect {
+			apiKeyHeader = settings.GoogleAPIKey
+		}
+		req.Header.Set(HeaderKeyGoogleAPIKey, apiKeyHeader)
+	}
+	return req
+}
+
 func ApplyForwardedOAuthIdentity(_ context.Context, requestHeaders map[string]string, settings models.InfinitySettings, req *http.Request, includeSect bool) *http.Request {
 	if settings.ForwardOauthIdentity {
 		authHeader := dummyHeader
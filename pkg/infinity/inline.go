@@ -2,8 +2,10 @@ package infinity
 
 import (
 	"context"
+	"encoding/base64"
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/grafana/grafana-infinity-datasource/pkg/models"
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
@@ -13,13 +15,53 @@ import (
 
 func GetFrameForInlineSources(ctx context.Context, query models.Query) (*data.Frame, error) {
 	frame := GetDummyFrame(query)
-	if query.Type == models.QueryTypeGROQ || query.Type == models.QueryTypeUQL {
-		return frame, nil
+	if isUQLQuery(query) {
+		frame, err := GetUQLBackendResponse(ctx, query.Data, query)
+		if err != nil {
+			return frame, err
+		}
+		return PostProcessFrame(ctx, frame, query)
+	}
+	if isGROQQuery(query) {
+		frame, err := GetGROQBackendResponse(ctx, query.Data, query)
+		if err != nil {
+			return frame, err
+		}
+		return PostProcessFrame(ctx, frame, query)
 	}
 	if query.Parser != models.InfinityParserBackend && query.Parser != models.InfinityParserJQBackend {
 		return frame, nil
 	}
 	switch query.Type {
+	case models.QueryTypeNDJSON:
+		frame, err := GetNDJSONBackendResponse(ctx, query.Data, query)
+		if err != nil {
+			return frame, err
+		}
+		return PostProcessFrame(ctx, frame, query)
+	case models.QueryTypePrometheus:
+		frame, err := GetPrometheusBackendResponse(ctx, query.Data, query)
+		if err != nil {
+			return frame, err
+		}
+		return PostProcessFrame(ctx, frame, query)
+	case models.QueryTypeXLSX:
+		// binary files are provided as base64 encoded string in the inline data
+		content, err := base64.StdEncoding.DecodeString(strings.TrimSpace(query.Data))
+		if err != nil {
+			return frame, backend.DownstreamError(fmt.Errorf("error decoding the base64 encoded xlsx data. %w", err))
+		}
+		frame, err := GetXLSXBackendResponse(ctx, content, query)
+		if err != nil {
+			return frame, err
+		}
+		return PostProcessFrame(ctx, frame, query)
+	case models.QueryTypeYAML:
+		frame, err := GetYAMLBackendResponse(ctx, query.Data, query)
+		if err != nil {
+			return frame, err
+		}
+		return PostProcessFrame(ctx, frame, query)
 	case models.QueryTypeCSV, models.QueryTypeTSV:
 		frame, err := GetCSVBackendResponse(ctx, query.Data, query)
 		if err != nil {
@@ -18,6 +18,10 @@ type CustomMeta struct {
 	ResponseCodeFromServer int           `json:"responseCodeFromServer"`
 	Duration               time.Duration `json:"duration"`
 	Error                  string        `json:"error"`
+	// Attempts is the number of requests made to the server. Only set when the retries are enabled
+	Attempts int `json:"attempts,omitempty"`
+	// RateLimitWait is the time the request was queued by the rate limiter
+	RateLimitWait time.Duration `json:"rateLimitWait,omitempty"`
 }
 
 func GetDummyFrame(query models.Query) *data.Frame {
@@ -49,9 +53,14 @@ func WrapMetaForInlineQuery(ctx context.Context, frame *data.Frame, err error, q
 		customMeta.Error = err.Error()
 		err = backend.PluginError(err)
 	}
+	var notices []data.Notice
+	if frame.Meta != nil {
+		notices = frame.Meta.Notices
+	}
 	frame.Meta = &data.FrameMeta{
 		ExecutedQueryString: "This feature is not available for this type of query yet",
 		Custom:              customMeta,
+		Notices:             notices,
 	}
 	frame = ApplyLogMeta(ctx, frame, query)
 	frame = ApplyTraceMeta(ctx, frame, query)
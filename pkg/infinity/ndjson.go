@@ -0,0 +1,134 @@
+package infinity
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/grafana-infinity-datasource/pkg/models"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/tracing"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+const (
+	// lines longer than this can't be parsed by the line based parsers
+	maxLineSize = 10 * 1024 * 1024
+	// number of malformed line numbers listed in the notice
+	maxMalformedLinesInNotice = 10
+)
+
+func isNDJSONBackendQuery(query models.Query) bool {
+	return query.Type == models.QueryTypeNDJSON && isBackendQuery(query)
+}
+
+// ndjsonResponse holds the decoded records of the newline delimited JSON response along with the line numbers of the
+// lines which are not valid json
+type ndjsonResponse struct {
+	records        []any
+	malformedLines []int
+}
+
+// GetNDJSONBackendResponse converts the records of the newline delimited JSON response into a frame using the backend json
+// parser. Response can be the raw string or the already parsed response. Malformed lines are skipped and reported as a
+// notice instead of failing the query
+func GetNDJSONBackendResponse(ctx context.Context, response any, query models.Query) (*data.Frame, error) {
+	ctx, span := tracing.DefaultTracer().Start(ctx, "GetNDJSONBackendResponse")
+	defer span.End()
+	parsed, ok := response.(*ndjsonResponse)
+	if !ok {
+		responseString, _ := response.(string)
+		var err error
+		if parsed, err = parseNDJSON(strings.NewReader(responseString)); err != nil {
+			span.RecordError(err)
+			return GetDummyFrame(query), backend.DownstreamError(fmt.Errorf("error reading ndjson response. %w", err))
+		}
+	}
+	frame, err := GetJSONBackendResponse(ctx, parsed.records, query)
+	if err != nil {
+		return frame, err
+	}
+	if len(parsed.malformedLines) > 0 {
+		backend.Logger.FromContext(ctx).Debug("skipped malformed ndjson lines", "count", len(parsed.malformedLines))
+		frame.AppendNotices(data.Notice{
+			Severity: data.NoticeSeverityWarning,
+			Text:     getMalformedLinesNotice("ndjson", parsed.malformedLines),
+		})
+	}
+	return frame, nil
+}
+
+// parseNDJSON reads the input line by line and decodes each non empty line as a json value. The input is never read as
+// a whole. Lines which are not valid json or longer than maxLineSize are reported as malformed lines
+func parseNDJSON(reader io.Reader) (*ndjsonResponse, error) {
+	out := &ndjsonResponse{records: []any{}}
+	splitter := &lineSplitter{maxSize: maxLineSize}
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	scanner.Split(splitter.split)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		if splitter.tooLong {
+			splitter.tooLong = false
+			out.malformedLines = append(out.malformedLines, lineNumber)
+			continue
+		}
+		line := scanner.Bytes()
+		if lineNumber == 1 {
+			line = removeBOMContent(line)
+		}
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		var record any
+		if err := json.Unmarshal(line, &record); err != nil {
+			out.malformedLines = append(out.malformedLines, lineNumber)
+			continue
+		}
+		out.records = append(out.records, record)
+	}
+	return out, scanner.Err()
+}
+
+// lineSplitter is same as bufio.ScanLines except that the lines longer than maxSize don't stop the scanner. Such lines
+// are returned as an empty token with tooLong set and the rest of the line is discarded
+type lineSplitter struct {
+	maxSize  int
+	tooLong  bool
+	skipping bool
+}
+
+func (l *lineSplitter) split(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if l.skipping {
+		if i := bytes.IndexByte(data, '\n'); i >= 0 {
+			l.skipping = false
+			return i + 1, nil, nil
+		}
+		return len(data), nil, nil
+	}
+	advance, token, err = bufio.ScanLines(data, atEOF)
+	if advance == 0 && token == nil && err == nil && len(data) >= l.maxSize {
+		l.tooLong, l.skipping = true, true
+		return len(data), []byte{}, nil
+	}
+	return advance, token, err
+}
+
+// getMalformedLinesNotice returns the text of the notice listing the lines skipped by the line based parsers
+func getMalformedLinesNotice(format string, malformedLines []int) string {
+	lines := []string{}
+	for _, line := range malformedLines[:min(len(malformedLines), maxMalformedLinesInNotice)] {
+		lines = append(lines, strconv.Itoa(line))
+	}
+	if len(malformedLines) > maxMalformedLinesInNotice {
+		lines = append(lines, "...")
+	}
+	return fmt.Sprintf("skipped %d malformed line(s) in the %s response. line number(s): %s", len(malformedLines), format, strings.Join(lines, ", "))
+}
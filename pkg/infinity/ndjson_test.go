@@ -0,0 +1,67 @@
+package infinity
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/grafana/grafana-infinity-datasource/pkg/models"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseNDJSON(t *testing.T) {
+	tests := []struct {
+		name               string
+		input              string
+		wantRecords        []any
+		wantMalformedLines []int
+	}{
+		{name: "empty input", input: "", wantRecords: []any{}},
+		{name: "objects", input: "{\"a\":1}\n{\"a\":2}\n", wantRecords: []any{map[string]any{"a": 1.0}, map[string]any{"a": 2.0}}},
+		{name: "crlf and blank lines", input: "{\"a\":1}\r\n\r\n  \n[1,2]\r\n\"foo\"", wantRecords: []any{map[string]any{"a": 1.0}, []any{1.0, 2.0}, "foo"}},
+		{name: "byte order mark", input: "\xef\xbb\xbf{\"a\":1}", wantRecords: []any{map[string]any{"a": 1.0}}},
+		{name: "lines longer than the max line size", input: "{\"a\":1}\n\"" + strings.Repeat("x", maxLineSize+10) + "\"\n{\"a\":2}\n" + strings.Repeat("y", maxLineSize), wantRecords: []any{map[string]any{"a": 1.0}, map[string]any{"a": 2.0}}, wantMalformedLines: []int{2, 4}},
+		{name: "malformed lines", input: "{\"a\":1}\n{\"a\":\nnot json\n{\"a\":2}", wantRecords: []any{map[string]any{"a": 1.0}, map[string]any{"a": 2.0}}, wantMalformedLines: []int{2, 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseNDJSON(strings.NewReader(tt.input))
+			require.NoError(t, err)
+			require.Equal(t, tt.wantRecords, got.records)
+			require.Equal(t, tt.wantMalformedLines, got.malformedLines)
+		})
+	}
+}
+
+func TestGetMalformedLinesNotice(t *testing.T) {
+	require.Equal(t, "skipped 2 malformed line(s) in the ndjson response. line number(s): 2, 5", getMalformedLinesNotice("ndjson", []int{2, 5}))
+	require.Equal(t, "skipped 12 malformed line(s) in the ndjson response. line number(s): 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, ...", getMalformedLinesNotice("ndjson", []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}))
+}
+
+func TestNDJSONQuery(t *testing.T) {
+	input := "{\"name\":\"foo\"}\n{invalid\n{\"name\":\"bar\"}\n"
+	wantNotice := data.Notice{Severity: data.NoticeSeverityWarning, Text: "skipped 1 malformed line(s) in the ndjson response. line number(s): 2"}
+	t.Run("url source", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Contains(t, r.Header.Get("Accept"), "application/x-ndjson")
+			w.Header().Set("Content-Type", "application/x-ndjson")
+			_, _ = w.Write([]byte(input))
+		}))
+		defer server.Close()
+		query := models.Query{RefID: "A", Type: models.QueryTypeNDJSON, Source: "url", Parser: models.InfinityParserBackend, URL: server.URL, URLOptions: models.URLOptions{Method: http.MethodGet}}
+		frame, err := GetFrameForURLSources(context.Background(), &backend.PluginContext{}, query, Client{HttpClient: server.Client()}, nil)
+		require.NoError(t, err)
+		require.Equal(t, []any{map[string]any{"name": "foo"}, map[string]any{"name": "bar"}}, frame.Meta.Custom.(*CustomMeta).Data)
+		require.Equal(t, []data.Notice{wantNotice}, frame.Meta.Notices)
+	})
+	t.Run("inline source", func(t *testing.T) {
+		query := models.Query{RefID: "A", Type: models.QueryTypeNDJSON, Source: "inline", Parser: models.InfinityParserBackend, Data: input}
+		frame, err := GetFrameForInlineSources(context.Background(), query)
+		require.NoError(t, err)
+		require.Equal(t, []data.Notice{wantNotice}, frame.Meta.Notices)
+	})
+}
@@ -47,7 +47,12 @@ func PostProcessFrame(ctx context.Context, frame *data.Frame, query models.Query
 		}
 		return summaryFrame, err
 	}
-	frame.Meta = &data.FrameMeta{Custom: &CustomMeta{Query: query}}
+	var notices []data.Notice
+	if frame.Meta != nil {
+		// notices added by the parsers such as skipped lines are kept
+		notices = frame.Meta.Notices
+	}
+	frame.Meta = &data.FrameMeta{Custom: &CustomMeta{Query: query}, Notices: notices}
 	if query.Source == "inline" {
 		frame, err = WrapMetaForInlineQuery(ctx, frame, err, query)
 		if err != nil {
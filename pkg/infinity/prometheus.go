@@ -0,0 +1,296 @@
+package infinity
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-infinity-datasource/pkg/models"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/tracing"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+const (
+	prometheusFieldName      = "__name__"
+	prometheusFieldValue     = "value"
+	prometheusFieldTimestamp = "timestamp"
+	// labels using the names of the value or timestamp fields are prefixed to avoid duplicate field names
+	prometheusConflictingLabelPrefix = "label_"
+	openMetricsEOF                   = "# EOF"
+)
+
+var errInvalidPrometheusSample = errors.New("invalid sample")
+
+type prometheusSample struct {
+	name      string
+	labels    map[string]string
+	value     float64
+	timestamp *time.Time
+}
+
+// GetPrometheusBackendResponse parses the Prometheus text or OpenMetrics exposition format into a numeric long frame with
+// the metric name, one field per label, the value and the optional timestamp. Malformed lines are skipped and reported as a notice
+func GetPrometheusBackendResponse(ctx context.Context, responseString string, query models.Query) (*data.Frame, error) {
+	ctx, span := tracing.DefaultTracer().Start(ctx, "GetPrometheusBackendResponse")
+	defer span.End()
+	samples, malformedLines, err := parsePrometheusMetrics(responseString)
+	if err != nil {
+		span.RecordError(err)
+		return GetDummyFrame(query), backend.DownstreamError(fmt.Errorf("error reading prometheus metrics response. %w", err))
+	}
+	frame := getPrometheusFrame(query, samples)
+	if len(malformedLines) > 0 {
+		backend.Logger.FromContext(ctx).Debug("skipped malformed prometheus metrics lines", "count", len(malformedLines))
+		frame.AppendNotices(data.Notice{
+			Severity: data.NoticeSeverityWarning,
+			Text:     getMalformedLinesNotice("prometheus metrics", malformedLines),
+		})
+	}
+	return frame, nil
+}
+
+// parsePrometheusMetrics reads the samples line by line. Comments such as HELP, TYPE and UNIT are ignored as the samples
+// of histograms and summaries are returned as they are exposed. Returns the line numbers of the samples which can't be parsed
+func parsePrometheusMetrics(input string) (samples []prometheusSample, malformedLines []int, err error) {
+	// OpenMetrics exposition must end with EOF and uses seconds instead of milliseconds for the timestamps
+	openMetrics := strings.HasSuffix(strings.TrimRight(input, " \t\r\n"), openMetricsEOF)
+	samples = []prometheusSample{}
+	scanner := bufio.NewScanner(strings.NewReader(input))
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
+			if openMetrics && line == openMetricsEOF {
+				break
+			}
+			continue
+		}
+		sample, err := parsePrometheusSample(line, openMetrics)
+		if err != nil {
+			malformedLines = append(malformedLines, lineNumber)
+			continue
+		}
+		samples = append(samples, sample)
+	}
+	return samples, malformedLines, scanner.Err()
+}
+
+// parsePrometheusSample parses a single sample line such as `http_requests_total{method="post",code="200"} 1027 1395066363000`.
+// Quoted metric names inside the braces and OpenMetrics exemplars are supported
+func parsePrometheusSample(line string, openMetrics bool) (prometheusSample, error) {
+	sample := prometheusSample{labels: map[string]string{}}
+	pos := strings.IndexFunc(line, func(r rune) bool { return !isPrometheusNameChar(r) })
+	if pos < 0 {
+		return sample, errInvalidPrometheusSample
+	}
+	sample.name = line[:pos]
+	if line[pos] == '{' {
+		end, err := parsePrometheusLabels(line, pos+1, &sample)
+		if err != nil {
+			return sample, err
+		}
+		pos = end
+	}
+	if sample.name == "" {
+		return sample, errInvalidPrometheusSample
+	}
+	rest := line[pos:]
+	if rest == "" || (rest[0] != ' ' && rest[0] != '\t') {
+		return sample, errInvalidPrometheusSample
+	}
+	// exemplars are not part of the sample
+	rest, _, _ = strings.Cut(rest, "#")
+	tokens := strings.Fields(rest)
+	if len(tokens) == 0 || len(tokens) > 2 {
+		return sample, errInvalidPrometheusSample
+	}
+	value, err := strconv.ParseFloat(tokens[0], 64)
+	if err != nil {
+		return sample, errInvalidPrometheusSample
+	}
+	sample.value = value
+	if len(tokens) == 2 {
+		timestamp, err := parsePrometheusTimestamp(tokens[1], openMetrics)
+		if err != nil {
+			return sample, err
+		}
+		sample.timestamp = &timestamp
+	}
+	return sample, nil
+}
+
+// parsePrometheusLabels parses the labels starting after the opening brace and returns the position after the closing brace
+func parsePrometheusLabels(line string, pos int, sample *prometheusSample) (int, error) {
+	for {
+		pos = skipPrometheusSpaces(line, pos)
+		if pos >= len(line) {
+			return pos, errInvalidPrometheusSample
+		}
+		if line[pos] == '}' {
+			return pos + 1, nil
+		}
+		var key string
+		if line[pos] == '"' {
+			value, end, err := readPrometheusQuotedString(line, pos)
+			if err != nil {
+				return pos, err
+			}
+			pos = skipPrometheusSpaces(line, end)
+			if pos < len(line) && line[pos] != '=' {
+				// quoted string without value is the metric name using utf-8 characters
+				if sample.name != "" {
+					return pos, errInvalidPrometheusSample
+				}
+				sample.name = value
+			} else {
+				key = value
+			}
+		} else {
+			end := pos
+			for end < len(line) && isPrometheusNameChar(rune(line[end])) && line[end] != ':' {
+				end++
+			}
+			key = line[pos:end]
+			if key == "" {
+				return pos, errInvalidPrometheusSample
+			}
+			pos = skipPrometheusSpaces(line, end)
+		}
+		if key != "" {
+			if pos >= len(line) || line[pos] != '=' {
+				return pos, errInvalidPrometheusSample
+			}
+			pos = skipPrometheusSpaces(line, pos+1)
+			value, end, err := readPrometheusQuotedString(line, pos)
+			if err != nil {
+				return pos, err
+			}
+			sample.labels[key] = value
+			pos = skipPrometheusSpaces(line, end)
+		}
+		if pos >= len(line) {
+			return pos, errInvalidPrometheusSample
+		}
+		switch line[pos] {
+		case ',':
+			pos++
+		case '}':
+		default:
+			return pos, errInvalidPrometheusSample
+		}
+	}
+}
+
+// readPrometheusQuotedString reads the double quoted string starting at pos and returns the unescaped value and the position after the closing quote
+func readPrometheusQuotedString(line string, pos int) (string, int, error) {
+	if pos >= len(line) || line[pos] != '"' {
+		return "", pos, errInvalidPrometheusSample
+	}
+	value := strings.Builder{}
+	for i := pos + 1; i < len(line); i++ {
+		switch line[i] {
+		case '"':
+			return value.String(), i + 1, nil
+		case '\\':
+			i++
+			if i >= len(line) {
+				return "", i, errInvalidPrometheusSample
+			}
+			switch line[i] {
+			case 'n':
+				value.WriteByte('\n')
+			case '\\', '"':
+				value.WriteByte(line[i])
+			default:
+				value.WriteByte('\\')
+				value.WriteByte(line[i])
+			}
+		default:
+			value.WriteByte(line[i])
+		}
+	}
+	return "", len(line), errInvalidPrometheusSample
+}
+
+// parsePrometheusTimestamp parses the timestamp in milliseconds for the Prometheus text format and in seconds for OpenMetrics
+func parsePrometheusTimestamp(value string, openMetrics bool) (time.Time, error) {
+	if openMetrics {
+		seconds, err := strconv.ParseFloat(value, 64)
+		if err != nil || math.IsNaN(seconds) || math.IsInf(seconds, 0) {
+			return time.Time{}, errInvalidPrometheusSample
+		}
+		return time.UnixMilli(int64(math.Round(seconds * 1000))).UTC(), nil
+	}
+	milliseconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}, errInvalidPrometheusSample
+	}
+	return time.UnixMilli(milliseconds).UTC(), nil
+}
+
+func isPrometheusNameChar(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' || r == ':'
+}
+
+func skipPrometheusSpaces(line string, pos int) int {
+	for pos < len(line) && (line[pos] == ' ' || line[pos] == '\t') {
+		pos++
+	}
+	return pos
+}
+
+// getPrometheusFrame converts the samples into a numeric long frame. Samples without a label get an empty string for the label
+// as Prometheus treats missing and empty labels the same. Timestamp field is only added when at least one sample has a timestamp
+func getPrometheusFrame(query models.Query, samples []prometheusSample) *data.Frame {
+	frame := GetDummyFrame(query)
+	labelNames := []string{}
+	hasTimestamp := false
+	for _, sample := range samples {
+		for name := range sample.labels {
+			if !slices.Contains(labelNames, name) {
+				labelNames = append(labelNames, name)
+			}
+		}
+		hasTimestamp = hasTimestamp || sample.timestamp != nil
+	}
+	slices.Sort(labelNames)
+	names := make([]string, len(samples))
+	values := make([]float64, len(samples))
+	timestamps := make([]*time.Time, len(samples))
+	labelValues := make([][]string, len(labelNames))
+	for i := range labelNames {
+		labelValues[i] = make([]string, len(samples))
+	}
+	for i, sample := range samples {
+		names[i] = sample.name
+		values[i] = sample.value
+		timestamps[i] = sample.timestamp
+		for j, name := range labelNames {
+			labelValues[j][i] = sample.labels[name]
+		}
+	}
+	if hasTimestamp {
+		frame.Fields = append(frame.Fields, data.NewField(prometheusFieldTimestamp, nil, timestamps))
+	}
+	frame.Fields = append(frame.Fields, data.NewField(prometheusFieldName, nil, names))
+	for i, name := range labelNames {
+		if name == prometheusFieldValue || name == prometheusFieldTimestamp {
+			name = prometheusConflictingLabelPrefix + name
+		}
+		frame.Fields = append(frame.Fields, data.NewField(name, nil, labelValues[i]))
+	}
+	frame.Fields = append(frame.Fields, data.NewField(prometheusFieldValue, nil, values))
+	return frame
+}
@@ -0,0 +1,101 @@
+package infinity
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-infinity-datasource/pkg/dataplane"
+	"github.com/grafana/grafana-infinity-datasource/pkg/models"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePrometheusSample(t *testing.T) {
+	ts := time.UnixMilli(1395066363000).UTC()
+	tests := []struct {
+		name        string
+		line        string
+		openMetrics bool
+		want        prometheusSample
+		wantErr     bool
+	}{
+		{name: "without labels", line: "go_goroutines 42", want: prometheusSample{name: "go_goroutines", labels: map[string]string{}, value: 42}},
+		{name: "with labels and timestamp", line: `http_requests_total{method="post",code="200"} 1027 1395066363000`, want: prometheusSample{name: "http_requests_total", labels: map[string]string{"method": "post", "code": "200"}, value: 1027, timestamp: &ts}},
+		{name: "escaped label values and trailing comma", line: `msdos_file_access_time_seconds{path="C:\\DIR\\FILE.TXT",error="Cannot find file:\n\"FILE.TXT\"",} 1.458255915e9`, want: prometheusSample{name: "msdos_file_access_time_seconds", labels: map[string]string{"path": `C:\DIR\FILE.TXT`, "error": "Cannot find file:\n\"FILE.TXT\""}, value: 1.458255915e9}},
+		{name: "special values", line: `http_request_duration_seconds_bucket{le="+Inf"} +Inf`, want: prometheusSample{name: "http_request_duration_seconds_bucket", labels: map[string]string{"le": "+Inf"}, value: math.Inf(1)}},
+		{name: "quoted metric name", line: `{"my.metric", "service.name"="foo"} 1`, want: prometheusSample{name: "my.metric", labels: map[string]string{"service.name": "foo"}, value: 1}},
+		{name: "openmetrics timestamp and exemplar", line: `foo_total{a="b"} 17 1395066363.000 # {trace_id="KOO5S4vxi0o"} 0.67`, openMetrics: true, want: prometheusSample{name: "foo_total", labels: map[string]string{"a": "b"}, value: 17, timestamp: &ts}},
+		{name: "missing value", line: "foo", wantErr: true},
+		{name: "invalid value", line: "foo bar", wantErr: true},
+		{name: "unterminated labels", line: `foo{a="b" 1`, wantErr: true},
+		{name: "unquoted label value", line: `foo{a=b} 1`, wantErr: true},
+		{name: "missing name", line: `{a="b"} 1`, wantErr: true},
+		{name: "invalid timestamp", line: "foo 1 bar", wantErr: true},
+		{name: "too many tokens", line: "foo 1 2 3", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parsePrometheusSample(tt.line, tt.openMetrics)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestPrometheusQuery(t *testing.T) {
+	metrics := `# HELP http_requests_total The total number of HTTP requests.
+# TYPE http_requests_total counter
+http_requests_total{method="post",code="200"} 1027
+http_requests_total{method="post",code="400"}    3
+http_requests_total{value="foo"} 1
+
+invalid{ 1
+# TYPE go_goroutines gauge
+go_goroutines 42 1395066363000
+`
+	t.Run("url source", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Contains(t, r.Header.Get("Accept"), "application/openmetrics-text")
+			w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+			_, _ = w.Write([]byte(metrics))
+		}))
+		defer server.Close()
+		query := models.Query{RefID: "A", Type: models.QueryTypePrometheus, Source: "url", Parser: models.InfinityParserBackend, URL: server.URL, URLOptions: models.URLOptions{Method: http.MethodGet}}
+		frame, err := GetFrameForURLSources(context.Background(), &backend.PluginContext{}, query, Client{HttpClient: server.Client()}, nil)
+		require.NoError(t, err)
+		require.True(t, dataplane.CanBeNumericLong(frame))
+		require.Equal(t, data.FrameTypeNumericLong, frame.Meta.Type)
+		require.Equal(t, []data.Notice{{Severity: data.NoticeSeverityWarning, Text: "skipped 1 malformed line(s) in the prometheus metrics response. line number(s): 7"}}, frame.Meta.Notices)
+		ts := time.UnixMilli(1395066363000).UTC()
+		want := data.NewFrame("A",
+			data.NewField("timestamp", nil, []*time.Time{nil, nil, nil, &ts}),
+			data.NewField("__name__", nil, []string{"http_requests_total", "http_requests_total", "http_requests_total", "go_goroutines"}),
+			data.NewField("code", nil, []string{"200", "400", "", ""}),
+			data.NewField("method", nil, []string{"post", "post", "", ""}),
+			data.NewField("label_value", nil, []string{"", "", "foo", ""}),
+			data.NewField("value", nil, []float64{1027, 3, 1, 42}),
+		)
+		require.Equal(t, want.Fields, frame.Fields)
+	})
+	t.Run("inline openmetrics source", func(t *testing.T) {
+		query := models.Query{RefID: "A", Type: models.QueryTypePrometheus, Source: "inline", Parser: models.InfinityParserBackend, Data: "# TYPE foo gauge\n# UNIT foo seconds\nfoo 1.5 1395066363\n# EOF\n"}
+		frame, err := GetFrameForInlineSources(context.Background(), query)
+		require.NoError(t, err)
+		require.Equal(t, data.FrameTypeNumericLong, frame.Meta.Type)
+		ts := time.UnixMilli(1395066363000).UTC()
+		require.Equal(t, data.NewFrame("A",
+			data.NewField("timestamp", nil, []*time.Time{&ts}),
+			data.NewField("__name__", nil, []string{"foo"}),
+			data.NewField("value", nil, []float64{1.5}),
+		).Fields, frame.Fields)
+	})
+}
@@ -0,0 +1,81 @@
+package infinity
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-infinity-datasource/pkg/models"
+)
+
+// rateLimiter is a token bucket limiting the requests made by a datasource instance. Requests exceeding the limit
+// are queued until a token is available instead of failing
+type rateLimiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+	now    func() time.Time
+}
+
+func newRateLimiter(settings models.InfinitySettings) *rateLimiter {
+	if settings.RateLimitRequestsPerSecond <= 0 {
+		return nil
+	}
+	burst := float64(settings.RateLimitBurst)
+	if burst <= 0 {
+		burst = math.Max(1, math.Ceil(settings.RateLimitRequestsPerSecond))
+	}
+	return &rateLimiter{
+		rate:   settings.RateLimitRequestsPerSecond,
+		burst:  burst,
+		tokens: burst,
+		last:   time.Now(),
+		now:    time.Now,
+	}
+}
+
+// reserve takes a token from the bucket and returns how long the caller has to wait before using it.
+// Tokens can go negative so that the waiting requests are served in the order they arrived
+func (l *rateLimiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	l.tokens = math.Min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+// cancel returns the token of a request which was cancelled while waiting
+func (l *rateLimiter) cancel() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.tokens = math.Min(l.burst, l.tokens+1)
+}
+
+// wait blocks until the request is allowed by the rate limit and returns the time spent waiting
+func (l *rateLimiter) wait(ctx context.Context) (time.Duration, error) {
+	if l == nil {
+		return 0, nil
+	}
+	delay := l.reserve()
+	if delay <= 0 {
+		return 0, nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	start := time.Now()
+	select {
+	case <-ctx.Done():
+		l.cancel()
+		return time.Since(start), ctx.Err()
+	case <-timer.C:
+		return time.Since(start), nil
+	}
+}
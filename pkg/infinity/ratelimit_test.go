@@ -0,0 +1,66 @@
+package infinity
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-infinity-datasource/pkg/models"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimiter(t *testing.T) {
+	t.Run("should be disabled by default", func(t *testing.T) {
+		limiter := newRateLimiter(models.InfinitySettings{})
+		require.Nil(t, limiter)
+		wait, err := limiter.wait(context.Background())
+		require.NoError(t, err)
+		require.Zero(t, wait)
+	})
+	t.Run("should allow the burst and queue the rest", func(t *testing.T) {
+		now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		limiter := newRateLimiter(models.InfinitySettings{RateLimitRequestsPerSecond: 2, RateLimitBurst: 3})
+		limiter.now = func() time.Time { return now }
+		limiter.last = now
+		for range 3 {
+			require.Zero(t, limiter.reserve())
+		}
+		require.Equal(t, 500*time.Millisecond, limiter.reserve())
+		require.Equal(t, time.Second, limiter.reserve())
+		now = now.Add(10 * time.Second)
+		require.Zero(t, limiter.reserve())
+	})
+	t.Run("should default the burst to the rate", func(t *testing.T) {
+		require.Equal(t, float64(1), newRateLimiter(models.InfinitySettings{RateLimitRequestsPerSecond: 0.5}).burst)
+		require.Equal(t, float64(3), newRateLimiter(models.InfinitySettings{RateLimitRequestsPerSecond: 2.5}).burst)
+	})
+	t.Run("should return the token when cancelled", func(t *testing.T) {
+		limiter := newRateLimiter(models.InfinitySettings{RateLimitRequestsPerSecond: 0.001})
+		_, err := limiter.wait(context.Background())
+		require.NoError(t, err)
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		_, err = limiter.wait(ctx)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		require.InDelta(t, 0, limiter.tokens, 0.01)
+	})
+	t.Run("should queue the requests of the client and report the wait time", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{}`))
+		}))
+		defer server.Close()
+		settings := models.InfinitySettings{RateLimitRequestsPerSecond: 20, RateLimitBurst: 1}
+		client := Client{Settings: settings, HttpClient: server.Client(), rateLimiter: newRateLimiter(settings)}
+		query := models.Query{Type: models.QueryTypeJSON, Source: "url", URL: server.URL, URLOptions: models.URLOptions{Method: http.MethodGet}}
+		_, info, err := client.GetResultsWithInfo(context.Background(), &backend.PluginContext{}, query, nil)
+		require.NoError(t, err)
+		require.Zero(t, info.RateLimitWait)
+		frame, _, err := GetFrameForURLSourcesWithPostProcessing(context.Background(), &backend.PluginContext{}, query, client, nil, false)
+		require.NoError(t, err)
+		require.Greater(t, frame.Meta.Custom.(*CustomMeta).RateLimitWait, 10*time.Millisecond)
+	})
+}
@@ -5,8 +5,15 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
 
+	"github.com/grafana/dskit/concurrency"
 	"github.com/grafana/grafana-infinity-datasource/pkg/models"
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/tracing"
@@ -15,10 +22,17 @@ import (
 	"github.com/grafana/infinity-libs/lib/go/transformations"
 )
 
+const defaultConcurrentQueryCount = 10
+
 func isBackendQuery(query models.Query) bool {
 	return query.Parser == models.InfinityParserBackend || query.Parser == models.InfinityParserJQBackend
 }
 
+// isBinaryQuery returns true for the query types where the response body is a binary file instead of text
+func isBinaryQuery(query models.Query) bool {
+	return isColumnarQuery(query) || query.Type == models.QueryTypeXLSX
+}
+
 func GetFrameForURLSources(ctx context.Context, pCtx *backend.PluginContext, query models.Query, infClient Client, requestHeaders map[string]string) (*data.Frame, error) {
 	ctx, span := tracing.DefaultTracer().Start(ctx, "GetFrameForURLSources")
 	defer span.End()
@@ -40,8 +54,8 @@ func GetPaginatedResults(ctx context.Context, pCtx *backend.PluginContext, query
 		for pageNumber := 1; pageNumber <= query.PageMaxPages; pageNumber++ {
 			offset := query.PageParamOffsetFieldVal + ((pageNumber - 1) * query.PageParamSizeFieldVal)
 			currentQuery := query
-			currentQuery = ApplyPaginationItemToQuery(currentQuery, query.PageParamSizeFieldType, query.PageParamSizeFieldName, fmt.Sprintf("%d", query.PageParamSizeFieldVal))
-			currentQuery = ApplyPaginationItemToQuery(currentQuery, query.PageParamOffsetFieldType, query.PageParamOffsetFieldName, fmt.Sprintf("%d", offset))
+			currentQuery = applyPaginationNumberToQuery(currentQuery, query.PageParamSizeFieldType, query.PageParamSizeFieldName, query.PageParamSizeFieldVal)
+			currentQuery = applyPaginationNumberToQuery(currentQuery, query.PageParamOffsetFieldType, query.PageParamOffsetFieldName, offset)
 			queries = append(queries, currentQuery)
 		}
 	case models.PaginationModePage:
@@ -51,8 +65,8 @@ func GetPaginatedResults(ctx context.Context, pCtx *backend.PluginContext, query
 		}
 		for pageNumber := 0; pageNumber < query.PageMaxPages; pageNumber++ {
 			currentQuery := query
-			currentQuery = ApplyPaginationItemToQuery(currentQuery, query.PageParamSizeFieldType, query.PageParamSizeFieldName, fmt.Sprintf("%d", query.PageParamSizeFieldVal))
-			currentQuery = ApplyPaginationItemToQuery(currentQuery, query.PageParamPageFieldType, query.PageParamPageFieldName, fmt.Sprintf("%d", initialPageNumber+pageNumber))
+			currentQuery = applyPaginationNumberToQuery(currentQuery, query.PageParamSizeFieldType, query.PageParamSizeFieldName, query.PageParamSizeFieldVal)
+			currentQuery = applyPaginationNumberToQuery(currentQuery, query.PageParamPageFieldType, query.PageParamPageFieldName, initialPageNumber+pageNumber)
 			queries = append(queries, currentQuery)
 		}
 	case models.PaginationModeList:
@@ -65,18 +79,28 @@ func GetPaginatedResults(ctx context.Context, pCtx *backend.PluginContext, query
 			currentQuery = ApplyPaginationItemToQuery(currentQuery, query.PageParamListFieldType, query.PageParamListFieldName, strings.TrimSpace(listItem))
 			queries = append(queries, currentQuery)
 		}
-	case models.PaginationModeCursor:
+	case models.PaginationModeCursor, models.PaginationModeNextLink:
 		queries = append(queries, query)
 	default:
 		frame, _, err := GetFrameForURLSourcesWithPostProcessing(ctx, pCtx, query, infClient, requestHeaders, true)
 		return frame, err
 	}
-	if query.PageMode != models.PaginationModeCursor {
-		for _, currentQuery := range queries {
-			frame, _, err := GetFrameForURLSourcesWithPostProcessing(ctx, pCtx, currentQuery, infClient, requestHeaders, false)
-			frames = append(frames, frame)
+	if (query.PageMode == models.PaginationModeOffset || query.PageMode == models.PaginationModePage) && hasTotalCount(query) && len(queries) > 1 {
+		// fetch the first page to find the total count and then only request the pages required
+		frame, totalCount, err := GetFrameForURLSourcesWithPostProcessing(ctx, pCtx, queries[0], infClient, requestHeaders, false)
+		frames = append(frames, frame)
+		errs = errors.Join(errs, err)
+		if err == nil && !isEmptyPage(frame, err) {
+			pageCount := GetPageCountFromTotalCount(ctx, query, totalCount, len(queries))
+			remainingFrames, err := getPagesConcurrently(ctx, pCtx, withoutTotalCount(queries[1:pageCount]), infClient, requestHeaders)
+			if len(remainingFrames) > 0 && isEmptyPage(remainingFrames[0], nil) {
+				remainingFrames = remainingFrames[1:]
+			}
+			frames = append(frames, remainingFrames...)
 			errs = errors.Join(errs, err)
 		}
+	} else if query.PageMode != models.PaginationModeCursor && query.PageMode != models.PaginationModeNextLink {
+		frames, errs = getPagesConcurrently(ctx, pCtx, queries, infClient, requestHeaders)
 	}
 	if query.PageMode == models.PaginationModeCursor {
 		i := 0
@@ -96,6 +120,30 @@ func GetPaginatedResults(ctx context.Context, pCtx *backend.PluginContext, query
 			errs = errors.Join(errs, err)
 		}
 	}
+	if query.PageMode == models.PaginationModeNextLink {
+		currentQuery := query
+		for pageNumber := 1; pageNumber <= query.PageMaxPages; pageNumber++ {
+			frame, nextLink, err := GetFrameForURLSourcesWithPostProcessing(ctx, pCtx, currentQuery, infClient, requestHeaders, false)
+			if len(frames) > 0 && isEmptyPage(frame, err) {
+				break
+			}
+			frames = append(frames, frame)
+			errs = errors.Join(errs, err)
+			if err != nil || nextLink == "" || isEmptyPage(frame, err) {
+				break
+			}
+			nextQuery, err := ApplyNextLinkToQuery(currentQuery, nextLink, infClient.Settings)
+			if err != nil {
+				errs = errors.Join(errs, err)
+				break
+			}
+			if nextQuery.URL == currentQuery.URL {
+				// the api returned the same link again. stop here instead of fetching the same page till the max pages
+				break
+			}
+			currentQuery = nextQuery
+		}
+	}
 	if errs != nil {
 		return nil, errs
 	}
@@ -106,11 +154,127 @@ func GetPaginatedResults(ctx context.Context, pCtx *backend.PluginContext, query
 	return PostProcessFrame(ctx, mergedFrame, query)
 }
 
+// getPagesConcurrently fetches the pages using up to ConcurrentQueryCount parallel requests. Frames are returned in the
+// same order as the queries. When a page returns no rows, the subsequent pages are cancelled and dropped from the results
+func getPagesConcurrently(ctx context.Context, pCtx *backend.PluginContext, queries []models.Query, infClient Client, requestHeaders map[string]string) ([]*data.Frame, error) {
+	ctx, span := tracing.DefaultTracer().Start(ctx, "getPagesConcurrently")
+	defer span.End()
+	frames := make([]*data.Frame, len(queries))
+	pageErrs := make([]error, len(queries))
+	cancels := make([]context.CancelFunc, len(queries))
+	var m sync.Mutex
+	emptyPageIndex := len(queries)
+	_ = concurrency.ForEachJob(ctx, len(queries), GetConcurrentQueryCount(ctx, pCtx), func(ctx context.Context, idx int) error {
+		m.Lock()
+		if idx > emptyPageIndex {
+			m.Unlock()
+			return nil
+		}
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		cancels[idx] = cancel
+		m.Unlock()
+		frame, _, err := GetFrameForURLSourcesWithPostProcessing(ctx, pCtx, queries[idx], infClient, requestHeaders, false)
+		m.Lock()
+		defer m.Unlock()
+		frames[idx], pageErrs[idx] = frame, err
+		if idx < emptyPageIndex && isEmptyPage(frame, err) {
+			// no more data available. cancel the pages after this one as they will be empty too
+			emptyPageIndex = idx
+			for i := idx + 1; i < len(cancels); i++ {
+				if cancels[i] != nil {
+					cancels[i]()
+				}
+			}
+		}
+		return nil
+	})
+	lastPageIndex := min(emptyPageIndex, len(queries)-1)
+	if emptyPageIndex > 0 && emptyPageIndex < len(queries) {
+		// the empty page is only kept when it is the first page so that the response still has a frame
+		lastPageIndex = emptyPageIndex - 1
+	}
+	var errs error
+	for i := 0; i <= lastPageIndex; i++ {
+		errs = errors.Join(errs, pageErrs[i])
+	}
+	return frames[:lastPageIndex+1], errs
+}
+
+// withoutTotalCount returns copies of the queries which don't read the total count.
+// The total count is only read from the first page, and the later pages may not have it
+func withoutTotalCount(queries []models.Query) []models.Query {
+	out := slices.Clone(queries)
+	for i := range out {
+		out[i].PageTotalCountHeader = ""
+		out[i].PageTotalCountExtractionPath = ""
+	}
+	return out
+}
+
+// isEmptyPage reports whether the page was fetched successfully but returned no rows
+func isEmptyPage(frame *data.Frame, err error) bool {
+	return err == nil && (frame == nil || frame.Rows() == 0)
+}
+
+func hasTotalCount(query models.Query) bool {
+	return strings.TrimSpace(query.PageTotalCountExtractionPath) != "" || strings.TrimSpace(query.PageTotalCountHeader) != ""
+}
+
+// GetPageCountFromTotalCount returns the number of pages required to fetch all the items based on the total count
+// and the page size. The result is limited to maxPages. If the total count is not a valid number, maxPages is returned
+func GetPageCountFromTotalCount(ctx context.Context, query models.Query, totalCount string, maxPages int) int {
+	total, err := strconv.ParseFloat(strings.Trim(strings.TrimSpace(totalCount), `"`), 64)
+	if err != nil || total < 0 || query.PageParamSizeFieldVal <= 0 {
+		backend.Logger.FromContext(ctx).Debug("unable to find the number of pages from the total count", "total_count", totalCount)
+		return maxPages
+	}
+	items := int(math.Ceil(total))
+	if query.PageMode == models.PaginationModeOffset {
+		// initial offset skips the items before it
+		items -= query.PageParamOffsetFieldVal
+	}
+	pages := (items + query.PageParamSizeFieldVal - 1) / query.PageParamSizeFieldVal
+	return max(1, min(pages, maxPages))
+}
+
+// GetConcurrentQueryCount returns the concurrent query count from the grafana config. Defaults to 10 if not configured
+func GetConcurrentQueryCount(ctx context.Context, pCtx *backend.PluginContext) int {
+	if pCtx == nil || pCtx.GrafanaConfig == nil {
+		return defaultConcurrentQueryCount
+	}
+	concurrentQueryCount, err := pCtx.GrafanaConfig.ConcurrentQueryCount()
+	if err != nil || concurrentQueryCount < 1 {
+		if err != nil {
+			backend.Logger.FromContext(ctx).Debug(fmt.Sprintf("Concurrent Query Count read/parse error: %v", err))
+		}
+		return defaultConcurrentQueryCount
+	}
+	return concurrentQueryCount
+}
+
+// ApplyPaginationItemToQuery applies the pagination value such as the cursor or the list item to the query. The value is
+// set as string in the json body
 func ApplyPaginationItemToQuery(query models.Query, fieldType models.PaginationParamType, fieldName string, fieldValue string) models.Query {
+	return applyPaginationValueToQuery(query, fieldType, fieldName, fieldValue, fieldValue)
+}
+
+// applyPaginationNumberToQuery applies the numeric pagination value such as the offset, size or page to the query. The
+// value is set as number in the json body
+func applyPaginationNumberToQuery(query models.Query, fieldType models.PaginationParamType, fieldName string, fieldValue int) models.Query {
+	return applyPaginationValueToQuery(query, fieldType, fieldName, strconv.Itoa(fieldValue), fieldValue)
+}
+
+// applyPaginationValueToQuery applies the pagination value to the query. jsonValue is the value set in the json body
+func applyPaginationValueToQuery(query models.Query, fieldType models.PaginationParamType, fieldName string, fieldValue string, jsonValue any) models.Query {
 	if strings.TrimSpace(fieldValue) == "" {
 		return query
 	}
 	field := models.URLOptionKeyValuePair{Key: fieldName, Value: fieldValue}
+	// copies of the query share the same slices. clone them so that the other pages are not modified
+	query.URLOptions.Params = slices.Clone(query.URLOptions.Params)
+	query.URLOptions.Headers = slices.Clone(query.URLOptions.Headers)
+	query.URLOptions.BodyForm = slices.Clone(query.URLOptions.BodyForm)
 	switch fieldType {
 	case models.PaginationParamTypeHeader:
 		query.URLOptions.Headers = append(query.URLOptions.Headers, field)
@@ -134,6 +298,12 @@ func ApplyPaginationItemToQuery(query models.Query, fieldType models.PaginationP
 			query.URLOptions.BodyForm[bodyFormIndex].Key = strings.ReplaceAll(bodyFormItem.Key, fieldNameUpdated, field.Value)
 			query.URLOptions.BodyForm[bodyFormIndex].Value = strings.ReplaceAll(bodyFormItem.Value, fieldNameUpdated, field.Value)
 		}
+	case models.PaginationParamTypeBodyJson:
+		if query.URLOptions.BodyType == "graphql" {
+			query.URLOptions.BodyGraphQLVariables = SetJSONBodyValue(query.URLOptions.BodyGraphQLVariables, fieldName, jsonValue)
+		} else {
+			query.URLOptions.Body = SetJSONBodyValue(query.URLOptions.Body, fieldName, jsonValue)
+		}
 	case models.PaginationParamTypeQuery:
 		fallthrough // It should go to default
 	default:
@@ -142,13 +312,73 @@ func ApplyPaginationItemToQuery(query models.Query, fieldType models.PaginationP
 	return query
 }
 
+// SetJSONBodyValue sets the value in the json body at the given path. Nested fields are separated by dots and array items
+// are referred by their index. Example: query.bool.filter.0.range. The value is set with its go type. i.e. strings stay
+// strings even when they look like numbers. If the body is not a valid json object, it is returned unchanged
+func SetJSONBodyValue(body string, path string, value any) string {
+	var bodyObject any = map[string]any{}
+	if strings.TrimSpace(body) != "" {
+		decoder := json.NewDecoder(strings.NewReader(body))
+		decoder.UseNumber()
+		if err := decoder.Decode(&bodyObject); err != nil {
+			backend.Logger.Debug("error parsing the body as json. pagination value not applied", "err", err.Error())
+			return body
+		}
+	}
+	updatedBody, ok := setJSONPathValue(bodyObject, strings.Split(path, "."), value)
+	if !ok {
+		backend.Logger.Debug("invalid json path for the body. pagination value not applied", "path", path)
+		return body
+	}
+	out, err := json.Marshal(updatedBody)
+	if err != nil {
+		return body
+	}
+	return string(out)
+}
+
+func setJSONPathValue(current any, path []string, value any) (any, bool) {
+	if len(path) == 0 {
+		return value, true
+	}
+	key := strings.TrimSpace(path[0])
+	switch c := current.(type) {
+	case map[string]any:
+		updated, ok := setJSONPathValue(c[key], path[1:], value)
+		if ok {
+			c[key] = updated
+		}
+		return c, ok
+	case []any:
+		index, err := strconv.Atoi(key)
+		if err != nil || index < 0 || index >= len(c) {
+			return current, false
+		}
+		updated, ok := setJSONPathValue(c[index], path[1:], value)
+		if ok {
+			c[index] = updated
+		}
+		return c, ok
+	case nil:
+		// missing intermediate objects are created
+		return setJSONPathValue(map[string]any{}, path, value)
+	}
+	return current, false
+}
+
+// GetFrameForURLSourcesWithPostProcessing returns the frame for the url query along with the pagination value found in the response.
+// Depending on the pagination mode, it is the cursor, the next link or the total count
 func GetFrameForURLSourcesWithPostProcessing(ctx context.Context, pCtx *backend.PluginContext, query models.Query, infClient Client, requestHeaders map[string]string, postProcessingRequired bool) (*data.Frame, string, error) {
 	ctx, span := tracing.DefaultTracer().Start(ctx, "GetFrameForURLSourcesWithPostProcessing")
 	logger := backend.Logger.FromContext(ctx)
 	defer span.End()
 	frame := GetDummyFrame(query)
 	cursor := ""
-	urlResponseObject, statusCode, duration, err := infClient.GetResults(ctx, pCtx, query, requestHeaders)
+	urlResponseObject, responseInfo, err := infClient.GetResultsWithInfo(ctx, pCtx, query, requestHeaders)
+	responseHeaders, statusCode, duration, attempts := responseInfo.Headers, responseInfo.StatusCode, responseInfo.Duration, 0
+	if infClient.Settings.RetryMaxAttempts > 1 {
+		attempts = responseInfo.Attempts
+	}
 	frame.Meta.ExecutedQueryString = infClient.GetExecutedURL(ctx, query)
 	if infClient.IsMock {
 		duration = 123
@@ -160,22 +390,74 @@ func GetFrameForURLSourcesWithPostProcessing(ctx context.Context, pCtx *backend.
 			Data:                   urlResponseObject,
 			ResponseCodeFromServer: statusCode,
 			Duration:               duration,
+			Attempts:               attempts,
+			RateLimitWait:          responseInfo.RateLimitWait,
 			Query:                  query,
 			Error:                  err.Error(),
 		}
 		return frame, cursor, err
 	}
-	if query.Type == models.QueryTypeGSheets {
-		if frame, err = GetGoogleSheetsResponse(ctx, urlResponseObject, query); err != nil {
-			return frame, cursor, err
+	// binary files are not sent to the frontend
+	responseData := urlResponseObject
+	if isBinaryQuery(query) {
+		responseData = nil
+	}
+	if parsed, ok := urlResponseObject.(*ndjsonResponse); ok {
+		responseData = parsed.records
+	}
+	if raw, ok := urlResponseObject.(json.RawMessage); ok {
+		// uql and groq queries receive the raw json. The frame meta still holds the decoded response same as the other json queries
+		var decoded any
+		if json.Unmarshal(raw, &decoded) == nil {
+			responseData = decoded
 		}
 	}
-	if isBackendQuery(query) {
+	if isUQLQuery(query) {
+		// errors are handled below so that the response data is still available in the frame meta
+		frame, err = GetUQLBackendResponse(ctx, urlResponseObject, query)
+		if err == nil && postProcessingRequired {
+			frame, err = PostProcessFrame(ctx, frame, query)
+		}
+	} else if isGROQQuery(query) {
+		frame, err = GetGROQBackendResponse(ctx, urlResponseObject, query)
+		if err == nil && postProcessingRequired {
+			frame, err = PostProcessFrame(ctx, frame, query)
+		}
+	} else if isBackendQuery(query) {
 		if query.Type == models.QueryTypeJSON || query.Type == models.QueryTypeGraphQL {
 			if frame, err = GetJSONBackendResponse(ctx, urlResponseObject, query); err != nil {
 				return frame, cursor, err
 			}
 		}
+		if query.Type == models.QueryTypeNDJSON {
+			if frame, err = GetNDJSONBackendResponse(ctx, urlResponseObject, query); err != nil {
+				return frame, cursor, err
+			}
+		}
+		if isColumnarQuery(query) {
+			if frame, err = GetColumnarBackendResponse(ctx, urlResponseObject, query); err != nil {
+				return frame, cursor, err
+			}
+		}
+		if query.Type == models.QueryTypeXLSX {
+			if frame, err = GetXLSXBackendResponse(ctx, urlResponseObject, query); err != nil {
+				return frame, cursor, err
+			}
+		}
+		if query.Type == models.QueryTypePrometheus {
+			if responseString, ok := urlResponseObject.(string); ok {
+				if frame, err = GetPrometheusBackendResponse(ctx, responseString, query); err != nil {
+					return frame, cursor, err
+				}
+			}
+		}
+		if query.Type == models.QueryTypeYAML {
+			if responseString, ok := urlResponseObject.(string); ok {
+				if frame, err = GetYAMLBackendResponse(ctx, responseString, query); err != nil {
+					return frame, cursor, err
+				}
+			}
+		}
 		if query.Type == models.QueryTypeCSV || query.Type == models.QueryTypeTSV {
 			if responseString, ok := urlResponseObject.(string); ok {
 				if frame, err = GetCSVBackendResponse(ctx, responseString, query); err != nil {
@@ -203,16 +485,20 @@ func GetFrameForURLSourcesWithPostProcessing(ctx context.Context, pCtx *backend.
 	}
 	frame.Meta.Custom = &CustomMeta{
 		Query:                  query,
-		Data:                   urlResponseObject,
+		Data:                   responseData,
 		ResponseCodeFromServer: statusCode,
 		Duration:               duration,
+		Attempts:               attempts,
+		RateLimitWait:          responseInfo.RateLimitWait,
 	}
 	if err != nil {
 		logger.Error("error getting response for query", "error", err.Error())
 		frame.Meta.Custom = &CustomMeta{
-			Data:                   urlResponseObject,
+			Data:                   responseData,
 			ResponseCodeFromServer: statusCode,
 			Duration:               duration,
+			Attempts:               attempts,
+			RateLimitWait:          responseInfo.RateLimitWait,
 			Query:                  query,
 			Error:                  err.Error(),
 		}
@@ -232,5 +518,129 @@ func GetFrameForURLSourcesWithPostProcessing(ctx context.Context, pCtx *backend.
 			return frame, cursor, backend.PluginError(errors.New("error while extracting the cursor value"))
 		}
 	}
+	if (query.PageMode == models.PaginationModeOffset || query.PageMode == models.PaginationModePage) && hasTotalCount(query) {
+		// for the offset and page pagination, cursor holds the total count
+		if cursor, err = GetTotalCount(query, urlResponseObject, responseHeaders); err != nil {
+			return frame, cursor, err
+		}
+	}
+	if query.PageMode == models.PaginationModeNextLink {
+		// for the next link pagination, cursor holds the url of the next page
+		if cursor, err = GetNextLink(query, urlResponseObject, responseHeaders); err != nil {
+			return frame, cursor, err
+		}
+	}
 	return frame, cursor, nil
 }
+
+// GetNextLink returns the url of the next page. When the extraction path is set, the link is read from the response body.
+// Otherwise it is read from the RFC 8288 Link header with rel="next"
+func GetNextLink(query models.Query, urlResponseObject any, responseHeaders http.Header) (string, error) {
+	if strings.TrimSpace(query.PageParamNextLinkExtractionPath) == "" {
+		return GetNextLinkFromHeaders(responseHeaders), nil
+	}
+	body, err := json.Marshal(urlResponseObject)
+	if err != nil {
+		return "", backend.PluginError(errors.New("error while finding the next link"))
+	}
+	framerType := jsonframer.FramerTypeGJSON
+	if query.Parser == models.InfinityParserJQBackend {
+		framerType = jsonframer.FramerTypeJQ
+	}
+	nextLink, err := jsonframer.GetRootData(string(body), query.PageParamNextLinkExtractionPath, framerType)
+	if err != nil {
+		return "", backend.DownstreamError(fmt.Errorf("error while extracting the next link. %w", err))
+	}
+	nextLink = strings.TrimSpace(nextLink)
+	if nextLink == "null" {
+		// last page usually returns null as the next link
+		return "", nil
+	}
+	return nextLink, nil
+}
+
+// GetTotalCount returns the total number of items available from the response header or from the response body
+func GetTotalCount(query models.Query, urlResponseObject any, responseHeaders http.Header) (string, error) {
+	if header := strings.TrimSpace(query.PageTotalCountHeader); header != "" {
+		return responseHeaders.Get(header), nil
+	}
+	body, err := json.Marshal(urlResponseObject)
+	if err != nil {
+		return "", backend.PluginError(errors.New("error while finding the total count"))
+	}
+	framerType := jsonframer.FramerTypeGJSON
+	if query.Parser == models.InfinityParserJQBackend {
+		framerType = jsonframer.FramerTypeJQ
+	}
+	totalCount, err := jsonframer.GetRootData(string(body), query.PageTotalCountExtractionPath, framerType)
+	if err != nil {
+		return "", backend.DownstreamError(fmt.Errorf("error while extracting the total count. %w", err))
+	}
+	return totalCount, nil
+}
+
+// GetNextLinkFromHeaders returns the target of the rel="next" link from the Link headers.
+// Example: Link: <https://api.github.com/repositories/1/issues?page=2>; rel="next", <https://api.github.com/repositories/1/issues?page=5>; rel="last"
+func GetNextLinkFromHeaders(headers http.Header) string {
+	for _, value := range headers.Values(headerKeyLink) {
+		for {
+			start := strings.Index(value, "<")
+			end := strings.Index(value, ">")
+			if start < 0 || end < start {
+				break
+			}
+			target := value[start+1 : end]
+			params := value[end+1:]
+			value = ""
+			if nextStart := strings.Index(params, "<"); nextStart >= 0 {
+				params, value = params[:nextStart], params[nextStart:]
+			}
+			for _, param := range strings.Split(params, ";") {
+				key, val, ok := strings.Cut(param, "=")
+				if !ok || !strings.EqualFold(strings.TrimSpace(key), "rel") {
+					continue
+				}
+				// rel can have multiple space separated values. Example: rel="next last"
+				for _, rel := range strings.Fields(strings.Trim(strings.TrimSpace(val), `",`)) {
+					if strings.EqualFold(rel, "next") {
+						return strings.TrimSpace(target)
+					}
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// ApplyNextLinkToQuery updates the query url with the next link. Relative links are resolved against the current url.
+// The next link must be in the allowed hosts list. When the allowed hosts are not configured, only the links
+// on the same host as the current url are followed so that credentials are not sent to other hosts. The updated query url
+// is absolute and used as is without the base url of the settings
+func ApplyNextLinkToQuery(query models.Query, nextLink string, settings models.InfinitySettings) (models.Query, error) {
+	currentURL := query.URL
+	if !query.URLIsAbsolute && !strings.HasPrefix(query.URL, settings.URL) {
+		currentURL = settings.URL + query.URL
+	}
+	baseURL, err := url.Parse(models.FixMissingURLSchema(currentURL))
+	if err != nil {
+		return query, backend.DownstreamError(fmt.Errorf("error parsing the url. %w", err))
+	}
+	nextURL, err := url.Parse(nextLink)
+	if err != nil {
+		return query, backend.DownstreamError(fmt.Errorf("invalid next link %s. %w", nextLink, err))
+	}
+	resolvedURL := baseURL.ResolveReference(nextURL)
+	allowedHosts := settings.AllowedHosts
+	if len(allowedHosts) == 0 {
+		allowedHosts = []string{fmt.Sprintf("%s://%s", baseURL.Scheme, baseURL.Host)}
+	}
+	if !CanAllowURL(resolvedURL.String(), allowedHosts) {
+		return query, backend.DownstreamError(fmt.Errorf("%w. next link: %s", models.ErrInvalidConfigHostNotAllowed, resolvedURL.Redacted()))
+	}
+	query.URL = resolvedURL.String()
+	// the resolved link can be outside of the base url path. So it shouldn't be prefixed with the base url again
+	query.URLIsAbsolute = true
+	// next link already contains the query params required for the next page
+	query.URLOptions.Params = []models.URLOptionKeyValuePair{}
+	return query, nil
+}
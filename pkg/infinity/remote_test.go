@@ -1,9 +1,20 @@
 package infinity
 
 import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/grafana/grafana-infinity-datasource/pkg/models"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/stretchr/testify/require"
 )
 
@@ -266,4 +277,453 @@ func TestApplyPaginationItemToQuery(t *testing.T) {
 			}
 		})
 	})
+	t.Run(string(models.PaginationParamTypeBodyJson), func(t *testing.T) {
+		tests := []struct {
+			name       string
+			urlOptions models.URLOptions
+			fieldName  string
+			fieldValue any // numbers are applied as offset, size or page and strings as cursor or list item
+			want       models.URLOptions
+		}{
+			{
+				name:       "should add pagination params to empty body",
+				fieldName:  "from",
+				fieldValue: 10,
+				want:       models.URLOptions{Body: `{"from":10}`},
+			},
+			{
+				name:       "should override existing pagination params in raw body",
+				urlOptions: models.URLOptions{BodyType: "raw", Body: `{"from":0,"size":10,"query":{"match_all":{}}}`},
+				fieldName:  "from",
+				fieldValue: 20,
+				want:       models.URLOptions{BodyType: "raw", Body: `{"from":20,"query":{"match_all":{}},"size":10}`},
+			},
+			{
+				name:       "should set nested pagination params and create missing objects",
+				urlOptions: models.URLOptions{BodyType: "raw", Body: `{"query":{"match_all":{}}}`},
+				fieldName:  "page.after",
+				fieldValue: "abc",
+				want:       models.URLOptions{BodyType: "raw", Body: `{"page":{"after":"abc"},"query":{"match_all":{}}}`},
+			},
+			{
+				name:       "should set cursor values which look like json as string",
+				urlOptions: models.URLOptions{BodyType: "raw", Body: `{"size":10}`},
+				fieldName:  "cursor",
+				fieldValue: "10",
+				want:       models.URLOptions{BodyType: "raw", Body: `{"cursor":"10","size":10}`},
+			},
+			{
+				name:       "should set boolean and null like cursor values as string",
+				urlOptions: models.URLOptions{BodyType: "raw", Body: `{"a":"x"}`},
+				fieldName:  "a",
+				fieldValue: "null",
+				want:       models.URLOptions{BodyType: "raw", Body: `{"a":"null"}`},
+			},
+			{
+				name:       "should set values inside arrays by index",
+				urlOptions: models.URLOptions{BodyType: "raw", Body: `{"requests":[{"size":10},{"size":10}]}`},
+				fieldName:  "requests.1.from",
+				fieldValue: 10,
+				want:       models.URLOptions{BodyType: "raw", Body: `{"requests":[{"size":10},{"from":10,"size":10}]}`},
+			},
+			{
+				name:       "should not lose precision of large numbers",
+				urlOptions: models.URLOptions{BodyType: "raw", Body: `{"id":9007199254740993}`},
+				fieldName:  "cursor",
+				fieldValue: "9007199254740995",
+				want:       models.URLOptions{BodyType: "raw", Body: `{"cursor":"9007199254740995","id":9007199254740993}`},
+			},
+			{
+				name:       "should not modify invalid json body",
+				urlOptions: models.URLOptions{BodyType: "raw", Body: `from=${from}`},
+				fieldName:  "from",
+				fieldValue: 10,
+				want:       models.URLOptions{BodyType: "raw", Body: `from=${from}`},
+			},
+			{
+				name:       "should not modify the body when the path is invalid",
+				urlOptions: models.URLOptions{BodyType: "raw", Body: `{"from":0}`},
+				fieldName:  "from.value",
+				fieldValue: 10,
+				want:       models.URLOptions{BodyType: "raw", Body: `{"from":0}`},
+			},
+			{
+				name:       "should set pagination params in graphql variables",
+				urlOptions: models.URLOptions{BodyType: "graphql", BodyGraphQLQuery: `query($first: Int, $after: String) { items(first: $first, after: $after) { id } }`, BodyGraphQLVariables: `{"first":10}`},
+				fieldName:  "after",
+				fieldValue: "Y3Vyc29yOjEw",
+				want:       models.URLOptions{BodyType: "graphql", BodyGraphQLQuery: `query($first: Int, $after: String) { items(first: $first, after: $after) { id } }`, BodyGraphQLVariables: `{"after":"Y3Vyc29yOjEw","first":10}`},
+			},
+			{
+				name:       "should keep numeric cursors of graphql variables as string",
+				urlOptions: models.URLOptions{BodyType: "graphql", BodyGraphQLQuery: `query($first: Int, $after: String) { items(first: $first, after: $after) { id } }`, BodyGraphQLVariables: `{"first":10}`},
+				fieldName:  "after",
+				fieldValue: "10",
+				want:       models.URLOptions{BodyType: "graphql", BodyGraphQLQuery: `query($first: Int, $after: String) { items(first: $first, after: $after) { id } }`, BodyGraphQLVariables: `{"after":"10","first":10}`},
+			},
+			{
+				name:       "should set nested pagination params in empty graphql variables",
+				urlOptions: models.URLOptions{BodyType: "graphql"},
+				fieldName:  "page.number",
+				fieldValue: 2,
+				want:       models.URLOptions{BodyType: "graphql", BodyGraphQLVariables: `{"page":{"number":2}}`},
+			},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				var got models.Query
+				switch value := tt.fieldValue.(type) {
+				case int:
+					got = applyPaginationNumberToQuery(models.Query{URLOptions: tt.urlOptions}, models.PaginationParamTypeBodyJson, tt.fieldName, value)
+				case string:
+					got = ApplyPaginationItemToQuery(models.Query{URLOptions: tt.urlOptions}, models.PaginationParamTypeBodyJson, tt.fieldName, value)
+				}
+				require.Equal(t, tt.want, got.URLOptions)
+			})
+		}
+	})
+}
+
+func TestGetNextLinkFromHeaders(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers http.Header
+		want    string
+	}{
+		{name: "no link header", headers: http.Header{}, want: ""},
+		{
+			name:    "github style link header",
+			headers: http.Header{"Link": []string{`<https://api.github.com/repositories/1/issues?page=2>; rel="next", <https://api.github.com/repositories/1/issues?page=5>; rel="last"`}},
+			want:    "https://api.github.com/repositories/1/issues?page=2",
+		},
+		{
+			name:    "next link is not the first link",
+			headers: http.Header{"Link": []string{`<https://example.com/items?page=1>; rel="prev", <https://example.com/items?page=3>; rel="next"`}},
+			want:    "https://example.com/items?page=3",
+		},
+		{
+			name:    "link with commas and multiple rel values",
+			headers: http.Header{"Link": []string{`</items?ids=1,2,3>; title="items"; rel="next last"`}},
+			want:    "/items?ids=1,2,3",
+		},
+		{
+			name:    "multiple link headers",
+			headers: http.Header{"Link": []string{`<https://example.com/items?page=1>; rel=prev`, `<https://example.com/items?page=3>; rel=next`}},
+			want:    "https://example.com/items?page=3",
+		},
+		{
+			name:    "no next link in the last page",
+			headers: http.Header{"Link": []string{`<https://example.com/items?page=1>; rel="first", <https://example.com/items?page=2>; rel="prev"`}},
+			want:    "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, GetNextLinkFromHeaders(tt.headers))
+		})
+	}
+}
+
+func TestApplyNextLinkToQuery(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          models.Query
+		nextLink       string
+		settings       models.InfinitySettings
+		wantURL        string
+		wantRequestURL string
+		wantErr        error
+	}{
+		{
+			name:     "should replace the url and remove the params",
+			query:    models.Query{URL: "https://example.com/items", URLOptions: models.URLOptions{Params: []models.URLOptionKeyValuePair{{Key: "per_page", Value: "10"}}}},
+			nextLink: "https://example.com/items?per_page=10&page=2",
+			wantURL:  "https://example.com/items?per_page=10&page=2",
+		},
+		{
+			name:     "should resolve relative links",
+			query:    models.Query{URL: "https://example.com/api/items?page=1"},
+			nextLink: "/api/items?page=2",
+			wantURL:  "https://example.com/api/items?page=2",
+		},
+		{
+			name:     "should resolve relative links against the base url from the settings",
+			query:    models.Query{URL: "/api/items"},
+			settings: models.InfinitySettings{URL: "https://example.com"},
+			nextLink: "items?page=2",
+			wantURL:  "https://example.com/api/items?page=2",
+		},
+		{
+			name:     "should not follow links to other hosts when allowed hosts are not configured",
+			query:    models.Query{URL: "https://example.com/items"},
+			nextLink: "https://attacker.example.org/items?page=2",
+			wantErr:  models.ErrInvalidConfigHostNotAllowed,
+		},
+		{
+			name:     "should follow links to other hosts in the allowed hosts list",
+			query:    models.Query{URL: "https://example.com/items"},
+			settings: models.InfinitySettings{AllowedHosts: []string{"https://example.com", "https://cdn.example.com"}},
+			nextLink: "https://cdn.example.com/items?page=2",
+			wantURL:  "https://cdn.example.com/items?page=2",
+		},
+		{
+			name:           "should not prefix the base url again when the link is outside of the base url path",
+			query:          models.Query{URL: "/items"},
+			settings:       models.InfinitySettings{URL: "https://example.com/api/v1"},
+			nextLink:       "https://example.com/api/v2/items?page=2",
+			wantURL:        "https://example.com/api/v2/items?page=2",
+			wantRequestURL: "https://example.com/api/v2/items?page=2",
+		},
+		{
+			name:           "should resolve relative links outside of the base url path",
+			query:          models.Query{URL: "/items"},
+			settings:       models.InfinitySettings{URL: "https://example.com/api/v1"},
+			nextLink:       "/api/v2/items?page=2",
+			wantURL:        "https://example.com/api/v2/items?page=2",
+			wantRequestURL: "https://example.com/api/v2/items?page=2",
+		},
+		{
+			name:     "should not follow links which are not in the allowed hosts list",
+			query:    models.Query{URL: "https://example.com/items"},
+			settings: models.InfinitySettings{AllowedHosts: []string{"https://example.com/items"}},
+			nextLink: "https://example.com/admin?page=2",
+			wantErr:  models.ErrInvalidConfigHostNotAllowed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ApplyNextLinkToQuery(tt.query, tt.nextLink, tt.settings)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.wantURL, got.URL)
+			require.Empty(t, got.URLOptions.Params)
+			if tt.wantRequestURL != "" {
+				requestURL, err := GetQueryURL(context.Background(), &backend.PluginContext{}, tt.settings, got, true)
+				require.NoError(t, err)
+				require.Equal(t, tt.wantRequestURL, requestURL)
+				// following the next link again from the absolute url
+				got, err = ApplyNextLinkToQuery(got, "?page=3", tt.settings)
+				require.NoError(t, err)
+				requestURL, err = GetQueryURL(context.Background(), &backend.PluginContext{}, tt.settings, got, true)
+				require.NoError(t, err)
+				require.Equal(t, strings.Replace(tt.wantRequestURL, "page=2", "page=3", 1), requestURL)
+			}
+		})
+	}
+}
+
+func TestGetPaginatedResultsWithNextLink(t *testing.T) {
+	requestedURLs := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestedURLs = append(requestedURLs, r.URL.RequestURI())
+		page := r.URL.Query().Get("page")
+		switch page {
+		case "":
+			w.Header().Set("Link", `</items?page=2>; rel="next"`)
+		case "2":
+			w.Header().Set("Link", `</items?page=3>; rel="next", </items>; rel="first"`)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `[{"page":"%s"}]`, page)
+	}))
+	defer server.Close()
+	client := Client{Settings: models.InfinitySettings{}, HttpClient: server.Client()}
+	query := models.Query{
+		Type:         models.QueryTypeJSON,
+		Parser:       models.InfinityParserUQL,
+		UQL:          "parse-json",
+		Source:       "url",
+		URL:          server.URL + "/items",
+		URLOptions:   models.URLOptions{Method: http.MethodGet, Params: []models.URLOptionKeyValuePair{{Key: "per_page", Value: "1"}}},
+		PageMode:     models.PaginationModeNextLink,
+		PageMaxPages: 5,
+	}
+	_, err := GetPaginatedResults(context.Background(), &backend.PluginContext{}, query, client, map[string]string{})
+	require.NoError(t, err)
+	require.Equal(t, []string{"/items?per_page=1", "/items?page=2", "/items?page=3"}, requestedURLs)
+
+	t.Run("should stop after max pages", func(t *testing.T) {
+		requestedURLs = []string{}
+		query.PageMaxPages = 2
+		_, err := GetPaginatedResults(context.Background(), &backend.PluginContext{}, query, client, map[string]string{})
+		require.NoError(t, err)
+		require.Equal(t, []string{"/items?per_page=1", "/items?page=2"}, requestedURLs)
+	})
+}
+
+func TestGetPageCountFromTotalCount(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      models.Query
+		totalCount string
+		maxPages   int
+		want       int
+	}{
+		{name: "should find the number of pages", query: models.Query{PageMode: models.PaginationModePage, PageParamSizeFieldVal: 10}, totalCount: "25", maxPages: 5, want: 3},
+		{name: "should find the number of pages when the total count is multiple of page size", query: models.Query{PageMode: models.PaginationModePage, PageParamSizeFieldVal: 10}, totalCount: "20", maxPages: 5, want: 2},
+		{name: "should not exceed the max pages", query: models.Query{PageMode: models.PaginationModePage, PageParamSizeFieldVal: 10}, totalCount: "1000", maxPages: 5, want: 5},
+		{name: "should skip the items before the initial offset", query: models.Query{PageMode: models.PaginationModeOffset, PageParamSizeFieldVal: 10, PageParamOffsetFieldVal: 10}, totalCount: "25", maxPages: 5, want: 2},
+		{name: "should request at least one page", query: models.Query{PageMode: models.PaginationModePage, PageParamSizeFieldVal: 10}, totalCount: "0", maxPages: 5, want: 1},
+		{name: "should allow quoted total count", query: models.Query{PageMode: models.PaginationModePage, PageParamSizeFieldVal: 10}, totalCount: `"11"`, maxPages: 5, want: 2},
+		{name: "should fallback to max pages for invalid total count", query: models.Query{PageMode: models.PaginationModePage, PageParamSizeFieldVal: 10}, totalCount: "", maxPages: 5, want: 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, GetPageCountFromTotalCount(context.Background(), tt.query, tt.totalCount, tt.maxPages))
+		})
+	}
+}
+
+func TestWithoutTotalCount(t *testing.T) {
+	queries := []models.Query{
+		{RefID: "A", PageTotalCountHeader: "X-Total-Count"},
+		{RefID: "B", PageTotalCountExtractionPath: "meta.total"},
+	}
+	got := withoutTotalCount(queries)
+	require.Equal(t, []models.Query{{RefID: "A"}, {RefID: "B"}}, got)
+	require.Equal(t, "X-Total-Count", queries[0].PageTotalCountHeader)
+	require.Equal(t, "meta.total", queries[1].PageTotalCountExtractionPath)
+}
+
+func TestGetPaginatedResultsStopsEarly(t *testing.T) {
+	requestedURLs := []string{}
+	var m sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m.Lock()
+		requestedURLs = append(requestedURLs, r.URL.RequestURI())
+		m.Unlock()
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Total-Count", "5")
+		w.Header().Set("Link", fmt.Sprintf(`</items?page=%d>; rel="next"`, page+1))
+		if page > 2 {
+			_, _ = w.Write([]byte(`[]`))
+			return
+		}
+		_, _ = fmt.Fprintf(w, `[{"page":%d},{"page":%d}]`, page, page)
+	}))
+	defer server.Close()
+	client := Client{Settings: models.InfinitySettings{}, HttpClient: server.Client()}
+	pCtx := &backend.PluginContext{GrafanaConfig: backend.NewGrafanaCfg(map[string]string{backend.ConcurrentQueryCount: "1"})}
+	query := models.Query{
+		Type:                   models.QueryTypeJSON,
+		Parser:                 models.InfinityParserUQL,
+		UQL:                    "parse-json",
+		Source:                 "url",
+		URL:                    server.URL + "/items",
+		URLOptions:             models.URLOptions{Method: http.MethodGet},
+		PageMaxPages:           5,
+		PageParamSizeFieldName: "size",
+		PageParamSizeFieldVal:  2,
+		PageParamPageFieldName: "page",
+		PageParamPageFieldVal:  1,
+	}
+	t.Run("page mode should stop at the empty page", func(t *testing.T) {
+		requestedURLs = []string{}
+		query := query
+		query.PageMode = models.PaginationModePage
+		_, err := GetPaginatedResults(context.Background(), pCtx, query, client, map[string]string{})
+		require.NoError(t, err)
+		require.Equal(t, []string{"/items?page=1&size=2", "/items?page=2&size=2", "/items?page=3&size=2"}, requestedURLs)
+	})
+	t.Run("page mode should only request the pages required by the total count", func(t *testing.T) {
+		requestedURLs = []string{}
+		query := query
+		query.PageMode = models.PaginationModePage
+		query.PageParamSizeFieldVal = 3
+		query.PageTotalCountHeader = "X-Total-Count"
+		_, err := GetPaginatedResults(context.Background(), pCtx, query, client, map[string]string{})
+		require.NoError(t, err)
+		require.Equal(t, []string{"/items?page=1&size=3", "/items?page=2&size=3"}, requestedURLs)
+	})
+	t.Run("next link mode should stop at the empty page", func(t *testing.T) {
+		requestedURLs = []string{}
+		query := query
+		query.PageMode = models.PaginationModeNextLink
+		_, err := GetPaginatedResults(context.Background(), pCtx, query, client, map[string]string{})
+		require.NoError(t, err)
+		require.Equal(t, []string{"/items", "/items?page=1", "/items?page=2", "/items?page=3"}, requestedURLs)
+	})
+}
+
+func TestGetPagesConcurrently(t *testing.T) {
+	var inFlight, maxInFlight atomic.Int32
+	var m sync.Mutex
+	requestedPages := []int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			previous := maxInFlight.Load()
+			if current <= previous || maxInFlight.CompareAndSwap(previous, current) {
+				break
+			}
+		}
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		m.Lock()
+		requestedPages = append(requestedPages, page)
+		m.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		if page > 3 {
+			_, _ = w.Write([]byte(`[]`))
+			return
+		}
+		// earlier pages respond slower so that the responses arrive out of order
+		time.Sleep(time.Duration(4-page) * 20 * time.Millisecond)
+		_, _ = fmt.Fprintf(w, `[{"page":%d}]`, page)
+	}))
+	defer server.Close()
+	client := Client{Settings: models.InfinitySettings{}, HttpClient: server.Client()}
+	getQueries := func(pages int) []models.Query {
+		queries := []models.Query{}
+		for page := 1; page <= pages; page++ {
+			query := models.Query{Type: models.QueryTypeJSON, Parser: models.InfinityParserUQL, UQL: "parse-json", Source: "url", URL: server.URL, URLOptions: models.URLOptions{Method: http.MethodGet}}
+			queries = append(queries, ApplyPaginationItemToQuery(query, models.PaginationParamTypeQuery, "page", strconv.Itoa(page)))
+		}
+		return queries
+	}
+	pageValues := func(t *testing.T, frames []*data.Frame) []float64 {
+		t.Helper()
+		values := []float64{}
+		for _, frame := range frames {
+			require.Equal(t, 1, frame.Rows())
+			value, ok := frame.Fields[0].ConcreteAt(0)
+			require.True(t, ok)
+			values = append(values, value.(float64))
+		}
+		return values
+	}
+	t.Run("should keep the order of the pages and drop the pages after the empty page", func(t *testing.T) {
+		frames, err := getPagesConcurrently(context.Background(), &backend.PluginContext{}, getQueries(6), client, map[string]string{})
+		require.NoError(t, err)
+		require.Equal(t, []float64{1, 2, 3}, pageValues(t, frames))
+	})
+	t.Run("should respect the concurrent query count", func(t *testing.T) {
+		maxInFlight.Store(0)
+		pCtx := &backend.PluginContext{GrafanaConfig: backend.NewGrafanaCfg(map[string]string{backend.ConcurrentQueryCount: "2"})}
+		frames, err := getPagesConcurrently(context.Background(), pCtx, getQueries(3), client, map[string]string{})
+		require.NoError(t, err)
+		require.Equal(t, []float64{1, 2, 3}, pageValues(t, frames))
+		require.LessOrEqual(t, maxInFlight.Load(), int32(2))
+	})
+	t.Run("should not request the pages after the empty page", func(t *testing.T) {
+		m.Lock()
+		requestedPages = []int{}
+		m.Unlock()
+		pCtx := &backend.PluginContext{GrafanaConfig: backend.NewGrafanaCfg(map[string]string{backend.ConcurrentQueryCount: "1"})}
+		frames, err := getPagesConcurrently(context.Background(), pCtx, getQueries(6), client, map[string]string{})
+		require.NoError(t, err)
+		require.Len(t, frames, 3)
+		require.Equal(t, []int{1, 2, 3, 4}, requestedPages)
+	})
+	t.Run("should keep the first page when it is empty", func(t *testing.T) {
+		queries := getQueries(6)[3:]
+		frames, err := getPagesConcurrently(context.Background(), &backend.PluginContext{}, queries, client, map[string]string{})
+		require.NoError(t, err)
+		require.Len(t, frames, 1)
+		require.Equal(t, 0, frames[0].Rows())
+	})
 }
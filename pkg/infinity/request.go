@@ -44,6 +44,7 @@ func GetRequest(ctx context.Context, pCtx *backend.PluginContext, settings model
 	req = ApplyBasicAuth(ctx, settings, req, includeSect)
 	req = ApplyBearerToken(ctx, settings, req, includeSect)
 	req = ApplyApiKeyAuth(ctx, settings, req, includeSect)
+	req = ApplyGoogleAPIKeyAuth(ctx, settings, req, includeSect)
 	req = ApplyForwardedOAuthIdentity(ctx, requestHeaders, settings, req, includeSect)
 	req = ApplyTraceHead(ctx, req)
 	req = ApplyForwardedCookies(ctx, settings, req, requestHeaders)
@@ -54,7 +55,7 @@ func GetQueryURL(ctx context.Context, pCtx *backend.PluginContext, settings mode
 	_, span := tracing.DefaultTracer().Start(ctx, "GetQueryURL")
 	defer span.End()
 	urlString := query.URL
-	if !strings.HasPrefix(query.URL, settings.URL) {
+	if !query.URLIsAbsolute && !strings.HasPrefix(query.URL, settings.URL) {
 		urlString = settings.URL + urlString
 	}
 	urlString = replaceSect(urlString, settings, includeSect)
@@ -109,7 +110,7 @@ func NormalizeURL(u string) string {
 
 func (client *Client) GetExecutedURL(ctx context.Context, query models.Query) string {
 	out := []string{}
-	if query.Source != "inline" && query.Source != "azure-blob" {
+	if query.Source != "inline" && query.Source != "azure-blob" && query.Source != "s3" && query.Source != "gcs" {
 		req, err := GetRequest(ctx, nil, client.Settings, GetQueryBody(ctx, query), query, map[string]string{}, false)
 		if err != nil {
 			return fmt.Sprintf("error retrieving full url. %s", query.URL)
@@ -133,9 +134,15 @@ func (client *Client) GetExecutedURL(ctx context.Context, query models.Query) st
 	if client.Settings.AuthenticationMethod == models.AuthenticationMethodAWS {
 		out = append(out, "###############", "> Authentication steps not included for AWS authentication")
 	}
+	if client.Settings.AuthenticationMethod == models.AuthenticationMethodGoogle && client.Settings.GoogleSettings.AuthType != models.GoogleAuthTypeAPIKey {
+		out = append(out, "###############", "> Authentication steps not included for google service account authentication")
+	}
 	if client.Settings.AuthenticationMethod == models.AuthenticationMethodAzureBlob {
 		out = append(out, "###############", "> Authentication steps not included for azure blob authentication")
 	}
+	if client.Settings.AuthenticationMethod == models.AuthenticationMethodAzureAD {
+		out = append(out, "###############", "> Authentication steps not included for azure AD authentication")
+	}
 	return strings.Join(out, "\n")
 }
 
@@ -0,0 +1,147 @@
+package infinity
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+)
+
+const (
+	defaultRetryInitialBackoff = 500 * time.Millisecond
+	defaultRetryMaxBackoff     = 10 * time.Second
+)
+
+var defaultRetryStatusCodes = []int{http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout}
+
+// canRetry reports whether the request can be retried. Only the idempotent methods are retried unless retrying the
+// non-idempotent methods is explicitly enabled in the settings
+func (client *Client) canRetry(req *http.Request) bool {
+	if client.Settings.RetryMaxAttempts <= 1 {
+		return false
+	}
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		// body can't be sent again once consumed
+		return false
+	}
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return client.Settings.RetryNonIdempotentMethods
+}
+
+// do sends the request and retries the transient failures with exponential backoff and jitter. Each attempt waits for
+// the rate limiter. Returns the number of attempts made and the time spent waiting for the rate limiter along with the response
+func (client *Client) do(ctx context.Context, req *http.Request, logger log.Logger) (res *http.Response, attempts int, rateLimitWait time.Duration, err error) {
+	maxAttempts := 1
+	if client.canRetry(req) {
+		maxAttempts = client.Settings.RetryMaxAttempts
+	}
+	for attempts = 1; ; attempts++ {
+		if attempts > 1 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, attempts - 1, rateLimitWait, err
+			}
+			req.Body = body
+		}
+		waited, err := client.rateLimiter.wait(ctx)
+		rateLimitWait += waited
+		if err != nil {
+			return nil, attempts - 1, rateLimitWait, err
+		}
+		if waited > 0 {
+			logger.Debug("request delayed by the rate limit", "url_path", req.URL.Path, "method", req.Method, "wait_ms", waited.Milliseconds())
+		}
+		res, err = client.HttpClient.Do(req)
+		if attempts >= maxAttempts || !client.isRetryable(ctx, res, err) {
+			return res, attempts, rateLimitWait, err
+		}
+		wait, ok := client.getRetryWait(res, attempts)
+		if !ok {
+			logger.Debug("not retrying the request as the server asked to wait longer than the max backoff", "url_path", req.URL.Path, "method", req.Method)
+			return res, attempts, rateLimitWait, err
+		}
+		if res != nil {
+			// body is drained so that the connection can be reused
+			_, _ = io.Copy(io.Discard, res.Body)
+			if err := res.Body.Close(); err != nil {
+				logger.Warn("error closing response body", "error", err.Error())
+			}
+		}
+		logger.Debug("retrying the request", "url_path", req.URL.Path, "method", req.Method, "attempt", attempts, "wait_ms", wait.Milliseconds())
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, attempts, rateLimitWait, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// isRetryable reports whether the failure is transient. Network errors such as connection resets and the configured
+// status codes are considered transient. Cancelled requests are never retried
+func (client *Client) isRetryable(ctx context.Context, res *http.Response, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if err != nil {
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+	if res == nil {
+		return false
+	}
+	statusCodes := client.Settings.RetryStatusCodes
+	if len(statusCodes) == 0 {
+		statusCodes = defaultRetryStatusCodes
+	}
+	return slices.Contains(statusCodes, res.StatusCode)
+}
+
+// getRetryWait returns how long to wait before the next attempt. Retry-After header of 429 and 503 responses takes
+// precedence over the backoff. Returns false when the server asks to wait longer than the max backoff
+func (client *Client) getRetryWait(res *http.Response, attempt int) (time.Duration, bool) {
+	initialBackoff := defaultRetryInitialBackoff
+	if client.Settings.RetryInitialBackoffInMs > 0 {
+		initialBackoff = time.Duration(client.Settings.RetryInitialBackoffInMs) * time.Millisecond
+	}
+	maxBackoff := defaultRetryMaxBackoff
+	if client.Settings.RetryMaxBackoffInMs > 0 {
+		maxBackoff = time.Duration(client.Settings.RetryMaxBackoffInMs) * time.Millisecond
+	}
+	if res != nil && (res.StatusCode == http.StatusTooManyRequests || res.StatusCode == http.StatusServiceUnavailable) {
+		if wait, ok := parseRetryAfter(res.Header.Get("Retry-After"), time.Now()); ok {
+			return wait, wait <= maxBackoff
+		}
+	}
+	backoff := maxBackoff
+	if shift := attempt - 1; shift < 32 {
+		backoff = min(maxBackoff, initialBackoff<<shift)
+	}
+	// equal jitter keeps at least half of the backoff while spreading the retries of concurrent requests
+	return backoff/2 + rand.N(backoff/2+1), true
+}
+
+// parseRetryAfter parses the Retry-After header value which can be either delay in seconds or an HTTP date
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(max(seconds, 0)) * time.Second, true
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return max(t.Sub(now), 0), true
+	}
+	return 0, false
+}
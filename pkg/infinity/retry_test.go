@@ -0,0 +1,144 @@
+package infinity
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-infinity-datasource/pkg/models"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetry(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempt := hits.Add(1)
+		body, _ := io.ReadAll(r.Body)
+		switch r.URL.Path {
+		case "/flaky":
+			if attempt < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+		case "/throttled":
+			w.Header().Set("Retry-After", r.URL.Query().Get("retry_after"))
+			if attempt < 2 {
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+		case "/not-found":
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"body":"` + string(body) + `"}`))
+	}))
+	defer server.Close()
+	getClient := func(settings models.InfinitySettings) *Client {
+		settings.RetryInitialBackoffInMs = 1
+		settings.RetryMaxBackoffInMs = 10
+		settings.AllowDangerousHTTPMethods = true
+		return &Client{Settings: settings, HttpClient: server.Client()}
+	}
+	getQuery := func(method, path string) models.Query {
+		return models.Query{Type: models.QueryTypeJSON, Source: "url", URL: server.URL + path, URLOptions: models.URLOptions{Method: method, BodyType: "raw", Body: "hello"}}
+	}
+	tests := []struct {
+		name         string
+		settings     models.InfinitySettings
+		query        models.Query
+		wantAttempts int
+		wantErr      bool
+	}{
+		{name: "should not retry when disabled", query: getQuery(http.MethodGet, "/flaky"), wantAttempts: 1, wantErr: true},
+		{name: "should retry until success", settings: models.InfinitySettings{RetryMaxAttempts: 3}, query: getQuery(http.MethodGet, "/flaky"), wantAttempts: 3},
+		{name: "should stop after max attempts", settings: models.InfinitySettings{RetryMaxAttempts: 2}, query: getQuery(http.MethodGet, "/flaky"), wantAttempts: 2, wantErr: true},
+		{name: "should retry idempotent methods with body", settings: models.InfinitySettings{RetryMaxAttempts: 3}, query: getQuery(http.MethodPut, "/flaky"), wantAttempts: 3},
+		{name: "should not retry post by default", settings: models.InfinitySettings{RetryMaxAttempts: 3}, query: getQuery(http.MethodPost, "/flaky"), wantAttempts: 1, wantErr: true},
+		{name: "should retry post when enabled", settings: models.InfinitySettings{RetryMaxAttempts: 3, RetryNonIdempotentMethods: true}, query: getQuery(http.MethodPost, "/flaky"), wantAttempts: 3},
+		{name: "should not retry non retryable status codes", settings: models.InfinitySettings{RetryMaxAttempts: 3}, query: getQuery(http.MethodGet, "/not-found"), wantAttempts: 1, wantErr: true},
+		{name: "should retry configured status codes", settings: models.InfinitySettings{RetryMaxAttempts: 2, RetryStatusCodes: []int{http.StatusNotFound}}, query: getQuery(http.MethodGet, "/not-found"), wantAttempts: 2, wantErr: true},
+		{name: "should honour retry after", settings: models.InfinitySettings{RetryMaxAttempts: 3}, query: getQuery(http.MethodGet, "/throttled?retry_after=0"), wantAttempts: 2},
+		{name: "should not retry when retry after is longer than max backoff", settings: models.InfinitySettings{RetryMaxAttempts: 3}, query: getQuery(http.MethodGet, "/throttled?retry_after=60"), wantAttempts: 1, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hits.Store(0)
+			o, info, err := getClient(tt.settings).GetResultsWithInfo(context.Background(), &backend.PluginContext{}, tt.query, nil)
+			require.Equal(t, tt.wantAttempts, info.Attempts)
+			require.Equal(t, int32(tt.wantAttempts), hits.Load())
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			if tt.query.URLOptions.Method != http.MethodGet {
+				require.Equal(t, map[string]any{"body": "hello"}, o)
+			}
+		})
+	}
+	t.Run("should record the attempts in the frame meta", func(t *testing.T) {
+		hits.Store(0)
+		frame, _, err := GetFrameForURLSourcesWithPostProcessing(context.Background(), &backend.PluginContext{}, getQuery(http.MethodGet, "/flaky"), *getClient(models.InfinitySettings{RetryMaxAttempts: 3}), nil, false)
+		require.NoError(t, err)
+		require.Equal(t, 3, frame.Meta.Custom.(*CustomMeta).Attempts)
+	})
+	t.Run("should stop retrying when the context is cancelled", func(t *testing.T) {
+		hits.Store(0)
+		client := getClient(models.InfinitySettings{RetryMaxAttempts: 3})
+		client.Settings.RetryInitialBackoffInMs = 60000
+		client.Settings.RetryMaxBackoffInMs = 60000
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		_, info, err := client.GetResultsWithInfo(ctx, &backend.PluginContext{}, getQuery(http.MethodGet, "/flaky"), nil)
+		require.Error(t, err)
+		require.Equal(t, 1, info.Attempts)
+	})
+}
+
+func TestGetRetryWait(t *testing.T) {
+	client := &Client{Settings: models.InfinitySettings{RetryInitialBackoffInMs: 100, RetryMaxBackoffInMs: 1000}}
+	for attempt, maxWait := range map[int]time.Duration{1: 100 * time.Millisecond, 2: 200 * time.Millisecond, 3: 400 * time.Millisecond, 5: time.Second, 100: time.Second} {
+		wait, ok := client.getRetryWait(nil, attempt)
+		require.True(t, ok)
+		require.GreaterOrEqual(t, wait, maxWait/2)
+		require.LessOrEqual(t, wait, maxWait)
+	}
+	wait, ok := client.getRetryWait(&http.Response{StatusCode: http.StatusServiceUnavailable, Header: http.Header{"Retry-After": []string{"1"}}}, 1)
+	require.True(t, ok)
+	require.Equal(t, time.Second, wait)
+	wait, ok = client.getRetryWait(&http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{"Retry-After": []string{"0"}}}, 1)
+	require.True(t, ok)
+	require.Equal(t, time.Duration(0), wait)
+	// the server asks to wait longer than the max backoff. the 429 is returned instead of retrying early
+	_, ok = client.getRetryWait(&http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{"Retry-After": []string{"2"}}}, 1)
+	require.False(t, ok)
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value  string
+		want   time.Duration
+		wantOk bool
+	}{
+		{value: "", wantOk: false},
+		{value: "5", want: 5 * time.Second, wantOk: true},
+		{value: "-5", want: 0, wantOk: true},
+		{value: "Mon, 01 Jan 2024 00:00:30 GMT", want: 30 * time.Second, wantOk: true},
+		{value: "Sun, 31 Dec 2023 23:59:00 GMT", want: 0, wantOk: true},
+		{value: "soon", wantOk: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, ok := parseRetryAfter(tt.value, now)
+			require.Equal(t, tt.wantOk, ok)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
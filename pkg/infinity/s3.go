@@ -0,0 +1,167 @@
+package infinity
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/grafana/grafana-aws-sdk/pkg/awsauth"
+	"github.com/grafana/grafana-infinity-datasource/pkg/httpclient"
+	"github.com/grafana/grafana-infinity-datasource/pkg/models"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+)
+
+const s3DefaultRegion = "us-east-1"
+
+// NewS3Client creates the client for Amazon S3 and the s3 compatible object storages such as MinIO using the AWS
+// credentials of the settings. Buckets are accessed anonymously when no keys configured with the keys auth type
+func NewS3Client(ctx context.Context, settings models.InfinitySettings) (*s3.Client, error) {
+	httpClient, err := httpclient.GetBaseHTTPClient(ctx, settings)
+	if err != nil {
+		return nil, err
+	}
+	options := s3.Options{
+		Region:       strings.TrimSpace(settings.AWSSettings.Region),
+		HTTPClient:   httpClient,
+		UsePathStyle: settings.AWSSettings.UsePathStyle,
+		Credentials:  aws.AnonymousCredentials{},
+		// s3 compatible storages don't support all the checksum algorithms. so checksums are only used when required
+		RequestChecksumCalculation: aws.RequestChecksumCalculationWhenRequired,
+		ResponseChecksumValidation: aws.ResponseChecksumValidationWhenRequired,
+	}
+	if isS3CredentialChainConfigured(settings) {
+		cfg, err := awsauth.NewConfigProvider().GetConfig(ctx, getS3AuthSettings(settings, httpClient))
+		if err != nil {
+			return nil, err
+		}
+		options.Credentials = cfg.Credentials
+		if options.Region == "" {
+			options.Region = cfg.Region
+		}
+	} else if strings.TrimSpace(settings.AWSAccessKey) != "" && strings.TrimSpace(settings.AWSSecretKey) != "" {
+		options.Credentials = credentials.NewStaticCredentialsProvider(settings.AWSAccessKey, settings.AWSSecretKey, "")
+	}
+	if options.Region == "" || options.Region == "default" {
+		options.Region = s3DefaultRegion
+	}
+	if endpoint := strings.TrimSpace(settings.AWSSettings.Endpoint); endpoint != "" {
+		options.BaseEndpoint = aws.String(models.FixMissingURLSchema(endpoint))
+	}
+	return s3.New(options), nil
+}
+
+// isS3CredentialChainConfigured returns true when the credentials are resolved by the AWS SDK instead of the static keys
+func isS3CredentialChainConfigured(settings models.InfinitySettings) bool {
+	authType := settings.AWSSettings.AuthType
+	return (authType != "" && authType != models.AWSAuthTypeKeys) || strings.TrimSpace(settings.AWSSettings.AssumeRoleARN) != ""
+}
+
+// getS3AuthSettings returns the auth settings of the grafana aws sdk which resolves the default credential chain,
+// the shared credentials profiles and the STS assume role. The auth types and assume role are allowed or denied by
+// the aws section of the grafana configuration
+func getS3AuthSettings(settings models.InfinitySettings, httpClient *http.Client) awsauth.Settings {
+	authType := settings.AWSSettings.AuthType
+	if authType == "" {
+		authType = models.AWSAuthTypeKeys
+	}
+	return awsauth.Settings{
+		AuthType:           awsauth.AuthType(authType),
+		AccessKey:          settings.AWSAccessKey,
+		SecretKey:          settings.AWSSecretKey,
+		Region:             strings.TrimSpace(settings.AWSSettings.Region),
+		CredentialsProfile: strings.TrimSpace(settings.AWSSettings.Profile),
+		AssumeRoleARN:      strings.TrimSpace(settings.AWSSettings.AssumeRoleARN),
+		ExternalID:         settings.AWSSettings.ExternalID,
+		HTTPClient:         httpClient,
+	}
+}
+
+// getS3Object downloads the object of the query. parquet and arrow files are read in ranges instead
+func (client *Client) getS3Object(ctx context.Context, query models.Query) (any, ResponseInfo, error) {
+	bucket, key := strings.TrimSpace(query.S3Bucket), strings.TrimSpace(query.S3Key)
+	if bucket == "" || key == "" {
+		return nil, ResponseInfo{StatusCode: http.StatusBadRequest}, backend.DownstreamError(errors.New("invalid/empty bucket name/object key"))
+	}
+	if client.S3Client == nil {
+		return nil, ResponseInfo{StatusCode: http.StatusInternalServerError}, backend.DownstreamError(models.ErrInvalidS3Client)
+	}
+	if isColumnarQuery(query) {
+		s3File, err := client.getS3File(ctx, bucket, key)
+		if err != nil {
+			return nil, ResponseInfo{StatusCode: GetS3ErrorStatusCode(err)}, backend.DownstreamError(err)
+		}
+		return s3File, ResponseInfo{StatusCode: http.StatusOK}, nil
+	}
+	object, err := client.S3Client.GetObject(ctx, &s3.GetObjectInput{Bucket: aws.String(bucket), Key: aws.String(key)})
+	if err != nil {
+		return nil, ResponseInfo{StatusCode: GetS3ErrorStatusCode(err)}, backend.DownstreamError(err)
+	}
+	defer func() { _ = object.Body.Close() }()
+	bodyBytes, err := io.ReadAll(object.Body)
+	if err != nil {
+		return nil, ResponseInfo{StatusCode: http.StatusInternalServerError}, backend.DownstreamError(fmt.Errorf("error reading s3 object. %w", err))
+	}
+	out, err := parseObjectContent(ctx, query, bodyBytes, aws.ToString(object.ContentType), key)
+	return out, ResponseInfo{StatusCode: http.StatusOK}, err
+}
+
+// GetS3ErrorStatusCode returns the http status code of the s3 error response
+func GetS3ErrorStatusCode(err error) int {
+	var responseError *awshttp.ResponseError
+	if errors.As(err, &responseError) {
+		return responseError.HTTPStatusCode()
+	}
+	return http.StatusInternalServerError
+}
+
+// s3File reads the s3 object in ranges
+type s3File struct {
+	ctx    context.Context
+	client *s3.Client
+	bucket string
+	key    string
+	size   int64
+}
+
+func (client *Client) getS3File(ctx context.Context, bucket, key string) (*s3File, error) {
+	head, err := client.S3Client.HeadObject(ctx, &s3.HeadObjectInput{Bucket: aws.String(bucket), Key: aws.String(key)})
+	if err != nil {
+		return nil, err
+	}
+	if head.ContentLength == nil {
+		return nil, errors.New("unable to get the object size")
+	}
+	return &s3File{ctx: ctx, client: client.S3Client, bucket: bucket, key: key, size: *head.ContentLength}, nil
+}
+
+func (f *s3File) Size() int64 {
+	return f.size
+}
+
+func (f *s3File) ReadAt(p []byte, off int64) (int, error) {
+	if off >= f.size {
+		return 0, io.EOF
+	}
+	count := min(int64(len(p)), f.size-off)
+	res, err := f.client.GetObject(f.ctx, &s3.GetObjectInput{
+		Bucket: aws.String(f.bucket),
+		Key:    aws.String(f.key),
+		Range:  aws.String(fmt.Sprintf("bytes=%d-%d", off, off+count-1)),
+	})
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = res.Body.Close() }()
+	n, err := io.ReadFull(res.Body, p[:count])
+	if err == nil && int(count) < len(p) {
+		err = io.EOF
+	}
+	return n, err
+}
@@ -0,0 +1,160 @@
+package infinity
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/grafana/grafana-infinity-datasource/pkg/models"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/config"
+	"github.com/stretchr/testify/require"
+)
+
+// getS3TestServer returns a s3 compatible server with path style addressing which serves the objects in ranges
+func getS3TestServer(t *testing.T, objects map[string][]byte, downloaded *atomic.Int64) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Contains(t, r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=my-access-key/")
+		require.Contains(t, r.Header.Get("Authorization"), "/eu-west-1/s3/aws4_request")
+		content, ok := objects[r.URL.Path]
+		if !ok {
+			w.Header().Set("Content-Type", "application/xml")
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?><Error><Code>NoSuchKey</Code><Message>The specified key does not exist.</Message></Error>`))
+			return
+		}
+		if r.Method == http.MethodHead {
+			w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+			return
+		}
+		start, end := 0, len(content)-1
+		if byteRange := r.Header.Get("Range"); byteRange != "" {
+			from, to, _ := strings.Cut(strings.TrimPrefix(byteRange, "bytes="), "-")
+			start, _ = strconv.Atoi(from)
+			end, _ = strconv.Atoi(to)
+		}
+		if downloaded != nil {
+			downloaded.Add(int64(end - start + 1))
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(end-start+1))
+		if r.Header.Get("Range") != "" {
+			w.WriteHeader(http.StatusPartialContent)
+		}
+		_, _ = w.Write(content[start : end+1])
+	}))
+}
+
+func getS3TestClient(t *testing.T, endpoint string) Client {
+	t.Helper()
+	settings := models.InfinitySettings{
+		AuthenticationMethod: models.AuthenticationMethodAWS,
+		AWSSettings:          models.AWSSettings{AuthType: models.AWSAuthTypeKeys, Region: "eu-west-1", Endpoint: endpoint, UsePathStyle: true},
+		AWSAccessKey:         "my-access-key",
+		AWSSecretKey:         "my-secret-key",
+		TimeoutInSeconds:     10,
+	}
+	s3Client, err := NewS3Client(context.Background(), settings)
+	require.NoError(t, err)
+	return Client{Settings: settings, S3Client: s3Client}
+}
+
+func TestS3Query(t *testing.T) {
+	t.Run("should download the object and parse the json content", func(t *testing.T) {
+		server := getS3TestServer(t, map[string][]byte{"/my-bucket/data/users.json": []byte("\xef\xbb\xbf" + `[{"name":"foo"}]`)}, nil)
+		defer server.Close()
+		client := getS3TestClient(t, server.URL)
+		out, info, err := client.GetResultsWithInfo(context.Background(), &backend.PluginContext{}, models.Query{Type: models.QueryTypeJSON, Source: "s3", S3Bucket: "my-bucket", S3Key: "data/users.json"}, nil)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, info.StatusCode)
+		require.Equal(t, []any{map[string]any{"name": "foo"}}, out)
+	})
+	t.Run("should return the text content as string", func(t *testing.T) {
+		server := getS3TestServer(t, map[string][]byte{"/my-bucket/users.csv": []byte("name\nfoo")}, nil)
+		defer server.Close()
+		client := getS3TestClient(t, server.URL)
+		out, _, err := client.GetResultsWithInfo(context.Background(), &backend.PluginContext{}, models.Query{Type: models.QueryTypeCSV, Source: "s3", S3Bucket: "my-bucket", S3Key: "users.csv"}, nil)
+		require.NoError(t, err)
+		require.Equal(t, "name\nfoo", out)
+	})
+	t.Run("should return downstream error for missing objects", func(t *testing.T) {
+		server := getS3TestServer(t, map[string][]byte{}, nil)
+		defer server.Close()
+		client := getS3TestClient(t, server.URL)
+		_, info, err := client.GetResultsWithInfo(context.Background(), &backend.PluginContext{}, models.Query{Type: models.QueryTypeCSV, Source: "s3", S3Bucket: "my-bucket", S3Key: "users.csv"}, nil)
+		require.Error(t, err)
+		require.True(t, backend.IsDownstreamError(err))
+		require.Equal(t, http.StatusNotFound, info.StatusCode)
+	})
+	t.Run("should return error for empty bucket or key", func(t *testing.T) {
+		client := getS3TestClient(t, "http://localhost")
+		_, info, err := client.GetResultsWithInfo(context.Background(), &backend.PluginContext{}, models.Query{Type: models.QueryTypeCSV, Source: "s3", S3Bucket: "my-bucket"}, nil)
+		require.Error(t, err)
+		require.Equal(t, http.StatusBadRequest, info.StatusCode)
+	})
+	t.Run("should return error when the s3 client is not configured", func(t *testing.T) {
+		_, _, err := (&Client{}).GetResultsWithInfo(context.Background(), &backend.PluginContext{}, models.Query{Type: models.QueryTypeCSV, Source: "s3", S3Bucket: "my-bucket", S3Key: "users.csv"}, nil)
+		require.ErrorIs(t, err, models.ErrInvalidS3Client)
+	})
+	t.Run("xlsx files", func(t *testing.T) {
+		server := getS3TestServer(t, map[string][]byte{"/my-bucket/kpis.xlsx": getXLSXTestFile(t)}, nil)
+		defer server.Close()
+		query := models.Query{RefID: "A", Type: models.QueryTypeXLSX, Source: "s3", Parser: models.InfinityParserBackend, S3Bucket: "my-bucket", S3Key: "kpis.xlsx", SheetName: "KPIs"}
+		frame, err := GetFrameForURLSources(context.Background(), &backend.PluginContext{}, query, getS3TestClient(t, server.URL), nil)
+		require.NoError(t, err)
+		require.Equal(t, 3, frame.Rows())
+		require.Nil(t, frame.Meta.Custom.(*CustomMeta).Data)
+	})
+	t.Run("parquet files should only download the required ranges", func(t *testing.T) {
+		content := getParquetTestFile(t, 50)
+		var downloaded atomic.Int64
+		server := getS3TestServer(t, map[string][]byte{"/my-bucket/blob.parquet": content}, &downloaded)
+		defer server.Close()
+		query := models.Query{RefID: "A", Type: models.QueryTypeParquet, Source: "s3", Parser: models.InfinityParserBackend, S3Bucket: "my-bucket", S3Key: "blob.parquet", MaxRows: 2, Columns: []models.InfinityColumn{{Selector: "value"}}}
+		frame, err := GetFrameForURLSources(context.Background(), &backend.PluginContext{}, query, getS3TestClient(t, server.URL), nil)
+		require.NoError(t, err)
+		require.Equal(t, 2, frame.Rows())
+		require.Equal(t, 1.5, *frame.Fields[0].At(1).(*float64))
+		require.Less(t, downloaded.Load(), int64(len(content)))
+	})
+}
+
+func TestNewS3ClientCredentialChain(t *testing.T) {
+	t.Run("default credential chain should use the credentials of the grafana host environment", func(t *testing.T) {
+		t.Setenv("AWS_ACCESS_KEY_ID", "my-access-key")
+		t.Setenv("AWS_SECRET_ACCESS_KEY", "my-secret-key")
+		t.Setenv("AWS_REGION", "eu-west-1")
+		t.Setenv("AWS_CONFIG_FILE", "/dev/null")
+		t.Setenv("AWS_SHARED_CREDENTIALS_FILE", "/dev/null")
+		t.Setenv("AWS_CA_BUNDLE", "")
+		server := getS3TestServer(t, map[string][]byte{"/my-bucket/users.csv": []byte("name\nfoo")}, nil)
+		defer server.Close()
+		settings := models.InfinitySettings{
+			AuthenticationMethod: models.AuthenticationMethodAWS,
+			AWSSettings:          models.AWSSettings{AuthType: models.AWSAuthTypeDefault, Endpoint: server.URL, UsePathStyle: true},
+			TimeoutInSeconds:     10,
+		}
+		s3Client, err := NewS3Client(context.Background(), settings)
+		require.NoError(t, err)
+		client := Client{Settings: settings, S3Client: s3Client}
+		out, _, err := client.GetResultsWithInfo(context.Background(), &backend.PluginContext{}, models.Query{Type: models.QueryTypeCSV, Source: "s3", S3Bucket: "my-bucket", S3Key: "users.csv"}, nil)
+		require.NoError(t, err)
+		require.Equal(t, "name\nfoo", out)
+	})
+	t.Run("should return error when the auth type is not allowed by the grafana configuration", func(t *testing.T) {
+		ctx := config.WithGrafanaConfig(context.Background(), config.NewGrafanaCfg(map[string]string{"AWS_AUTH_AllowedAuthProviders": "keys"}))
+		settings := models.InfinitySettings{AuthenticationMethod: models.AuthenticationMethodAWS, AWSSettings: models.AWSSettings{AuthType: models.AWSAuthTypeDefault, Region: "eu-west-1"}}
+		_, err := NewS3Client(ctx, settings)
+		require.ErrorContains(t, err, "trying to use non-allowed auth method default")
+	})
+	t.Run("should return error when assume role is disabled in the grafana configuration", func(t *testing.T) {
+		ctx := config.WithGrafanaConfig(context.Background(), config.NewGrafanaCfg(map[string]string{"AWS_AUTH_AllowedAuthProviders": "default", "AWS_AUTH_AssumeRoleEnabled": "false"}))
+		settings := models.InfinitySettings{AuthenticationMethod: models.AuthenticationMethodAWS, AWSSettings: models.AWSSettings{AuthType: models.AWSAuthTypeDefault, Region: "eu-west-1", AssumeRoleARN: "arn:aws:iam::123456789012:role/grafana", ExternalID: "foo"}}
+		_, err := NewS3Client(ctx, settings)
+		require.ErrorContains(t, err, "trying to use assume role but it is disabled in grafana config")
+	})
+}
@@ -0,0 +1,150 @@
+package infinity
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-infinity-datasource/pkg/models"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// sheetCellKind is the kind of the value of a spreadsheet cell. xlsx files and google sheets cells are converted into the same kinds
+type sheetCellKind int
+
+const (
+	sheetCellEmpty sheetCellKind = iota
+	sheetCellString
+	sheetCellNumber
+	sheetCellBool
+	sheetCellTime
+)
+
+type sheetCell struct {
+	kind      sheetCellKind
+	raw       string
+	formatted string
+	number    float64
+	time      time.Time
+}
+
+// appendSheetFields appends a field for each of the header columns. Columns of the query are matched by the header
+// to set the alias and the type of the field
+func appendSheetFields(frame *data.Frame, headers []string, records [][]sheetCell, query models.Query) {
+	for idx, header := range headers {
+		cells := make([]sheetCell, len(records))
+		hasValue := false
+		for i, record := range records {
+			if idx < len(record) {
+				cells[i] = record[idx]
+				hasValue = hasValue || record[idx].kind != sheetCellEmpty
+			}
+		}
+		name := strings.TrimSpace(header)
+		if name == "" {
+			// columns without header and values are the empty columns before the table
+			if !hasValue {
+				continue
+			}
+			name = fmt.Sprintf("Field %d", idx+1)
+		}
+		columnType, alias, timeFormat := "", name, ""
+		for _, col := range query.Columns {
+			if strings.TrimSpace(col.Selector) == name {
+				columnType, timeFormat = col.Type, col.TimeStampFormat
+				if col.Text != "" {
+					alias = col.Text
+				}
+			}
+		}
+		field := getSheetField(cells, columnType, timeFormat)
+		field.Name = alias
+		frame.Fields = append(frame.Fields, field)
+	}
+}
+
+// getSheetField creates a nullable field from the cells. When the column type is not set, cells of the same kind keep
+// their type and columns with mixed kinds are converted to the formatted strings
+func getSheetField(cells []sheetCell, columnType string, timeFormat string) *data.Field {
+	if columnType == "" {
+		columnType = getSheetColumnType(cells)
+	}
+	switch columnType {
+	case "number":
+		values := make([]*float64, len(cells))
+		for i, cell := range cells {
+			if cell.kind == sheetCellNumber || cell.kind == sheetCellTime {
+				values[i] = toPointer(cell.number)
+			} else if number, err := strconv.ParseFloat(strings.TrimSpace(cell.raw), 64); err == nil {
+				values[i] = &number
+			}
+		}
+		return data.NewField("", nil, values)
+	case "boolean":
+		values := make([]*bool, len(cells))
+		for i, cell := range cells {
+			if value, err := strconv.ParseBool(strings.TrimSpace(cell.raw)); err == nil {
+				values[i] = &value
+			}
+		}
+		return data.NewField("", nil, values)
+	case "timestamp", "timestamp_epoch", "timestamp_epoch_s":
+		values := make([]*time.Time, len(cells))
+		for i, cell := range cells {
+			values[i] = getSheetCellTime(cell, columnType, timeFormat)
+		}
+		return data.NewField("", nil, values)
+	default:
+		values := make([]*string, len(cells))
+		for i, cell := range cells {
+			if cell.kind != sheetCellEmpty {
+				values[i] = toPointer(cell.formatted)
+			}
+		}
+		return data.NewField("", nil, values)
+	}
+}
+
+func getSheetColumnType(cells []sheetCell) string {
+	kind := sheetCellEmpty
+	for _, cell := range cells {
+		if cell.kind == sheetCellEmpty || cell.kind == kind {
+			continue
+		}
+		if kind != sheetCellEmpty {
+			return "string"
+		}
+		kind = cell.kind
+	}
+	switch kind {
+	case sheetCellNumber:
+		return "number"
+	case sheetCellBool:
+		return "boolean"
+	case sheetCellTime:
+		return "timestamp"
+	default:
+		return "string"
+	}
+}
+
+func getSheetCellTime(cell sheetCell, columnType string, timeFormat string) *time.Time {
+	switch {
+	case cell.kind == sheetCellEmpty:
+		return nil
+	case columnType == "timestamp_epoch" && cell.kind == sheetCellNumber:
+		return toPointer(time.UnixMilli(int64(cell.number)).UTC())
+	case columnType == "timestamp_epoch_s" && cell.kind == sheetCellNumber:
+		return toPointer(time.Unix(int64(cell.number), 0).UTC())
+	case cell.kind == sheetCellTime:
+		return toPointer(cell.time)
+	}
+	if timeFormat == "" {
+		timeFormat = time.RFC3339
+	}
+	if t, err := time.Parse(timeFormat, strings.TrimSpace(cell.formatted)); err == nil {
+		return &t
+	}
+	return nil
+}
@@ -0,0 +1,313 @@
+package infinity
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-infinity-datasource/pkg/models"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/tracing"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+const (
+	defaultStreamPollingInterval = 10 * time.Second
+	minStreamPollingInterval     = time.Second
+	defaultStreamReconnectDelay  = 3 * time.Second
+	// the retry field of the server can't make the plugin reconnect faster than this
+	minStreamReconnectDelay = time.Second
+	// reconnects without any event back off up to this delay unless the server asks for a longer delay
+	maxStreamReconnectBackoff = time.Minute
+	// events larger than this are considered invalid to protect the plugin from unbounded lines
+	maxStreamEventSize = 1024 * 1024
+)
+
+// IsStreamingQuery reports whether the query should be streamed through Grafana Live instead of returning a single response
+func IsStreamingQuery(query models.Query) bool {
+	if query.Source != "url" {
+		return false
+	}
+	switch query.StreamMode {
+	case models.StreamModePolling, models.StreamModeSSE, models.StreamModeNDJSON:
+		return true
+	}
+	return false
+}
+
+// ValidateStreamingQuery verifies the query can be streamed before the subscription is accepted
+func ValidateStreamingQuery(ctx context.Context, pCtx *backend.PluginContext, settings models.InfinitySettings, query models.Query) error {
+	if !IsStreamingQuery(query) {
+		return backend.DownstreamError(errors.New("query is not a streaming query. streaming is only supported for url sources"))
+	}
+	if query.StreamMode == models.StreamModePolling && !isBackendQuery(query) && !isUQLQuery(query) && !isGROQQuery(query) {
+		// frontend parsers are not applied to the live frames
+		return backend.DownstreamError(errors.New("polling is only supported for the backend, uql and groq parsers"))
+	}
+	if query.StreamMode != models.StreamModePolling && query.Type != models.QueryTypeJSON && query.Type != models.QueryTypeCSV && query.Type != models.QueryTypeTSV {
+		return backend.DownstreamError(fmt.Errorf("%s streaming is only supported for json, csv and tsv queries", query.StreamMode))
+	}
+	// same security checks as the regular queries
+	if err := settings.Validate(); err != nil {
+		return backend.DownstreamError(err)
+	}
+	if settings.UnsecuredQueryHandling == models.UnsecuredQueryHandlingDeny && len(GetSecureHeaderWarnings(query)) > 0 {
+		return backend.DownstreamError(errors.New("query contain sensitive content and denied by the unsecuredQueryHandling config"))
+	}
+	url, err := GetQueryURL(ctx, pCtx, settings, query, true)
+	if err != nil {
+		return backend.DownstreamError(err)
+	}
+	if !CanAllowURL(url, settings.AllowedHosts) {
+		return backend.DownstreamError(models.ErrInvalidConfigHostNotAllowed)
+	}
+	return nil
+}
+
+// RunStream streams the results of the query until the context is cancelled. Each result is sent as a frame using the send function
+func RunStream(ctx context.Context, pCtx *backend.PluginContext, query models.Query, infClient Client, requestHeaders map[string]string, send func(*data.Frame) error) error {
+	ctx, span := tracing.DefaultTracer().Start(ctx, "RunStream")
+	defer span.End()
+	if query.StreamMode == models.StreamModePolling {
+		return pollStream(ctx, pCtx, query, infClient, requestHeaders, send)
+	}
+	logger := backend.Logger.FromContext(ctx)
+	stream := &eventStream{query: query, reconnectDelay: defaultStreamReconnectDelay}
+	for {
+		err := infClient.readEventStream(ctx, pCtx, stream, requestHeaders, send, logger)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if errors.Is(err, errStreamSend) {
+			return err
+		}
+		if err != nil {
+			logger.Warn("error reading the stream. reconnecting", "error", err.Error(), "refId", query.RefID)
+		} else {
+			logger.Debug("stream closed by the server. reconnecting", "refId", query.RefID)
+		}
+		if err := sleepWithContext(ctx, stream.nextReconnectDelay()); err != nil {
+			return nil
+		}
+	}
+}
+
+var errStreamSend = errors.New("error sending stream frame")
+
+// pollStream runs the query immediately and then on every interval
+func pollStream(ctx context.Context, pCtx *backend.PluginContext, query models.Query, infClient Client, requestHeaders map[string]string, send func(*data.Frame) error) error {
+	logger := backend.Logger.FromContext(ctx)
+	interval := defaultStreamPollingInterval
+	if query.StreamIntervalInMs > 0 {
+		interval = max(minStreamPollingInterval, time.Duration(query.StreamIntervalInMs)*time.Millisecond)
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		frame, err := GetFrameForURLSources(ctx, pCtx, query, infClient, requestHeaders)
+		if err != nil {
+			// transient failures should not end the stream. the next poll may succeed
+			logger.Warn("error polling the url", "error", err.Error(), "refId", query.RefID)
+		} else if frame != nil {
+			if err := send(toStreamFrame(frame)); err != nil {
+				return fmt.Errorf("%w. %w", errStreamSend, err)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+type eventStream struct {
+	query          models.Query
+	lastEventID    string
+	reconnectDelay time.Duration
+	// emptyReconnects is the number of reconnects since the last event
+	emptyReconnects int
+}
+
+// nextReconnectDelay returns the delay before reconnecting. The delay doubles for every reconnect without any event
+// so that a server which keeps dropping the connection is not hammered
+func (stream *eventStream) nextReconnectDelay() time.Duration {
+	delay := max(stream.reconnectDelay, minStreamReconnectDelay)
+	maxDelay := max(delay, maxStreamReconnectBackoff)
+	for i := 0; i < stream.emptyReconnects && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	stream.emptyReconnects++
+	return min(delay, maxDelay)
+}
+
+// readEventStream connects to the SSE or NDJSON endpoint and sends a frame for each event until the server closes the connection
+func (client *Client) readEventStream(ctx context.Context, pCtx *backend.PluginContext, stream *eventStream, requestHeaders map[string]string, send func(*data.Frame) error, logger log.Logger) error {
+	query := stream.query
+	req, err := GetRequest(ctx, pCtx, client.Settings, GetQueryBody(ctx, query), query, requestHeaders, true)
+	if err != nil {
+		return backend.DownstreamError(fmt.Errorf("error preparing request. %w", err))
+	}
+	if !CanAllowURL(req.URL.String(), client.Settings.AllowedHosts) {
+		return backend.DownstreamError(models.ErrInvalidConfigHostNotAllowed)
+	}
+	switch query.StreamMode {
+	case models.StreamModeSSE:
+		req.Header.Set(headerKeyAccept, "text/event-stream")
+		req.Header.Set("Cache-Control", "no-cache")
+		if stream.lastEventID != "" {
+			req.Header.Set("Last-Event-ID", stream.lastEventID)
+		}
+	case models.StreamModeNDJSON:
+		req.Header.Set(headerKeyAccept, "application/x-ndjson")
+	}
+	if _, err := client.rateLimiter.wait(ctx); err != nil {
+		return err
+	}
+	// the configured timeout applies to the whole response body, which would close the long-lived stream
+	httpClient := *client.HttpClient
+	httpClient.Timeout = 0
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return backend.DownstreamError(fmt.Errorf("error connecting to the stream. %w", err))
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			logger.Warn("error closing response body", "error", err.Error())
+		}
+	}()
+	if res.StatusCode >= http.StatusBadRequest {
+		return backend.DownstreamError(fmt.Errorf("%w\nstatus code : %s", models.ErrUnsuccessfulHTTPResponseStatus, res.Status))
+	}
+	var body io.Reader = res.Body
+	if strings.EqualFold(res.Header.Get("Content-Encoding"), "gzip") {
+		reader, err := gzip.NewReader(res.Body)
+		if err != nil {
+			return backend.DownstreamError(err)
+		}
+		defer func() {
+			if err := reader.Close(); err != nil {
+				logger.Warn("error closing gzip reader", "error", err.Error())
+			}
+		}()
+		body = reader
+	}
+	sendEvent := func(payload string) error {
+		stream.emptyReconnects = 0
+		frame, err := GetFrameForStreamEvent(ctx, query, payload)
+		if err != nil {
+			// invalid events are skipped so that a single malformed event doesn't end the stream
+			logger.Warn("error converting the stream event to frame", "error", err.Error(), "refId", query.RefID)
+			return nil
+		}
+		if err := send(toStreamFrame(frame)); err != nil {
+			return fmt.Errorf("%w. %w", errStreamSend, err)
+		}
+		return nil
+	}
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxStreamEventSize)
+	if query.StreamMode == models.StreamModeNDJSON {
+		for scanner.Scan() {
+			if line := strings.TrimSpace(scanner.Text()); line != "" {
+				if err := sendEvent(line); err != nil {
+					return err
+				}
+			}
+		}
+		return scanner.Err()
+	}
+	return parseServerSentEvents(scanner, stream, sendEvent)
+}
+
+// parseServerSentEvents reads the events as per https://html.spec.whatwg.org/multipage/server-sent-events.html#event-stream-interpretation
+// and calls sendEvent with the data of each event. Event id and retry fields are stored in the stream for reconnecting
+func parseServerSentEvents(scanner *bufio.Scanner, stream *eventStream, sendEvent func(payload string) error) error {
+	eventData := strings.Builder{}
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if line == "" {
+			if eventData.Len() > 0 {
+				if err := sendEvent(strings.TrimSuffix(eventData.String(), "\n")); err != nil {
+					return err
+				}
+			}
+			eventData.Reset()
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			// comments are used as keep alive messages
+			continue
+		}
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "data":
+			eventData.WriteString(value)
+			eventData.WriteString("\n")
+		case "id":
+			if !strings.Contains(value, "\x00") {
+				stream.lastEventID = value
+			}
+		case "retry":
+			if delay, err := strconv.Atoi(value); err == nil && delay >= 0 {
+				stream.reconnectDelay = max(minStreamReconnectDelay, time.Duration(delay)*time.Millisecond)
+			}
+		}
+	}
+	return scanner.Err()
+}
+
+// GetFrameForStreamEvent converts the data of a single stream event into a frame using the backend parsers
+func GetFrameForStreamEvent(ctx context.Context, query models.Query, payload string) (*data.Frame, error) {
+	var frame *data.Frame
+	var err error
+	switch query.Type {
+	case models.QueryTypeCSV, models.QueryTypeTSV:
+		frame, err = GetCSVBackendResponse(ctx, payload, query)
+	default:
+		var obj any
+		if obj, err = unmarshalJSONBody([]byte(payload), query); err != nil {
+			return nil, backend.DownstreamError(fmt.Errorf("%w. %w", models.ErrParsingResponseBodyAsJson, err))
+		}
+		switch {
+		case isUQLQuery(query):
+			frame, err = GetUQLBackendResponse(ctx, obj, query)
+		case isGROQQuery(query):
+			frame, err = GetGROQBackendResponse(ctx, obj, query)
+		default:
+			frame, err = GetJSONBackendResponse(ctx, obj, query)
+		}
+	}
+	if err != nil {
+		return frame, err
+	}
+	return PostProcessFrame(ctx, frame, query)
+}
+
+// toStreamFrame removes the response data from the frame meta as it is not used by the live panels and would be sent with every frame
+func toStreamFrame(frame *data.Frame) *data.Frame {
+	if frame.Meta != nil {
+		frame.Meta.Custom = nil
+	}
+	return frame
+}
+
+func sleepWithContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
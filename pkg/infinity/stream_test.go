@@ -0,0 +1,175 @@
+package infinity
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-infinity-datasource/pkg/models"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseServerSentEvents(t *testing.T) {
+	input := strings.Join([]string{
+		": keep alive",
+		"id: 1",
+		"data: {\"a\":1}",
+		"",
+		"event: update",
+		"retry: 5000",
+		"data:{\"a\":",
+		"data: 2}\r",
+		"id: 2",
+		"",
+		"retry: 0",
+		"data: incomplete",
+	}, "\n")
+	stream := &eventStream{reconnectDelay: defaultStreamReconnectDelay}
+	events := []string{}
+	err := parseServerSentEvents(bufio.NewScanner(strings.NewReader(input)), stream, func(payload string) error {
+		events = append(events, payload)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{`{"a":1}`, "{\"a\":\n2}"}, events)
+	require.Equal(t, "2", stream.lastEventID)
+	// retry below the minimum delay is clamped
+	require.Equal(t, minStreamReconnectDelay, stream.reconnectDelay)
+}
+
+func TestEventStreamNextReconnectDelay(t *testing.T) {
+	stream := &eventStream{reconnectDelay: defaultStreamReconnectDelay}
+	delays := []time.Duration{}
+	for range 7 {
+		delays = append(delays, stream.nextReconnectDelay())
+	}
+	require.Equal(t, []time.Duration{3 * time.Second, 6 * time.Second, 12 * time.Second, 24 * time.Second, 48 * time.Second, time.Minute, time.Minute}, delays)
+	// an event resets the backoff
+	stream.emptyReconnects = 0
+	require.Equal(t, 3*time.Second, stream.nextReconnectDelay())
+	// a retry longer than the maximum backoff is kept as is
+	stream = &eventStream{reconnectDelay: 5 * time.Minute}
+	require.Equal(t, 5*time.Minute, stream.nextReconnectDelay())
+	require.Equal(t, 5*time.Minute, stream.nextReconnectDelay())
+}
+
+func TestRunStream(t *testing.T) {
+	var connections atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		connection := connections.Add(1)
+		switch r.URL.Path {
+		case "/sse":
+			require.Equal(t, "text/event-stream", r.Header.Get("Accept"))
+			if connection > 1 {
+				require.Equal(t, "2", r.Header.Get("Last-Event-ID"))
+			}
+			w.Header().Set("Content-Type", "text/event-stream")
+			_, _ = fmt.Fprintf(w, "retry: 1\nid: 1\ndata: {\"value\":%d}\n\nid: 2\ndata: invalid\n\n", connection)
+		case "/ndjson":
+			w.Header().Set("Content-Type", "application/x-ndjson")
+			_, _ = fmt.Fprint(w, "{\"value\":1}\n\n{\"value\":2}\n")
+		case "/poll":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = fmt.Fprintf(w, `{"value":%d}`, connection)
+		}
+	}))
+	defer server.Close()
+	client := Client{Settings: models.InfinitySettings{TimeoutInSeconds: 1}, HttpClient: &http.Client{Timeout: time.Second}}
+	getQuery := func(mode models.StreamMode, path string) models.Query {
+		return models.Query{RefID: "A", Type: models.QueryTypeJSON, Source: "url", Parser: models.InfinityParserUQL, UQL: "parse-json", URL: server.URL + path, URLOptions: models.URLOptions{Method: http.MethodGet}, StreamMode: mode, StreamIntervalInMs: 1}
+	}
+	collect := func(t *testing.T, query models.Query, count int) []*data.Frame {
+		t.Helper()
+		connections.Store(0)
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		frames := []*data.Frame{}
+		err := RunStream(ctx, &backend.PluginContext{}, query, client, nil, func(frame *data.Frame) error {
+			frames = append(frames, frame)
+			if len(frames) == count {
+				cancel()
+			}
+			return nil
+		})
+		require.NoError(t, err)
+		require.Len(t, frames, count)
+		return frames
+	}
+	getValue := func(frame *data.Frame) any {
+		v, _ := frame.Fields[0].ConcreteAt(0)
+		return v
+	}
+	t.Run("should reconnect to sse endpoint and skip invalid events", func(t *testing.T) {
+		frames := collect(t, getQuery(models.StreamModeSSE, "/sse"), 2)
+		require.Equal(t, 1.0, getValue(frames[0]))
+		require.Equal(t, 2.0, getValue(frames[1]))
+		require.Nil(t, frames[0].Meta.Custom)
+	})
+	t.Run("should send a frame for each ndjson line", func(t *testing.T) {
+		frames := collect(t, getQuery(models.StreamModeNDJSON, "/ndjson"), 3)
+		require.Equal(t, 1.0, getValue(frames[0]))
+		require.Equal(t, 2.0, getValue(frames[1]))
+		require.Equal(t, 1.0, getValue(frames[2]))
+	})
+	t.Run("should poll the url", func(t *testing.T) {
+		frames := collect(t, getQuery(models.StreamModePolling, "/poll"), 2)
+		require.Equal(t, 1.0, getValue(frames[0]))
+		require.Equal(t, 2.0, getValue(frames[1]))
+	})
+}
+
+func TestValidateStreamingQuery(t *testing.T) {
+	settings := models.InfinitySettings{AllowedHosts: []string{"https://allowed.com"}}
+	tests := []struct {
+		name     string
+		settings *models.InfinitySettings
+		query    models.Query
+		wantErr  bool
+	}{
+		{name: "not a streaming query", query: models.Query{Source: "url", URL: "https://allowed.com"}, wantErr: true},
+		{name: "inline source", query: models.Query{Source: "inline", StreamMode: models.StreamModePolling}, wantErr: true},
+		{name: "unsupported type", query: models.Query{Type: models.QueryTypeXML, Source: "url", URL: "https://allowed.com", StreamMode: models.StreamModeSSE}, wantErr: true},
+		{name: "host not allowed", query: models.Query{Type: models.QueryTypeJSON, Source: "url", URL: "https://foo.com", StreamMode: models.StreamModeSSE}, wantErr: true},
+		{name: "valid sse query", query: models.Query{Type: models.QueryTypeJSON, Source: "url", URL: "https://allowed.com/events", StreamMode: models.StreamModeSSE}},
+		{name: "polling with frontend parser", query: models.Query{Type: models.QueryTypeXML, Parser: models.InfinityParserSimple, Source: "url", URL: "https://allowed.com", StreamMode: models.StreamModePolling}, wantErr: true},
+		{name: "valid polling query", query: models.Query{Type: models.QueryTypeXML, Parser: models.InfinityParserBackend, Source: "url", URL: "https://allowed.com", StreamMode: models.StreamModePolling}},
+		{
+			name:     "invalid settings",
+			settings: &models.InfinitySettings{AuthenticationMethod: models.AuthenticationMethodBearerToken, AllowedHosts: []string{"https://allowed.com"}},
+			query:    models.Query{Type: models.QueryTypeJSON, Source: "url", URL: "https://allowed.com/events", StreamMode: models.StreamModeSSE},
+			wantErr:  true,
+		},
+		{
+			name:     "secure headers denied",
+			settings: &models.InfinitySettings{AllowedHosts: []string{"https://allowed.com"}, UnsecuredQueryHandling: models.UnsecuredQueryHandlingDeny},
+			query:    models.Query{Type: models.QueryTypeJSON, Source: "url", URL: "https://allowed.com/events", StreamMode: models.StreamModeSSE, URLOptions: models.URLOptions{Headers: []models.URLOptionKeyValuePair{{Key: "Authorization", Value: "foo"}}}},
+			wantErr:  true,
+		},
+		{
+			name:  "secure headers allowed",
+			query: models.Query{Type: models.QueryTypeJSON, Source: "url", URL: "https://allowed.com/events", StreamMode: models.StreamModeSSE, URLOptions: models.URLOptions{Headers: []models.URLOptionKeyValuePair{{Key: "Authorization", Value: "foo"}}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			querySettings := settings
+			if tt.settings != nil {
+				querySettings = *tt.settings
+			}
+			err := ValidateStreamingQuery(context.Background(), &backend.PluginContext{}, querySettings, tt.query)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
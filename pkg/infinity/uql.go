@@ -0,0 +1,35 @@
+package infinity
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/grafana/grafana-infinity-datasource/pkg/models"
+	"github.com/grafana/grafana-infinity-datasource/pkg/uql"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/tracing"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+func isUQLQuery(query models.Query) bool {
+	return query.Type == models.QueryTypeUQL || query.Parser == models.InfinityParserUQL
+}
+
+// GetUQLBackendResponse runs the UQL query against the response and converts the result into a frame
+func GetUQLBackendResponse(ctx context.Context, response any, query models.Query) (*data.Frame, error) {
+	ctx, span := tracing.DefaultTracer().Start(ctx, "GetUQLBackendResponse")
+	defer span.End()
+	frame := GetDummyFrame(query)
+	result, err := uql.Run(ctx, query.UQL, response)
+	if err != nil {
+		span.RecordError(err)
+		if errors.Is(err, uql.ErrInvalidQuery) || errors.Is(err, uql.ErrUnknownCommand) || errors.Is(err, uql.ErrUnknownFunction) || errors.Is(err, uql.ErrParsingInput) {
+			return frame, backend.DownstreamError(fmt.Errorf("error executing uql query: %w", err))
+		}
+		return frame, backend.PluginError(fmt.Errorf("error executing uql query: %w", err))
+	}
+	newFrame := result.ToFrame(frame.Name)
+	frame.Fields = append(frame.Fields, newFrame.Fields...)
+	return frame, nil
+}
@@ -0,0 +1,270 @@
+package infinity
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-infinity-datasource/pkg/models"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/tracing"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/xuri/excelize/v2"
+)
+
+// xlsx files are zip archives. unzipped size is limited to protect against zip bombs
+const xlsxUnzipSizeLimit = 512 << 20
+
+var (
+	errSheetNotFound     = errors.New("sheet not found in the file")
+	errInvalidSheetRange = errors.New("invalid sheet range")
+
+	// quoted text, escaped characters and sections in brackets such as colors are not part of the date tokens of the number format
+	xlsxNumFmtLiterals = regexp.MustCompile(`"[^"]*"|\\.|\[[^\]]*\]`)
+)
+
+// xlsxRange is the 1 based inclusive range of cells to read. zero values of the end column and row means no limit
+type xlsxRange struct {
+	fromCol, fromRow, toCol, toRow int
+}
+
+// GetXLSXBackendResponse converts the sheet of the xlsx file into a frame. First non empty row of the range is used as
+// the header and the type of each field is inferred from the cells of the column
+func GetXLSXBackendResponse(ctx context.Context, response any, query models.Query) (*data.Frame, error) {
+	_, span := tracing.DefaultTracer().Start(ctx, "GetXLSXBackendResponse")
+	defer span.End()
+	var content []byte
+	switch r := response.(type) {
+	case []byte:
+		content = r
+	case string:
+		content = []byte(r)
+	default:
+		return GetDummyFrame(query), backend.PluginError(fmt.Errorf("unsupported response type %T for xlsx query", response))
+	}
+	frame, err := getFrameFromXLSX(content, query)
+	if err != nil {
+		span.RecordError(err)
+		return GetDummyFrame(query), backend.DownstreamError(fmt.Errorf("error reading xlsx file. %w", err))
+	}
+	return frame, nil
+}
+
+func getFrameFromXLSX(content []byte, query models.Query) (*data.Frame, error) {
+	f, err := excelize.OpenReader(bytes.NewReader(content), excelize.Options{UnzipSizeLimit: xlsxUnzipSizeLimit})
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	sheetName, sheetRange := getXLSXSheetAndRange(query)
+	if sheetName == "" {
+		sheets := f.GetSheetList()
+		if len(sheets) == 0 {
+			return nil, errSheetNotFound
+		}
+		sheetName = sheets[0]
+	}
+	if index, err := f.GetSheetIndex(sheetName); err != nil || index < 0 {
+		return nil, fmt.Errorf("%w: %s", errSheetNotFound, sheetName)
+	}
+	cellRange, err := parseXLSXRange(sheetRange)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := readXLSXRows(f, sheetName, cellRange)
+	if err != nil {
+		return nil, err
+	}
+	frame := GetDummyFrame(query)
+	if len(rows) == 0 {
+		return frame, nil
+	}
+	headers := make([]string, len(rows[0]))
+	for i, cell := range rows[0] {
+		headers[i] = cell.formatted
+	}
+	appendSheetFields(frame, headers, rows[1:], query)
+	return frame, nil
+}
+
+// getXLSXSheetAndRange returns the sheet name and the range of the query. Sheet name can also be part of the range as in Sheet1!A1:D10
+func getXLSXSheetAndRange(query models.Query) (string, string) {
+	sheetName, sheetRange := strings.TrimSpace(query.SheetName), strings.TrimSpace(query.SheetRange)
+	if name, cells, ok := strings.Cut(sheetRange, "!"); ok {
+		sheetRange = cells
+		if sheetName == "" {
+			sheetName = strings.ReplaceAll(strings.Trim(name, "'"), "''", "'")
+		}
+	}
+	return sheetName, sheetRange
+}
+
+// parseXLSXRange parses the A1 notation ranges such as A1:D10, A:D, A2:D or B3. Empty range reads the entire sheet
+func parseXLSXRange(input string) (xlsxRange, error) {
+	out := xlsxRange{fromCol: 1, fromRow: 1}
+	input = strings.ReplaceAll(strings.TrimSpace(input), "$", "")
+	if input == "" {
+		return out, nil
+	}
+	from, to, hasEnd := strings.Cut(input, ":")
+	var err error
+	if out.fromCol, out.fromRow, err = parseXLSXCellRef(from); err != nil {
+		return out, err
+	}
+	if out.fromCol == 0 {
+		out.fromCol = 1
+	}
+	if out.fromRow == 0 {
+		out.fromRow = 1
+	}
+	if !hasEnd {
+		return out, nil
+	}
+	if out.toCol, out.toRow, err = parseXLSXCellRef(to); err != nil {
+		return out, err
+	}
+	if (out.toCol > 0 && out.toCol < out.fromCol) || (out.toRow > 0 && out.toRow < out.fromRow) {
+		return out, fmt.Errorf("%w: %s", errInvalidSheetRange, input)
+	}
+	return out, nil
+}
+
+// parseXLSXCellRef parses the column and row of the cell reference. Column or row can be omitted in which case zero is returned
+func parseXLSXCellRef(ref string) (col int, row int, err error) {
+	ref = strings.ToUpper(strings.TrimSpace(ref))
+	if ref == "" {
+		return 0, 0, fmt.Errorf("%w: empty cell reference", errInvalidSheetRange)
+	}
+	letters := strings.IndexFunc(ref, func(r rune) bool { return r < 'A' || r > 'Z' })
+	if letters < 0 {
+		letters = len(ref)
+	}
+	if letters > 0 {
+		if col, err = excelize.ColumnNameToNumber(ref[:letters]); err != nil {
+			return 0, 0, fmt.Errorf("%w: %s", errInvalidSheetRange, ref)
+		}
+	}
+	if letters < len(ref) {
+		if row, err = strconv.Atoi(ref[letters:]); err != nil || row < 1 {
+			return 0, 0, fmt.Errorf("%w: %s", errInvalidSheetRange, ref)
+		}
+	}
+	return col, row, nil
+}
+
+// readXLSXRows reads the cells in the range along with the kind of the value. Rows without any value are skipped
+func readXLSXRows(f *excelize.File, sheetName string, cellRange xlsxRange) ([][]sheetCell, error) {
+	rawRows, err := f.GetRows(sheetName, excelize.Options{RawCellValue: true})
+	if err != nil {
+		return nil, err
+	}
+	formattedRows, err := f.GetRows(sheetName)
+	if err != nil {
+		return nil, err
+	}
+	props, err := f.GetWorkbookProps()
+	if err != nil {
+		return nil, err
+	}
+	date1904 := props.Date1904 != nil && *props.Date1904
+	dateStyles := map[int]bool{}
+	out := [][]sheetCell{}
+	for rowIdx := cellRange.fromRow - 1; rowIdx < len(rawRows) && (cellRange.toRow == 0 || rowIdx < cellRange.toRow); rowIdx++ {
+		toCol := len(rawRows[rowIdx])
+		if cellRange.toCol > 0 {
+			toCol = min(toCol, cellRange.toCol)
+		}
+		row := []sheetCell{}
+		hasValue := false
+		for colIdx := cellRange.fromCol - 1; colIdx < toCol; colIdx++ {
+			cell := sheetCell{raw: rawRows[rowIdx][colIdx]}
+			if rowIdx < len(formattedRows) && colIdx < len(formattedRows[rowIdx]) {
+				cell.formatted = formattedRows[rowIdx][colIdx]
+			}
+			if cell.raw != "" {
+				cellName, err := excelize.CoordinatesToCellName(colIdx+1, rowIdx+1)
+				if err != nil {
+					return nil, err
+				}
+				if err := setXLSXCellKind(f, sheetName, cellName, date1904, dateStyles, &cell); err != nil {
+					return nil, err
+				}
+			}
+			hasValue = hasValue || cell.kind != sheetCellEmpty
+			row = append(row, cell)
+		}
+		if hasValue {
+			out = append(out, row)
+		}
+	}
+	return out, nil
+}
+
+// setXLSXCellKind finds the kind of the cell value. Dates are stored as numbers in the xlsx files and can only be
+// identified by the number format of the cell style. dateStyles caches whether a style uses a date format
+func setXLSXCellKind(f *excelize.File, sheetName, cellName string, date1904 bool, dateStyles map[int]bool, cell *sheetCell) error {
+	cellType, err := f.GetCellType(sheetName, cellName)
+	if err != nil {
+		return err
+	}
+	switch cellType {
+	case excelize.CellTypeError:
+		cell.kind = sheetCellEmpty
+	case excelize.CellTypeBool:
+		cell.kind = sheetCellBool
+	case excelize.CellTypeDate:
+		cell.kind = sheetCellString
+		for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999999", "2006-01-02"} {
+			if t, err := time.Parse(layout, cell.raw); err == nil {
+				cell.kind, cell.time = sheetCellTime, t
+				break
+			}
+		}
+	case excelize.CellTypeSharedString, excelize.CellTypeInlineString, excelize.CellTypeFormula:
+		cell.kind = sheetCellString
+	default:
+		number, err := strconv.ParseFloat(cell.raw, 64)
+		if err != nil {
+			cell.kind = sheetCellString
+			return nil
+		}
+		cell.kind, cell.number = sheetCellNumber, number
+		styleID, err := f.GetCellStyle(sheetName, cellName)
+		if err != nil {
+			return err
+		}
+		isDate, ok := dateStyles[styleID]
+		if !ok {
+			isDate = isXLSXDateStyle(f, styleID)
+			dateStyles[styleID] = isDate
+		}
+		if isDate {
+			if t, err := excelize.ExcelDateToTime(number, date1904); err == nil {
+				cell.kind, cell.time = sheetCellTime, t
+			}
+		}
+	}
+	return nil
+}
+
+func isXLSXDateStyle(f *excelize.File, styleID int) bool {
+	style, err := f.GetStyle(styleID)
+	if err != nil || style == nil {
+		return false
+	}
+	if style.CustomNumFmt != nil {
+		format := strings.ToLower(xlsxNumFmtLiterals.ReplaceAllString(*style.CustomNumFmt, ""))
+		return strings.ContainsAny(format, "ymdhs")
+	}
+	// built-in date and time formats including the language specific ones
+	switch {
+	case style.NumFmt >= 14 && style.NumFmt <= 22, style.NumFmt >= 27 && style.NumFmt <= 36, style.NumFmt >= 45 && style.NumFmt <= 47, style.NumFmt >= 50 && style.NumFmt <= 58:
+		return true
+	}
+	return false
+}
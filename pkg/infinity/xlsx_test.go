@@ -0,0 +1,174 @@
+package infinity
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/grafana/grafana-infinity-datasource/pkg/models"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+// getXLSXTestFile returns a workbook with the KPIs in the second sheet. Sheet1 is left empty
+func getXLSXTestFile(t *testing.T) []byte {
+	t.Helper()
+	f := excelize.NewFile()
+	defer f.Close()
+	_, err := f.NewSheet("KPIs")
+	require.NoError(t, err)
+	dateStyle, err := f.NewStyle(&excelize.Style{NumFmt: 14})
+	require.NoError(t, err)
+	rows := [][]any{
+		{"Day", "Region", "Revenue", "Target met", "Notes"},
+		{time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), "EMEA", 1250.5, true, "ok"},
+		{},
+		{time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), "APAC", 980, false, 42},
+		{time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC), nil, 1100, true, nil},
+	}
+	for i, row := range rows {
+		cell, err := excelize.CoordinatesToCellName(2, i+2)
+		require.NoError(t, err)
+		require.NoError(t, f.SetSheetRow("KPIs", cell, &row))
+	}
+	require.NoError(t, f.SetCellStyle("KPIs", "B3", "B6", dateStyle))
+	buf, err := f.WriteToBuffer()
+	require.NoError(t, err)
+	return buf.Bytes()
+}
+
+func TestParseXLSXRange(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    xlsxRange
+		wantErr bool
+	}{
+		{input: "", want: xlsxRange{fromCol: 1, fromRow: 1}},
+		{input: "A1:D10", want: xlsxRange{fromCol: 1, fromRow: 1, toCol: 4, toRow: 10}},
+		{input: "$B$2:$C$5", want: xlsxRange{fromCol: 2, fromRow: 2, toCol: 3, toRow: 5}},
+		{input: "b:d", want: xlsxRange{fromCol: 2, fromRow: 1, toCol: 4}},
+		{input: "A2:D", want: xlsxRange{fromCol: 1, fromRow: 2, toCol: 4}},
+		{input: "C3", want: xlsxRange{fromCol: 3, fromRow: 3}},
+		{input: "D1:A1", wantErr: true},
+		{input: "A0:B2", wantErr: true},
+		{input: "A1:", wantErr: true},
+		{input: "1A:B2", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseXLSXRange(tt.input)
+			if tt.wantErr {
+				require.ErrorIs(t, err, errInvalidSheetRange)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestGetXLSXBackendResponse(t *testing.T) {
+	content := getXLSXTestFile(t)
+	day := func(d int) *time.Time { return toPointer(time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC)) }
+	t.Run("should use the first row as header and infer the field types", func(t *testing.T) {
+		frame, err := GetXLSXBackendResponse(context.Background(), content, models.Query{RefID: "A", Type: models.QueryTypeXLSX, SheetName: "KPIs"})
+		require.NoError(t, err)
+		want := data.NewFrame("A",
+			data.NewField("Day", nil, []*time.Time{day(1), day(2), day(3)}),
+			data.NewField("Region", nil, []*string{toPointer("EMEA"), toPointer("APAC"), nil}),
+			data.NewField("Revenue", nil, []*float64{toPointer(1250.5), toPointer(980.0), toPointer(1100.0)}),
+			data.NewField("Target met", nil, []*bool{toPointer(true), toPointer(false), toPointer(true)}),
+			data.NewField("Notes", nil, []*string{toPointer("ok"), toPointer("42"), nil}),
+		)
+		require.Equal(t, want.Fields, frame.Fields)
+	})
+	t.Run("should read the range with the sheet name in the range", func(t *testing.T) {
+		frame, err := GetXLSXBackendResponse(context.Background(), content, models.Query{RefID: "A", Type: models.QueryTypeXLSX, SheetRange: "'KPIs'!C2:D4"})
+		require.NoError(t, err)
+		want := data.NewFrame("A",
+			data.NewField("Region", nil, []*string{toPointer("EMEA")}),
+			data.NewField("Revenue", nil, []*float64{toPointer(1250.5)}),
+		)
+		require.Equal(t, want.Fields, frame.Fields)
+	})
+	t.Run("should apply the column alias and type", func(t *testing.T) {
+		query := models.Query{RefID: "A", Type: models.QueryTypeXLSX, SheetName: "KPIs", SheetRange: "D:E", Columns: []models.InfinityColumn{{Selector: "Revenue", Text: "Amount", Type: "string"}}}
+		frame, err := GetXLSXBackendResponse(context.Background(), content, query)
+		require.NoError(t, err)
+		require.Equal(t, "Amount", frame.Fields[0].Name)
+		require.Equal(t, "980", *frame.Fields[0].At(1).(*string))
+		require.Equal(t, data.FieldTypeNullableBool, frame.Fields[1].Type())
+	})
+	t.Run("should return empty frame for empty sheets", func(t *testing.T) {
+		frame, err := GetXLSXBackendResponse(context.Background(), content, models.Query{RefID: "A", Type: models.QueryTypeXLSX})
+		require.NoError(t, err)
+		require.Empty(t, frame.Fields)
+	})
+	t.Run("should return error for unknown sheets", func(t *testing.T) {
+		_, err := GetXLSXBackendResponse(context.Background(), content, models.Query{RefID: "A", Type: models.QueryTypeXLSX, SheetName: "foo"})
+		require.ErrorIs(t, err, errSheetNotFound)
+		require.True(t, backend.IsDownstreamError(err))
+	})
+	t.Run("should return error for invalid files", func(t *testing.T) {
+		_, err := GetXLSXBackendResponse(context.Background(), []byte("foo"), models.Query{RefID: "A", Type: models.QueryTypeXLSX})
+		require.Error(t, err)
+		require.True(t, backend.IsDownstreamError(err))
+	})
+}
+
+func TestXLSXQuery(t *testing.T) {
+	content := getXLSXTestFile(t)
+	query := models.Query{RefID: "A", Type: models.QueryTypeXLSX, Parser: models.InfinityParserBackend, SheetName: "KPIs", SheetRange: "B2:D"}
+	t.Run("url source", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Contains(t, r.Header.Get("Accept"), "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+			_, _ = w.Write(content)
+		}))
+		defer server.Close()
+		query := query
+		query.Source, query.URL, query.URLOptions = "url", server.URL, models.URLOptions{Method: http.MethodGet}
+		frame, err := GetFrameForURLSources(context.Background(), &backend.PluginContext{}, query, Client{HttpClient: server.Client()}, nil)
+		require.NoError(t, err)
+		require.Equal(t, 3, frame.Rows())
+		require.Len(t, frame.Fields, 3)
+		require.Nil(t, frame.Meta.Custom.(*CustomMeta).Data)
+	})
+	t.Run("inline source", func(t *testing.T) {
+		query := query
+		query.Source, query.Data = "inline", base64.StdEncoding.EncodeToString(content)
+		frame, err := GetFrameForInlineSources(context.Background(), query)
+		require.NoError(t, err)
+		require.Equal(t, 3, frame.Rows())
+		require.Equal(t, "APAC", *frame.Fields[1].At(1).(*string))
+	})
+	t.Run("inline source with invalid base64", func(t *testing.T) {
+		query := query
+		query.Source, query.Data = "inline", "foo bar"
+		_, err := GetFrameForInlineSources(context.Background(), query)
+		require.Error(t, err)
+		require.True(t, backend.IsDownstreamError(err))
+	})
+	t.Run("azure blob source", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "/container/kpis.xlsx", r.URL.Path)
+			w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+			_, _ = w.Write(content)
+		}))
+		defer server.Close()
+		azClient, err := azblob.NewClientWithNoCredential(server.URL, nil)
+		require.NoError(t, err)
+		query := query
+		query.Source, query.AzBlobContainerName, query.AzBlobName = "azure-blob", "container", "kpis.xlsx"
+		frame, err := GetFrameForURLSources(context.Background(), &backend.PluginContext{}, query, Client{AzureBlobClient: azClient}, nil)
+		require.NoError(t, err)
+		require.Equal(t, 3, frame.Rows())
+		require.Equal(t, 980.0, *frame.Fields[2].At(1).(*float64))
+	})
+}
@@ -0,0 +1,41 @@
+package infinity
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/grafana/grafana-infinity-datasource/pkg/models"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/tracing"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"sigs.k8s.io/yaml"
+)
+
+// GetYAMLBackendResponse decodes the YAML response into the same object model as JSON responses and converts it into a frame
+// using the backend json parser. This allows root selector, columns and the post processing to work the same way as JSON queries
+func GetYAMLBackendResponse(ctx context.Context, responseString string, query models.Query) (*data.Frame, error) {
+	ctx, span := tracing.DefaultTracer().Start(ctx, "GetYAMLBackendResponse")
+	defer span.End()
+	obj, err := parseYAML(responseString)
+	if err != nil {
+		span.RecordError(err)
+		frame := GetDummyFrame(query)
+		frame.Meta.Custom = &CustomMeta{Query: query, Error: err.Error()}
+		return frame, backend.DownstreamError(err)
+	}
+	return GetJSONBackendResponse(ctx, obj, query)
+}
+
+// parseYAML converts the YAML document into generic json values. Keys of the mappings are converted to strings
+func parseYAML(input string) (any, error) {
+	jsonBytes, err := yaml.YAMLToJSON([]byte(input))
+	if err != nil {
+		return nil, fmt.Errorf("%w. %w", models.ErrParsingResponseBodyAsYAML, err)
+	}
+	var obj any
+	if err := json.Unmarshal(jsonBytes, &obj); err != nil {
+		return nil, fmt.Errorf("%w. %w", models.ErrParsingResponseBodyAsYAML, err)
+	}
+	return obj, nil
+}
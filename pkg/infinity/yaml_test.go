@@ -0,0 +1,67 @@
+package infinity
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/grafana/grafana-infinity-datasource/pkg/models"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseYAML(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    any
+		wantErr error
+	}{
+		{name: "list", input: "- name: foo\n  age: 1\n- name: bar\n  age: 2.5", want: []any{map[string]any{"name": "foo", "age": 1.0}, map[string]any{"name": "bar", "age": 2.5}}},
+		{name: "nested object", input: "kind: List\nitems:\n  - metadata:\n      name: foo\n    enabled: true\n", want: map[string]any{"kind": "List", "items": []any{map[string]any{"metadata": map[string]any{"name": "foo"}, "enabled": true}}}},
+		{name: "non string keys", input: "1: one\ntrue: two", want: map[string]any{"1": "one", "true": "two"}},
+		{name: "empty value", input: "- name: foo\n  salary:", want: []any{map[string]any{"name": "foo", "salary": nil}}},
+		{name: "invalid yaml", input: "foo: [bar", wantErr: models.ErrParsingResponseBodyAsYAML},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseYAML(tt.input)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestYAMLQuery(t *testing.T) {
+	users, err := os.ReadFile("../../testdata/users.yaml")
+	require.NoError(t, err)
+	t.Run("url source", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Contains(t, r.Header.Get("Accept"), "application/yaml")
+			w.Header().Set("Content-Type", "application/yaml")
+			_, _ = w.Write(users)
+		}))
+		defer server.Close()
+		query := models.Query{RefID: "A", Type: models.QueryTypeYAML, Source: "url", Parser: models.InfinityParserBackend, URL: server.URL, URLOptions: models.URLOptions{Method: http.MethodGet}}
+		frame, err := GetFrameForURLSources(context.Background(), &backend.PluginContext{}, query, Client{HttpClient: server.Client()}, nil)
+		require.NoError(t, err)
+		require.Equal(t, string(users), frame.Meta.Custom.(*CustomMeta).Data)
+	})
+	t.Run("inline source", func(t *testing.T) {
+		query := models.Query{RefID: "A", Type: models.QueryTypeYAML, Source: "inline", Parser: models.InfinityParserBackend, Data: string(users)}
+		_, err := GetFrameForInlineSources(context.Background(), query)
+		require.NoError(t, err)
+	})
+	t.Run("invalid yaml should return downstream error", func(t *testing.T) {
+		query := models.Query{RefID: "A", Type: models.QueryTypeYAML, Source: "inline", Parser: models.InfinityParserBackend, Data: "foo: [bar"}
+		_, err := GetFrameForInlineSources(context.Background(), query)
+		require.ErrorIs(t, err, models.ErrParsingResponseBodyAsYAML)
+		require.True(t, backend.IsDownstreamError(err))
+	})
+}
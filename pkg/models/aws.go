@@ -0,0 +1,74 @@
+package models
+
+import (
+	"os"
+	"strings"
+)
+
+// The access and secret key authentication and the data sources saved before the authentication type was introduced
+// don't require the region and the service. The requests of those data sources are signed for the below defaults
+const (
+	AWSLegacyDefaultRegion  = "us-east-2"
+	AWSLegacyDefaultService = "monitoring"
+)
+
+// UsesLegacyDefaults reports whether the empty region and service default to us-east-2 and monitoring. This is the
+// case for the access and secret key authentication and the settings saved before the authentication type was introduced
+func (s AWSSettings) UsesLegacyDefaults() bool {
+	return s.AuthType == "" || s.AuthType == AWSAuthTypeKeys
+}
+
+// ResolveRegion returns the configured region. When not configured, the region of the grafana host environment
+// (AWS_REGION / AWS_DEFAULT_REGION) is used. This is the case for EKS pods using IRSA
+func (s AWSSettings) ResolveRegion() string {
+	if region := strings.TrimSpace(s.Region); region != "" && region != "default" {
+		return region
+	}
+	for _, key := range []string{"AWS_REGION", "AWS_DEFAULT_REGION"} {
+		if region := strings.TrimSpace(os.Getenv(key)); region != "" {
+			return region
+		}
+	}
+	return ""
+}
+
+func (s *InfinitySettings) validateAWS() error {
+	switch s.AWSSettings.AuthType {
+	case AWSAuthTypeKeys:
+		if strings.TrimSpace(s.AWSAccessKey) == "" {
+			return ErrInvalidConfigAWSAccessKey
+		}
+		if strings.TrimSpace(s.AWSSecretKey) == "" {
+			return ErrInvalidConfigAWSSecretKey
+		}
+	case AWSAuthTypeDefault, AWSAuthTypeSharedCreds, AWSAuthTypeEC2IAMRole, "":
+	default:
+		return ErrInvalidConfigAWSAuthType
+	}
+	if s.AWSSettings.ExternalID != "" && strings.TrimSpace(s.AWSSettings.AssumeRoleARN) == "" {
+		return ErrInvalidConfigAWSExternalID
+	}
+	if !s.AWSSettings.UsesLegacyDefaults() {
+		if s.AWSSettings.ResolveRegion() == "" {
+			return ErrInvalidConfigAWSRegion
+		}
+		if strings.TrimSpace(s.AWSSettings.Service) == "" {
+			return ErrInvalidConfigAWSService
+		}
+	}
+	return nil
+}
+
+func (s *InfinitySettings) awsWarnings() []string {
+	if !s.AWSSettings.UsesLegacyDefaults() {
+		return nil
+	}
+	warnings := []string{}
+	if s.AWSSettings.ResolveRegion() == "" {
+		warnings = append(warnings, "AWS region is not configured. The requests are signed for the legacy default region "+AWSLegacyDefaultRegion+". Configure the region in the data source config")
+	}
+	if strings.TrimSpace(s.AWSSettings.Service) == "" {
+		warnings = append(warnings, "AWS service is not configured. The requests are signed for the legacy default service "+AWSLegacyDefaultService+". Configure the service in the data source config")
+	}
+	return warnings
+}
@@ -0,0 +1,207 @@
+package models
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+const (
+	AzureCloudPublic       = "AzureCloud"
+	AzureCloudUSGovernment = "AzureUSGovernment"
+	AzureCloudChina        = "AzureChinaCloud"
+)
+
+type AzureBlobAuthType string
+
+const (
+	AzureBlobAuthTypeSharedKey        AzureBlobAuthType = "sharedKey"
+	AzureBlobAuthTypeSASToken         AzureBlobAuthType = "sasToken"
+	AzureBlobAuthTypeClientSecret     AzureBlobAuthType = "clientSecret"
+	AzureBlobAuthTypeManagedIdentity  AzureBlobAuthType = "managedIdentity"
+	AzureBlobAuthTypeWorkloadIdentity AzureBlobAuthType = "workloadIdentity"
+)
+
+// AzureAuthType is the Microsoft Entra ID credential type of the azure AD authentication
+type AzureAuthType string
+
+const (
+	AzureAuthTypeClientSecret      AzureAuthType = "clientSecret"
+	AzureAuthTypeClientCertificate AzureAuthType = "clientCertificate"
+	AzureAuthTypeManagedIdentity   AzureAuthType = "managedIdentity"
+	AzureAuthTypeWorkloadIdentity  AzureAuthType = "workloadIdentity"
+)
+
+// AzureCredentials are the Microsoft Entra ID credentials shared by the azure blob and the azure AD authentication
+type AzureCredentials struct {
+	AuthType          AzureAuthType
+	CloudType         string
+	TenantID          string
+	ClientID          string
+	ClientSecret      string
+	ClientCertificate string
+}
+
+// azureADScopes are the scopes of the well known Microsoft APIs, keyed by the API host
+var azureADScopes = map[string]string{
+	"management.azure.com":            "https://management.azure.com/.default",
+	"management.usgovcloudapi.net":    "https://management.usgovcloudapi.net/.default",
+	"management.chinacloudapi.cn":     "https://management.chinacloudapi.cn/.default",
+	"graph.microsoft.com":             "https://graph.microsoft.com/.default",
+	"graph.microsoft.us":              "https://graph.microsoft.us/.default",
+	"dod-graph.microsoft.us":          "https://dod-graph.microsoft.us/.default",
+	"microsoftgraph.chinacloudapi.cn": "https://microsoftgraph.chinacloudapi.cn/.default",
+	"api.loganalytics.io":             "https://api.loganalytics.io/.default",
+	"api.loganalytics.us":             "https://api.loganalytics.us/.default",
+	"api.loganalytics.azure.cn":       "https://api.loganalytics.azure.cn/.default",
+}
+
+// azureADScopesByDomain are the scopes of the Microsoft APIs which use one host per resource. i.e. key vaults
+var azureADScopesByDomain = map[string]string{
+	".vault.azure.net":         "https://vault.azure.net/.default",
+	".vault.usgovcloudapi.net": "https://vault.usgovcloudapi.net/.default",
+	".vault.azure.cn":          "https://vault.azure.cn/.default",
+}
+
+func (s *InfinitySettings) validateAzureAD() error {
+	switch s.AzureADSettings.AuthType {
+	case AzureAuthTypeClientSecret, "":
+		if strings.TrimSpace(s.AzureADSettings.TenantID) == "" {
+			return ErrInvalidConfigAzureTenantID
+		}
+		if strings.TrimSpace(s.AzureADSettings.ClientID) == "" {
+			return ErrInvalidConfigAzureClientID
+		}
+		if strings.TrimSpace(s.AzureADClientSecret) == "" {
+			return ErrInvalidConfigAzureClientSecret
+		}
+	case AzureAuthTypeClientCertificate:
+		if strings.TrimSpace(s.AzureADSettings.TenantID) == "" {
+			return ErrInvalidConfigAzureTenantID
+		}
+		if strings.TrimSpace(s.AzureADSettings.ClientID) == "" {
+			return ErrInvalidConfigAzureClientID
+		}
+		if strings.TrimSpace(s.AzureADClientCertificate) == "" {
+			return ErrInvalidConfigAzureClientCert
+		}
+	case AzureAuthTypeManagedIdentity, AzureAuthTypeWorkloadIdentity:
+		// identity of the grafana host. enabled by the grafana configuration
+	default:
+		return ErrInvalidConfigAzureAuthType
+	}
+	return nil
+}
+
+// AzureBlobCredentials returns the Entra ID credentials of the azure blob authentication
+func (s *InfinitySettings) AzureBlobCredentials() AzureCredentials {
+	return AzureCredentials{
+		AuthType:     AzureAuthType(s.AzureBlobAuthType),
+		CloudType:    s.AzureBlobCloudType,
+		TenantID:     s.AzureBlobTenantID,
+		ClientID:     s.AzureBlobClientID,
+		ClientSecret: s.AzureBlobClientSecret,
+	}
+}
+
+// AzureADCredentials returns the Entra ID credentials of the azure AD authentication
+func (s *InfinitySettings) AzureADCredentials() AzureCredentials {
+	return AzureCredentials{
+		AuthType:          s.AzureADSettings.AuthType,
+		CloudType:         s.AzureADSettings.CloudType,
+		TenantID:          s.AzureADSettings.TenantID,
+		ClientID:          s.AzureADSettings.ClientID,
+		ClientSecret:      s.AzureADClientSecret,
+		ClientCertificate: s.AzureADClientCertificate,
+	}
+}
+
+// AzureADScope returns the scope of the Entra ID token for the given host. Scopes configured for the host take precedence
+// over the scopes of the well known Microsoft APIs. Other hosts use the default scope of the host as the resource
+func (s *InfinitySettings) AzureADScope(host string) string {
+	host = strings.ToLower(host)
+	for k, scope := range s.AzureADSettings.Scopes {
+		u, err := url.Parse(FixMissingURLSchema(strings.TrimSpace(k)))
+		if err == nil && strings.EqualFold(u.Hostname(), host) && strings.TrimSpace(scope) != "" {
+			return strings.TrimSpace(scope)
+		}
+	}
+	if scope, ok := azureADScopes[host]; ok {
+		return scope
+	}
+	for domain, scope := range azureADScopesByDomain {
+		if strings.HasSuffix(host, domain) {
+			return scope
+		}
+	}
+	return fmt.Sprintf("https://%s/.default", host)
+}
+
+func (s *InfinitySettings) validateAzureBlob() error {
+	switch s.AzureBlobAuthType {
+	case AzureBlobAuthTypeSharedKey, "":
+		if strings.TrimSpace(s.AzureBlobAccountName) == "" {
+			return ErrInvalidConfigAzBlobAccName
+		}
+		if strings.TrimSpace(s.AzureBlobAccountKey) == "" {
+			return ErrInvalidConfigAzBlobKey
+		}
+	case AzureBlobAuthTypeSASToken:
+		if strings.TrimSpace(s.AzureBlobSASToken) == "" {
+			return ErrInvalidConfigAzBlobSASToken
+		}
+		// account name is not required when the SAS token is a full url
+		if strings.TrimSpace(s.AzureBlobAccountName) == "" && !isURL(s.AzureBlobSASToken) {
+			return ErrInvalidConfigAzBlobAccName
+		}
+	case AzureBlobAuthTypeClientSecret:
+		if strings.TrimSpace(s.AzureBlobAccountName) == "" {
+			return ErrInvalidConfigAzBlobAccName
+		}
+		if strings.TrimSpace(s.AzureBlobTenantID) == "" {
+			return ErrInvalidConfigAzBlobTenantID
+		}
+		if strings.TrimSpace(s.AzureBlobClientID) == "" {
+			return ErrInvalidConfigAzBlobClientID
+		}
+		if strings.TrimSpace(s.AzureBlobClientSecret) == "" {
+			return ErrInvalidConfigAzBlobClientSecret
+		}
+	case AzureBlobAuthTypeManagedIdentity, AzureBlobAuthTypeWorkloadIdentity:
+		if strings.TrimSpace(s.AzureBlobAccountName) == "" {
+			return ErrInvalidConfigAzBlobAccName
+		}
+	default:
+		return ErrInvalidConfigAzBlobAuthType
+	}
+	return nil
+}
+
+// AzureBlobServiceURL returns the blob service url of the storage account
+func (s *InfinitySettings) AzureBlobServiceURL() string {
+	serviceURL := "https://%s.blob.core.windows.net/"
+	if s.AzureBlobAccountUrl != "" {
+		serviceURL = s.AzureBlobAccountUrl
+	}
+	if strings.Contains(serviceURL, "%s") {
+		serviceURL = fmt.Sprintf(serviceURL, s.AzureBlobAccountName)
+	}
+	return serviceURL
+}
+
+// AzureBlobSASServiceURL returns the blob service url with the SAS token. The SAS token can be either the query
+// string generated by the azure portal or the full SAS url. Container and blob paths of the SAS url are ignored
+// as the container and blob names are part of the query
+func (s *InfinitySettings) AzureBlobSASServiceURL() string {
+	token := strings.TrimSpace(s.AzureBlobSASToken)
+	if isURL(token) {
+		u, _ := url.Parse(token)
+		return fmt.Sprintf("%s://%s/?%s", u.Scheme, u.Host, u.RawQuery)
+	}
+	return strings.TrimSuffix(s.AzureBlobServiceURL(), "?") + "?" + strings.TrimPrefix(token, "?")
+}
+
+func isURL(input string) bool {
+	u, err := url.Parse(strings.TrimSpace(input))
+	return err == nil && (u.Scheme == "https" || u.Scheme == "http") && u.Host != ""
+}
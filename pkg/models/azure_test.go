@@ -0,0 +1,68 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAzureBlobSASServiceURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings InfinitySettings
+		want     string
+	}{
+		{
+			name:     "SAS token",
+			settings: InfinitySettings{AzureBlobAccountName: "foo", AzureBlobSASToken: "sv=2022-11-02&sig=bar"},
+			want:     "https://foo.blob.core.windows.net/?sv=2022-11-02&sig=bar",
+		},
+		{
+			name:     "SAS token with leading question mark and sovereign cloud",
+			settings: InfinitySettings{AzureBlobAccountName: "foo", AzureBlobAccountUrl: "https://%s.blob.core.usgovcloudapi.net/", AzureBlobSASToken: "?sv=2022-11-02&sig=bar"},
+			want:     "https://foo.blob.core.usgovcloudapi.net/?sv=2022-11-02&sig=bar",
+		},
+		{
+			name:     "SAS url",
+			settings: InfinitySettings{AzureBlobSASToken: "https://foo.blob.core.windows.net/?sv=2022-11-02&sig=bar"},
+			want:     "https://foo.blob.core.windows.net/?sv=2022-11-02&sig=bar",
+		},
+		{
+			name:     "container SAS url",
+			settings: InfinitySettings{AzureBlobSASToken: " https://foo.blob.core.windows.net/my-container?sv=2022-11-02&sr=c&sig=bar "},
+			want:     "https://foo.blob.core.windows.net/?sv=2022-11-02&sr=c&sig=bar",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, tt.settings.AzureBlobSASServiceURL())
+		})
+	}
+}
+
+func TestAzureADScope(t *testing.T) {
+	settings := InfinitySettings{AzureADSettings: AzureADSettings{Scopes: map[string]string{
+		"https://api.foo.com": "api://foo/.default",
+		"bar.com":             " api://bar/.default ",
+		"baz.com":             "",
+	}}}
+	tests := []struct {
+		host string
+		want string
+	}{
+		{host: "management.azure.com", want: "https://management.azure.com/.default"},
+		{host: "management.usgovcloudapi.net", want: "https://management.usgovcloudapi.net/.default"},
+		{host: "Graph.Microsoft.com", want: "https://graph.microsoft.com/.default"},
+		{host: "microsoftgraph.chinacloudapi.cn", want: "https://microsoftgraph.chinacloudapi.cn/.default"},
+		{host: "myvault.vault.azure.net", want: "https://vault.azure.net/.default"},
+		{host: "api.foo.com", want: "api://foo/.default"},
+		{host: "bar.com", want: "api://bar/.default"},
+		{host: "baz.com", want: "https://baz.com/.default"},
+		{host: "myaccount.blob.core.windows.net", want: "https://myaccount.blob.core.windows.net/.default"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			require.Equal(t, tt.want, settings.AzureADScope(tt.host))
+		})
+	}
+}
@@ -10,20 +10,37 @@ var (
 var (
 	ErrUnsuccessfulHTTPResponseStatus error = errors.New("unsuccessful HTTP response code")
 	ErrParsingResponseBodyAsJson      error = errors.New("unable to parse response body as JSON")
+	ErrParsingResponseBodyAsYAML      error = errors.New("unable to parse response body as YAML")
 	ErrCreatingHTTPClient             error = errors.New("error creating HTTP client")
 	ErrNotAllowedDangerousHTTPMethods error = errors.New(`only GET and POST HTTP methods are allowed for this data source. To make use other methods, enable the "Allow dangerous HTTP methods" in the data source configuration`)
 )
 
 var (
-	ErrInvalidConfig               error = errors.New("invalid settings")
-	ErrInvalidConfigPassword       error = errors.New("invalid or empty password detected")
-	ErrInvalidConfigAPIKey         error = errors.New("invalid API key specified")
-	ErrInvalidConfigBearerToken    error = errors.New("invalid or empty bearer token detected")
-	ErrInvalidConfigAzBlobAccName  error = errors.New("invalid/empty azure blob account name")
-	ErrInvalidConfigAzBlobKey      error = errors.New("invalid/empty azure blob key")
-	ErrInvalidConfigAWSAccessKey   error = errors.New("invalid/empty AWS access key")
-	ErrInvalidConfigAWSSecretKey   error = errors.New("invalid/empty AWS secret key")
-	ErrInvalidConfigHostNotAllowed error = errors.New("requested URL not allowed. To allow this URL, update the data source config in the Security tab, Allowed hosts section")
+	ErrInvalidConfig                   error = errors.New("invalid settings")
+	ErrInvalidConfigPassword           error = errors.New("invalid or empty password detected")
+	ErrInvalidConfigAPIKey             error = errors.New("invalid API key specified")
+	ErrInvalidConfigBearerToken        error = errors.New("invalid or empty bearer token detected")
+	ErrInvalidConfigAzBlobAccName      error = errors.New("invalid/empty azure blob account name")
+	ErrInvalidConfigAzBlobKey          error = errors.New("invalid/empty azure blob key")
+	ErrInvalidConfigAzBlobSASToken     error = errors.New("invalid/empty azure blob SAS token")
+	ErrInvalidConfigAzBlobTenantID     error = errors.New("invalid/empty azure blob tenant id")
+	ErrInvalidConfigAzBlobClientID     error = errors.New("invalid/empty azure blob client id")
+	ErrInvalidConfigAzBlobClientSecret error = errors.New("invalid/empty azure blob client secret")
+	ErrInvalidConfigAzBlobAuthType     error = errors.New("invalid azure blob authentication type")
+	ErrInvalidConfigAWSAccessKey       error = errors.New("invalid/empty AWS access key")
+	ErrInvalidConfigAWSSecretKey       error = errors.New("invalid/empty AWS secret key")
+	ErrInvalidConfigAWSAuthType        error = errors.New("invalid AWS authentication type")
+	ErrInvalidConfigAWSExternalID      error = errors.New("AWS external id requires the assume role ARN")
+	ErrInvalidConfigAWSRegion          error = errors.New("invalid/empty AWS region")
+	ErrInvalidConfigAWSService         error = errors.New("invalid/empty AWS service")
+	ErrInvalidConfigOAuth2AuthURL      error = errors.New("invalid/empty OAuth2 authorization URL")
+	ErrInvalidConfigOAuth2TokenURL     error = errors.New("invalid/empty OAuth2 token URL")
+	ErrInvalidConfigOAuth2PrivateKey   error = errors.New("invalid/empty OAuth2 private key. PEM encoded PKCS #8, PKCS #1 or EC private key expected")
+	ErrInvalidConfigOAuth2ClientAuth   error = errors.New("invalid OAuth2 client authentication method")
+	ErrInvalidConfigOAuth2ClientCert   error = errors.New("OAuth2 mutual TLS client authentication requires the TLS client certificate and key")
+	ErrInvalidConfigGoogleKey          error = errors.New("invalid/empty google service account key")
+	ErrInvalidConfigGoogleAPIKey       error = errors.New("invalid/empty google API key")
+	ErrInvalidConfigHostNotAllowed     error = errors.New("requested URL not allowed. To allow this URL, update the data source config in the Security tab, Allowed hosts section")
 )
 
 var (
@@ -31,5 +48,51 @@ var (
 	ErrConnectingAzBlobAccount  error = errors.New("error connecting to azure blob storage")
 	ErrInvalidAzBlobStorageName error = errors.New("invalid azure blob storage name")
 	ErrAzBlob403                error = errors.New("http 403. check azure blob storage key")
+	ErrAzBlob403SASToken        error = errors.New("http 403. check the permissions and the expiry of the azure blob SAS token")
+	ErrAzBlob403EntraID         error = errors.New("http 403. assign the Storage Blob Data Reader role to the identity")
 	ErrAzBlob500                error = errors.New("http 500")
+	ErrAzureIdentityDisabled    error = errors.New("managed identity and workload identity authentication must be enabled in the azure section of the Grafana configuration and forwarded to the plugin")
+)
+
+var (
+	ErrInvalidConfigAzureAuthType     error = errors.New("invalid azure authentication type")
+	ErrInvalidConfigAzureTenantID     error = errors.New("invalid/empty azure tenant id")
+	ErrInvalidConfigAzureClientID     error = errors.New("invalid/empty azure client id")
+	ErrInvalidConfigAzureClientSecret error = errors.New("invalid/empty azure client secret")
+	ErrInvalidConfigAzureClientCert   error = errors.New("invalid/empty azure client certificate. PEM encoded certificate and private key expected")
+	ErrAzureADToken                   error = errors.New("error getting the Microsoft Entra ID token")
+)
+
+var (
+	ErrOAuth2AuthorizationRequired error = errors.New("OAuth2 authorization is not completed. authorize the data source in the data source configuration page")
+	ErrOAuth2InvalidState          error = errors.New("invalid or expired OAuth2 authorization state. start the authorization again")
+	ErrOAuth2AdminRequired         error = errors.New("only the organization admins can authorize the data source")
+	ErrOAuth2NotAuthorizationCode  error = errors.New("the data source is not configured with the OAuth2 authorization code grant type")
+	ErrOAuth2RefreshTokenNotSaved  error = errors.New("the OAuth2 refresh token rotated by the authorization server is not saved. authorize the data source again after restarting grafana")
+	ErrOAuth2RefreshTokenNoSaving  error = errors.New("the OAuth2 refresh tokens rotated by the authorization server can't be saved without the externalServiceAccounts feature toggle of grafana")
+)
+
+var (
+	ErrInvalidConfigTokenExchangeType             error = errors.New("invalid token exchange type")
+	ErrInvalidConfigTokenExchangeTokenURL         error = errors.New("invalid/empty token exchange token URL")
+	ErrInvalidConfigTokenExchangeClient           error = errors.New("token exchange using on-behalf-of flow requires the client id and the client secret")
+	ErrInvalidConfigTokenExchangeSubjectTokenType error = errors.New("invalid token exchange subject token type")
+	ErrTokenExchangeSubjectTokenRequired          error = errors.New("token exchange requires the OAuth identity of the signed-in user. make sure the user is signed in using OAuth")
+	ErrTokenExchangeFailed                        error = errors.New("error exchanging the OAuth identity of the user for the token of the API")
+)
+
+var (
+	ErrInvalidS3Client   error = errors.New("invalid s3 client. check the AWS authentication settings")
+	ErrConnectingS3      error = errors.New("error connecting to s3")
+	ErrS3BucketNotFound  error = errors.New("bucket not found")
+	ErrS3AccessDenied    error = errors.New("http 403. check the AWS access key, secret key and the bucket policy")
+	ErrS3InvalidEndpoint error = errors.New("invalid s3 endpoint")
+)
+
+var (
+	ErrInvalidGCSClient   error = errors.New("invalid google cloud storage client. check the google service account key")
+	ErrConnectingGCS      error = errors.New("error connecting to google cloud storage")
+	ErrGCSBucketNotFound  error = errors.New("bucket not found")
+	ErrGCSAccessDenied    error = errors.New("http 403. grant the storage object viewer role on the bucket to the service account")
+	ErrGCSInvalidListType error = errors.New("listing the objects is only supported with the JSON type")
 )
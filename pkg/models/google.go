@@ -0,0 +1,76 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+const (
+	GoogleSheetsHost      = "https://sheets.googleapis.com"
+	GoogleStorageHost     = "https://storage.googleapis.com"
+	GoogleDefaultTokenURL = "https://oauth2.googleapis.com/token"
+	GoogleStorageScope    = "https://www.googleapis.com/auth/devstorage.read_only"
+)
+
+// googleScopes are the read only scopes of the google APIs, keyed by the API host
+var googleScopes = map[string]string{
+	"sheets.googleapis.com":   "https://www.googleapis.com/auth/spreadsheets.readonly",
+	"drive.googleapis.com":    "https://www.googleapis.com/auth/drive.readonly",
+	"storage.googleapis.com":  GoogleStorageScope,
+	"bigquery.googleapis.com": "https://www.googleapis.com/auth/bigquery.readonly",
+}
+
+const googleCloudPlatformReadOnlyScope = "https://www.googleapis.com/auth/cloud-platform.read-only"
+
+// GoogleServiceAccountKey is the JSON key file of the google service account
+type GoogleServiceAccountKey struct {
+	Type         string `json:"type"`
+	ProjectID    string `json:"project_id"`
+	PrivateKeyID string `json:"private_key_id"`
+	PrivateKey   string `json:"private_key"`
+	ClientEmail  string `json:"client_email"`
+	TokenURI     string `json:"token_uri"`
+}
+
+// ParseGoogleServiceAccountKey parses the service account JSON key file downloaded from the google cloud console
+func ParseGoogleServiceAccountKey(input string) (key GoogleServiceAccountKey, err error) {
+	if strings.TrimSpace(input) == "" {
+		return key, ErrInvalidConfigGoogleKey
+	}
+	if err := json.Unmarshal([]byte(input), &key); err != nil {
+		return key, fmt.Errorf("%w. %s", ErrInvalidConfigGoogleKey, err.Error())
+	}
+	if key.Type != "service_account" {
+		return key, fmt.Errorf("%w. expected key of type service_account but found %q", ErrInvalidConfigGoogleKey, key.Type)
+	}
+	if key.ClientEmail == "" || key.PrivateKey == "" {
+		return key, fmt.Errorf("%w. client_email and private_key are required", ErrInvalidConfigGoogleKey)
+	}
+	key.PrivateKey = normalizePEMContent(key.PrivateKey)
+	if key.TokenURI == "" {
+		key.TokenURI = GoogleDefaultTokenURL
+	}
+	return key, nil
+}
+
+// GoogleScopes returns the read only scopes of the google APIs listed in the allowed hosts. Sheets scope is always included
+func (s *InfinitySettings) GoogleScopes() []string {
+	scopes := []string{googleScopes["sheets.googleapis.com"]}
+	for _, host := range s.AllowedHosts {
+		u, err := url.Parse(FixMissingURLSchema(strings.TrimSpace(host)))
+		if err != nil || u.Hostname() == "" {
+			continue
+		}
+		scope, ok := googleScopes[u.Hostname()]
+		if !ok && strings.HasSuffix(u.Hostname(), ".googleapis.com") {
+			scope = googleCloudPlatformReadOnlyScope
+		}
+		if scope != "" && !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes
+}
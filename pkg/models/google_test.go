@@ -0,0 +1,81 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseGoogleServiceAccountKey(t *testing.T) {
+	validKey := func(overrides map[string]string) string {
+		key := map[string]string{
+			"type":           "service_account",
+			"project_id":     "my-project",
+			"private_key_id": "key1",
+			"private_key":    testRSAPrivateKey,
+			"client_email":   "infinity@my-project.iam.gserviceaccount.com",
+		}
+		for k, v := range overrides {
+			key[k] = v
+		}
+		b, err := json.Marshal(key)
+		require.NoError(t, err)
+		return string(b)
+	}
+	t.Run("should parse the key and use the default token url", func(t *testing.T) {
+		key, err := ParseGoogleServiceAccountKey(validKey(nil))
+		require.NoError(t, err)
+		require.Equal(t, "infinity@my-project.iam.gserviceaccount.com", key.ClientEmail)
+		require.Equal(t, "key1", key.PrivateKeyID)
+		require.Equal(t, testRSAPrivateKey, key.PrivateKey)
+		require.Equal(t, GoogleDefaultTokenURL, key.TokenURI)
+	})
+	t.Run("should keep the token url of the key", func(t *testing.T) {
+		key, err := ParseGoogleServiceAccountKey(validKey(map[string]string{"token_uri": "https://foo.com/token"}))
+		require.NoError(t, err)
+		require.Equal(t, "https://foo.com/token", key.TokenURI)
+	})
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{name: "empty key", input: " "},
+		{name: "invalid json", input: "foo"},
+		{name: "non service account key", input: validKey(map[string]string{"type": "authorized_user"})},
+		{name: "key without private key", input: validKey(map[string]string{"private_key": ""})},
+		{name: "key without client email", input: validKey(map[string]string{"client_email": ""})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseGoogleServiceAccountKey(tt.input)
+			require.ErrorIs(t, err, ErrInvalidConfigGoogleKey)
+		})
+	}
+}
+
+func TestGoogleScopes(t *testing.T) {
+	tests := []struct {
+		name         string
+		allowedHosts []string
+		want         []string
+	}{
+		{name: "no allowed hosts", want: []string{"https://www.googleapis.com/auth/spreadsheets.readonly"}},
+		{name: "sheets host", allowedHosts: []string{"https://sheets.googleapis.com"}, want: []string{"https://www.googleapis.com/auth/spreadsheets.readonly"}},
+		{
+			name:         "multiple google APIs",
+			allowedHosts: []string{"https://sheets.googleapis.com", "drive.googleapis.com/drive/v3", "https://monitoring.googleapis.com", "https://logging.googleapis.com", "https://foo.com"},
+			want: []string{
+				"https://www.googleapis.com/auth/spreadsheets.readonly",
+				"https://www.googleapis.com/auth/drive.readonly",
+				"https://www.googleapis.com/auth/cloud-platform.read-only",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &InfinitySettings{AllowedHosts: tt.allowedHosts}
+			require.Equal(t, tt.want, s.GoogleScopes())
+		})
+	}
+}
@@ -1,6 +1,11 @@
 package models
 
-import "strings"
+import (
+	"crypto"
+	"crypto/x509"
+	"encoding/pem"
+	"strings"
+)
 
 // normalizePEMContent normalizes line endings in a PEM-encoded string (private
 // keys, certificates, CA bundles, etc.). It handles content stored with escaped
@@ -14,3 +19,23 @@ func normalizePEMContent(s string) string {
 	s = strings.ReplaceAll(s, "\r", "")     // actual standalone CR → strip
 	return s
 }
+
+// ParsePEMPrivateKey parses the PEM encoded PKCS #8, PKCS #1 (RSA) or SEC 1 (EC) private key
+func ParsePEMPrivateKey(s string) (crypto.Signer, error) {
+	block, _ := pem.Decode([]byte(normalizePEMContent(strings.TrimSpace(s))))
+	if block == nil {
+		return nil, ErrInvalidConfigOAuth2PrivateKey
+	}
+	if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		if signer, ok := key.(crypto.Signer); ok {
+			return signer, nil
+		}
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParseECPrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	return nil, ErrInvalidConfigOAuth2PrivateKey
+}
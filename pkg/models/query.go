@@ -23,6 +23,12 @@ const (
 	QueryTypeHTML            QueryType = "html"
 	QueryTypeUQL             QueryType = "uql"
 	QueryTypeGROQ            QueryType = "groq"
+	QueryTypeNDJSON          QueryType = "ndjson"
+	QueryTypeYAML            QueryType = "yaml"
+	QueryTypePrometheus      QueryType = "prometheus"
+	QueryTypeParquet         QueryType = "parquet"
+	QueryTypeArrow           QueryType = "arrow"
+	QueryTypeXLSX            QueryType = "xlsx"
 	QueryTypeGSheets         QueryType = "google-sheets"
 	QueryTypeTransformations QueryType = "transformations"
 )
@@ -45,6 +51,8 @@ const (
 	PaginationModePage   PaginationMode = "page"
 	PaginationModeCursor PaginationMode = "cursor"
 	PaginationModeList   PaginationMode = "list"
+	// PaginationModeNextLink follows the next page URL from the Link response header or from the response body
+	PaginationModeNextLink PaginationMode = "next_link"
 )
 
 type PaginationParamType string
@@ -53,10 +61,23 @@ const (
 	PaginationParamTypeQuery    PaginationParamType = "query"
 	PaginationParamTypeHeader   PaginationParamType = "header"
 	PaginationParamTypeBodyData PaginationParamType = "body_data"
-	PaginationParamTypeBodyJson PaginationParamType = "body_json" // TODO: NOT IMPLEMENTED YET
+	PaginationParamTypeBodyJson PaginationParamType = "body_json"
 	PaginationParamTypeReplace  PaginationParamType = "replace"
 )
 
+// StreamMode defines how the query results are streamed to the panels through Grafana Live
+type StreamMode string
+
+const (
+	StreamModeNone StreamMode = "none"
+	// StreamModePolling runs the query on an interval
+	StreamModePolling StreamMode = "polling"
+	// StreamModeSSE subscribes to a Server-Sent Events endpoint and frames the data of each event
+	StreamModeSSE StreamMode = "sse"
+	// StreamModeNDJSON reads a chunked newline delimited JSON response and frames each line
+	StreamModeNDJSON StreamMode = "ndjson"
+)
+
 type Transformation string
 
 const (
@@ -89,9 +110,9 @@ type TransformationItem struct {
 
 type Query struct {
 	RefID                              string                 `json:"refId"`
-	Type                               QueryType              `json:"type"`   // 'json' | 'json-backend' | 'csv' | 'tsv' | 'xml' | 'graphql' | 'html' | 'uql' | 'groq' | 'series' | 'global' | 'google-sheets'
+	Type                               QueryType              `json:"type"`   // 'json' | 'json-backend' | 'csv' | 'tsv' | 'xml' | 'graphql' | 'html' | 'uql' | 'groq' | 'ndjson' | 'yaml' | 'prometheus' | 'parquet' | 'arrow' | 'xlsx' | 'series' | 'global' | 'google-sheets'
 	Format                             string                 `json:"format"` // 'table' | 'timeseries' | 'logs' | 'dataframe' | 'as-is' | 'node-graph-nodes' | 'node-graph-edges'
-	Source                             string                 `json:"source"` // 'url' | 'inline' | 'azure-blob' | 'reference' | 'random-walk' | 'expression'
+	Source                             string                 `json:"source"` // 'url' | 'inline' | 'azure-blob' | 's3' | 'gcs' | 'reference' | 'random-walk' | 'expression'
 	RefName                            string                 `json:"referenceName,omitempty"`
 	URL                                string                 `json:"url"`
 	URLOptions                         URLOptions             `json:"url_options"`
@@ -121,6 +142,10 @@ type Query struct {
 	SheetRange                         string                 `json:"sheetRange,omitempty"`
 	AzBlobContainerName                string                 `json:"azContainerName,omitempty"`
 	AzBlobName                         string                 `json:"azBlobName,omitempty"`
+	S3Bucket                           string                 `json:"s3Bucket,omitempty"`
+	S3Key                              string                 `json:"s3Key,omitempty"`
+	GCSBucket                          string                 `json:"gcsBucket,omitempty"`
+	GCSObject                          string                 `json:"gcsObject,omitempty"`
 	PageMode                           PaginationMode         `json:"pagination_mode,omitempty"`
 	PageMaxPages                       int                    `json:"pagination_max_pages,omitempty"`
 	PageParamSizeFieldName             string                 `json:"pagination_param_size_field_name,omitempty"`
@@ -138,7 +163,14 @@ type Query struct {
 	PageParamListFieldName             string                 `json:"pagination_param_list_field_name,omitempty"`
 	PageParamListFieldType             PaginationParamType    `json:"pagination_param_list_field_type,omitempty"`
 	PageParamListFieldValue            string                 `json:"pagination_param_list_value,omitempty"`
+	PageParamNextLinkExtractionPath    string                 `json:"pagination_param_next_link_extraction_path,omitempty"`
+	PageTotalCountExtractionPath       string                 `json:"pagination_total_count_extraction_path,omitempty"`
+	PageTotalCountHeader               string                 `json:"pagination_total_count_header,omitempty"`
 	Transformations                    []TransformationItem   `json:"transformations,omitempty"`
+	StreamMode                         StreamMode             `json:"stream_mode,omitempty"`
+	StreamIntervalInMs                 int64                  `json:"stream_interval_ms,omitempty"`
+	MaxRows                            int64                  `json:"max_rows,omitempty"` // limits the number of rows read from parquet and arrow files. zero means no limit
+	URLIsAbsolute                      bool                   `json:"-"`                  // set for the next links followed by the pagination. such urls are used as is without the base url of the settings
 }
 
 type URLOptionKeyValuePair struct {
@@ -204,12 +236,20 @@ func ApplyDefaultsToQuery(ctx context.Context, pCtx *backend.PluginContext, quer
 	if query.Parser == "" && strings.TrimSpace(query.RootSelector) == "" && len(query.Columns) == 0 && query.Type != QueryTypeUQL && query.Type != QueryTypeGROQ && query.Type != QueryTypeGSheets {
 		query.Parser = InfinityParserBackend
 	}
+	// ndjson and yaml are only parsed in the backend
+	if (query.Type == QueryTypeNDJSON || query.Type == QueryTypeYAML) && query.Parser != InfinityParserJQBackend {
+		query.Parser = InfinityParserBackend
+	}
+	// prometheus metrics, parquet, arrow and xlsx files are parsed without selectors
+	if query.Type == QueryTypePrometheus || query.Type == QueryTypeParquet || query.Type == QueryTypeArrow || query.Type == QueryTypeXLSX {
+		query.Parser = InfinityParserBackend
+	}
 	if query.Type == QueryTypeJSON && query.Source == "inline" && query.Data == "" {
 		query.Data = "[]"
 	}
 	if query.Source == "url" && query.URL == "" && strings.TrimSpace(settings.URL) == "" {
 		switch query.Type {
-		case QueryTypeJSON, QueryTypeCSV, QueryTypeTSV, QueryTypeXML, QueryTypeHTML:
+		case QueryTypeJSON, QueryTypeCSV, QueryTypeTSV, QueryTypeXML, QueryTypeHTML, QueryTypeYAML:
 			query.URL = fmt.Sprintf("https://raw.githubusercontent.com/grafana/grafana-infinity-datasource/main/testdata/users.%s", strings.ToLower(string(query.Type)))
 		case QueryTypeGraphQL, QueryTypeUQL, QueryTypeGROQ:
 			query.URL = "https://raw.githubusercontent.com/grafana/grafana-infinity-datasource/main/testdata/users.json"
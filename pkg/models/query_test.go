@@ -118,6 +118,19 @@ func TestLoadQuery(t *testing.T) {
 				DataOverrides: []models.InfinityDataOverride{{Override: "ov1", Operator: "op1", Values: []string{"ov1"}}},
 			},
 		},
+		{
+			name:      "should always use the backend parser for ndjson queries",
+			queryJSON: `{ "type": "ndjson", "source": "inline", "parser": "simple", "root_selector": "foo", "data": "{}" }`,
+			want: models.Query{
+				Type:            models.QueryTypeNDJSON,
+				Source:          "inline",
+				Parser:          models.InfinityParserBackend,
+				RootSelector:    "foo",
+				Data:            "{}",
+				Columns:         []models.InfinityColumn{},
+				ComputedColumns: []models.InfinityColumn{},
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -24,46 +24,138 @@ const (
 	AuthenticationMethodOAuth        = "oauth2"
 	AuthenticationMethodAWS          = "aws"
 	AuthenticationMethodAzureBlob    = "azureBlob"
+	AuthenticationMethodGoogle       = "google"
+	AuthenticationMethodAzureAD      = "azureAD"
 )
 
 const (
 	AuthOAuthTypeClientCredentials = "client_credentials"
 	AuthOAuthJWT                   = "jwt"
+	AuthOAuthAuthorizationCode     = "authorization_code"
 	AuthOAuthOthers                = "others"
 )
 
+// OAuth2 client authentication methods at the token endpoint for the client credentials grant type
+const (
+	OAuth2ClientAuthSecret = "client_secret"
+	// OAuth2ClientAuthPrivateKeyJWT authenticates using a JWT signed by the private key of the client (RFC 7523)
+	OAuth2ClientAuthPrivateKeyJWT = "private_key_jwt"
+	// OAuth2ClientAuthTLS authenticates using the TLS client certificate (RFC 8705)
+	OAuth2ClientAuthTLS = "tls_client_auth"
+)
+
 const (
 	ApiKeyTypeHeader = "header"
 	ApiKeyTypeQuery  = "query"
 )
 
 type OAuth2Settings struct {
-	OAuth2Type     string           `json:"oauth2_type,omitempty"`
-	ClientID       string           `json:"client_id,omitempty"`
-	TokenURL       string           `json:"token_url,omitempty"`
-	Email          string           `json:"email,omitempty"`
-	PrivateKeyID   string           `json:"private_key_id,omitempty"`
-	Subject        string           `json:"subject,omitempty"`
-	Scopes         []string         `json:"scopes,omitempty"`
-	AuthStyle      oauth2.AuthStyle `json:"authStyle,omitempty"`
-	AuthHeader     string           `json:"authHeader,omitempty"`
-	TokenTemplate  string           `json:"tokenTemplate,omitempty"`
-	TokenHeaders   map[string]string `json:"tokenHeaders,omitempty"`
-	ClientSecret   string
-	PrivateKey     string
-	EndpointParams map[string]string
+	OAuth2Type       string            `json:"oauth2_type,omitempty"`
+	ClientID         string            `json:"client_id,omitempty"`
+	TokenURL         string            `json:"token_url,omitempty"`
+	AuthURL          string            `json:"auth_url,omitempty"`
+	Email            string            `json:"email,omitempty"`
+	PrivateKeyID     string            `json:"private_key_id,omitempty"`
+	Subject          string            `json:"subject,omitempty"`
+	Scopes           []string          `json:"scopes,omitempty"`
+	AuthStyle        oauth2.AuthStyle  `json:"authStyle,omitempty"`
+	AuthHeader       string            `json:"authHeader,omitempty"`
+	TokenTemplate    string            `json:"tokenTemplate,omitempty"`
+	TokenHeaders     map[string]string `json:"tokenHeaders,omitempty"`
+	ClientAuthMethod string            `json:"client_auth_method,omitempty"`
+	// ClientAssertionAudience is the audience of the private_key_jwt client assertion. Defaults to the token URL
+	ClientAssertionAudience string `json:"client_assertion_audience,omitempty"`
+	ClientSecret            string
+	PrivateKey              string
+	RefreshToken            string
+	EndpointParams          map[string]string
+}
+
+type TokenExchangeType string
+
+const (
+	// TokenExchangeTypeRFC8693 exchanges the forwarded token using the OAuth 2.0 token exchange grant (RFC 8693)
+	TokenExchangeTypeRFC8693 TokenExchangeType = "rfc8693"
+	// TokenExchangeTypeOnBehalfOf exchanges the forwarded token using the Microsoft Entra ID on-behalf-of flow
+	TokenExchangeTypeOnBehalfOf TokenExchangeType = "on_behalf_of"
+)
+
+const (
+	TokenTypeAccessToken = "urn:ietf:params:oauth:token-type:access_token"
+	TokenTypeIDToken     = "urn:ietf:params:oauth:token-type:id_token"
+)
+
+// TokenExchangeSettings exchanges the forwarded OAuth identity of the user for a token of the downstream API.
+// Applicable only when forwarding the OAuth identity
+type TokenExchangeSettings struct {
+	Type     TokenExchangeType `json:"type,omitempty"`
+	TokenURL string            `json:"tokenUrl,omitempty"`
+	ClientID string            `json:"clientId,omitempty"`
+	// AuthStyle of the client authentication at the token endpoint
+	AuthStyle oauth2.AuthStyle `json:"authStyle,omitempty"`
+	Scopes    []string         `json:"scopes,omitempty"`
+	// Audience and Resource identify the downstream API (RFC 8693 only)
+	Audience string `json:"audience,omitempty"`
+	Resource string `json:"resource,omitempty"`
+	// SubjectTokenType is the type of the forwarded token exchanged (RFC 8693 only). Defaults to the access token
+	SubjectTokenType string `json:"subjectTokenType,omitempty"`
+	ClientSecret     string `json:"-"`
 }
 
 type AWSAuthType string
 
 const (
 	AWSAuthTypeKeys AWSAuthType = "keys"
+	// AWSAuthTypeDefault uses the default credential chain of the AWS SDK. i.e. environment variables, web identity
+	// token (IRSA / EKS pod identity), shared credentials file, ECS task role and EC2 instance role
+	AWSAuthTypeDefault AWSAuthType = "default"
+	// AWSAuthTypeSharedCreds uses the profile of the shared credentials file of the grafana host
+	AWSAuthTypeSharedCreds AWSAuthType = "credentials"
+	// AWSAuthTypeEC2IAMRole uses the IAM role of the EC2 instance
+	AWSAuthTypeEC2IAMRole AWSAuthType = "ec2_iam_role"
 )
 
 type AWSSettings struct {
 	AuthType AWSAuthType `json:"authType"`
 	Region   string      `json:"region"`
 	Service  string      `json:"service"`
+	// Profile of the shared credentials file. Used by the credentials auth type. Empty for the default profile
+	Profile string `json:"profile,omitempty"`
+	// AssumeRoleARN is the IAM role assumed using STS after resolving the credentials of the auth type
+	AssumeRoleARN string `json:"assumeRoleArn,omitempty"`
+	// ExternalID is passed to STS when assuming the role
+	ExternalID string `json:"externalId,omitempty"`
+	// Endpoint of the s3 compatible object storages such as MinIO. Empty for Amazon S3
+	Endpoint string `json:"endpoint,omitempty"`
+	// UsePathStyle addresses the bucket in the path instead of the host name. Required by most of the s3 compatible storages
+	UsePathStyle bool `json:"usePathStyle,omitempty"`
+	// HealthCheckBucket is the bucket verified by the health check when using the s3 source
+	HealthCheckBucket string `json:"healthCheckBucket,omitempty"`
+}
+
+type GoogleAuthType string
+
+const (
+	GoogleAuthTypeServiceAccount GoogleAuthType = "serviceAccount"
+	GoogleAuthTypeAPIKey         GoogleAuthType = "apiKey"
+)
+
+type GoogleSettings struct {
+	AuthType GoogleAuthType `json:"authType,omitempty"`
+	// HealthCheckSpreadsheetID is the spreadsheet used by the health check to verify the access
+	HealthCheckSpreadsheetID string `json:"healthCheckSpreadsheetId,omitempty"`
+	// HealthCheckBucket is the google cloud storage bucket used by the health check to verify the access
+	HealthCheckBucket string `json:"healthCheckBucket,omitempty"`
+}
+
+// AzureADSettings are the Microsoft Entra ID settings used to authenticate the requests to the APIs protected by Entra ID
+type AzureADSettings struct {
+	AuthType  AzureAuthType `json:"authType,omitempty"`
+	CloudType string        `json:"cloudType,omitempty"`
+	TenantID  string        `json:"tenantId,omitempty"`
+	ClientID  string        `json:"clientId,omitempty"`
+	// Scopes overrides the scope of the token requested for the host. Keyed by the allowed host
+	Scopes map[string]string `json:"scopes,omitempty"`
 }
 
 type ProxyType string
@@ -95,11 +187,18 @@ type InfinitySettings struct {
 	AWSSettings                 AWSSettings
 	AWSAccessKey                string
 	AWSSecretKey                string
+	GoogleSettings              GoogleSettings
+	GoogleServiceAccountKey     string
+	GoogleAPIKey                string
+	AzureADSettings             AzureADSettings
+	AzureADClientSecret         string
+	AzureADClientCertificate    string
 	URL                         string
 	BasicAuthEnabled            bool
 	UserName                    string
 	Password                    string
 	ForwardOauthIdentity        bool
+	TokenExchangeSettings       TokenExchangeSettings
 	CustomHeaders               map[string]string
 	SecureQueryFields           map[string]string
 	InsecureSkipVerify          bool
@@ -123,10 +222,24 @@ type InfinitySettings struct {
 	AzureBlobAccountUrl         string
 	AzureBlobAccountName        string
 	AzureBlobAccountKey         string
+	AzureBlobAuthType           AzureBlobAuthType
+	AzureBlobTenantID           string
+	AzureBlobClientID           string
+	AzureBlobClientSecret       string
+	AzureBlobSASToken           string
 	UnsecuredQueryHandling      UnsecuredQueryHandlingMode
 	PathEncodedURLsEnabled      bool
 	IgnoreStatusCodeCheck       bool
 	AllowDangerousHTTPMethods   bool
+	ResponseCacheTTLInSeconds   int64
+	ResponseCacheMaxEntries     int
+	RetryMaxAttempts            int
+	RetryInitialBackoffInMs     int64
+	RetryMaxBackoffInMs         int64
+	RetryStatusCodes            []int
+	RetryNonIdempotentMethods   bool
+	RateLimitRequestsPerSecond  float64
+	RateLimitBurst              int
 	// ProxyOpts is used for Secure Socks Proxy configuration
 	ProxyOpts httpclient.Options
 	// Specific cookies included by Grafana for forwarding
@@ -143,23 +256,55 @@ func (s *InfinitySettings) Validate() error {
 	if s.AuthenticationMethod == AuthenticationMethodBearerToken && s.BearerToken == "" {
 		return ErrInvalidConfigBearerToken
 	}
-	if s.AuthenticationMethod == AuthenticationMethodAzureBlob {
-		if strings.TrimSpace(s.AzureBlobAccountName) == "" {
-			return ErrInvalidConfigAzBlobAccName
+	if s.AuthenticationMethod == AuthenticationMethodOAuth && s.OAuth2Settings.OAuth2Type == AuthOAuthAuthorizationCode {
+		if strings.TrimSpace(s.OAuth2Settings.AuthURL) == "" {
+			return ErrInvalidConfigOAuth2AuthURL
+		}
+		if strings.TrimSpace(s.OAuth2Settings.TokenURL) == "" {
+			return ErrInvalidConfigOAuth2TokenURL
+		}
+	}
+	if s.ForwardOauthIdentity && s.TokenExchangeSettings.Type != "" {
+		if err := s.validateTokenExchange(); err != nil {
+			return err
+		}
+	}
+	if s.AuthenticationMethod == AuthenticationMethodOAuth && (s.OAuth2Settings.OAuth2Type == AuthOAuthTypeClientCredentials || s.OAuth2Settings.OAuth2Type == "") {
+		switch s.OAuth2Settings.ClientAuthMethod {
+		case OAuth2ClientAuthSecret, "":
+		case OAuth2ClientAuthPrivateKeyJWT:
+			if _, err := ParsePEMPrivateKey(s.OAuth2Settings.PrivateKey); err != nil {
+				return err
+			}
+		case OAuth2ClientAuthTLS:
+			if !s.TLSClientAuth || s.TLSClientCert == "" || s.TLSClientKey == "" {
+				return ErrInvalidConfigOAuth2ClientCert
+			}
+		default:
+			return ErrInvalidConfigOAuth2ClientAuth
 		}
-		if strings.TrimSpace(s.AzureBlobAccountKey) == "" {
-			return ErrInvalidConfigAzBlobKey
+	}
+	if s.AuthenticationMethod == AuthenticationMethodAzureBlob {
+		return s.validateAzureBlob()
+	}
+	if s.AuthenticationMethod == AuthenticationMethodAWS {
+		if err := s.validateAWS(); err != nil {
+			return err
 		}
-		return nil
 	}
-	if s.AuthenticationMethod == AuthenticationMethodAWS && s.AWSSettings.AuthType == AWSAuthTypeKeys {
-		if strings.TrimSpace(s.AWSAccessKey) == "" {
-			return ErrInvalidConfigAWSAccessKey
+	if s.AuthenticationMethod == AuthenticationMethodAzureAD {
+		if err := s.validateAzureAD(); err != nil {
+			return err
 		}
-		if strings.TrimSpace(s.AWSSecretKey) == "" {
-			return ErrInvalidConfigAWSSecretKey
+	}
+	if s.AuthenticationMethod == AuthenticationMethodGoogle {
+		if s.GoogleSettings.AuthType == GoogleAuthTypeAPIKey {
+			if strings.TrimSpace(s.GoogleAPIKey) == "" {
+				return ErrInvalidConfigGoogleAPIKey
+			}
+		} else if _, err := ParseGoogleServiceAccountKey(s.GoogleServiceAccountKey); err != nil {
+			return err
 		}
-		return nil
 	}
 	if s.DoesAllowedHostsRequired() && len(s.AllowedHosts) < 1 {
 		return ErrInvalidConfigHostNotAllowed
@@ -167,6 +312,16 @@ func (s *InfinitySettings) Validate() error {
 	return ValidateAllowedHosts(s.AllowedHosts)
 }
 
+// Warnings returns the configuration issues which don't fail the queries but should be fixed by the user.
+// These are reported by the health check
+func (s *InfinitySettings) Warnings() []string {
+	warnings := []string{}
+	if s.AuthenticationMethod == AuthenticationMethodAWS {
+		warnings = append(warnings, s.awsWarnings()...)
+	}
+	return warnings
+}
+
 func (s *InfinitySettings) DoesAllowedHostsRequired() bool {
 	// If base url is configured, there is no need for allowed hosts
 	if strings.TrimSpace(s.URL) != "" {
@@ -260,31 +415,46 @@ type RefData struct {
 // The drift guards in pkg/pluginschema/schema_test.go fail if these get out of
 // sync (key set, JSON type, and secret list are all checked).
 type InfinitySettingsJson struct {
-	IsMock                      bool           `json:"is_mock,omitempty"`
-	AuthenticationMethod        string         `json:"auth_method,omitempty"`
-	APIKeyKey                   string         `json:"apiKeyKey,omitempty"`
-	APIKeyType                  string         `json:"apiKeyType,omitempty"`
-	OAuth2Settings              OAuth2Settings `json:"oauth2,omitempty"`
-	AWSSettings                 AWSSettings    `json:"aws,omitempty"`
-	ForwardOauthIdentity        bool           `json:"oauthPassThru,omitempty"`
-	InsecureSkipVerify          bool           `json:"tlsSkipVerify,omitempty"`
-	ServerName                  string         `json:"serverName,omitempty"`
-	TLSClientAuth               bool           `json:"tlsAuth,omitempty"`
-	TLSAuthWithCACert           bool           `json:"tlsAuthWithCACert,omitempty"`
-	TimeoutInSeconds            int64          `json:"timeoutInSeconds,omitempty"`
-	ProxyType                   ProxyType      `json:"proxy_type,omitempty"`
-	ProxyUrl                    string         `json:"proxy_url,omitempty"`
-	ProxyUserName               string         `json:"proxy_username,omitempty"`
-	ReferenceData               []RefData      `json:"refData,omitempty"`
-	CustomHealthCheckEnabled    bool           `json:"customHealthCheckEnabled,omitempty"`
-	CustomHealthCheckUrl        string         `json:"customHealthCheckUrl,omitempty"`
-	CustomHealthCheckUrlOptions URLOptions     `json:"customHealthCheckUrlOptions,omitempty"`
-	AzureBlobCloudType          string         `json:"azureBlobCloudType,omitempty"`
-	AzureBlobAccountUrl         string         `json:"azureBlobAccountUrl,omitempty"`
-	AzureBlobAccountName        string         `json:"azureBlobAccountName,omitempty"`
-	PathEncodedURLsEnabled      bool           `json:"pathEncodedUrlsEnabled,omitempty"`
-	IgnoreStatusCodeCheck       bool           `json:"ignoreStatusCodeCheck,omitempty"`
-	AllowDangerousHTTPMethods   bool           `json:"allowDangerousHTTPMethods,omitempty"`
+	IsMock                      bool                  `json:"is_mock,omitempty"`
+	AuthenticationMethod        string                `json:"auth_method,omitempty"`
+	APIKeyKey                   string                `json:"apiKeyKey,omitempty"`
+	APIKeyType                  string                `json:"apiKeyType,omitempty"`
+	OAuth2Settings              OAuth2Settings        `json:"oauth2,omitempty"`
+	AWSSettings                 AWSSettings           `json:"aws,omitempty"`
+	GoogleSettings              GoogleSettings        `json:"google,omitempty"`
+	AzureADSettings             AzureADSettings       `json:"azureAD,omitempty"`
+	ForwardOauthIdentity        bool                  `json:"oauthPassThru,omitempty"`
+	TokenExchangeSettings       TokenExchangeSettings `json:"tokenExchange,omitempty"`
+	InsecureSkipVerify          bool                  `json:"tlsSkipVerify,omitempty"`
+	ServerName                  string                `json:"serverName,omitempty"`
+	TLSClientAuth               bool                  `json:"tlsAuth,omitempty"`
+	TLSAuthWithCACert           bool                  `json:"tlsAuthWithCACert,omitempty"`
+	TimeoutInSeconds            int64                 `json:"timeoutInSeconds,omitempty"`
+	ProxyType                   ProxyType             `json:"proxy_type,omitempty"`
+	ProxyUrl                    string                `json:"proxy_url,omitempty"`
+	ProxyUserName               string                `json:"proxy_username,omitempty"`
+	ReferenceData               []RefData             `json:"refData,omitempty"`
+	CustomHealthCheckEnabled    bool                  `json:"customHealthCheckEnabled,omitempty"`
+	CustomHealthCheckUrl        string                `json:"customHealthCheckUrl,omitempty"`
+	CustomHealthCheckUrlOptions URLOptions            `json:"customHealthCheckUrlOptions,omitempty"`
+	AzureBlobCloudType          string                `json:"azureBlobCloudType,omitempty"`
+	AzureBlobAccountUrl         string                `json:"azureBlobAccountUrl,omitempty"`
+	AzureBlobAccountName        string                `json:"azureBlobAccountName,omitempty"`
+	AzureBlobAuthType           string                `json:"azureBlobAuthType,omitempty"`
+	AzureBlobTenantID           string                `json:"azureBlobTenantId,omitempty"`
+	AzureBlobClientID           string                `json:"azureBlobClientId,omitempty"`
+	PathEncodedURLsEnabled      bool                  `json:"pathEncodedUrlsEnabled,omitempty"`
+	IgnoreStatusCodeCheck       bool                  `json:"ignoreStatusCodeCheck,omitempty"`
+	AllowDangerousHTTPMethods   bool                  `json:"allowDangerousHTTPMethods,omitempty"`
+	ResponseCacheTTLInSeconds   int64                 `json:"responseCacheTTLInSeconds,omitempty"`
+	ResponseCacheMaxEntries     int                   `json:"responseCacheMaxEntries,omitempty"`
+	RetryMaxAttempts            int                   `json:"retryMaxAttempts,omitempty"`
+	RetryInitialBackoffInMs     int64                 `json:"retryInitialBackoffInMs,omitempty"`
+	RetryMaxBackoffInMs         int64                 `json:"retryMaxBackoffInMs,omitempty"`
+	RetryStatusCodes            []int                 `json:"retryStatusCodes,omitempty"`
+	RetryNonIdempotentMethods   bool                  `json:"retryNonIdempotentMethods,omitempty"`
+	RateLimitRequestsPerSecond  float64               `json:"rateLimitRequestsPerSecond,omitempty"`
+	RateLimitBurst              int                   `json:"rateLimitBurst,omitempty"`
 	// Security
 	AllowedHosts           []string                   `json:"allowedHosts,omitempty"`
 	UnsecuredQueryHandling UnsecuredQueryHandlingMode `json:"unsecuredQueryHandling,omitempty"`
@@ -314,6 +484,8 @@ func LoadSettings(ctx context.Context, config backend.DataSourceInstanceSettings
 		settings.ApiKeyKey = infJson.APIKeyKey
 		settings.ApiKeyType = infJson.APIKeyType
 		settings.AWSSettings = infJson.AWSSettings
+		settings.GoogleSettings = infJson.GoogleSettings
+		settings.AzureADSettings = infJson.AzureADSettings
 		if settings.ApiKeyType == "" {
 			settings.ApiKeyType = "header"
 		}
@@ -321,6 +493,7 @@ func LoadSettings(ctx context.Context, config backend.DataSourceInstanceSettings
 			settings.ApiKeyValue = val
 		}
 		settings.ForwardOauthIdentity = infJson.ForwardOauthIdentity
+		settings.TokenExchangeSettings = infJson.TokenExchangeSettings
 		settings.InsecureSkipVerify = infJson.InsecureSkipVerify
 		settings.ServerName = infJson.ServerName
 		settings.TLSClientAuth = infJson.TLSClientAuth
@@ -332,6 +505,15 @@ func LoadSettings(ctx context.Context, config backend.DataSourceInstanceSettings
 		settings.PathEncodedURLsEnabled = infJson.PathEncodedURLsEnabled
 		settings.IgnoreStatusCodeCheck = infJson.IgnoreStatusCodeCheck
 		settings.AllowDangerousHTTPMethods = infJson.AllowDangerousHTTPMethods
+		settings.ResponseCacheTTLInSeconds = infJson.ResponseCacheTTLInSeconds
+		settings.ResponseCacheMaxEntries = infJson.ResponseCacheMaxEntries
+		settings.RetryMaxAttempts = infJson.RetryMaxAttempts
+		settings.RetryInitialBackoffInMs = infJson.RetryInitialBackoffInMs
+		settings.RetryMaxBackoffInMs = infJson.RetryMaxBackoffInMs
+		settings.RetryStatusCodes = infJson.RetryStatusCodes
+		settings.RetryNonIdempotentMethods = infJson.RetryNonIdempotentMethods
+		settings.RateLimitRequestsPerSecond = infJson.RateLimitRequestsPerSecond
+		settings.RateLimitBurst = infJson.RateLimitBurst
 		if settings.ProxyType == "" {
 			settings.ProxyType = ProxyTypeEnv
 		}
@@ -356,6 +538,9 @@ func LoadSettings(ctx context.Context, config backend.DataSourceInstanceSettings
 	settings.AzureBlobCloudType = infJson.AzureBlobCloudType
 	settings.AzureBlobAccountUrl = infJson.AzureBlobAccountUrl
 	settings.AzureBlobAccountName = infJson.AzureBlobAccountName
+	settings.AzureBlobAuthType = AzureBlobAuthType(infJson.AzureBlobAuthType)
+	settings.AzureBlobTenantID = infJson.AzureBlobTenantID
+	settings.AzureBlobClientID = infJson.AzureBlobClientID
 	if val, ok := config.DecryptedSecureJSONData["basicAuthPassword"]; ok {
 		settings.Password = val
 	}
@@ -365,6 +550,12 @@ func LoadSettings(ctx context.Context, config backend.DataSourceInstanceSettings
 	if val, ok := config.DecryptedSecureJSONData["oauth2JWTPrivateKey"]; ok {
 		settings.OAuth2Settings.PrivateKey = normalizePEMContent(val)
 	}
+	if val, ok := config.DecryptedSecureJSONData["oauth2RefreshToken"]; ok {
+		settings.OAuth2Settings.RefreshToken = val
+	}
+	if val, ok := config.DecryptedSecureJSONData["tokenExchangeClientSecret"]; ok {
+		settings.TokenExchangeSettings.ClientSecret = val
+	}
 	if val, ok := config.DecryptedSecureJSONData["tlsCACert"]; ok {
 		settings.TLSCACert = normalizePEMContent(val)
 	}
@@ -383,9 +574,27 @@ func LoadSettings(ctx context.Context, config backend.DataSourceInstanceSettings
 	if val, ok := config.DecryptedSecureJSONData["awsSecretKey"]; ok {
 		settings.AWSSecretKey = val
 	}
+	if val, ok := config.DecryptedSecureJSONData["googleServiceAccountKey"]; ok {
+		settings.GoogleServiceAccountKey = val
+	}
+	if val, ok := config.DecryptedSecureJSONData["googleApiKey"]; ok {
+		settings.GoogleAPIKey = val
+	}
 	if val, ok := config.DecryptedSecureJSONData["azureBlobAccountKey"]; ok {
 		settings.AzureBlobAccountKey = val
 	}
+	if val, ok := config.DecryptedSecureJSONData["azureBlobClientSecret"]; ok {
+		settings.AzureBlobClientSecret = val
+	}
+	if val, ok := config.DecryptedSecureJSONData["azureBlobSASToken"]; ok {
+		settings.AzureBlobSASToken = val
+	}
+	if val, ok := config.DecryptedSecureJSONData["azureADClientSecret"]; ok {
+		settings.AzureADClientSecret = val
+	}
+	if val, ok := config.DecryptedSecureJSONData["azureADClientCertificate"]; ok {
+		settings.AzureADClientCertificate = normalizePEMContent(val)
+	}
 	if val, ok := config.DecryptedSecureJSONData["proxyUserPassword"]; ok {
 		settings.ProxyUserPassword = val
 	}
@@ -402,15 +611,35 @@ func LoadSettings(ctx context.Context, config backend.DataSourceInstanceSettings
 			settings.AuthenticationMethod = AuthenticationMethodForwardOauth
 		}
 	}
+	if settings.AuthenticationMethod == AuthenticationMethodGoogle {
+		if settings.GoogleSettings.AuthType == "" {
+			settings.GoogleSettings.AuthType = GoogleAuthTypeServiceAccount
+		}
+		// google sheets is the primary use of the google authentication. so allow it when no hosts are configured
+		if len(settings.AllowedHosts) == 0 && strings.TrimSpace(settings.URL) == "" {
+			settings.AllowedHosts = []string{GoogleSheetsHost}
+		}
+	}
+	if settings.AuthenticationMethod == AuthenticationMethodAzureAD {
+		if settings.AzureADSettings.AuthType == "" {
+			settings.AzureADSettings.AuthType = AzureAuthTypeClientSecret
+		}
+		if settings.AzureADSettings.CloudType == "" {
+			settings.AzureADSettings.CloudType = AzureCloudPublic
+		}
+	}
 	if settings.AuthenticationMethod == AuthenticationMethodAzureBlob {
+		if settings.AzureBlobAuthType == "" {
+			settings.AzureBlobAuthType = AzureBlobAuthTypeSharedKey
+		}
 		if settings.AzureBlobCloudType == "" {
-			settings.AzureBlobCloudType = "AzureCloud"
+			settings.AzureBlobCloudType = AzureCloudPublic
 		}
 		if settings.AzureBlobAccountUrl == "" {
 			switch settings.AzureBlobCloudType {
-			case "AzureUSGovernment":
+			case AzureCloudUSGovernment:
 				settings.AzureBlobAccountUrl = "https://%s.blob.core.usgovcloudapi.net/"
-			case "AzureChinaCloud":
+			case AzureCloudChina:
 				settings.AzureBlobAccountUrl = "https://%s.blob.core.chinacloudapi.cn/"
 			default:
 				settings.AzureBlobAccountUrl = "https://%s.blob.core.windows.net/"
@@ -11,6 +11,7 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/backend/httpclient"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
 )
 
 func TestLoadSettings(t *testing.T) {
@@ -163,11 +164,51 @@ func TestAllSettingsAgainstFrontEnd(t *testing.T) {
 			"customHealthCheckEnabled" : true,
 			"customHealthCheckUrl" : "https://foo-check/",
 			"allowDangerousHTTPMethods": true,
+			"responseCacheTTLInSeconds": 60,
+			"responseCacheMaxEntries": 50,
+			"retryMaxAttempts": 3,
+			"retryInitialBackoffInMs": 200,
+			"retryMaxBackoffInMs": 5000,
+			"retryStatusCodes": [429, 503],
+			"retryNonIdempotentMethods": true,
+			"rateLimitRequestsPerSecond": 0.5,
+			"rateLimitBurst": 5,
 			"unsecuredQueryHandling" : "deny",
+			"azureBlobAuthType" : "clientSecret",
+			"azureBlobTenantId" : "tenant1",
+			"azureBlobClientId" : "client1",
 			"aws" : {
 				"authType" 	: "keys",
 				"region" 	: "region1",
-				"service" 	: "service1"
+				"service" 	: "service1",
+				"endpoint" 	: "https://minio.foo.com",
+				"usePathStyle" : true,
+				"healthCheckBucket" : "bucket1",
+				"profile" : "profile1",
+				"assumeRoleArn" : "arn:aws:iam::123456789012:role/grafana",
+				"externalId" : "external1"
+			},
+			"tokenExchange" : {
+				"type" : "rfc8693",
+				"tokenUrl" : "https://foo.com/token",
+				"clientId" : "client1",
+				"authStyle" : 1,
+				"scopes" : ["scope1"],
+				"audience" : "audience1",
+				"resource" : "https://api.foo.com",
+				"subjectTokenType" : "urn:ietf:params:oauth:token-type:id_token"
+			},
+			"azureAD" : {
+				"authType" : "clientCertificate",
+				"cloudType" : "AzureUSGovernment",
+				"tenantId" : "tenant1",
+				"clientId" : "client1",
+				"scopes" : { "https://api.foo.com" : "api://foo/.default" }
+			},
+			"google" : {
+				"authType" : "apiKey",
+				"healthCheckSpreadsheetId" : "spreadsheet1",
+				"healthCheckBucket" : "bucket1"
 			},
 			"oauth2" : {
 				"client_id":"myClientID",
@@ -175,6 +216,9 @@ func TestAllSettingsAgainstFrontEnd(t *testing.T) {
 				"private_key_id":"saturn",
 				"subject":"mySubject",
 				"token_url":"TOKEN_URL",
+				"auth_url":"AUTH_URL",
+				"client_auth_method":"private_key_jwt",
+				"client_assertion_audience":"AUDIENCE",
 				"scopes":["scope1","scope2"]
 			}
 		}`),
@@ -189,8 +233,16 @@ func TestAllSettingsAgainstFrontEnd(t *testing.T) {
 			"bearerToken":                "myBearerToken",
 			"awsAccessKey":               "awsAccessKey1",
 			"awsSecretKey":               "awsSecretKey1",
+			"googleServiceAccountKey":    "myGoogleServiceAccountKey",
+			"googleApiKey":               "myGoogleApiKey",
+			"azureBlobClientSecret":      "myAzureBlobClientSecret",
+			"azureBlobSASToken":          "myAzureBlobSASToken",
+			"azureADClientSecret":        "myAzureADClientSecret",
+			"azureADClientCertificate":   "myAzureADClientCertificate",
 			"oauth2ClientSecret":         "myOauth2ClientSecret",
 			"oauth2JWTPrivateKey":        "myOauth2JWTPrivateKey",
+			"oauth2RefreshToken":         "myOauth2RefreshToken",
+			"tokenExchangeClientSecret":  "myTokenExchangeClientSecret",
 			"oauth2EndPointParamsValue1": "Resource1",
 			"oauth2EndPointParamsValue2": "Resource2",
 		},
@@ -217,41 +269,92 @@ func TestAllSettingsAgainstFrontEnd(t *testing.T) {
 		AWSAccessKey:         "awsAccessKey1",
 		AWSSecretKey:         "awsSecretKey1",
 		AWSSettings: models.AWSSettings{
-			AuthType: models.AWSAuthTypeKeys,
-			Service:  "service1",
-			Region:   "region1",
-		},
+			AuthType:          models.AWSAuthTypeKeys,
+			Service:           "service1",
+			Region:            "region1",
+			Endpoint:          "https://minio.foo.com",
+			UsePathStyle:      true,
+			HealthCheckBucket: "bucket1",
+			Profile:           "profile1",
+			AssumeRoleARN:     "arn:aws:iam::123456789012:role/grafana",
+			ExternalID:        "external1",
+		},
+		TokenExchangeSettings: models.TokenExchangeSettings{
+			Type:             models.TokenExchangeTypeRFC8693,
+			TokenURL:         "https://foo.com/token",
+			ClientID:         "client1",
+			ClientSecret:     "myTokenExchangeClientSecret",
+			AuthStyle:        oauth2.AuthStyleInParams,
+			Scopes:           []string{"scope1"},
+			Audience:         "audience1",
+			Resource:         "https://api.foo.com",
+			SubjectTokenType: models.TokenTypeIDToken,
+		},
+		GoogleSettings: models.GoogleSettings{
+			AuthType:                 models.GoogleAuthTypeAPIKey,
+			HealthCheckSpreadsheetID: "spreadsheet1",
+			HealthCheckBucket:        "bucket1",
+		},
+		GoogleServiceAccountKey: "myGoogleServiceAccountKey",
+		GoogleAPIKey:            "myGoogleApiKey",
+		AzureBlobAuthType:       models.AzureBlobAuthTypeClientSecret,
+		AzureBlobTenantID:       "tenant1",
+		AzureBlobClientID:       "client1",
+		AzureBlobClientSecret:   "myAzureBlobClientSecret",
+		AzureBlobSASToken:       "myAzureBlobSASToken",
+		AzureADSettings: models.AzureADSettings{
+			AuthType:  models.AzureAuthTypeClientCertificate,
+			CloudType: models.AzureCloudUSGovernment,
+			TenantID:  "tenant1",
+			ClientID:  "client1",
+			Scopes:    map[string]string{"https://api.foo.com": "api://foo/.default"},
+		},
+		AzureADClientSecret:      "myAzureADClientSecret",
+		AzureADClientCertificate: "myAzureADClientCertificate",
 		OAuth2Settings: models.OAuth2Settings{
-			ClientID:     "myClientID",
-			OAuth2Type:   "client_credentials",
-			ClientSecret: "myOauth2ClientSecret",
-			PrivateKey:   "myOauth2JWTPrivateKey",
-			Email:        "myEmail",
-			PrivateKeyID: "saturn",
-			Subject:      "mySubject",
-			TokenURL:     "TOKEN_URL",
-			Scopes:       []string{"scope1", "scope2"},
+			ClientID:                "myClientID",
+			OAuth2Type:              "client_credentials",
+			ClientSecret:            "myOauth2ClientSecret",
+			PrivateKey:              "myOauth2JWTPrivateKey",
+			Email:                   "myEmail",
+			PrivateKeyID:            "saturn",
+			Subject:                 "mySubject",
+			TokenURL:                "TOKEN_URL",
+			AuthURL:                 "AUTH_URL",
+			RefreshToken:            "myOauth2RefreshToken",
+			Scopes:                  []string{"scope1", "scope2"},
+			ClientAuthMethod:        models.OAuth2ClientAuthPrivateKeyJWT,
+			ClientAssertionAudience: "AUDIENCE",
 			EndpointParams: map[string]string{
 				"resource": "Resource1",
 				"name":     "Resource2",
 			},
 			TokenHeaders: map[string]string{},
 		},
-		BearerToken:               "myBearerToken",
-		ApiKeyKey:                 "hello",
-		ApiKeyType:                "query",
-		ApiKeyValue:               "earth",
-		URL:                       "https://foo.com",
-		ProxyType:                 models.ProxyTypeUrl,
-		ProxyUrl:                  "https://foo.com",
-		AllowedHosts:              []string{"host1", "host2"},
-		UserName:                  "user",
-		Password:                  "password",
-		TimeoutInSeconds:          30,
-		CustomHealthCheckEnabled:  true,
-		CustomHealthCheckUrl:      "https://foo-check/",
-		UnsecuredQueryHandling:    models.UnsecuredQueryHandlingDeny,
-		AllowDangerousHTTPMethods: true,
+		BearerToken:                "myBearerToken",
+		ApiKeyKey:                  "hello",
+		ApiKeyType:                 "query",
+		ApiKeyValue:                "earth",
+		URL:                        "https://foo.com",
+		ProxyType:                  models.ProxyTypeUrl,
+		ProxyUrl:                   "https://foo.com",
+		AllowedHosts:               []string{"host1", "host2"},
+		UserName:                   "user",
+		Password:                   "password",
+		TimeoutInSeconds:           30,
+		CustomHealthCheckEnabled:   true,
+		CustomHealthCheckUrl:       "https://foo-check/",
+		UnsecuredQueryHandling:     models.UnsecuredQueryHandlingDeny,
+		AllowDangerousHTTPMethods:  true,
+		ResponseCacheTTLInSeconds:  60,
+		ResponseCacheMaxEntries:    50,
+		RetryMaxAttempts:           3,
+		RetryInitialBackoffInMs:    200,
+		RetryMaxBackoffInMs:        5000,
+		RetryStatusCodes:           []int{429, 503},
+		RetryNonIdempotentMethods:  true,
+		RateLimitRequestsPerSecond: 0.5,
+		RateLimitBurst:             5,
 		CustomHeaders: map[string]string{
 			"header1": "headervalue1",
 		},
@@ -338,6 +441,8 @@ func Test_getSecrets(t *testing.T) {
 }
 
 func TestInfinitySettings_Validate(t *testing.T) {
+	t.Setenv("AWS_REGION", "")
+	t.Setenv("AWS_DEFAULT_REGION", "")
 	tests := []struct {
 		name     string
 		settings models.InfinitySettings
@@ -398,6 +503,137 @@ func TestInfinitySettings_Validate(t *testing.T) {
 			settings: models.InfinitySettings{AuthenticationMethod: models.AuthenticationMethodBearerToken, BearerToken: "foo"},
 			wantErr:  models.ErrInvalidConfigHostNotAllowed,
 		},
+		{
+			settings: models.InfinitySettings{AuthenticationMethod: models.AuthenticationMethodAzureBlob, AzureBlobAccountName: "foo"},
+			wantErr:  models.ErrInvalidConfigAzBlobKey,
+		},
+		{
+			settings: models.InfinitySettings{AuthenticationMethod: models.AuthenticationMethodAzureBlob, AzureBlobAuthType: models.AzureBlobAuthTypeSASToken, AzureBlobAccountName: "foo"},
+			wantErr:  models.ErrInvalidConfigAzBlobSASToken,
+		},
+		{
+			settings: models.InfinitySettings{AuthenticationMethod: models.AuthenticationMethodAzureBlob, AzureBlobAuthType: models.AzureBlobAuthTypeSASToken, AzureBlobSASToken: "sv=2022-11-02&sig=foo"},
+			wantErr:  models.ErrInvalidConfigAzBlobAccName,
+		},
+		{
+			settings: models.InfinitySettings{AuthenticationMethod: models.AuthenticationMethodAzureBlob, AzureBlobAuthType: models.AzureBlobAuthTypeSASToken, AzureBlobSASToken: "https://foo.blob.core.windows.net/?sv=2022-11-02&sig=foo"},
+		},
+		{
+			settings: models.InfinitySettings{AuthenticationMethod: models.AuthenticationMethodAzureBlob, AzureBlobAuthType: models.AzureBlobAuthTypeClientSecret, AzureBlobAccountName: "foo", AzureBlobTenantID: "tenant1"},
+			wantErr:  models.ErrInvalidConfigAzBlobClientID,
+		},
+		{
+			settings: models.InfinitySettings{AuthenticationMethod: models.AuthenticationMethodAzureBlob, AzureBlobAuthType: models.AzureBlobAuthTypeClientSecret, AzureBlobAccountName: "foo", AzureBlobTenantID: "tenant1", AzureBlobClientID: "client1"},
+			wantErr:  models.ErrInvalidConfigAzBlobClientSecret,
+		},
+		{
+			settings: models.InfinitySettings{AuthenticationMethod: models.AuthenticationMethodAzureBlob, AzureBlobAuthType: models.AzureBlobAuthTypeManagedIdentity, AzureBlobAccountName: "foo"},
+		},
+		{
+			settings: models.InfinitySettings{AuthenticationMethod: models.AuthenticationMethodAzureBlob, AzureBlobAuthType: "foo", AzureBlobAccountName: "foo"},
+			wantErr:  models.ErrInvalidConfigAzBlobAuthType,
+		},
+		{
+			settings: models.InfinitySettings{AuthenticationMethod: models.AuthenticationMethodAzureAD, AzureADSettings: models.AzureADSettings{TenantID: "tenant1", ClientID: "client1"}},
+			wantErr:  models.ErrInvalidConfigAzureClientSecret,
+		},
+		{
+			settings: models.InfinitySettings{AuthenticationMethod: models.AuthenticationMethodAzureAD, AzureADSettings: models.AzureADSettings{AuthType: models.AzureAuthTypeClientCertificate, ClientID: "client1"}},
+			wantErr:  models.ErrInvalidConfigAzureTenantID,
+		},
+		{
+			settings: models.InfinitySettings{AuthenticationMethod: models.AuthenticationMethodAzureAD, AzureADSettings: models.AzureADSettings{AuthType: models.AzureAuthTypeClientCertificate, TenantID: "tenant1", ClientID: "client1"}},
+			wantErr:  models.ErrInvalidConfigAzureClientCert,
+		},
+		{
+			settings: models.InfinitySettings{AuthenticationMethod: models.AuthenticationMethodAzureAD, AzureADSettings: models.AzureADSettings{AuthType: models.AzureAuthTypeManagedIdentity}},
+			wantErr:  models.ErrInvalidConfigHostNotAllowed,
+		},
+		{
+			settings: models.InfinitySettings{AuthenticationMethod: models.AuthenticationMethodAzureAD, AzureADSettings: models.AzureADSettings{AuthType: models.AzureAuthTypeManagedIdentity}, AllowedHosts: []string{"https://management.azure.com"}},
+		},
+		{
+			settings: models.InfinitySettings{AuthenticationMethod: models.AuthenticationMethodAzureAD, AzureADSettings: models.AzureADSettings{AuthType: "foo"}},
+			wantErr:  models.ErrInvalidConfigAzureAuthType,
+		},
+		{
+			settings: models.InfinitySettings{AuthenticationMethod: models.AuthenticationMethodOAuth, OAuth2Settings: models.OAuth2Settings{OAuth2Type: models.AuthOAuthAuthorizationCode, TokenURL: "https://foo.com/token"}},
+			wantErr:  models.ErrInvalidConfigOAuth2AuthURL,
+		},
+		{
+			settings: models.InfinitySettings{AuthenticationMethod: models.AuthenticationMethodOAuth, OAuth2Settings: models.OAuth2Settings{OAuth2Type: models.AuthOAuthAuthorizationCode, AuthURL: "https://foo.com/authorize"}},
+			wantErr:  models.ErrInvalidConfigOAuth2TokenURL,
+		},
+		{
+			settings: models.InfinitySettings{AuthenticationMethod: models.AuthenticationMethodOAuth, OAuth2Settings: models.OAuth2Settings{OAuth2Type: models.AuthOAuthTypeClientCredentials, ClientAuthMethod: "foo"}},
+			wantErr:  models.ErrInvalidConfigOAuth2ClientAuth,
+		},
+		{
+			settings: models.InfinitySettings{AuthenticationMethod: models.AuthenticationMethodOAuth, OAuth2Settings: models.OAuth2Settings{OAuth2Type: models.AuthOAuthTypeClientCredentials, ClientAuthMethod: models.OAuth2ClientAuthPrivateKeyJWT, PrivateKey: "foo"}},
+			wantErr:  models.ErrInvalidConfigOAuth2PrivateKey,
+		},
+		{
+			settings: models.InfinitySettings{AuthenticationMethod: models.AuthenticationMethodOAuth, OAuth2Settings: models.OAuth2Settings{OAuth2Type: models.AuthOAuthTypeClientCredentials, ClientAuthMethod: models.OAuth2ClientAuthTLS}},
+			wantErr:  models.ErrInvalidConfigOAuth2ClientCert,
+		},
+		{
+			settings: models.InfinitySettings{URL: "https://foo.com", AuthenticationMethod: models.AuthenticationMethodForwardOauth, ForwardOauthIdentity: true, TokenExchangeSettings: models.TokenExchangeSettings{Type: models.TokenExchangeTypeRFC8693, TokenURL: "https://foo.com/token"}},
+		},
+		{
+			settings: models.InfinitySettings{AuthenticationMethod: models.AuthenticationMethodForwardOauth, ForwardOauthIdentity: true, TokenExchangeSettings: models.TokenExchangeSettings{Type: "foo", TokenURL: "https://foo.com/token"}},
+			wantErr:  models.ErrInvalidConfigTokenExchangeType,
+		},
+		{
+			settings: models.InfinitySettings{AuthenticationMethod: models.AuthenticationMethodForwardOauth, ForwardOauthIdentity: true, TokenExchangeSettings: models.TokenExchangeSettings{Type: models.TokenExchangeTypeRFC8693}},
+			wantErr:  models.ErrInvalidConfigTokenExchangeTokenURL,
+		},
+		{
+			settings: models.InfinitySettings{AuthenticationMethod: models.AuthenticationMethodForwardOauth, ForwardOauthIdentity: true, TokenExchangeSettings: models.TokenExchangeSettings{Type: models.TokenExchangeTypeOnBehalfOf, TokenURL: "https://foo.com/token", ClientID: "client1"}},
+			wantErr:  models.ErrInvalidConfigTokenExchangeClient,
+		},
+		{
+			settings: models.InfinitySettings{AuthenticationMethod: models.AuthenticationMethodAWS, AWSSettings: models.AWSSettings{AuthType: models.AWSAuthTypeKeys}, AWSAccessKey: "foo"},
+			wantErr:  models.ErrInvalidConfigAWSSecretKey,
+		},
+		{
+			settings: models.InfinitySettings{AuthenticationMethod: models.AuthenticationMethodAWS, AWSSettings: models.AWSSettings{AuthType: models.AWSAuthTypeDefault, Region: "us-east-1", Service: "execute-api", AssumeRoleARN: "arn:aws:iam::123456789012:role/grafana", ExternalID: "foo"}, AllowedHosts: []string{"https://foo.com"}},
+		},
+		{
+			settings: models.InfinitySettings{AuthenticationMethod: models.AuthenticationMethodAWS, AWSSettings: models.AWSSettings{AuthType: models.AWSAuthTypeDefault, ExternalID: "foo"}},
+			wantErr:  models.ErrInvalidConfigAWSExternalID,
+		},
+		{
+			settings: models.InfinitySettings{AuthenticationMethod: models.AuthenticationMethodAWS, AWSSettings: models.AWSSettings{AuthType: models.AWSAuthTypeSharedCreds, Region: "us-east-1", Service: "execute-api"}, AllowedHosts: []string{"https://foo.com"}},
+		},
+		{
+			settings: models.InfinitySettings{AuthenticationMethod: models.AuthenticationMethodAWS, AWSSettings: models.AWSSettings{AuthType: models.AWSAuthTypeDefault, Service: "execute-api"}},
+			wantErr:  models.ErrInvalidConfigAWSRegion,
+		},
+		{
+			settings: models.InfinitySettings{AuthenticationMethod: models.AuthenticationMethodAWS, AWSSettings: models.AWSSettings{AuthType: models.AWSAuthTypeDefault, Region: "us-east-1"}},
+			wantErr:  models.ErrInvalidConfigAWSService,
+		},
+		{
+			name:     "legacy AWS settings without the auth type use the default region and service",
+			settings: models.InfinitySettings{AuthenticationMethod: models.AuthenticationMethodAWS, AWSAccessKey: "foo", AWSSecretKey: "bar", AllowedHosts: []string{"https://foo.com"}},
+		},
+		{
+			name:     "AWS access and secret keys use the default region and service",
+			settings: models.InfinitySettings{AuthenticationMethod: models.AuthenticationMethodAWS, AWSSettings: models.AWSSettings{AuthType: models.AWSAuthTypeKeys}, AWSAccessKey: "foo", AWSSecretKey: "bar", AllowedHosts: []string{"https://foo.com"}},
+		},
+		{
+			name:     "AWS settings require the allowed hosts",
+			settings: models.InfinitySettings{AuthenticationMethod: models.AuthenticationMethodAWS, AWSAccessKey: "foo", AWSSecretKey: "bar"},
+			wantErr:  models.ErrInvalidConfigHostNotAllowed,
+		},
+		{
+			settings: models.InfinitySettings{AuthenticationMethod: models.AuthenticationMethodAWS, AWSSettings: models.AWSSettings{AuthType: models.AWSAuthTypeDefault, Region: "us-east-1", Service: "execute-api"}},
+			wantErr:  models.ErrInvalidConfigHostNotAllowed,
+		},
+		{
+			settings: models.InfinitySettings{AuthenticationMethod: models.AuthenticationMethodAWS, AWSSettings: models.AWSSettings{AuthType: "foo"}},
+			wantErr:  models.ErrInvalidConfigAWSAuthType,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"strings"
+)
+
+func (s *InfinitySettings) validateTokenExchange() error {
+	switch s.TokenExchangeSettings.Type {
+	case TokenExchangeTypeRFC8693:
+		switch s.TokenExchangeSettings.SubjectTokenType {
+		case TokenTypeAccessToken, TokenTypeIDToken, "":
+		default:
+			return ErrInvalidConfigTokenExchangeSubjectTokenType
+		}
+	case TokenExchangeTypeOnBehalfOf:
+		if strings.TrimSpace(s.TokenExchangeSettings.ClientID) == "" || strings.TrimSpace(s.TokenExchangeSettings.ClientSecret) == "" {
+			return ErrInvalidConfigTokenExchangeClient
+		}
+	default:
+		return ErrInvalidConfigTokenExchangeType
+	}
+	if strings.TrimSpace(s.TokenExchangeSettings.TokenURL) == "" {
+		return ErrInvalidConfigTokenExchangeTokenURL
+	}
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"net/http"
 
+	"github.com/grafana/grafana-infinity-datasource/pkg/httpclient"
 	"github.com/grafana/grafana-infinity-datasource/pkg/infinity"
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/resource/httpadapter"
@@ -19,6 +20,10 @@ func (host *DataSource) getRouter() *http.ServeMux {
 	router := http.NewServeMux()
 	router.HandleFunc("GET /reference-data", host.withDatasourceHandlerFunc(getReferenceDataHandler))
 	router.HandleFunc("GET /ping", host.withDatasourceHandlerFunc(getPingHandler))
+	router.HandleFunc("GET /oauth2/authorize", host.withDatasourceHandlerFunc(getOAuth2AuthorizeHandler))
+	router.HandleFunc("GET /"+httpclient.OAuth2CallbackPath, host.withDatasourceHandlerFunc(getOAuth2CallbackHandler))
+	router.HandleFunc("GET /oauth2/token", host.withDatasourceHandlerFunc(getOAuth2TokenHandler))
+	router.HandleFunc("GET /oauth2/status", host.withDatasourceHandlerFunc(getOAuth2StatusHandler))
 	router.HandleFunc("/", host.withDatasourceHandlerFunc(defaultHandler))
 	return router
 }
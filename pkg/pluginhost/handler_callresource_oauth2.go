@@ -0,0 +1,190 @@
+package pluginhost
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-infinity-datasource/pkg/httpclient"
+	"github.com/grafana/grafana-infinity-datasource/pkg/infinity"
+	"github.com/grafana/grafana-infinity-datasource/pkg/models"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/config"
+	"golang.org/x/oauth2"
+)
+
+// oauth2AuthorizationTimeout is the time the admin has to complete the consent flow of the authorization server
+const oauth2AuthorizationTimeout = 10 * time.Minute
+
+// oauth2Authorization is the consent flow started by the admin. The refresh token received by the callback is kept
+// until collected by the config editor of the same admin
+type oauth2Authorization struct {
+	uid          string
+	login        string
+	verifier     string
+	redirectURL  string
+	refreshToken string
+	err          error
+	completed    bool
+	expires      time.Time
+}
+
+type oauth2AuthorizationStore struct {
+	mu             sync.Mutex
+	authorizations map[string]*oauth2Authorization
+}
+
+var oauth2Authorizations = &oauth2AuthorizationStore{authorizations: map[string]*oauth2Authorization{}}
+
+func (s *oauth2AuthorizationStore) add(state string, authorization *oauth2Authorization) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for k, v := range s.authorizations {
+		if time.Now().After(v.expires) {
+			delete(s.authorizations, k)
+		}
+	}
+	s.authorizations[state] = authorization
+}
+
+func (s *oauth2AuthorizationStore) get(state string, uid string) (*oauth2Authorization, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	authorization, ok := s.authorizations[state]
+	if !ok || authorization.uid != uid || time.Now().After(authorization.expires) {
+		return nil, false
+	}
+	return authorization, true
+}
+
+func (s *oauth2AuthorizationStore) complete(state string, refreshToken string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if authorization, ok := s.authorizations[state]; ok {
+		authorization.refreshToken = refreshToken
+		authorization.err = err
+		authorization.completed = true
+	}
+}
+
+func (s *oauth2AuthorizationStore) delete(state string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.authorizations, state)
+}
+
+// getOAuth2AuthorizeHandler starts the consent flow and returns the url of the consent page of the authorization server
+func getOAuth2AuthorizeHandler(client *infinity.Client) http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		user, err := getOAuth2Admin(r, client)
+		if err != nil {
+			writeResponse(map[string]any{"error": err.Error()}, nil, rw, http.StatusForbidden)
+			return
+		}
+		appURL, err := config.GrafanaConfigFromContext(r.Context()).AppURL()
+		if err != nil {
+			writeResponse(map[string]any{"error": "grafana app url is not available. " + err.Error()}, nil, rw, http.StatusInternalServerError)
+			return
+		}
+		state, verifier := oauth2.GenerateVerifier(), oauth2.GenerateVerifier()
+		redirectURL := httpclient.GetOAuth2RedirectURL(appURL, client.Settings.UID)
+		oauth2Authorizations.add(state, &oauth2Authorization{
+			uid:         client.Settings.UID,
+			login:       user.Login,
+			verifier:    verifier,
+			redirectURL: redirectURL,
+			expires:     time.Now().Add(oauth2AuthorizationTimeout),
+		})
+		writeResponse(map[string]any{
+			"authorizeUrl": httpclient.GetOAuth2AuthorizationCodeURL(client.Settings, redirectURL, state, verifier),
+			"redirectUrl":  redirectURL,
+			"state":        state,
+		}, nil, rw, http.StatusOK)
+	}
+}
+
+// getOAuth2CallbackHandler receives the authorization code from the authorization server and exchanges it for the refresh token
+func getOAuth2CallbackHandler(client *infinity.Client) http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		state := r.URL.Query().Get("state")
+		authorization, ok := oauth2Authorizations.get(state, client.Settings.UID)
+		if !ok || !isSameUser(r, authorization.login) {
+			writeResponse(models.ErrOAuth2InvalidState.Error(), nil, rw, http.StatusBadRequest)
+			return
+		}
+		if authErr := r.URL.Query().Get("error"); authErr != "" {
+			err := errors.New("authorization failed. " + authErr + " " + r.URL.Query().Get("error_description"))
+			oauth2Authorizations.complete(state, "", err)
+			writeResponse(err.Error(), nil, rw, http.StatusBadRequest)
+			return
+		}
+		token, err := httpclient.ExchangeOAuth2AuthorizationCode(r.Context(), client.Settings, authorization.redirectURL, r.URL.Query().Get("code"), authorization.verifier)
+		if err == nil && token.RefreshToken == "" {
+			err = errors.New("the authorization server didn't issue a refresh token. request the offline access scope such as offline_access")
+		}
+		if err != nil {
+			backend.Logger.FromContext(r.Context()).Error("error exchanging the OAuth2 authorization code", "error", err.Error())
+			oauth2Authorizations.complete(state, "", err)
+			writeResponse("authorization failed. "+err.Error(), nil, rw, http.StatusBadRequest)
+			return
+		}
+		oauth2Authorizations.complete(state, token.RefreshToken, nil)
+		writeResponse("Authorization completed. Close this window and save the data source.", nil, rw, http.StatusOK)
+	}
+}
+
+// getOAuth2TokenHandler returns the refresh token of the completed consent flow to the config editor. The refresh token
+// is returned only once
+func getOAuth2TokenHandler(client *infinity.Client) http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		if _, err := getOAuth2Admin(r, client); err != nil {
+			writeResponse(map[string]any{"error": err.Error()}, nil, rw, http.StatusForbidden)
+			return
+		}
+		state := r.URL.Query().Get("state")
+		authorization, ok := oauth2Authorizations.get(state, client.Settings.UID)
+		if !ok || !isSameUser(r, authorization.login) {
+			writeResponse(map[string]any{"error": models.ErrOAuth2InvalidState.Error()}, nil, rw, http.StatusBadRequest)
+			return
+		}
+		if !authorization.completed {
+			writeResponse(map[string]any{"status": "pending"}, nil, rw, http.StatusAccepted)
+			return
+		}
+		oauth2Authorizations.delete(state)
+		if authorization.err != nil {
+			writeResponse(map[string]any{"error": authorization.err.Error()}, nil, rw, http.StatusBadRequest)
+			return
+		}
+		writeResponse(map[string]any{"status": "completed", "refreshToken": authorization.refreshToken}, nil, rw, http.StatusOK)
+	}
+}
+
+// getOAuth2StatusHandler returns the warnings about the refresh tokens of the data source to the config editor
+func getOAuth2StatusHandler(client *infinity.Client) http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		if _, err := getOAuth2Admin(r, client); err != nil {
+			writeResponse(map[string]any{"error": err.Error()}, nil, rw, http.StatusForbidden)
+			return
+		}
+		writeResponse(map[string]any{"warnings": httpclient.GetOAuth2RefreshTokenWarnings(r.Context(), client.Settings)}, nil, rw, http.StatusOK)
+	}
+}
+
+// getOAuth2Admin returns the user of the request when the user is allowed to authorize the data source
+func getOAuth2Admin(r *http.Request, client *infinity.Client) (*backend.User, error) {
+	if client.Settings.AuthenticationMethod != models.AuthenticationMethodOAuth || client.Settings.OAuth2Settings.OAuth2Type != models.AuthOAuthAuthorizationCode {
+		return nil, models.ErrOAuth2NotAuthorizationCode
+	}
+	user := backend.UserFromContext(r.Context())
+	if user == nil || user.Role != "Admin" {
+		return nil, models.ErrOAuth2AdminRequired
+	}
+	return user, nil
+}
+
+func isSameUser(r *http.Request, login string) bool {
+	user := backend.UserFromContext(r.Context())
+	return user != nil && user.Login == login
+}
@@ -0,0 +1,100 @@
+package pluginhost
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/grafana/grafana-infinity-datasource/pkg/infinity"
+	"github.com/grafana/grafana-infinity-datasource/pkg/models"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOAuth2AuthorizationCodeResources(t *testing.T) {
+	authServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Nil(t, r.ParseForm())
+		require.Equal(t, "authorization_code", r.Form.Get("grant_type"))
+		require.Equal(t, "https://grafana.foo.com/api/datasources/uid/ds1/resources/oauth2/callback", r.Form.Get("redirect_uri"))
+		require.NotEmpty(t, r.Form.Get("code_verifier"))
+		w.Header().Set("Content-Type", "application/json")
+		if r.Form.Get("code") != "code1" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"invalid_grant"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"access_token":"access1","token_type":"Bearer","expires_in":3600,"refresh_token":"refresh1"}`))
+	}))
+	defer authServer.Close()
+	host := &DataSource{client: &infinity.Client{Settings: models.InfinitySettings{
+		UID:                  "ds1",
+		AuthenticationMethod: models.AuthenticationMethodOAuth,
+		OAuth2Settings: models.OAuth2Settings{
+			OAuth2Type:   models.AuthOAuthAuthorizationCode,
+			ClientID:     "client",
+			ClientSecret: "secret",
+			AuthURL:      authServer.URL + "/authorize",
+			TokenURL:     authServer.URL + "/token",
+		},
+	}}}
+	callResource := func(t *testing.T, user *backend.User, path string) (int, map[string]any, string) {
+		t.Helper()
+		ctx := config.WithGrafanaConfig(context.Background(), config.NewGrafanaCfg(map[string]string{config.AppURL: "https://grafana.foo.com/"}))
+		req := httptest.NewRequest(http.MethodGet, path, nil).WithContext(backend.WithUser(ctx, user))
+		rec := httptest.NewRecorder()
+		host.getRouter().ServeHTTP(rec, req)
+		out := map[string]any{}
+		_ = json.Unmarshal(rec.Body.Bytes(), &out)
+		return rec.Code, out, rec.Body.String()
+	}
+	admin := &backend.User{Login: "admin", Role: "Admin"}
+	authorize := func(t *testing.T) string {
+		t.Helper()
+		code, out, _ := callResource(t, admin, "/oauth2/authorize")
+		require.Equal(t, http.StatusOK, code)
+		require.Equal(t, "https://grafana.foo.com/api/datasources/uid/ds1/resources/oauth2/callback", out["redirectUrl"])
+		authorizeURL, err := url.Parse(out["authorizeUrl"].(string))
+		require.NoError(t, err)
+		require.Equal(t, out["state"], authorizeURL.Query().Get("state"))
+		require.Equal(t, "S256", authorizeURL.Query().Get("code_challenge_method"))
+		return out["state"].(string)
+	}
+	t.Run("should return the refresh token once the consent flow is completed", func(t *testing.T) {
+		state := authorize(t)
+		code, out, _ := callResource(t, admin, "/oauth2/token?state="+state)
+		require.Equal(t, http.StatusAccepted, code)
+		require.Equal(t, "pending", out["status"])
+		code, _, body := callResource(t, admin, "/oauth2/callback?code=code1&state="+state)
+		require.Equal(t, http.StatusOK, code)
+		require.Contains(t, body, "Authorization completed")
+		code, out, _ = callResource(t, admin, "/oauth2/token?state="+state)
+		require.Equal(t, http.StatusOK, code)
+		require.Equal(t, "refresh1", out["refreshToken"])
+		code, _, _ = callResource(t, admin, "/oauth2/token?state="+state)
+		require.Equal(t, http.StatusBadRequest, code)
+	})
+	t.Run("should return the error of the code exchange", func(t *testing.T) {
+		state := authorize(t)
+		code, _, _ := callResource(t, admin, "/oauth2/callback?code=invalid&state="+state)
+		require.Equal(t, http.StatusBadRequest, code)
+		code, out, _ := callResource(t, admin, "/oauth2/token?state="+state)
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, out["error"], "invalid_grant")
+	})
+	t.Run("should reject unknown state and other users", func(t *testing.T) {
+		state := authorize(t)
+		code, _, _ := callResource(t, admin, "/oauth2/callback?code=code1&state=unknown")
+		require.Equal(t, http.StatusBadRequest, code)
+		code, _, _ = callResource(t, &backend.User{Login: "other", Role: "Admin"}, "/oauth2/callback?code=code1&state="+state)
+		require.Equal(t, http.StatusBadRequest, code)
+	})
+	t.Run("should allow only the admins to authorize", func(t *testing.T) {
+		code, out, _ := callResource(t, &backend.User{Login: "viewer", Role: "Viewer"}, "/oauth2/authorize")
+		require.Equal(t, http.StatusForbidden, code)
+		require.Equal(t, models.ErrOAuth2AdminRequired.Error(), out["error"])
+	})
+}
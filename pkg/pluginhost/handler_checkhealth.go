@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/grafana/grafana-infinity-datasource/pkg/httpclient"
 	"github.com/grafana/grafana-infinity-datasource/pkg/infinity"
 	"github.com/grafana/grafana-infinity-datasource/pkg/models"
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
@@ -25,6 +26,19 @@ func (ds *DataSource) CheckHealth(ctx context.Context, req *backend.CheckHealthR
 }
 
 func CheckHealth(ctx context.Context, client *infinity.Client, req *backend.CheckHealthRequest) (*backend.CheckHealthResult, error) {
+	healthCheckResult, err := checkHealth(ctx, client, req)
+	if err != nil || healthCheckResult == nil || healthCheckResult.Status != backend.HealthStatusOk {
+		return healthCheckResult, err
+	}
+	// configuration warnings don't fail the health check but are reported along with the successful result
+	warnings := append(client.Settings.Warnings(), httpclient.GetOAuth2RefreshTokenWarnings(ctx, client.Settings)...)
+	for _, warning := range warnings {
+		healthCheckResult.Message += ". Warning: " + warning
+	}
+	return healthCheckResult, nil
+}
+
+func checkHealth(ctx context.Context, client *infinity.Client, req *backend.CheckHealthRequest) (*backend.CheckHealthResult, error) {
 	logger := backend.Logger.FromContext(ctx)
 	if client == nil {
 		return healthCheckError("", models.ErrFailedToGetPluginInstance.Error(), "")
@@ -47,12 +61,22 @@ func CheckHealth(ctx context.Context, client *infinity.Client, req *backend.Chec
 	if client.Settings.AuthenticationMethod == models.AuthenticationMethodAzureBlob {
 		return checkHealthAzureBlobStorage(ctx, client)
 	}
+	if client.Settings.AuthenticationMethod == models.AuthenticationMethodAWS && strings.TrimSpace(client.Settings.AWSSettings.HealthCheckBucket) != "" {
+		return checkHealthS3(ctx, client)
+	}
+	if client.Settings.AuthenticationMethod == models.AuthenticationMethodGoogle && strings.TrimSpace(client.Settings.GoogleSettings.HealthCheckBucket) != "" {
+		return checkHealthGCS(ctx, client)
+	}
+	if client.Settings.AuthenticationMethod == models.AuthenticationMethodGoogle && !client.Settings.CustomHealthCheckEnabled {
+		return checkHealthGoogle(ctx, client, req)
+	}
 	if client.Settings.CustomHealthCheckEnabled && client.Settings.CustomHealthCheckUrl != "" {
 		urlOptions := client.Settings.CustomHealthCheckUrlOptions
 		if urlOptions.Method == "" {
 			urlOptions.Method = http.MethodGet
 		}
-		_, statusCode, _, err := client.GetResults(ctx, &backend.PluginContext{}, models.Query{
+		// health check must always reach the upstream instead of returning a cached response
+		_, statusCode, _, err := client.GetResults(infinity.WithoutResponseCache(ctx), &backend.PluginContext{}, models.Query{
 			Type:       models.QueryTypeUQL,
 			Source:     "url",
 			URL:        client.Settings.CustomHealthCheckUrl,
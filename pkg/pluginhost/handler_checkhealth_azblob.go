@@ -24,6 +24,12 @@ func checkHealthAzureBlobStorage(ctx context.Context, client *infinity.Client) (
 			return healthCheckError("", errors.Join(models.ErrConnectingAzBlobAccount, models.ErrInvalidAzBlobStorageName).Error(), "")
 		}
 		if strings.Contains(err.Error(), "RESPONSE 403") {
+			switch client.Settings.AzureBlobAuthType {
+			case models.AzureBlobAuthTypeSASToken:
+				return healthCheckError("", errors.Join(models.ErrConnectingAzBlobAccount, models.ErrAzBlob403SASToken).Error(), "")
+			case models.AzureBlobAuthTypeClientSecret, models.AzureBlobAuthTypeManagedIdentity, models.AzureBlobAuthTypeWorkloadIdentity:
+				return healthCheckError("", errors.Join(models.ErrConnectingAzBlobAccount, models.ErrAzBlob403EntraID).Error(), "")
+			}
 			return healthCheckError("", errors.Join(models.ErrConnectingAzBlobAccount, models.ErrAzBlob403).Error(), "")
 		}
 		if strings.Contains(err.Error(), "RESPONSE 500") {
@@ -0,0 +1,32 @@
+package pluginhost
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/grafana/grafana-infinity-datasource/pkg/infinity"
+	"github.com/grafana/grafana-infinity-datasource/pkg/models"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+)
+
+func checkHealthGCS(ctx context.Context, client *infinity.Client) (*backend.CheckHealthResult, error) {
+	if client == nil {
+		return healthCheckError("", models.ErrInvalidInfinityClient.Error(), "")
+	}
+	if client.GCSClient == nil {
+		return healthCheckError("", models.ErrInvalidGCSClient.Error(), "")
+	}
+	bucket := strings.TrimSpace(client.Settings.GoogleSettings.HealthCheckBucket)
+	if _, err := client.GCSClient.ListObjects(ctx, bucket, "", 1); err != nil {
+		switch infinity.GetGCSErrorStatusCode(err) {
+		case http.StatusNotFound:
+			return healthCheckError("", errors.Join(models.ErrConnectingGCS, models.ErrGCSBucketNotFound).Error(), "")
+		case http.StatusForbidden, http.StatusUnauthorized:
+			return healthCheckError("", errors.Join(models.ErrConnectingGCS, models.ErrGCSAccessDenied).Error(), "")
+		}
+		return healthCheckError("", errors.Join(models.ErrConnectingGCS, err).Error(), "")
+	}
+	return &backend.CheckHealthResult{Status: backend.HealthStatusOk, Message: "OK"}, nil
+}
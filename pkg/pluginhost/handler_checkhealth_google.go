@@ -0,0 +1,69 @@
+package pluginhost
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/grafana/grafana-infinity-datasource/pkg/infinity"
+	"github.com/grafana/grafana-infinity-datasource/pkg/models"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"golang.org/x/oauth2"
+)
+
+// checkHealthGoogle verifies the google credentials by fetching the metadata of the health check spreadsheet.
+// When no spreadsheet configured, service account credentials are verified by requesting an access token
+func checkHealthGoogle(ctx context.Context, client *infinity.Client, req *backend.CheckHealthRequest) (*backend.CheckHealthResult, error) {
+	if client == nil {
+		return healthCheckError("", models.ErrInvalidInfinityClient.Error(), "")
+	}
+	spreadsheetID := strings.TrimSpace(client.Settings.GoogleSettings.HealthCheckSpreadsheetID)
+	if spreadsheetID == "" {
+		if client.Settings.GoogleSettings.AuthType == models.GoogleAuthTypeAPIKey {
+			return healthCheckSuccess("Google API key configured. Set the health check spreadsheet ID to verify the access to the spreadsheets")
+		}
+		transport, ok := client.HttpClient.Transport.(*oauth2.Transport)
+		if !ok {
+			return healthCheckError("", models.ErrInvalidConfigGoogleKey.Error(), "")
+		}
+		if _, err := transport.Source.Token(); err != nil {
+			return healthCheckError("error getting access token for the google service account", err.Error(), "")
+		}
+		return healthCheckSuccess("Google service account authenticated successfully")
+	}
+	response, statusCode, _, err := client.GetResults(infinity.WithoutResponseCache(ctx), &backend.PluginContext{}, models.Query{
+		Type:       models.QueryTypeJSON,
+		Source:     "url",
+		URL:        fmt.Sprintf("%s/v4/spreadsheets/%s?fields=spreadsheetId,properties.title", models.GoogleSheetsHost, url.PathEscape(spreadsheetID)),
+		URLOptions: models.URLOptions{Method: http.MethodGet},
+	}, req.Headers)
+	if err != nil {
+		switch statusCode {
+		case http.StatusNotFound:
+			return healthCheckError(fmt.Sprintf("spreadsheet %s not found", spreadsheetID), err.Error(), "")
+		case http.StatusForbidden:
+			msg := "permission denied. Share the spreadsheet with the service account"
+			if client.Settings.GoogleSettings.AuthType == models.GoogleAuthTypeAPIKey {
+				msg = "permission denied. Make sure the spreadsheet is public and the API key is allowed to use the Google Sheets API"
+			}
+			return healthCheckError(msg, err.Error(), "")
+		case http.StatusUnauthorized, http.StatusBadRequest:
+			return healthCheckError("invalid google credentials", err.Error(), "")
+		}
+		if errors.Is(err, models.ErrInvalidConfigHostNotAllowed) {
+			return healthCheckError(models.ErrInvalidConfigHostNotAllowed.Error(), err.Error(), "")
+		}
+		return healthCheckError("error fetching the spreadsheet metadata", err.Error(), "")
+	}
+	if metadata, ok := response.(map[string]any); ok {
+		if properties, ok := metadata["properties"].(map[string]any); ok {
+			if title, ok := properties["title"].(string); ok && title != "" {
+				return healthCheckSuccess(fmt.Sprintf("Successfully fetched the spreadsheet %q", title))
+			}
+		}
+	}
+	return healthCheckSuccess("Successfully fetched the spreadsheet metadata")
+}
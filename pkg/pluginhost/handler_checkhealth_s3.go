@@ -0,0 +1,37 @@
+package pluginhost
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/grafana/grafana-infinity-datasource/pkg/infinity"
+	"github.com/grafana/grafana-infinity-datasource/pkg/models"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+)
+
+func checkHealthS3(ctx context.Context, client *infinity.Client) (*backend.CheckHealthResult, error) {
+	if client == nil {
+		return healthCheckError("", models.ErrInvalidInfinityClient.Error(), "")
+	}
+	if client.S3Client == nil {
+		return healthCheckError("", models.ErrInvalidS3Client.Error(), "")
+	}
+	bucket := strings.TrimSpace(client.Settings.AWSSettings.HealthCheckBucket)
+	if _, err := client.S3Client.HeadBucket(ctx, &s3.HeadBucketInput{Bucket: aws.String(bucket)}); err != nil {
+		switch infinity.GetS3ErrorStatusCode(err) {
+		case http.StatusNotFound:
+			return healthCheckError("", errors.Join(models.ErrConnectingS3, models.ErrS3BucketNotFound).Error(), "")
+		case http.StatusForbidden, http.StatusUnauthorized:
+			return healthCheckError("", errors.Join(models.ErrConnectingS3, models.ErrS3AccessDenied).Error(), "")
+		}
+		if strings.Contains(err.Error(), "no such host") {
+			return healthCheckError("", errors.Join(models.ErrConnectingS3, models.ErrS3InvalidEndpoint).Error(), "")
+		}
+		return healthCheckError("", errors.Join(models.ErrConnectingS3, err).Error(), "")
+	}
+	return &backend.CheckHealthResult{Status: backend.HealthStatusOk, Message: "OK"}, nil
+}
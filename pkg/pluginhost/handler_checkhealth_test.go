@@ -1,7 +1,16 @@
 package pluginhost
 
 import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
 	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/grafana/grafana-infinity-datasource/pkg/infinity"
@@ -9,6 +18,7 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
 )
 
 func TestCheckHealth(t *testing.T) {
@@ -62,6 +72,37 @@ func TestCheckHealth(t *testing.T) {
 				JSONDetails: []byte(fmt.Sprintf(`{"verboseMessage":"%s\n%s"}`, models.ErrInvalidConfig.Error(), models.ErrInvalidConfigPassword.Error())),
 			},
 		},
+		{
+			name: "should warn about the default AWS service",
+			config: backend.DataSourceInstanceSettings{
+				JSONData: []byte(`{
+					"auth_method": "aws",
+					"aws": { "authType": "keys", "region": "us-east-1" },
+					"allowedHosts": ["https://foo.com"]
+				}`),
+				DecryptedSecureJSONData: map[string]string{"awsAccessKey": "foo", "awsSecretKey": "bar"},
+			},
+			want: &backend.CheckHealthResult{
+				Status:  backend.HealthStatusOk,
+				Message: "Health check successful. Warning: AWS service is not configured. The requests are signed for the legacy default service monitoring. Configure the service in the data source config",
+			},
+		},
+		{
+			name: "should warn when the rotated OAuth2 refresh tokens can't be saved",
+			config: backend.DataSourceInstanceSettings{
+				UID: "ds-oauth2",
+				JSONData: []byte(`{
+					"auth_method": "oauth2",
+					"oauth2": { "oauth2_type": "authorization_code", "auth_url": "https://foo.com/authorize", "token_url": "https://foo.com/token" },
+					"allowedHosts": ["https://foo.com"]
+				}`),
+				DecryptedSecureJSONData: map[string]string{"oauth2RefreshToken": "foo"},
+			},
+			want: &backend.CheckHealthResult{
+				Status:  backend.HealthStatusOk,
+				Message: "Health check successful. Warning: " + models.ErrOAuth2RefreshTokenNoSaving.Error(),
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -75,3 +116,217 @@ func TestCheckHealth(t *testing.T) {
 		})
 	}
 }
+
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// getGoogleServiceAccountKey returns a service account key which uses a test token server
+func getGoogleServiceAccountKey(t *testing.T) string {
+	t.Helper()
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	privateKeyBytes, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	require.NoError(t, err)
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		require.Equal(t, "urn:ietf:params:oauth:grant-type:jwt-bearer", r.Form.Get("grant_type"))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token":"my-token","token_type":"Bearer","expires_in":3600}`))
+	}))
+	t.Cleanup(tokenServer.Close)
+	serviceAccountKey, err := json.Marshal(map[string]string{
+		"type":         "service_account",
+		"client_email": "infinity@my-project.iam.gserviceaccount.com",
+		"private_key":  string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateKeyBytes})),
+		"token_uri":    tokenServer.URL,
+	})
+	require.NoError(t, err)
+	return string(serviceAccountKey)
+}
+
+func TestCheckHealthGoogle(t *testing.T) {
+	serviceAccountKey := getGoogleServiceAccountKey(t)
+	sheetsAPI := func(t *testing.T, statusCode int, assertRequest func(req *http.Request)) http.RoundTripper {
+		return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			require.Equal(t, "sheets.googleapis.com", req.URL.Host)
+			require.Equal(t, "/v4/spreadsheets/my-sheet", req.URL.Path)
+			require.Equal(t, "spreadsheetId,properties.title", req.URL.Query().Get("fields"))
+			assertRequest(req)
+			body := `{"spreadsheetId":"my-sheet","properties":{"title":"KPIs"}}`
+			if statusCode != http.StatusOK {
+				body = `{"error":{"code":403,"message":"The caller does not have permission","status":"PERMISSION_DENIED"}}`
+			}
+			return &http.Response{StatusCode: statusCode, Status: http.StatusText(statusCode), Header: http.Header{"Content-Type": []string{"application/json"}}, Body: io.NopCloser(strings.NewReader(body)), Request: req}, nil
+		})
+	}
+	getClient := func(t *testing.T, jsonData string, secureJsonData map[string]string) *infinity.Client {
+		settings, err := models.LoadSettings(t.Context(), backend.DataSourceInstanceSettings{JSONData: []byte(jsonData), DecryptedSecureJSONData: secureJsonData})
+		require.NoError(t, err)
+		client, err := infinity.NewClient(t.Context(), settings)
+		require.NoError(t, err)
+		return client
+	}
+	t.Run("should fail for invalid service account key", func(t *testing.T) {
+		client := getClient(t, `{"auth_method":"google"}`, map[string]string{"googleServiceAccountKey": "foo"})
+		got, err := CheckHealth(t.Context(), client, &backend.CheckHealthRequest{})
+		require.NoError(t, err)
+		require.Equal(t, backend.HealthStatusError, got.Status)
+		require.Contains(t, got.Message, models.ErrInvalidConfigGoogleKey.Error())
+	})
+	t.Run("should fetch the access token when no spreadsheet configured", func(t *testing.T) {
+		client := getClient(t, `{"auth_method":"google"}`, map[string]string{"googleServiceAccountKey": serviceAccountKey})
+		got, err := CheckHealth(t.Context(), client, &backend.CheckHealthRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, &backend.CheckHealthResult{Status: backend.HealthStatusOk, Message: "Google service account authenticated successfully"}, got)
+	})
+	t.Run("should fetch the spreadsheet metadata with the service account", func(t *testing.T) {
+		client := getClient(t, `{"auth_method":"google","google":{"healthCheckSpreadsheetId":"my-sheet"}}`, map[string]string{"googleServiceAccountKey": serviceAccountKey})
+		client.HttpClient.Transport.(*oauth2.Transport).Base = sheetsAPI(t, http.StatusOK, func(req *http.Request) {
+			require.Equal(t, "Bearer my-token", req.Header.Get("Authorization"))
+		})
+		got, err := CheckHealth(t.Context(), client, &backend.CheckHealthRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, &backend.CheckHealthResult{Status: backend.HealthStatusOk, Message: `Successfully fetched the spreadsheet "KPIs"`}, got)
+	})
+	t.Run("should fetch the spreadsheet metadata with the api key", func(t *testing.T) {
+		client := getClient(t, `{"auth_method":"google","google":{"authType":"apiKey","healthCheckSpreadsheetId":"my-sheet"}}`, map[string]string{"googleApiKey": "my-key"})
+		client.HttpClient.Transport = sheetsAPI(t, http.StatusOK, func(req *http.Request) {
+			require.Equal(t, "my-key", req.Header.Get("X-Goog-Api-Key"))
+		})
+		got, err := CheckHealth(t.Context(), client, &backend.CheckHealthRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, backend.HealthStatusOk, got.Status)
+	})
+	t.Run("should report the spreadsheets without access", func(t *testing.T) {
+		client := getClient(t, `{"auth_method":"google","google":{"authType":"apiKey","healthCheckSpreadsheetId":"my-sheet"}}`, map[string]string{"googleApiKey": "my-key"})
+		client.HttpClient.Transport = sheetsAPI(t, http.StatusForbidden, func(req *http.Request) {})
+		got, err := CheckHealth(t.Context(), client, &backend.CheckHealthRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, backend.HealthStatusError, got.Status)
+		assert.Contains(t, got.Message, "permission denied")
+	})
+	t.Run("should fail for empty api key", func(t *testing.T) {
+		client := getClient(t, `{"auth_method":"google","google":{"authType":"apiKey"}}`, map[string]string{})
+		got, err := CheckHealth(t.Context(), client, &backend.CheckHealthRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, "Health check failed. "+models.ErrInvalidConfigGoogleAPIKey.Error(), got.Message)
+	})
+}
+
+func TestCheckHealthS3(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodHead, r.Method)
+		switch r.URL.Path {
+		case "/my-bucket":
+			w.WriteHeader(http.StatusOK)
+		case "/private-bucket":
+			w.WriteHeader(http.StatusForbidden)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	tests := []struct {
+		name   string
+		bucket string
+		want   *backend.CheckHealthResult
+	}{
+		{name: "should pass for accessible bucket", bucket: "my-bucket", want: &backend.CheckHealthResult{Status: backend.HealthStatusOk, Message: "OK"}},
+		{
+			name:   "should fail for missing bucket",
+			bucket: "foo",
+			want: &backend.CheckHealthResult{
+				Status:      backend.HealthStatusError,
+				Message:     "Health check failed",
+				JSONDetails: []byte(`{"verboseMessage":"error connecting to s3\nbucket not found"}`),
+			},
+		},
+		{
+			name:   "should fail for bucket without access",
+			bucket: "private-bucket",
+			want: &backend.CheckHealthResult{
+				Status:      backend.HealthStatusError,
+				Message:     "Health check failed",
+				JSONDetails: []byte(`{"verboseMessage":"error connecting to s3\nhttp 403. check the AWS access key, secret key and the bucket policy"}`),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings, err := models.LoadSettings(t.Context(), backend.DataSourceInstanceSettings{
+				JSONData:                []byte(fmt.Sprintf(`{"auth_method":"aws","aws":{"authType":"keys","region":"us-east-1","service":"s3","endpoint":%q,"usePathStyle":true,"healthCheckBucket":%q},"allowedHosts":[%q]}`, server.URL, tt.bucket, server.URL)),
+				DecryptedSecureJSONData: map[string]string{"awsAccessKey": "my-access-key", "awsSecretKey": "my-secret-key"},
+			})
+			require.NoError(t, err)
+			client, err := infinity.NewClient(t.Context(), settings)
+			require.NoError(t, err)
+			got, err := CheckHealth(t.Context(), client, &backend.CheckHealthRequest{})
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestCheckHealthGCS(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "Bearer my-token", r.Header.Get("Authorization"))
+		require.Equal(t, "1", r.URL.Query().Get("maxResults"))
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/storage/v1/b/my-bucket/o":
+			_, _ = w.Write([]byte(`{"items":[{"name":"users.csv","size":"8"}]}`))
+		case "/storage/v1/b/private-bucket/o":
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"error":{"code":403,"message":"infinity@my-project.iam.gserviceaccount.com does not have storage.objects.list access"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":{"code":404,"message":"The specified bucket does not exist."}}`))
+		}
+	}))
+	defer server.Close()
+	serviceAccountKey := getGoogleServiceAccountKey(t)
+	tests := []struct {
+		name   string
+		bucket string
+		want   *backend.CheckHealthResult
+	}{
+		{name: "should pass for accessible bucket", bucket: "my-bucket", want: &backend.CheckHealthResult{Status: backend.HealthStatusOk, Message: "OK"}},
+		{
+			name:   "should fail for missing bucket",
+			bucket: "foo",
+			want: &backend.CheckHealthResult{
+				Status:      backend.HealthStatusError,
+				Message:     "Health check failed",
+				JSONDetails: []byte(`{"verboseMessage":"error connecting to google cloud storage\nbucket not found"}`),
+			},
+		},
+		{
+			name:   "should fail for bucket without access",
+			bucket: "private-bucket",
+			want: &backend.CheckHealthResult{
+				Status:      backend.HealthStatusError,
+				Message:     "Health check failed",
+				JSONDetails: []byte(`{"verboseMessage":"error connecting to google cloud storage\nhttp 403. grant the storage object viewer role on the bucket to the service account"}`),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings, err := models.LoadSettings(t.Context(), backend.DataSourceInstanceSettings{
+				JSONData:                []byte(fmt.Sprintf(`{"auth_method":"google","google":{"healthCheckBucket":%q}}`, tt.bucket)),
+				DecryptedSecureJSONData: map[string]string{"googleServiceAccountKey": serviceAccountKey},
+			})
+			require.NoError(t, err)
+			client, err := infinity.NewClient(t.Context(), settings)
+			require.NoError(t, err)
+			require.NotNil(t, client.GCSClient)
+			client.GCSClient.Endpoint = server.URL
+			got, err := CheckHealth(t.Context(), client, &backend.CheckHealthRequest{})
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
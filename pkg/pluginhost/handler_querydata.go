@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"sync"
 
 	"github.com/grafana/dskit/concurrency"
@@ -14,6 +15,7 @@ import (
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
 
+	"github.com/grafana/grafana-infinity-datasource/pkg/httpclient"
 	"github.com/grafana/grafana-infinity-datasource/pkg/infinity"
 	"github.com/grafana/grafana-infinity-datasource/pkg/models"
 )
@@ -71,12 +73,7 @@ func (ds *DataSource) QueryData(ctx context.Context, req *backend.QueryDataReque
 	// as concurrent query execution breaks the transformation query to be applied correctly
 	if !hasTransformationQuery && hasInfinityRunQueriesInParallel {
 		var m sync.Mutex
-		concurrentQueryCount, err := req.PluginContext.GrafanaConfig.ConcurrentQueryCount()
-		if err != nil {
-			logger.Debug(fmt.Sprintf("Concurrent Query Count read/parse error: %v", err), "infinityRunQueriesInParallel")
-			concurrentQueryCount = 10
-		}
-
+		concurrentQueryCount := infinity.GetConcurrentQueryCount(ctx, &req.PluginContext)
 		_ = concurrency.ForEachJob(ctx, len(marshalledQueries), concurrentQueryCount, func(ctx context.Context, idx int) error {
 			query := marshalledQueries[idx]
 			dataResponse := QueryDataQuery(ctx, req.PluginContext, query, *ds.client, req.Headers)
@@ -130,21 +127,24 @@ func QueryDataQuery(ctx context.Context, pluginContext backend.PluginContext, qu
 	// region Frame Builder
 	switch query.Type {
 	case models.QueryTypeGSheets:
-		sheetId := query.Spreadsheet
-		sheetName := query.SheetName
-		sheetRange := query.SheetRange
-		if sheetName != "" {
-			sheetRange = sheetName + "!" + sheetRange
-		}
-		if sheetId == "" {
+		if strings.TrimSpace(query.Spreadsheet) == "" {
 			return backend.ErrorResponseWithErrorSource(backend.DownstreamError(errors.New("invalid or empty sheet ID")))
 		}
-		query.URL = fmt.Sprintf("https://sheets.googleapis.com/v4/spreadsheets/%s?includeGridData=true&ranges=%s", sheetId, sheetRange)
-		frame, err := infinity.GetFrameForURLSources(ctx, &pluginContext, query, infClient, requestHeaders)
+		// sheets queries with the other authentication methods never required the allowed hosts, so only the google authentication is validated
+		if infClient.Settings.AuthenticationMethod == models.AuthenticationMethodGoogle {
+			if err := infClient.Settings.Validate(); err != nil {
+				response.Error = backend.DownstreamError(err)
+				response.Status = backend.StatusForbidden
+				response.ErrorSource = backend.ErrorSourceDownstream
+				return response
+			}
+		}
+		// each range of the query is returned as a separate frame
+		frames, err := infinity.GetFramesForGoogleSheets(ctx, &pluginContext, query, infClient, requestHeaders)
 		if err != nil {
 			span.RecordError(err)
 			span.SetStatus(500, err.Error())
-			response.Frames = append(response.Frames, frame)
+			response.Frames = append(response.Frames, frames...)
 			wrappedError := fmt.Errorf("%s: %w", "error getting data frame from google sheets", err)
 			response.Error = wrappedError
 			// We should have error source from the original error, but in a case it is not there, we are using the plugin error as the default source
@@ -155,13 +155,11 @@ func QueryDataQuery(ctx context.Context, pluginContext backend.PluginContext, qu
 			}
 			return response
 		}
-		if frame != nil {
-			response.Frames = append(response.Frames, frame)
-		}
+		response.Frames = append(response.Frames, frames...)
 	default:
 		query, _ := infinity.UpdateQueryWithReferenceData(ctx, query, infClient.Settings)
 		switch query.Source {
-		case "url", "azure-blob":
+		case "url", "azure-blob", "s3", "gcs":
 			if err := infClient.Settings.Validate(); err != nil {
 				response.Error = backend.DownstreamError(err)
 				response.Status = backend.StatusForbidden
@@ -194,6 +192,9 @@ func QueryDataQuery(ctx context.Context, pluginContext backend.PluginContext, qu
 					Text: "Datasource is missing allowed hosts/URLs. Configure it in the datasource settings page for enhanced security.",
 				})
 			}
+			if err := httpclient.GetOAuth2RefreshTokenPersistError(infClient.Settings); frame != nil && err != nil {
+				frame.AppendNotices(data.Notice{Severity: data.NoticeSeverityWarning, Text: err.Error()})
+			}
 			if frame != nil {
 				frame, _ = infinity.WrapMetaForRemoteQuery(ctx, infClient.Settings, frame, nil, query)
 				response.Frames = append(response.Frames, frame)
@@ -0,0 +1,50 @@
+package pluginhost
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/grafana/grafana-infinity-datasource/pkg/infinity"
+	"github.com/grafana/grafana-infinity-datasource/pkg/models"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryDataQueryGoogleSheets(t *testing.T) {
+	query := models.Query{RefID: "A", Type: models.QueryTypeGSheets, Spreadsheet: "my-sheet", SheetRange: "A1:B2"}
+	getClient := func(t *testing.T, jsonData string, secureJsonData map[string]string) infinity.Client {
+		t.Helper()
+		settings, err := models.LoadSettings(t.Context(), backend.DataSourceInstanceSettings{JSONData: []byte(jsonData), DecryptedSecureJSONData: secureJsonData})
+		require.NoError(t, err)
+		client, err := infinity.NewClient(t.Context(), settings)
+		require.NoError(t, err)
+		return *client
+	}
+	t.Run("should not require the allowed hosts for the existing api key sheets data sources", func(t *testing.T) {
+		client := getClient(t, `{"auth_method":"apiKey","apiKeyKey":"key","apiKeyType":"query"}`, map[string]string{"apiKeyValue": "my-key"})
+		requested := false
+		client.HttpClient.Transport = roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			requested = true
+			require.Equal(t, "sheets.googleapis.com", req.URL.Host)
+			require.Equal(t, "my-key", req.URL.Query().Get("key"))
+			body := `{"spreadsheetId":"my-sheet","sheets":[{"data":[{"rowData":[{"values":[{"formattedValue":"name"}]},{"values":[{"formattedValue":"foo"}]}]}]}]}`
+			return &http.Response{StatusCode: http.StatusOK, Status: http.StatusText(http.StatusOK), Header: http.Header{"Content-Type": []string{"application/json"}}, Body: io.NopCloser(strings.NewReader(body)), Request: req}, nil
+		})
+		got := QueryDataQuery(t.Context(), backend.PluginContext{}, query, client, map[string]string{})
+		require.NoError(t, got.Error)
+		require.True(t, requested)
+	})
+	t.Run("should validate the google authentication", func(t *testing.T) {
+		client := getClient(t, `{"auth_method":"google","google":{"authType":"apiKey"}}`, map[string]string{})
+		client.HttpClient.Transport = roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			t.Fatal("unexpected request to the sheets api")
+			return nil, nil
+		})
+		got := QueryDataQuery(t.Context(), backend.PluginContext{}, query, client, map[string]string{})
+		require.ErrorIs(t, got.Error, models.ErrInvalidConfigGoogleAPIKey)
+		require.Equal(t, backend.StatusForbidden, got.Status)
+		require.Equal(t, backend.ErrorSourceDownstream, got.ErrorSource)
+	})
+}
@@ -0,0 +1,90 @@
+package pluginhost
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana-infinity-datasource/pkg/infinity"
+	"github.com/grafana/grafana-infinity-datasource/pkg/models"
+)
+
+// streamPathPrefix is the prefix of the live channel paths. The frontend adds the refId and hash of the user login and the query
+// after the prefix, so that identical queries of the same user share the same stream. Streams are not shared between the users
+// as the requests may carry the user's credentials such as the forwarded oauth identity or cookies
+const streamPathPrefix = "stream/"
+
+// SubscribeStream validates the streaming query before Grafana Live starts the stream
+func (ds *DataSource) SubscribeStream(ctx context.Context, req *backend.SubscribeStreamRequest) (*backend.SubscribeStreamResponse, error) {
+	logger := backend.Logger.FromContext(ctx)
+	if ds.client == nil {
+		return nil, backend.PluginError(errors.New("invalid infinity client"))
+	}
+	if !strings.HasPrefix(req.Path, streamPathPrefix) {
+		return &backend.SubscribeStreamResponse{Status: backend.SubscribeStreamStatusNotFound}, nil
+	}
+	if !strings.HasSuffix(req.Path, "/"+getStreamHash(getStreamUserLogin(req.PluginContext), req.Data)) {
+		// the path belongs to another user or a different query
+		logger.Debug("stream path doesn't match the query and the user", "path", req.Path)
+		return &backend.SubscribeStreamResponse{Status: backend.SubscribeStreamStatusNotFound}, nil
+	}
+	query, err := models.LoadQuery(ctx, backend.DataQuery{JSON: req.Data}, req.PluginContext, ds.client.Settings)
+	if err == nil {
+		err = infinity.ValidateStreamingQuery(ctx, &req.PluginContext, ds.client.Settings, query)
+	}
+	if err != nil {
+		logger.Debug("invalid streaming query", "path", req.Path, "error", err.Error())
+		return &backend.SubscribeStreamResponse{Status: backend.SubscribeStreamStatusNotFound}, nil
+	}
+	return &backend.SubscribeStreamResponse{Status: backend.SubscribeStreamStatusOK}, nil
+}
+
+// RunStream is called once per stream by Grafana Live and sends the frames until all the subscribers are gone
+func (ds *DataSource) RunStream(ctx context.Context, req *backend.RunStreamRequest, sender *backend.StreamSender) error {
+	logger := backend.Logger.FromContext(ctx)
+	if ds.client == nil {
+		return backend.PluginError(errors.New("invalid infinity client"))
+	}
+	query, err := models.LoadQuery(ctx, backend.DataQuery{JSON: req.Data}, req.PluginContext, ds.client.Settings)
+	if err != nil {
+		logger.Error("error un-marshaling the streaming query", "error", err.Error())
+		return err
+	}
+	// the settings of the data source may have changed since the subscription was accepted
+	if err := infinity.ValidateStreamingQuery(ctx, &req.PluginContext, ds.client.Settings, query); err != nil {
+		logger.Error("invalid streaming query", "path", req.Path, "error", err.Error())
+		return err
+	}
+	logger.Debug("starting stream", "path", req.Path, "mode", query.StreamMode)
+	return infinity.RunStream(ctx, &req.PluginContext, query, *ds.client, req.Headers, func(frame *data.Frame) error {
+		return sender.SendFrame(frame, data.IncludeAll)
+	})
+}
+
+func getStreamUserLogin(pCtx backend.PluginContext) string {
+	if pCtx.User == nil {
+		return ""
+	}
+	return pCtx.User.Login
+}
+
+// getStreamHash returns the 32-bit FNV-1a hash of the user login and the query json. Same as getStreamPath of the frontend,
+// the hash is computed over the UTF-16 code units
+func getStreamHash(login string, data []byte) string {
+	hash := uint32(0x811c9dc5)
+	for _, unit := range utf16.Encode([]rune(login + "\n" + string(data))) {
+		hash ^= uint32(unit)
+		hash *= 0x01000193
+	}
+	return strconv.FormatUint(uint64(hash), 16)
+}
+
+// PublishStream is not supported as the streams are read only
+func (ds *DataSource) PublishStream(ctx context.Context, req *backend.PublishStreamRequest) (*backend.PublishStreamResponse, error) {
+	return &backend.PublishStreamResponse{Status: backend.PublishStreamStatusPermissionDenied}, nil
+}
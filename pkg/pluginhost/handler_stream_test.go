@@ -0,0 +1,76 @@
+package pluginhost
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grafana/grafana-infinity-datasource/pkg/infinity"
+	"github.com/grafana/grafana-infinity-datasource/pkg/models"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubscribeStream(t *testing.T) {
+	ds := &DataSource{client: &infinity.Client{Settings: models.InfinitySettings{AllowedHosts: []string{"https://allowed.com"}}}}
+	admin := backend.PluginContext{User: &backend.User{Login: "admin"}}
+	validQuery := `{"refId":"A","type":"json","source":"url","parser":"backend","url":"https://allowed.com/events","stream_mode":"sse"}`
+	getPath := func(login string, data string) string {
+		return "stream/A/" + getStreamHash(login, []byte(data))
+	}
+	tests := []struct {
+		name string
+		path string
+		data string
+		want backend.SubscribeStreamStatus
+	}{
+		{name: "valid streaming query", path: getPath("admin", validQuery), data: validQuery, want: backend.SubscribeStreamStatusOK},
+		{name: "unknown path", path: "foo/A", data: validQuery, want: backend.SubscribeStreamStatusNotFound},
+		{name: "path of another query", path: getPath("admin", `{"refId":"A"}`), data: validQuery, want: backend.SubscribeStreamStatusNotFound},
+		{name: "path of another user", path: getPath("viewer", validQuery), data: validQuery, want: backend.SubscribeStreamStatusNotFound},
+		{name: "not a streaming query", path: getPath("admin", `{"type":"json","source":"url","parser":"backend","url":"https://allowed.com/events"}`), data: `{"type":"json","source":"url","parser":"backend","url":"https://allowed.com/events"}`, want: backend.SubscribeStreamStatusNotFound},
+		{name: "host not allowed", path: getPath("admin", `{"type":"json","source":"url","parser":"backend","url":"https://foo.com/events","stream_mode":"polling"}`), data: `{"type":"json","source":"url","parser":"backend","url":"https://foo.com/events","stream_mode":"polling"}`, want: backend.SubscribeStreamStatusNotFound},
+		{name: "invalid query", path: getPath("admin", `{`), data: `{`, want: backend.SubscribeStreamStatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := ds.SubscribeStream(context.Background(), &backend.SubscribeStreamRequest{PluginContext: admin, Path: tt.path, Data: []byte(tt.data)})
+			require.NoError(t, err)
+			require.Equal(t, tt.want, res.Status)
+		})
+	}
+	t.Run("users with the same query should not share the stream", func(t *testing.T) {
+		ds := &DataSource{client: &infinity.Client{Settings: models.InfinitySettings{AllowedHosts: []string{"https://allowed.com"}, ForwardOauthIdentity: true}}}
+		path := getPath("admin", validQuery)
+		res, err := ds.SubscribeStream(context.Background(), &backend.SubscribeStreamRequest{PluginContext: admin, Path: path, Data: []byte(validQuery)})
+		require.NoError(t, err)
+		require.Equal(t, backend.SubscribeStreamStatusOK, res.Status)
+		viewer := backend.PluginContext{User: &backend.User{Login: "viewer"}}
+		res, err = ds.SubscribeStream(context.Background(), &backend.SubscribeStreamRequest{PluginContext: viewer, Path: path, Data: []byte(validQuery)})
+		require.NoError(t, err)
+		require.Equal(t, backend.SubscribeStreamStatusNotFound, res.Status)
+		res, err = ds.SubscribeStream(context.Background(), &backend.SubscribeStreamRequest{PluginContext: viewer, Path: getPath("viewer", validQuery), Data: []byte(validQuery)})
+		require.NoError(t, err)
+		require.Equal(t, backend.SubscribeStreamStatusOK, res.Status)
+		require.NotEqual(t, path, getPath("viewer", validQuery))
+	})
+	t.Run("should not run the stream denied by the security settings", func(t *testing.T) {
+		ds := &DataSource{client: &infinity.Client{Settings: models.InfinitySettings{AllowedHosts: []string{"https://allowed.com"}, UnsecuredQueryHandling: models.UnsecuredQueryHandlingDeny}}}
+		query := `{"refId":"A","type":"json","source":"url","parser":"backend","url":"https://allowed.com/events","stream_mode":"sse","url_options":{"headers":[{"key":"Authorization","value":"foo"}]}}`
+		res, err := ds.SubscribeStream(context.Background(), &backend.SubscribeStreamRequest{PluginContext: admin, Path: getPath("admin", query), Data: []byte(query)})
+		require.NoError(t, err)
+		require.Equal(t, backend.SubscribeStreamStatusNotFound, res.Status)
+		err = ds.RunStream(context.Background(), &backend.RunStreamRequest{PluginContext: admin, Path: getPath("admin", query), Data: []byte(query)}, nil)
+		require.Error(t, err)
+	})
+	res, err := ds.PublishStream(context.Background(), &backend.PublishStreamRequest{Path: "stream/A/1234"})
+	require.NoError(t, err)
+	require.Equal(t, backend.PublishStreamStatusPermissionDenied, res.Status)
+}
+
+func TestGetStreamHash(t *testing.T) {
+	// same values are used in the tests of getStreamPath in the frontend
+	query := `{"refId":"A","type":"json","source":"url","url":"https://allowed.com/events","stream_mode":"sse"}`
+	require.Equal(t, "23f14445", getStreamHash("admin", []byte(query)))
+	require.Equal(t, "43cb0632", getStreamHash("viewer", []byte(query)))
+	require.Equal(t, "aea77c2f", getStreamHash("admin", []byte(`{"refId":"A","url":"https://allowed.com/ü"}`)))
+}
@@ -15,6 +15,7 @@ var (
 	_ backend.QueryDataHandler      = (*DataSource)(nil)
 	_ backend.CheckHealthHandler    = (*DataSource)(nil)
 	_ backend.CallResourceHandler   = (*DataSource)(nil)
+	_ backend.StreamHandler         = (*DataSource)(nil)
 	_ instancemgmt.InstanceDisposer = (*DataSource)(nil)
 )
 
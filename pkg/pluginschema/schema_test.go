@@ -139,6 +139,10 @@ func TestSecureValuesMatchLoadSettings(t *testing.T) {
 		"apiKeyValue", "basicAuthPassword", "oauth2ClientSecret", "oauth2JWTPrivateKey",
 		"tlsCACert", "tlsClientCert", "tlsClientKey", "bearerToken",
 		"awsAccessKey", "awsSecretKey", "azureBlobAccountKey", "proxyUserPassword",
+		"googleServiceAccountKey", "googleApiKey", "azureBlobClientSecret", "azureBlobSASToken",
+		"oauth2RefreshToken",
+		"tokenExchangeClientSecret",
+		"azureADClientSecret", "azureADClientCertificate",
 	}
 
 	sort.Strings(schemaKeys)
@@ -0,0 +1,585 @@
+package uql
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"strings"
+
+	jsonata "github.com/xiatechs/jsonata-go"
+)
+
+// mapRows applies the function to each row. Single objects stay single objects
+func mapRows(data any, fn func(row any) (any, error)) (any, error) {
+	if arr, ok := data.([]any); ok {
+		out := make([]any, 0, len(arr))
+		for _, row := range arr {
+			item, err := fn(row)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, item)
+		}
+		return out, nil
+	}
+	return fn(data)
+}
+
+func copyRow(row any) map[string]any {
+	out := map[string]any{}
+	if obj, ok := row.(map[string]any); ok {
+		for k, v := range obj {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+func project(s *state, p *parser) error {
+	items, err := p.parseAssignments()
+	if err != nil {
+		return err
+	}
+	data, err := mapRows(s.data, func(row any) (any, error) {
+		out := map[string]any{}
+		for _, item := range items {
+			v, err := item.value.eval(row)
+			if err != nil {
+				return nil, err
+			}
+			out[item.alias] = v
+		}
+		return out, nil
+	})
+	if err != nil {
+		return err
+	}
+	s.data = data
+	s.order = nil
+	for _, item := range items {
+		s.addColumn(item.alias)
+	}
+	return nil
+}
+
+func projectKV(s *state, p *parser) error {
+	p.pos++
+	args, err := p.parseList("(", ")")
+	if err != nil {
+		return err
+	}
+	input := s.data
+	if len(args) > 0 {
+		if input, err = args[0].eval(s.data); err != nil {
+			return err
+		}
+	}
+	out := []any{}
+	if obj, ok := input.(map[string]any); ok {
+		for _, key := range sortKeys(mapKeys(obj), s.order) {
+			out = append(out, map[string]any{"key": key, "value": obj[key]})
+		}
+	}
+	s.data = out
+	s.order = []string{"key", "value"}
+	return nil
+}
+
+func projectAway(s *state, p *parser) error {
+	fields, err := p.parseFieldNames()
+	if err != nil {
+		return err
+	}
+	data, err := mapRows(s.data, func(row any) (any, error) {
+		out := copyRow(row)
+		for _, field := range fields {
+			delete(out, field)
+		}
+		return out, nil
+	})
+	if err != nil {
+		return err
+	}
+	s.data = data
+	s.order = slices.DeleteFunc(s.order, func(item string) bool { return slices.Contains(fields, item) })
+	return nil
+}
+
+func extend(s *state, p *parser) error {
+	items, err := p.parseAssignments()
+	if err != nil {
+		return err
+	}
+	data, err := mapRows(s.data, func(row any) (any, error) {
+		out := copyRow(row)
+		for _, item := range items {
+			// evaluate against the extended row so that chained extends can refer to earlier items
+			v, err := item.value.eval(out)
+			if err != nil {
+				return nil, err
+			}
+			out[item.alias] = v
+		}
+		return out, nil
+	})
+	if err != nil {
+		return err
+	}
+	s.data = data
+	for _, item := range items {
+		s.addColumn(item.alias)
+	}
+	return nil
+}
+
+func where(s *state, p *parser) error {
+	condition, err := p.parseCondition()
+	if err != nil {
+		return err
+	}
+	if !p.done() {
+		return p.errorf("unexpected token")
+	}
+	out := []any{}
+	for _, row := range rows(s.data) {
+		v, err := condition.eval(row)
+		if err != nil {
+			return err
+		}
+		if truthy(v) {
+			out = append(out, row)
+		}
+	}
+	s.data = out
+	return nil
+}
+
+func orderBy(s *state, p *parser) error {
+	type sortField struct {
+		field string
+		desc  bool
+	}
+	fields := []sortField{}
+	for !p.done() {
+		t, _ := p.next()
+		if t.kind != tokenField {
+			p.pos--
+			return p.errorf("expected field name")
+		}
+		f := sortField{field: t.value}
+		if p.peekIs(tokenIdent, "desc") || p.peekIs(tokenIdent, "asc") {
+			next, _ := p.next()
+			f.desc = strings.EqualFold(next.value, "desc")
+		}
+		fields = append(fields, f)
+		if !p.peekIs(tokenPunct, ",") {
+			break
+		}
+		p.pos++
+	}
+	if !p.done() {
+		return p.errorf("unexpected token")
+	}
+	out := slices.Clone(rows(s.data))
+	slices.SortStableFunc(out, func(a, b any) int {
+		for _, f := range fields {
+			av, bv := getPath(a, f.field), getPath(b, f.field)
+			var c int
+			switch {
+			case av == nil && bv == nil:
+				continue
+			case av == nil:
+				return 1
+			case bv == nil:
+				return -1
+			default:
+				c, _ = compare(av, bv)
+			}
+			if f.desc {
+				c = -c
+			}
+			if c != 0 {
+				return c
+			}
+		}
+		return 0
+	})
+	s.data = out
+	return nil
+}
+
+func limit(s *state, p *parser) error {
+	t, ok := p.next()
+	if !ok || t.kind != tokenNumber {
+		return p.errorf("limit expects a number")
+	}
+	n, err := strconv.Atoi(t.value)
+	if err != nil || n < 0 {
+		return fmt.Errorf("%w: invalid limit %s", ErrInvalidQuery, t.value)
+	}
+	data := rows(s.data)
+	s.data = data[:min(n, len(data))]
+	return nil
+}
+
+func scope(s *state, p *parser) error {
+	t, ok := p.next()
+	if !ok || t.kind != tokenField {
+		return p.errorf("scope expects a field name")
+	}
+	s.data = getPath(s.data, t.value)
+	return nil
+}
+
+func mvExpand(s *state, p *parser) error {
+	items, err := p.parseAssignments()
+	if err != nil {
+		return err
+	}
+	if len(items) != 1 {
+		return fmt.Errorf("%w: mv-expand expects a single column", ErrInvalidQuery)
+	}
+	item := items[0]
+	source := ""
+	if f, ok := item.value.(*fieldExpr); ok {
+		source = f.path
+	}
+	out := []any{}
+	for _, row := range rows(s.data) {
+		v, err := item.value.eval(row)
+		if err != nil {
+			return err
+		}
+		values, ok := v.([]any)
+		if !ok {
+			values = []any{v}
+		}
+		for _, value := range values {
+			expanded := copyRow(row)
+			if source != item.alias {
+				delete(expanded, source)
+			}
+			expanded[item.alias] = value
+			out = append(out, expanded)
+		}
+	}
+	s.data = out
+	if source != item.alias {
+		for i, col := range s.order {
+			if col == source {
+				s.order[i] = item.alias
+			}
+		}
+	}
+	s.addColumn(item.alias)
+	return nil
+}
+
+func runJSONata(s *state, p *parser) error {
+	t, ok := p.next()
+	if !ok || (t.kind != tokenField && t.kind != tokenString) {
+		return p.errorf("jsonata expects an expression")
+	}
+	e, err := jsonata.Compile(t.value)
+	if err != nil {
+		return fmt.Errorf("%w: invalid jsonata expression. %w", ErrInvalidQuery, err)
+	}
+	result, err := e.Eval(s.data)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidQuery, err)
+	}
+	// normalize the jsonata output into plain json values
+	b, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	s.data = nil
+	return json.Unmarshal(b, &s.data)
+}
+
+type aggregation struct {
+	alias string
+	name  string
+	args  []expr
+}
+
+func (p *parser) parseAggregation() (aggregation, error) {
+	agg := aggregation{}
+	if t, ok := p.peek(); ok && t.kind == tokenField && p.pos+1 < len(p.tokens) && p.tokens[p.pos+1].is(tokenPunct, "=") {
+		agg.alias = t.value
+		p.pos += 2
+	}
+	t, ok := p.next()
+	if !ok || t.kind != tokenIdent {
+		p.pos--
+		return agg, p.errorf("expected aggregation function")
+	}
+	agg.name = strings.ToLower(t.value)
+	if agg.alias == "" {
+		agg.alias = agg.name
+	}
+	args, err := p.parseList("(", ")")
+	if err != nil {
+		return agg, err
+	}
+	agg.args = args
+	return agg, nil
+}
+
+func summarize(s *state, p *parser) error {
+	aggs := []aggregation{}
+	for !p.done() && !p.peekIs(tokenIdent, "by") {
+		agg, err := p.parseAggregation()
+		if err != nil {
+			return err
+		}
+		aggs = append(aggs, agg)
+		if !p.peekIs(tokenPunct, ",") {
+			break
+		}
+		p.pos++
+	}
+	by := []string{}
+	if p.peekIs(tokenIdent, "by") {
+		p.pos++
+		fields, err := p.parseFieldNames()
+		if err != nil {
+			return err
+		}
+		by = fields
+	}
+	if !p.done() {
+		return p.errorf("unexpected token")
+	}
+	keys, groups := groupRows(rows(s.data), by)
+	out := []any{}
+	for _, key := range keys {
+		group := groups[key]
+		row := map[string]any{}
+		for _, field := range by {
+			row[field] = getPath(group[0], field)
+		}
+		for _, agg := range aggs {
+			v, err := aggregate(agg, group)
+			if err != nil {
+				return err
+			}
+			row[agg.alias] = v
+		}
+		out = append(out, row)
+	}
+	s.data = out
+	s.order = nil
+	for _, field := range by {
+		s.addColumn(field)
+	}
+	for _, agg := range aggs {
+		s.addColumn(agg.alias)
+	}
+	return nil
+}
+
+func pivot(s *state, p *parser) error {
+	agg, err := p.parseAggregation()
+	if err != nil {
+		return err
+	}
+	if err := p.expect(tokenPunct, ","); err != nil {
+		return err
+	}
+	fields, err := p.parseFieldNames()
+	if err != nil {
+		return err
+	}
+	if len(fields) != 2 || !p.done() {
+		return fmt.Errorf("%w: pivot expects an aggregation, a row field and a column field", ErrInvalidQuery)
+	}
+	rowField, colField := fields[0], fields[1]
+	data := rows(s.data)
+	rowKeys, rowGroups := groupRows(data, []string{rowField})
+	colKeys, colGroups := groupRows(data, []string{colField})
+	out := []any{}
+	order := []string{rowField}
+	for _, colKey := range colKeys {
+		order = append(order, toString(getPath(colGroups[colKey][0], colField)))
+	}
+	for _, rowKey := range rowKeys {
+		group := rowGroups[rowKey]
+		row := map[string]any{rowField: getPath(group[0], rowField)}
+		for i, colKey := range colKeys {
+			matching := []any{}
+			for _, item := range group {
+				if groupKey(item, []string{colField}) == colKey {
+					matching = append(matching, item)
+				}
+			}
+			v, err := aggregate(agg, matching)
+			if err != nil {
+				return err
+			}
+			if v == nil {
+				v = float64(0)
+			}
+			row[order[i+1]] = v
+		}
+		out = append(out, row)
+	}
+	s.data = out
+	s.order = order
+	return nil
+}
+
+func groupKey(row any, by []string) string {
+	values := make([]string, len(by))
+	for i, field := range by {
+		values[i] = toString(getPath(row, field))
+	}
+	return strings.Join(values, "\x00")
+}
+
+// groupRows groups the rows by the given fields and returns the group keys in the order they first appear
+func groupRows(data []any, by []string) ([]string, map[string][]any) {
+	keys := []string{}
+	groups := map[string][]any{}
+	for _, row := range data {
+		key := groupKey(row, by)
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], row)
+	}
+	return keys, groups
+}
+
+func aggregate(agg aggregation, group []any) (any, error) {
+	values := make([]any, 0, len(group))
+	if len(agg.args) > 0 {
+		for _, row := range group {
+			v, err := agg.args[0].eval(row)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, v)
+		}
+	}
+	name := agg.name
+	if base, ok := strings.CutSuffix(name, "if"); ok && base != "" {
+		if len(agg.args) < 2 {
+			return nil, fmt.Errorf("%w: %s expects a field and a condition", ErrInvalidQuery, name)
+		}
+		cond, err := parseInlineCondition(agg.args[1])
+		if err != nil {
+			return nil, err
+		}
+		values = slices.DeleteFunc(values, func(v any) bool { return !cond(v) })
+		name = base
+	}
+	switch name {
+	case "count":
+		if len(agg.args) > 0 {
+			return float64(len(values)), nil
+		}
+		return float64(len(group)), nil
+	case "sum":
+		total := 0.0
+		for _, v := range values {
+			if n, ok := toNumber(v); ok && v != nil {
+				total += n
+			}
+		}
+		return total, nil
+	case "mean":
+		total, count := 0.0, 0
+		for _, v := range values {
+			if n, ok := toNumber(v); ok && v != nil {
+				total += n
+				count++
+			}
+		}
+		if count == 0 {
+			return nil, nil
+		}
+		return total / float64(count), nil
+	case "min", "max":
+		var out any
+		for _, v := range values {
+			n, ok := toNumber(v)
+			if !ok || v == nil {
+				continue
+			}
+			current, _ := out.(float64)
+			if out == nil || (name == "min" && n < current) || (name == "max" && n > current) {
+				out = n
+			}
+		}
+		return out, nil
+	case "first":
+		if len(values) == 0 {
+			return nil, nil
+		}
+		return values[0], nil
+	case "last":
+		if len(values) == 0 {
+			return nil, nil
+		}
+		return values[len(values)-1], nil
+	case "latest":
+		for i := len(values) - 1; i >= 0; i-- {
+			if values[i] != nil {
+				return values[i], nil
+			}
+		}
+		return nil, nil
+	case "random":
+		if len(values) == 0 {
+			return nil, nil
+		}
+		return values[rand.IntN(len(values))], nil
+	case "dcount", "distinct":
+		seen := map[string]bool{}
+		distinct := []any{}
+		for _, v := range values {
+			key := toString(v)
+			if !seen[key] {
+				seen[key] = true
+				distinct = append(distinct, v)
+			}
+		}
+		if name == "dcount" {
+			return float64(len(distinct)), nil
+		}
+		return distinct, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnknownFunction, agg.name)
+}
+
+// parseInlineCondition parses the conditions used by the *if aggregations such as "> 18" or "!= null"
+func parseInlineCondition(e expr) (func(v any) bool, error) {
+	raw := ""
+	switch c := e.(type) {
+	case *fieldExpr:
+		raw = c.path
+	case *literalExpr:
+		raw = toString(c.value)
+	default:
+		return nil, fmt.Errorf("%w: invalid condition", ErrInvalidQuery)
+	}
+	raw = strings.TrimSpace(raw)
+	for _, op := range []string{"==", "!=", ">=", "<=", ">", "<"} {
+		operand, ok := strings.CutPrefix(raw, op)
+		if !ok {
+			continue
+		}
+		operand = strings.Trim(strings.TrimSpace(operand), `'"`)
+		var target any = operand
+		if operand == "null" {
+			target = nil
+		} else if n, err := strconv.ParseFloat(operand, 64); err == nil {
+			target = n
+		}
+		return func(v any) bool { return compareValues(op, v, target) }, nil
+	}
+	return nil, fmt.Errorf("%w: invalid condition %s", ErrInvalidQuery, raw)
+}
@@ -0,0 +1,271 @@
+package uql
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type expr interface {
+	eval(row any) (any, error)
+}
+
+type fieldExpr struct{ path string }
+
+func (e *fieldExpr) eval(row any) (any, error) { return getPath(row, e.path), nil }
+
+type literalExpr struct{ value any }
+
+func (e *literalExpr) eval(_ any) (any, error) { return e.value, nil }
+
+type arrayExpr struct{ items []expr }
+
+func (e *arrayExpr) eval(row any) (any, error) {
+	out := []any{}
+	for _, item := range e.items {
+		v, err := item.eval(row)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, v)
+	}
+	return out, nil
+}
+
+type callExpr struct {
+	name string
+	args []expr
+}
+
+func (e *callExpr) eval(row any) (any, error) {
+	fn, ok := functions[e.name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownFunction, e.name)
+	}
+	args := make([]any, len(e.args))
+	for i, arg := range e.args {
+		v, err := arg.eval(row)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = v
+	}
+	return fn(args)
+}
+
+type logicalExpr struct {
+	op          string
+	left, right expr
+}
+
+func (e *logicalExpr) eval(row any) (any, error) {
+	l, err := e.left.eval(row)
+	if err != nil {
+		return nil, err
+	}
+	if e.op == "and" && !truthy(l) {
+		return false, nil
+	}
+	if e.op == "or" && truthy(l) {
+		return true, nil
+	}
+	r, err := e.right.eval(row)
+	if err != nil {
+		return nil, err
+	}
+	return truthy(r), nil
+}
+
+type compareExpr struct {
+	op          string
+	left, right expr
+}
+
+func (e *compareExpr) eval(row any) (any, error) {
+	l, err := e.left.eval(row)
+	if err != nil {
+		return nil, err
+	}
+	r, err := e.right.eval(row)
+	if err != nil {
+		return nil, err
+	}
+	return compareValues(e.op, l, r), nil
+}
+
+type inExpr struct {
+	left   expr
+	items  []expr
+	negate bool
+}
+
+func (e *inExpr) eval(row any) (any, error) {
+	l, err := e.left.eval(row)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range e.items {
+		v, err := item.eval(row)
+		if err != nil {
+			return nil, err
+		}
+		if compareValues("==", l, v) {
+			return !e.negate, nil
+		}
+	}
+	return e.negate, nil
+}
+
+// compareValues compares two values the loose way javascript does, numbers are compared numerically when both sides are numeric
+func compareValues(op string, l, r any) bool {
+	if l == nil || r == nil {
+		switch op {
+		case "==":
+			return l == nil && r == nil
+		case "!=":
+			return !(l == nil && r == nil)
+		}
+		return false
+	}
+	c, ok := compare(l, r)
+	if !ok {
+		return op == "!="
+	}
+	switch op {
+	case "==":
+		return c == 0
+	case "!=":
+		return c != 0
+	case ">":
+		return c > 0
+	case ">=":
+		return c >= 0
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	}
+	return false
+}
+
+// compare returns -1, 0 or 1. The second return value is false when the values are not comparable
+func compare(l, r any) (int, bool) {
+	if lt, ok := l.(time.Time); ok {
+		if rt, ok := toTime(r); ok {
+			return cmp(lt.UnixNano(), rt.UnixNano()), true
+		}
+	}
+	if lb, ok := l.(bool); ok {
+		if rb, ok := r.(bool); ok {
+			return cmp(boolToInt(lb), boolToInt(rb)), true
+		}
+	}
+	if ln, ok := toNumber(l); ok {
+		if rn, ok := toNumber(r); ok {
+			return cmp(ln, rn), true
+		}
+	}
+	return strings.Compare(toString(l), toString(r)), true
+}
+
+func cmp[T int | int64 | float64](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func truthy(v any) bool {
+	switch x := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return x
+	case float64:
+		return x != 0
+	case string:
+		return x != ""
+	}
+	return true
+}
+
+// getPath returns the value for the given dotted path. Keys containing dots are matched as-is before walking the path
+func getPath(input any, path string) any {
+	if obj, ok := input.(map[string]any); ok {
+		if v, ok := obj[path]; ok {
+			return v
+		}
+	}
+	current := input
+	for _, part := range strings.Split(path, ".") {
+		switch c := current.(type) {
+		case map[string]any:
+			current = c[part]
+		case []any:
+			idx, err := strconv.Atoi(part)
+			if err != nil || idx < 0 || idx >= len(c) {
+				return nil
+			}
+			current = c[idx]
+		default:
+			return nil
+		}
+	}
+	return current
+}
+
+func toNumber(v any) (float64, bool) {
+	switch x := v.(type) {
+	case float64:
+		return x, true
+	case int:
+		return float64(x), true
+	case int64:
+		return float64(x), true
+	case bool:
+		return float64(boolToInt(x)), true
+	case json.Number:
+		f, err := x.Float64()
+		return f, err == nil
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(x), 64)
+		return f, err == nil
+	case time.Time:
+		return float64(x.UnixMilli()), true
+	}
+	return 0, false
+}
+
+func toString(v any) string {
+	switch x := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return x
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(x)
+	case time.Time:
+		return x.UTC().Format(time.RFC3339Nano)
+	case map[string]any, []any:
+		b, err := json.Marshal(x)
+		if err != nil {
+			return ""
+		}
+		return string(b)
+	}
+	return fmt.Sprintf("%v", v)
+}
@@ -0,0 +1,165 @@
+package uql
+
+import (
+	"encoding/json"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// Result is the output of a UQL query
+type Result struct {
+	Data any
+	// Columns holds the preferred column order
+	Columns []string
+}
+
+// ToFrame converts the result into a data frame. Objects become rows, arrays and nested objects become json fields.
+// Scalar results are returned as a single field named "result"
+func (r *Result) ToFrame(name string) *data.Frame {
+	frame := data.NewFrame(name)
+	items := rows(r.Data)
+	columns := []string{}
+	seen := map[string]bool{}
+	scalar := false
+	for _, item := range items {
+		obj, ok := item.(map[string]any)
+		if !ok {
+			scalar = true
+			continue
+		}
+		for key := range obj {
+			if !seen[key] {
+				seen[key] = true
+				columns = append(columns, key)
+			}
+		}
+	}
+	if scalar {
+		values := make([]any, len(items))
+		copy(values, items)
+		frame.Fields = append(frame.Fields, newField("result", values))
+		return frame
+	}
+	for _, column := range sortKeys(columns, r.Columns) {
+		values := make([]any, len(items))
+		for i, item := range items {
+			values[i] = item.(map[string]any)[column]
+		}
+		frame.Fields = append(frame.Fields, newField(column, values))
+	}
+	return frame
+}
+
+func mapKeys(obj map[string]any) []string {
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// sortKeys sorts the keys by their position in order. Keys not listed in order are sorted alphabetically at the end
+func sortKeys(keys []string, order []string) []string {
+	out := slices.Clone(keys)
+	position := map[string]int{}
+	for i, key := range order {
+		if _, ok := position[key]; !ok {
+			position[key] = i
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		pi, iok := position[out[i]]
+		pj, jok := position[out[j]]
+		switch {
+		case iok && jok:
+			return pi < pj
+		case iok != jok:
+			return iok
+		}
+		return out[i] < out[j]
+	})
+	return out
+}
+
+// newField creates a nullable field with the type inferred from the values. Mixed types are converted to strings
+func newField(name string, values []any) *data.Field {
+	kind := ""
+	for _, v := range values {
+		k := valueKind(v)
+		if k == "" {
+			continue
+		}
+		if kind != "" && kind != k {
+			kind = "mixed"
+			break
+		}
+		kind = k
+	}
+	switch kind {
+	case "number":
+		out := make([]*float64, len(values))
+		for i, v := range values {
+			if n, ok := v.(float64); ok {
+				out[i] = &n
+			}
+		}
+		return data.NewField(name, nil, out)
+	case "bool":
+		out := make([]*bool, len(values))
+		for i, v := range values {
+			if b, ok := v.(bool); ok {
+				out[i] = &b
+			}
+		}
+		return data.NewField(name, nil, out)
+	case "time":
+		out := make([]*time.Time, len(values))
+		for i, v := range values {
+			if t, ok := v.(time.Time); ok {
+				out[i] = &t
+			}
+		}
+		return data.NewField(name, nil, out)
+	case "json":
+		out := make([]*json.RawMessage, len(values))
+		for i, v := range values {
+			if v == nil {
+				continue
+			}
+			if b, err := json.Marshal(v); err == nil {
+				msg := json.RawMessage(b)
+				out[i] = &msg
+			}
+		}
+		return data.NewField(name, nil, out)
+	}
+	out := make([]*string, len(values))
+	for i, v := range values {
+		if v != nil {
+			s := toString(v)
+			out[i] = &s
+		}
+	}
+	return data.NewField(name, nil, out)
+}
+
+func valueKind(v any) string {
+	switch v.(type) {
+	case nil:
+		return ""
+	case float64:
+		return "number"
+	case bool:
+		return "bool"
+	case time.Time:
+		return "time"
+	case string:
+		return "string"
+	case map[string]any, []any:
+		return "json"
+	}
+	return "string"
+}
@@ -0,0 +1,622 @@
+package uql
+
+import (
+	"encoding/base64"
+	"fmt"
+	"math"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type function func(args []any) (any, error)
+
+var functions map[string]function
+
+func init() {
+	functions = map[string]function{
+		// string functions
+		"toupper":        stringFn(strings.ToUpper),
+		"tolower":        stringFn(strings.ToLower),
+		"trim":           stringFn(strings.TrimSpace),
+		"trim_start":     stringFn(func(s string) string { return strings.TrimLeft(s, " \t\r\n") }),
+		"trim_end":       stringFn(func(s string) string { return strings.TrimRight(s, " \t\r\n") }),
+		"reverse":        stringFn(reverseString),
+		"strlen":         fnStrlen,
+		"strcat":         fnStrcat,
+		"substring":      fnSubstring,
+		"split":          fnSplit,
+		"replace_string": fnReplaceString,
+		"extract":        fnExtract,
+		// type conversion functions
+		"tonumber": fnToNumber,
+		"todouble": fnToNumber,
+		"tofloat":  fnToNumber,
+		"toint":    fnToInt,
+		"tolong":   fnToInt,
+		"tostring": fnToString,
+		"tobool":   fnToBool,
+		// datetime functions
+		"todatetime":                       fnToDatetime,
+		"tounixtime":                       fnToUnixTime,
+		"format_datetime":                  fnFormatDatetime,
+		"add_datetime":                     fnAddDatetime,
+		"unixtime_seconds_todatetime":      unixTimeFn(time.Second),
+		"unixtime_milliseconds_todatetime": unixTimeFn(time.Millisecond),
+		"unixtime_microseconds_todatetime": unixTimeFn(time.Microsecond),
+		"unixtime_nanoseconds_todatetime":  unixTimeFn(time.Nanosecond),
+		"startofminute":                    startOfFn("minute"),
+		"startofhour":                      startOfFn("hour"),
+		"startofday":                       startOfFn("day"),
+		"startofweek":                      startOfFn("week"),
+		"startofmonth":                     startOfFn("month"),
+		"startofyear":                      startOfFn("year"),
+		// math functions
+		"sum":        fnSum,
+		"diff":       fnDiff,
+		"mul":        fnMul,
+		"div":        fnDiv,
+		"percentage": fnPercentage,
+		"floor":      mathFn(math.Floor),
+		"ceil":       mathFn(math.Ceil),
+		"round":      mathFn(func(f float64) float64 { return math.Floor(f + 0.5) }),
+		"sign":       mathFn(sign),
+		"log":        mathFn(math.Log),
+		"log2":       mathFn(math.Log2),
+		"log10":      mathFn(math.Log10),
+		"sin":        mathFn(math.Sin),
+		"cos":        mathFn(math.Cos),
+		"tan":        mathFn(math.Tan),
+		"pow":        fnPow,
+		// url functions
+		"parse_url":      fnParseURL,
+		"parse_urlquery": fnParseURLQuery,
+		// encoding functions
+		"atob": fnAtob,
+		"btoa": fnBtoa,
+		// array/object functions
+		"pack":               fnPack,
+		"array_from_entries": fnArrayFromEntries,
+		"array_to_map":       fnArrayToMap,
+	}
+}
+
+func arg(args []any, idx int) any {
+	if idx < len(args) {
+		return args[idx]
+	}
+	return nil
+}
+
+func stringFn(fn func(string) string) function {
+	return func(args []any) (any, error) {
+		v := arg(args, 0)
+		if v == nil {
+			return nil, nil
+		}
+		return fn(toString(v)), nil
+	}
+}
+
+func mathFn(fn func(float64) float64) function {
+	return func(args []any) (any, error) {
+		n, ok := toNumber(arg(args, 0))
+		if !ok {
+			return nil, nil
+		}
+		return numberOrNil(fn(n)), nil
+	}
+}
+
+func numberOrNil(f float64) any {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil
+	}
+	return f
+}
+
+func reverseString(s string) string {
+	r := []rune(s)
+	for i, j := 0, len(r)-1; i < j; i, j = i+1, j-1 {
+		r[i], r[j] = r[j], r[i]
+	}
+	return string(r)
+}
+
+func sign(f float64) float64 {
+	switch {
+	case f > 0:
+		return 1
+	case f < 0:
+		return -1
+	}
+	return 0
+}
+
+func fnStrlen(args []any) (any, error) {
+	return float64(len([]rune(toString(arg(args, 0))))), nil
+}
+
+func fnStrcat(args []any) (any, error) {
+	var sb strings.Builder
+	for _, a := range args {
+		sb.WriteString(toString(a))
+	}
+	return sb.String(), nil
+}
+
+func fnSubstring(args []any) (any, error) {
+	r := []rune(toString(arg(args, 0)))
+	start, _ := toNumber(arg(args, 1))
+	from := min(max(int(start), 0), len(r))
+	to := len(r)
+	if length, ok := toNumber(arg(args, 2)); ok {
+		to = min(max(from+int(length), from), len(r))
+	}
+	return string(r[from:to]), nil
+}
+
+func fnSplit(args []any) (any, error) {
+	out := []any{}
+	for _, item := range strings.Split(toString(arg(args, 0)), toString(arg(args, 1))) {
+		out = append(out, item)
+	}
+	return out, nil
+}
+
+func fnReplaceString(args []any) (any, error) {
+	return strings.ReplaceAll(toString(arg(args, 0)), toString(arg(args, 1)), toString(arg(args, 2))), nil
+}
+
+func fnExtract(args []any) (any, error) {
+	rgx, err := regexp.Compile(toString(arg(args, 0)))
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid regular expression. %w", ErrInvalidQuery, err)
+	}
+	idx, _ := toNumber(arg(args, 1))
+	matches := rgx.FindStringSubmatch(toString(arg(args, 2)))
+	if int(idx) < 0 || int(idx) >= len(matches) {
+		return nil, nil
+	}
+	return matches[int(idx)], nil
+}
+
+func fnToNumber(args []any) (any, error) {
+	n, ok := toNumber(arg(args, 0))
+	if !ok {
+		return nil, nil
+	}
+	return n, nil
+}
+
+func fnToInt(args []any) (any, error) {
+	n, ok := toNumber(arg(args, 0))
+	if !ok {
+		return nil, nil
+	}
+	return math.Trunc(n), nil
+}
+
+func fnToString(args []any) (any, error) {
+	if v := arg(args, 0); v != nil {
+		return toString(v), nil
+	}
+	return nil, nil
+}
+
+func fnToBool(args []any) (any, error) {
+	switch v := arg(args, 0).(type) {
+	case nil:
+		return nil, nil
+	case bool:
+		return v, nil
+	case float64:
+		return v != 0, nil
+	case string:
+		b, err := strconv.ParseBool(strings.TrimSpace(v))
+		return err == nil && b, nil
+	}
+	return false, nil
+}
+
+var dateTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"2006/01/02 15:04:05",
+	"2006/01/02",
+	time.RFC1123Z,
+	time.RFC1123,
+	time.RFC850,
+	time.ANSIC,
+	time.UnixDate,
+	"Jan 2, 2006",
+	"January 2, 2006",
+	"2 Jan 2006",
+}
+
+// toTime converts strings, numbers (epoch milliseconds) and time values into time
+func toTime(v any) (time.Time, bool) {
+	switch x := v.(type) {
+	case time.Time:
+		return x, true
+	case float64:
+		return time.UnixMilli(int64(x)).UTC(), true
+	case string:
+		s := strings.TrimSpace(x)
+		for _, layout := range dateTimeLayouts {
+			if t, err := time.Parse(layout, s); err == nil {
+				return t.UTC(), true
+			}
+		}
+		if n, err := strconv.ParseFloat(s, 64); err == nil {
+			return time.UnixMilli(int64(n)).UTC(), true
+		}
+	}
+	return time.Time{}, false
+}
+
+func fnToDatetime(args []any) (any, error) {
+	t, ok := toTime(arg(args, 0))
+	if !ok {
+		return nil, nil
+	}
+	return t, nil
+}
+
+func fnToUnixTime(args []any) (any, error) {
+	t, ok := toTime(arg(args, 0))
+	if !ok {
+		return nil, nil
+	}
+	return float64(t.UnixMilli()), nil
+}
+
+func unixTimeFn(unit time.Duration) function {
+	return func(args []any) (any, error) {
+		n, ok := toNumber(arg(args, 0))
+		if !ok {
+			return nil, nil
+		}
+		return time.Unix(0, int64(n*float64(unit))).UTC(), nil
+	}
+}
+
+func startOfFn(unit string) function {
+	return func(args []any) (any, error) {
+		t, ok := toTime(arg(args, 0))
+		if !ok {
+			return nil, nil
+		}
+		t = t.UTC()
+		switch unit {
+		case "minute":
+			return t.Truncate(time.Minute), nil
+		case "hour":
+			return t.Truncate(time.Hour), nil
+		case "day":
+			return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC), nil
+		case "week":
+			d := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+			return d.AddDate(0, 0, -int(d.Weekday())), nil
+		case "month":
+			return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC), nil
+		default:
+			return time.Date(t.Year(), 1, 1, 0, 0, 0, 0, time.UTC), nil
+		}
+	}
+}
+
+var durationRegex = regexp.MustCompile(`([+-]?\d+)\s*(ms|s|m|h|d|w|M|y)`)
+
+func fnAddDatetime(args []any) (any, error) {
+	t, ok := toTime(arg(args, 0))
+	if !ok {
+		return nil, nil
+	}
+	for _, match := range durationRegex.FindAllStringSubmatch(toString(arg(args, 1)), -1) {
+		n, _ := strconv.Atoi(match[1])
+		switch match[2] {
+		case "ms":
+			t = t.Add(time.Duration(n) * time.Millisecond)
+		case "s":
+			t = t.Add(time.Duration(n) * time.Second)
+		case "m":
+			t = t.Add(time.Duration(n) * time.Minute)
+		case "h":
+			t = t.Add(time.Duration(n) * time.Hour)
+		case "d":
+			t = t.AddDate(0, 0, n)
+		case "w":
+			t = t.AddDate(0, 0, 7*n)
+		case "M":
+			t = t.AddDate(0, n, 0)
+		case "y":
+			t = t.AddDate(n, 0, 0)
+		}
+	}
+	return t, nil
+}
+
+var formatTokens = []string{"YYYY", "YY", "MMMM", "MMM", "MM", "M", "DD", "D", "dddd", "ddd", "HH", "H", "hh", "h", "mm", "m", "ss", "s", "SSS", "A", "a", "ZZ", "Z", "X", "x"}
+
+// formatDatetime formats the time using moment.js style format tokens
+func formatDatetime(t time.Time, format string) string {
+	var sb strings.Builder
+	for i := 0; i < len(format); {
+		if format[i] == '[' {
+			if end := strings.IndexByte(format[i:], ']'); end > 0 {
+				sb.WriteString(format[i+1 : i+end])
+				i += end + 1
+				continue
+			}
+		}
+		matched := false
+		for _, tok := range formatTokens {
+			if strings.HasPrefix(format[i:], tok) {
+				sb.WriteString(formatToken(t, tok))
+				i += len(tok)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			sb.WriteByte(format[i])
+			i++
+		}
+	}
+	return sb.String()
+}
+
+func formatToken(t time.Time, tok string) string {
+	hour12 := t.Hour() % 12
+	if hour12 == 0 {
+		hour12 = 12
+	}
+	switch tok {
+	case "YYYY":
+		return fmt.Sprintf("%04d", t.Year())
+	case "YY":
+		return fmt.Sprintf("%02d", t.Year()%100)
+	case "MMMM":
+		return t.Month().String()
+	case "MMM":
+		return t.Month().String()[:3]
+	case "MM":
+		return fmt.Sprintf("%02d", int(t.Month()))
+	case "M":
+		return strconv.Itoa(int(t.Month()))
+	case "DD":
+		return fmt.Sprintf("%02d", t.Day())
+	case "D":
+		return strconv.Itoa(t.Day())
+	case "dddd":
+		return t.Weekday().String()
+	case "ddd":
+		return t.Weekday().String()[:3]
+	case "HH":
+		return fmt.Sprintf("%02d", t.Hour())
+	case "H":
+		return strconv.Itoa(t.Hour())
+	case "hh":
+		return fmt.Sprintf("%02d", hour12)
+	case "h":
+		return strconv.Itoa(hour12)
+	case "mm":
+		return fmt.Sprintf("%02d", t.Minute())
+	case "m":
+		return strconv.Itoa(t.Minute())
+	case "ss":
+		return fmt.Sprintf("%02d", t.Second())
+	case "s":
+		return strconv.Itoa(t.Second())
+	case "SSS":
+		return fmt.Sprintf("%03d", t.Nanosecond()/int(time.Millisecond))
+	case "A":
+		return t.Format("PM")
+	case "a":
+		return t.Format("pm")
+	case "ZZ":
+		return t.Format("-0700")
+	case "Z":
+		return t.Format("-07:00")
+	case "X":
+		return strconv.FormatInt(t.Unix(), 10)
+	case "x":
+		return strconv.FormatInt(t.UnixMilli(), 10)
+	}
+	return tok
+}
+
+func fnFormatDatetime(args []any) (any, error) {
+	t, ok := toTime(arg(args, 0))
+	if !ok {
+		return nil, nil
+	}
+	format := toString(arg(args, 1))
+	if format == "" {
+		return t.Format(time.RFC3339), nil
+	}
+	return formatDatetime(t.UTC(), format), nil
+}
+
+func numbers(args []any) ([]float64, bool) {
+	out := []float64{}
+	for _, a := range args {
+		n, ok := toNumber(a)
+		if !ok {
+			return nil, false
+		}
+		out = append(out, n)
+	}
+	return out, len(out) > 0
+}
+
+func fnSum(args []any) (any, error) {
+	nums, ok := numbers(args)
+	if !ok {
+		return nil, nil
+	}
+	total := 0.0
+	for _, n := range nums {
+		total += n
+	}
+	return total, nil
+}
+
+func fnDiff(args []any) (any, error) {
+	nums, ok := numbers(args)
+	if !ok || len(nums) < 2 {
+		return nil, nil
+	}
+	return nums[0] - nums[1], nil
+}
+
+func fnMul(args []any) (any, error) {
+	nums, ok := numbers(args)
+	if !ok {
+		return nil, nil
+	}
+	total := 1.0
+	for _, n := range nums {
+		total *= n
+	}
+	return total, nil
+}
+
+func fnDiv(args []any) (any, error) {
+	nums, ok := numbers(args)
+	if !ok || len(nums) < 2 {
+		return nil, nil
+	}
+	return numberOrNil(nums[0] / nums[1]), nil
+}
+
+func fnPercentage(args []any) (any, error) {
+	nums, ok := numbers(args)
+	if !ok || len(nums) < 2 {
+		return nil, nil
+	}
+	return numberOrNil(nums[0] / nums[1] * 100), nil
+}
+
+func fnPow(args []any) (any, error) {
+	nums, ok := numbers(args)
+	if !ok || len(nums) < 2 {
+		return nil, nil
+	}
+	return numberOrNil(math.Pow(nums[0], nums[1])), nil
+}
+
+func fnParseURL(args []any) (any, error) {
+	u, err := url.Parse(toString(arg(args, 0)))
+	if err != nil {
+		return nil, nil
+	}
+	search := ""
+	if u.RawQuery != "" {
+		search = "?" + u.RawQuery
+	}
+	hash := ""
+	if u.Fragment != "" {
+		hash = "#" + u.Fragment
+	}
+	parts := map[string]any{
+		"hash":     hash,
+		"host":     u.Host,
+		"hostname": u.Hostname(),
+		"href":     u.String(),
+		"origin":   u.Scheme + "://" + u.Host,
+		"pathname": u.Path,
+		"port":     u.Port(),
+		"protocol": u.Scheme + ":",
+		"search":   search,
+	}
+	part := toString(arg(args, 1))
+	if part == "" {
+		return parts, nil
+	}
+	if part == "search" && arg(args, 2) != nil {
+		return queryValue(u.Query(), toString(arg(args, 2))), nil
+	}
+	return parts[part], nil
+}
+
+func fnParseURLQuery(args []any) (any, error) {
+	values, err := url.ParseQuery(strings.TrimPrefix(toString(arg(args, 0)), "?"))
+	if err != nil {
+		return nil, nil
+	}
+	if key := arg(args, 1); key != nil {
+		return queryValue(values, toString(key)), nil
+	}
+	out := map[string]any{}
+	for k := range values {
+		out[k] = values.Get(k)
+	}
+	return out, nil
+}
+
+func queryValue(values url.Values, key string) any {
+	if !values.Has(key) {
+		return nil
+	}
+	return values.Get(key)
+}
+
+func fnAtob(args []any) (any, error) {
+	b, err := base64.StdEncoding.DecodeString(toString(arg(args, 0)))
+	if err != nil {
+		return nil, nil
+	}
+	return string(b), nil
+}
+
+func fnBtoa(args []any) (any, error) {
+	return base64.StdEncoding.EncodeToString([]byte(toString(arg(args, 0)))), nil
+}
+
+func fnPack(args []any) (any, error) {
+	out := map[string]any{}
+	for i := 0; i+1 < len(args); i += 2 {
+		out[toString(args[i])] = args[i+1]
+	}
+	return out, nil
+}
+
+func fnArrayFromEntries(args []any) (any, error) {
+	out := []any{}
+	first, _ := arg(args, 1).([]any)
+	for idx := range first {
+		item := map[string]any{}
+		for i := 0; i+1 < len(args); i += 2 {
+			values, _ := args[i+1].([]any)
+			var value any
+			if idx < len(values) {
+				value = values[idx]
+			}
+			item[toString(args[i])] = value
+		}
+		out = append(out, item)
+	}
+	return out, nil
+}
+
+func fnArrayToMap(args []any) (any, error) {
+	values, _ := arg(args, 0).([]any)
+	out := map[string]any{}
+	for i, key := range args[min(1, len(args)):] {
+		var value any
+		if i < len(values) {
+			value = values[i]
+		}
+		out[toString(key)] = value
+	}
+	return out, nil
+}
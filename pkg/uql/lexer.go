@@ -0,0 +1,149 @@
+package uql
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokenIdent tokenKind = iota
+	tokenField
+	tokenString
+	tokenNumber
+	tokenPunct
+	tokenPipe
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+}
+
+func (t token) is(kind tokenKind, value string) bool {
+	return t.kind == kind && strings.EqualFold(t.value, value)
+}
+
+// stripComments removes the lines starting with # from the query
+func stripComments(query string) string {
+	lines := []string{}
+	for _, line := range strings.Split(query, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func tokenize(query string) ([]token, error) {
+	tokens := []token{}
+	runes := []rune(stripComments(query))
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '|':
+			tokens = append(tokens, token{kind: tokenPipe, value: "|"})
+			i++
+		case r == '"' || r == '\'':
+			value, next, err := readQuoted(runes, i)
+			if err != nil {
+				return nil, err
+			}
+			kind := tokenField
+			if r == '\'' {
+				kind = tokenString
+			}
+			tokens = append(tokens, token{kind: kind, value: value})
+			i = next
+		case unicode.IsDigit(r) || (r == '-' && i+1 < len(runes) && unicode.IsDigit(runes[i+1]) && isValueStart(tokens)):
+			start := i
+			i++
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.' || runes[i] == 'e' || runes[i] == 'E') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenNumber, value: string(runes[start:i])})
+		case unicode.IsLetter(r) || r == '_' || (r == '-' && i+1 < len(runes) && runes[i+1] == '-'):
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_' || runes[i] == '-') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, value: string(runes[start:i])})
+		default:
+			if i+1 < len(runes) {
+				switch string(runes[i : i+2]) {
+				case "==", "!=", ">=", "<=":
+					tokens = append(tokens, token{kind: tokenPunct, value: string(runes[i : i+2])})
+					i += 2
+					continue
+				}
+			}
+			if strings.ContainsRune("()[],=<>!", r) {
+				tokens = append(tokens, token{kind: tokenPunct, value: string(r)})
+				i++
+				continue
+			}
+			return nil, fmt.Errorf("%w: unexpected character %q", ErrInvalidQuery, r)
+		}
+	}
+	return tokens, nil
+}
+
+// isValueStart reports whether a '-' at the current position starts a negative number rather than an identifier
+func isValueStart(tokens []token) bool {
+	if len(tokens) == 0 {
+		return true
+	}
+	last := tokens[len(tokens)-1]
+	return last.kind == tokenPunct || last.kind == tokenIdent
+}
+
+func readQuoted(runes []rune, start int) (string, int, error) {
+	quote := runes[start]
+	var sb strings.Builder
+	for i := start + 1; i < len(runes); i++ {
+		if runes[i] == '\\' && i+1 < len(runes) {
+			switch runes[i+1] {
+			case 'n':
+				sb.WriteRune('\n')
+			case 't':
+				sb.WriteRune('\t')
+			case 'r':
+				sb.WriteRune('\r')
+			default:
+				sb.WriteRune(runes[i+1])
+			}
+			i++
+			continue
+		}
+		if runes[i] == quote {
+			return sb.String(), i + 1, nil
+		}
+		sb.WriteRune(runes[i])
+	}
+	return "", len(runes), fmt.Errorf("%w: unterminated quoted string", ErrInvalidQuery)
+}
+
+// splitPipeline splits the tokens into commands separated by |
+func splitPipeline(tokens []token) [][]token {
+	out := [][]token{}
+	current := []token{}
+	for _, t := range tokens {
+		if t.kind == tokenPipe {
+			if len(current) > 0 {
+				out = append(out, current)
+			}
+			current = []token{}
+			continue
+		}
+		current = append(current, t)
+	}
+	if len(current) > 0 {
+		out = append(out, current)
+	}
+	return out
+}
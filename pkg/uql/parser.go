@@ -0,0 +1,278 @@
+package uql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() (token, bool) {
+	if p.pos >= len(p.tokens) {
+		return token{}, false
+	}
+	return p.tokens[p.pos], true
+}
+
+func (p *parser) peekIs(kind tokenKind, value string) bool {
+	t, ok := p.peek()
+	return ok && t.is(kind, value)
+}
+
+func (p *parser) next() (token, bool) {
+	t, ok := p.peek()
+	if ok {
+		p.pos++
+	}
+	return t, ok
+}
+
+func (p *parser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *parser) expect(kind tokenKind, value string) error {
+	t, ok := p.next()
+	if !ok || !t.is(kind, value) {
+		return p.errorf("expected %q", value)
+	}
+	return nil
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	at := "end of command"
+	if t, ok := p.peek(); ok {
+		at = fmt.Sprintf("%q", t.value)
+	}
+	return fmt.Errorf("%w: %s near %s", ErrInvalidQuery, fmt.Sprintf(format, args...), at)
+}
+
+// parseCondition parses boolean expressions such as "a" > 1 and "b" in ('x','y')
+func (p *parser) parseCondition() (expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekIs(tokenIdent, "or") {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalExpr{op: "or", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (expr, error) {
+	left, err := p.parseComparison()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekIs(tokenIdent, "and") {
+		p.pos++
+		right, err := p.parseComparison()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalExpr{op: "and", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseComparison() (expr, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	t, ok := p.peek()
+	if !ok {
+		return left, nil
+	}
+	switch {
+	case t.kind == tokenPunct && (t.value == "==" || t.value == "!=" || t.value == ">" || t.value == ">=" || t.value == "<" || t.value == "<="):
+		p.pos++
+		right, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		return &compareExpr{op: t.value, left: left, right: right}, nil
+	case t.is(tokenIdent, "in"):
+		p.pos++
+		items, err := p.parseList("(", ")")
+		if err != nil {
+			return nil, err
+		}
+		return &inExpr{left: left, items: items}, nil
+	case t.is(tokenPunct, "!") && p.pos+1 < len(p.tokens) && p.tokens[p.pos+1].is(tokenIdent, "in"):
+		p.pos += 2
+		items, err := p.parseList("(", ")")
+		if err != nil {
+			return nil, err
+		}
+		return &inExpr{left: left, items: items, negate: true}, nil
+	}
+	return left, nil
+}
+
+func (p *parser) parseList(open, close string) ([]expr, error) {
+	if err := p.expect(tokenPunct, open); err != nil {
+		return nil, err
+	}
+	items := []expr{}
+	for !p.peekIs(tokenPunct, close) {
+		item, err := p.parseCondition()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+		if !p.peekIs(tokenPunct, ",") {
+			break
+		}
+		p.pos++
+	}
+	if err := p.expect(tokenPunct, close); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (p *parser) parseOperand() (expr, error) {
+	t, ok := p.next()
+	if !ok {
+		return nil, p.errorf("expected value")
+	}
+	switch t.kind {
+	case tokenField:
+		return &fieldExpr{path: t.value}, nil
+	case tokenString:
+		return &literalExpr{value: t.value}, nil
+	case tokenNumber:
+		v, err := strconv.ParseFloat(t.value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid number %s", ErrInvalidQuery, t.value)
+		}
+		return &literalExpr{value: v}, nil
+	case tokenIdent:
+		switch strings.ToLower(t.value) {
+		case "true":
+			return &literalExpr{value: true}, nil
+		case "false":
+			return &literalExpr{value: false}, nil
+		case "null":
+			return &literalExpr{value: nil}, nil
+		}
+		if !p.peekIs(tokenPunct, "(") {
+			return nil, fmt.Errorf("%w: unknown identifier %s", ErrInvalidQuery, t.value)
+		}
+		args, err := p.parseList("(", ")")
+		if err != nil {
+			return nil, err
+		}
+		return &callExpr{name: strings.ToLower(t.value), args: args}, nil
+	case tokenPunct:
+		switch t.value {
+		case "[":
+			p.pos--
+			items, err := p.parseList("[", "]")
+			if err != nil {
+				return nil, err
+			}
+			return &arrayExpr{items: items}, nil
+		case "(":
+			inner, err := p.parseCondition()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(tokenPunct, ")"); err != nil {
+				return nil, err
+			}
+			return inner, nil
+		}
+	}
+	p.pos--
+	return nil, p.errorf("unexpected token")
+}
+
+type assignment struct {
+	alias string
+	value expr
+}
+
+// parseAssignments parses comma separated items such as "alias"="field", "field", "alias"=fn("field") or fn("field")
+func (p *parser) parseAssignments() ([]assignment, error) {
+	out := []assignment{}
+	for !p.done() {
+		t, _ := p.peek()
+		switch {
+		case t.kind == tokenField && p.pos+1 < len(p.tokens) && p.tokens[p.pos+1].is(tokenPunct, "="):
+			p.pos += 2
+			value, err := p.parseOperand()
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, assignment{alias: t.value, value: value})
+		default:
+			value, err := p.parseOperand()
+			if err != nil {
+				return nil, err
+			}
+			alias := t.value
+			if call, ok := value.(*callExpr); ok {
+				alias = call.name
+			}
+			out = append(out, assignment{alias: alias, value: value})
+		}
+		if !p.peekIs(tokenPunct, ",") {
+			break
+		}
+		p.pos++
+	}
+	if !p.done() {
+		return nil, p.errorf("unexpected token")
+	}
+	return out, nil
+}
+
+// parseFieldNames parses a comma separated list of "field" names
+func (p *parser) parseFieldNames() ([]string, error) {
+	out := []string{}
+	for !p.done() {
+		t, _ := p.next()
+		if t.kind != tokenField {
+			p.pos--
+			return nil, p.errorf("expected field name")
+		}
+		out = append(out, t.value)
+		if !p.peekIs(tokenPunct, ",") {
+			break
+		}
+		p.pos++
+	}
+	return out, nil
+}
+
+// parseOptions parses command line style options such as --delimiter ";"
+func (p *parser) parseOptions() (map[string]string, error) {
+	options := map[string]string{}
+	for !p.done() {
+		t, _ := p.next()
+		if t.kind != tokenIdent || !strings.HasPrefix(t.value, "--") {
+			p.pos--
+			return nil, p.errorf("expected option")
+		}
+		name := strings.TrimPrefix(t.value, "--")
+		value, ok := p.peek()
+		if !ok || value.kind == tokenIdent {
+			options[name] = "true"
+			continue
+		}
+		p.pos++
+		options[name] = value.value
+	}
+	return options, nil
+}
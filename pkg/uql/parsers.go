@@ -0,0 +1,214 @@
+package uql
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+func parseJSON(s *state) error {
+	input, ok := s.data.(string)
+	if !ok {
+		// already decoded by the caller
+		return nil
+	}
+	dec := json.NewDecoder(strings.NewReader(input))
+	order := &keyOrder{seen: map[string]bool{}}
+	data, err := decodeJSONValue(dec, order)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrParsingInput, err)
+	}
+	s.data = data
+	s.order = order.keys
+	return nil
+}
+
+// keyOrder records the object keys in the order they first appear in the document
+type keyOrder struct {
+	keys []string
+	seen map[string]bool
+}
+
+func (k *keyOrder) add(key string) {
+	if !k.seen[key] {
+		k.seen[key] = true
+		k.keys = append(k.keys, key)
+	}
+}
+
+func decodeJSONValue(dec *json.Decoder, order *keyOrder) (any, error) {
+	t, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch v := t.(type) {
+	case json.Delim:
+		switch v {
+		case '{':
+			obj := map[string]any{}
+			for dec.More() {
+				kt, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				key, _ := kt.(string)
+				value, err := decodeJSONValue(dec, order)
+				if err != nil {
+					return nil, err
+				}
+				order.add(key)
+				obj[key] = value
+			}
+			_, err := dec.Token()
+			return obj, err
+		case '[':
+			arr := []any{}
+			for dec.More() {
+				value, err := decodeJSONValue(dec, order)
+				if err != nil {
+					return nil, err
+				}
+				arr = append(arr, value)
+			}
+			_, err := dec.Token()
+			return arr, err
+		}
+		return nil, fmt.Errorf("unexpected delimiter %s", v)
+	}
+	return t, nil
+}
+
+func parseCSV(s *state, options map[string]string) error {
+	input, ok := s.data.(string)
+	if !ok {
+		return fmt.Errorf("%w: parse-csv expects string input", ErrParsingInput)
+	}
+	r := csv.NewReader(strings.NewReader(input))
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	if delimiter := options["delimiter"]; delimiter != "" {
+		r.Comma = []rune(delimiter)[0]
+	}
+	if comment := options["comment"]; comment != "" {
+		r.Comment = []rune(comment)[0]
+	}
+	records, err := r.ReadAll()
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrParsingInput, err)
+	}
+	out := []any{}
+	if len(records) == 0 {
+		s.data = out
+		return nil
+	}
+	headers := records[0]
+	for _, record := range records[1:] {
+		if len(record) == 1 && strings.TrimSpace(record[0]) == "" {
+			continue
+		}
+		row := map[string]any{}
+		for i, header := range headers {
+			if i < len(record) {
+				row[header] = record[i]
+			}
+		}
+		out = append(out, row)
+	}
+	s.data = out
+	s.order = headers
+	return nil
+}
+
+// parseXML converts the xml document into the same structure xml2js produces.
+// Attributes are stored under "$", text content under "_" and child elements are always arrays.
+// When html is set, the decoder tolerates unclosed tags and html entities
+func parseXML(s *state, html bool) error {
+	input, ok := s.data.(string)
+	if !ok {
+		return fmt.Errorf("%w: parse-xml expects string input", ErrParsingInput)
+	}
+	dec := xml.NewDecoder(strings.NewReader(input))
+	if html {
+		dec.Strict = false
+		dec.AutoClose = xml.HTMLAutoClose
+		dec.Entity = xml.HTMLEntity
+	}
+	order := &keyOrder{seen: map[string]bool{}}
+	for {
+		t, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			return fmt.Errorf("%w: no root element found", ErrParsingInput)
+		}
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrParsingInput, err)
+		}
+		if start, ok := t.(xml.StartElement); ok {
+			value, err := decodeXMLElement(dec, start, order)
+			if err != nil {
+				return fmt.Errorf("%w: %w", ErrParsingInput, err)
+			}
+			order.add(start.Name.Local)
+			s.data = map[string]any{start.Name.Local: value}
+			s.order = order.keys
+			return nil
+		}
+	}
+}
+
+func decodeXMLElement(dec *xml.Decoder, start xml.StartElement, order *keyOrder) (any, error) {
+	obj := map[string]any{}
+	if len(start.Attr) > 0 {
+		attrs := map[string]any{}
+		for _, attr := range start.Attr {
+			attrs[attr.Name.Local] = attr.Value
+		}
+		obj["$"] = attrs
+	}
+	var text strings.Builder
+	for {
+		t, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch v := t.(type) {
+		case xml.StartElement:
+			child, err := decodeXMLElement(dec, v, order)
+			if err != nil {
+				return nil, err
+			}
+			order.add(v.Name.Local)
+			existing, _ := obj[v.Name.Local].([]any)
+			obj[v.Name.Local] = append(existing, child)
+		case xml.CharData:
+			text.Write(v)
+		case xml.EndElement:
+			content := strings.TrimSpace(text.String())
+			if len(obj) == 0 {
+				return content, nil
+			}
+			if content != "" {
+				obj["_"] = content
+			}
+			return obj, nil
+		}
+	}
+}
+
+func parseYAML(s *state) error {
+	input, ok := s.data.(string)
+	if !ok {
+		return nil
+	}
+	b, err := yaml.YAMLToJSON([]byte(input))
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrParsingInput, err)
+	}
+	s.data = string(b)
+	return parseJSON(s)
+}
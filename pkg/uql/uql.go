@@ -0,0 +1,141 @@
+// Package uql implements the unstructured query language (UQL) used by the infinity parser.
+//
+// A UQL query is a pipeline of commands separated by |. Each command receives the output of the previous command.
+//
+//	parse-json
+//	| scope "users"
+//	| where "age" > 18
+//	| project "name", "dob"=todatetime("dob")
+//	| order by "name" asc
+//
+// See docs/sources/query/uql.md for the full list of commands and functions.
+package uql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrInvalidQuery    = errors.New("invalid uql query")
+	ErrUnknownCommand  = errors.New("unknown uql command")
+	ErrUnknownFunction = errors.New("unknown uql function")
+	ErrParsingInput    = errors.New("error parsing the input data")
+)
+
+// state is passed through the commands of the pipeline.
+type state struct {
+	data any
+	// order keeps the preferred column order. Columns not listed here are sorted alphabetically
+	order []string
+}
+
+func (s *state) addColumn(name string) {
+	for _, item := range s.order {
+		if item == name {
+			return
+		}
+	}
+	s.order = append(s.order, name)
+}
+
+// Run executes the UQL query against the input. Input can be a raw string/byte slice, a json.RawMessage or an already decoded json value.
+// json.RawMessage is decoded up front keeping the key order of the document, so parse-json is optional for it
+func Run(ctx context.Context, query string, input any) (*Result, error) {
+	tokens, err := tokenize(query)
+	if err != nil {
+		return nil, err
+	}
+	s := &state{data: input}
+	switch v := input.(type) {
+	case []byte:
+		s.data = string(v)
+	case json.RawMessage:
+		s.data = string(v)
+		if err := parseJSON(s); err != nil {
+			return nil, err
+		}
+	}
+	for _, command := range splitPipeline(tokens) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if err := runCommand(s, command); err != nil {
+			return nil, err
+		}
+	}
+	return &Result{Data: s.data, Columns: s.order}, nil
+}
+
+func runCommand(s *state, command []token) error {
+	name := strings.ToLower(command[0].value)
+	p := &parser{tokens: command, pos: 1}
+	if command[0].kind != tokenIdent {
+		return fmt.Errorf("%w: %s", ErrUnknownCommand, command[0].value)
+	}
+	switch name {
+	case "parse-json":
+		return parseJSON(s)
+	case "parse-csv":
+		options, err := p.parseOptions()
+		if err != nil {
+			return err
+		}
+		return parseCSV(s, options)
+	case "parse-tsv":
+		return parseCSV(s, map[string]string{"delimiter": "\t"})
+	case "parse-xml":
+		return parseXML(s, false)
+	case "parse-html":
+		return parseXML(s, true)
+	case "parse-yaml":
+		return parseYAML(s)
+	case "project":
+		if p.peekIs(tokenIdent, "kv") {
+			return projectKV(s, p)
+		}
+		return project(s, p)
+	case "project-away":
+		return projectAway(s, p)
+	case "extend":
+		return extend(s, p)
+	case "where":
+		return where(s, p)
+	case "order":
+		if err := p.expect(tokenIdent, "by"); err != nil {
+			return err
+		}
+		return orderBy(s, p)
+	case "summarize":
+		return summarize(s, p)
+	case "pivot":
+		return pivot(s, p)
+	case "count":
+		s.data = float64(len(rows(s.data)))
+		s.order = nil
+		return nil
+	case "limit":
+		return limit(s, p)
+	case "scope":
+		return scope(s, p)
+	case "mv-expand":
+		return mvExpand(s, p)
+	case "jsonata":
+		return runJSONata(s, p)
+	}
+	return fmt.Errorf("%w: %s", ErrUnknownCommand, command[0].value)
+}
+
+// rows returns the data as a list of rows. A single object is treated as a single row
+func rows(data any) []any {
+	switch d := data.(type) {
+	case nil:
+		return []any{}
+	case []any:
+		return d
+	}
+	return []any{data}
+}
@@ -0,0 +1,251 @@
+package uql
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	users := `[
+		{ "name": "foo", "age": 20, "country": "india", "dob": "2000-01-05", "tags": ["a","b"] },
+		{ "name": "bar", "age": 40, "country": "usa", "dob": "1980-11-23", "tags": ["c"] },
+		{ "name": "baz", "age": 12, "country": "india", "dob": "2012-06-30", "tags": [] }
+	]`
+	tests := []struct {
+		name    string
+		query   string
+		input   any
+		want    any
+		columns []string
+		wantErr error
+	}{
+		{
+			name:    "parse-json keeps the key order",
+			query:   `parse-json`,
+			input:   `{ "b": 1, "a": { "d": true, "c": null } }`,
+			want:    map[string]any{"b": 1.0, "a": map[string]any{"d": true, "c": nil}},
+			columns: []string{"b", "d", "c", "a"},
+		},
+		{
+			name:    "raw json input is decoded keeping the key order without parse-json",
+			query:   `limit 1`,
+			input:   json.RawMessage(`[{ "name": "foo", "age": 20 }, { "name": "bar", "age": 40 }]`),
+			want:    []any{map[string]any{"name": "foo", "age": 20.0}},
+			columns: []string{"name", "age"},
+		},
+		{
+			name:    "invalid raw json input",
+			query:   `parse-json`,
+			input:   json.RawMessage(`{ "name": `),
+			wantErr: ErrParsingInput,
+		},
+		{
+			name:    "project with alias and functions",
+			query:   `parse-json | project "name", "user age"="age", "country"=toupper("country") | limit 1`,
+			input:   users,
+			want:    []any{map[string]any{"name": "foo", "user age": 20.0, "country": "INDIA"}},
+			columns: []string{"name", "user age", "country"},
+		},
+		{
+			name:  "project-away",
+			query: `parse-json | project-away "dob", "tags", "country" | order by "age" asc`,
+			input: users,
+			want: []any{
+				map[string]any{"name": "baz", "age": 12.0},
+				map[string]any{"name": "foo", "age": 20.0},
+				map[string]any{"name": "bar", "age": 40.0},
+			},
+			columns: []string{"name", "age"},
+		},
+		{
+			name:  "where with and/or and in",
+			query: "parse-json\n# only adults\n| where \"age\" >= 18 and \"country\" in ('india','uk') or \"name\" == 'baz' | project \"name\"",
+			input: users,
+			want:  []any{map[string]any{"name": "foo"}, map[string]any{"name": "baz"}},
+		},
+		{
+			name:  "where not in",
+			query: `parse-json | where "country" !in ('india') | project "name"`,
+			input: users,
+			want:  []any{map[string]any{"name": "bar"}},
+		},
+		{
+			name:  "extend with datetime functions",
+			query: `parse-json | extend "dob"=todatetime("dob"), "year"=format_datetime("dob",'YYYY'), "next"=add_datetime("dob",'1d') | project "dob", "year", "next" | limit 1`,
+			input: users,
+			want: []any{map[string]any{
+				"dob":  time.Date(2000, 1, 5, 0, 0, 0, 0, time.UTC),
+				"year": "2000",
+				"next": time.Date(2000, 1, 6, 0, 0, 0, 0, time.UTC),
+			}},
+		},
+		{
+			name:  "math functions",
+			query: `parse-json | project "a", "triple"=sum("a","a","a"), "thrice"=mul("a",3), sum("a","b"), diff("a","b"), mul("a","b")`,
+			input: `[{ "a": 12, "b": 20 }, { "a": 6, "b": 32 }]`,
+			want: []any{
+				map[string]any{"a": 12.0, "triple": 36.0, "thrice": 36.0, "sum": 32.0, "diff": -8.0, "mul": 240.0},
+				map[string]any{"a": 6.0, "triple": 18.0, "thrice": 18.0, "sum": 38.0, "diff": -26.0, "mul": 192.0},
+			},
+			columns: []string{"a", "triple", "thrice", "sum", "diff", "mul"},
+		},
+		{
+			name: "summarize by",
+			query: `parse-json
+				| summarize "number of cities"=count(), "total population"=sum("population") by "country"
+				| extend "country"=toupper("country")
+				| order by "total population" desc`,
+			input: `[
+				{ "city": "tokyo", "country": "japan", "population": 200 },
+				{ "city": "newyork", "country": "usa", "population": 60 },
+				{ "city": "oslo", "country": "usa", "population": 40 },
+				{ "city": "new delhi", "country": "india", "population": 180 },
+				{ "city": "mumbai", "country": "india", "population": 150 }
+			]`,
+			want: []any{
+				map[string]any{"country": "INDIA", "number of cities": 2.0, "total population": 330.0},
+				map[string]any{"country": "JAPAN", "number of cities": 1.0, "total population": 200.0},
+				map[string]any{"country": "USA", "number of cities": 2.0, "total population": 100.0},
+			},
+			columns: []string{"country", "number of cities", "total population"},
+		},
+		{
+			name:  "summarize with conditional aggregations",
+			query: `parse-json | summarize "adults"=countif("age", "> 18"), "youngest"=minif("age", "> 0"), "distinct"=dcount("country")`,
+			input: users,
+			want:  []any{map[string]any{"adults": 2.0, "youngest": 12.0, "distinct": 2.0}},
+		},
+		{
+			name:  "pivot",
+			query: "parse-csv | extend \"salary\"=tonumber(\"salary\") | pivot sum(\"salary\"), \"country\", \"occupation\"",
+			input: "name,country,occupation,salary\na,USA,Devops,3000\nb,USA,Engineer,2300\nc,UK,Engineer,2800\nd,USA,Engineer,3500",
+			want: []any{
+				map[string]any{"country": "USA", "Devops": 3000.0, "Engineer": 5800.0},
+				map[string]any{"country": "UK", "Devops": 0.0, "Engineer": 2800.0},
+			},
+			columns: []string{"country", "Devops", "Engineer"},
+		},
+		{
+			name:    "parse-csv with delimiter",
+			query:   `parse-csv --delimiter ";"`,
+			input:   "name;age\nfoo;20\n",
+			want:    []any{map[string]any{"name": "foo", "age": "20"}},
+			columns: []string{"name", "age"},
+		},
+		{
+			name:  "parse-xml",
+			query: `parse-xml | scope "users.user" | project "name"=strcat("name"), "id"="$.id"`,
+			input: `<users><user id="1"><name>foo</name></user><user id="2"><name>bar</name></user></users>`,
+			want: []any{
+				map[string]any{"name": `["foo"]`, "id": "1"},
+				map[string]any{"name": `["bar"]`, "id": "2"},
+			},
+		},
+		{
+			name:  "parse-yaml",
+			query: `parse-yaml | scope "users"`,
+			input: "users:\n  - name: foo\n  - name: bar\n",
+			want:  []any{map[string]any{"name": "foo"}, map[string]any{"name": "bar"}},
+		},
+		{
+			name:  "count",
+			query: `parse-json | where "age" > 18 | count`,
+			input: users,
+			want:  2.0,
+		},
+		{
+			name:  "mv-expand",
+			query: `parse-json | mv-expand "tag"="tags" | project "name", "tag"`,
+			input: users,
+			want: []any{
+				map[string]any{"name": "foo", "tag": "a"},
+				map[string]any{"name": "foo", "tag": "b"},
+				map[string]any{"name": "bar", "tag": "c"},
+			},
+		},
+		{
+			name:    "project kv",
+			query:   `parse-json | project kv("data")`,
+			input:   `{ "data": { "b": { "name": "b1" }, "a": { "name": "a1" } } }`,
+			want:    []any{map[string]any{"key": "b", "value": map[string]any{"name": "b1"}}, map[string]any{"key": "a", "value": map[string]any{"name": "a1"}}},
+			columns: []string{"key", "value"},
+		},
+		{
+			name:  "jsonata",
+			query: `parse-json | jsonata "$[age > 18].name"`,
+			input: users,
+			want:  []any{"foo", "bar"},
+		},
+		{
+			name:  "already decoded input",
+			query: `parse-json | project "name"=pack('first',"name")`,
+			input: []any{map[string]any{"name": "foo"}},
+			want:  []any{map[string]any{"name": map[string]any{"first": "foo"}}},
+		},
+		{
+			name:    "unknown command",
+			query:   `parse-json | foo`,
+			input:   users,
+			wantErr: ErrUnknownCommand,
+		},
+		{
+			name:    "unknown function",
+			query:   `parse-json | extend "a"=foo("name")`,
+			input:   users,
+			wantErr: ErrUnknownFunction,
+		},
+		{
+			name:    "invalid json",
+			query:   `parse-json`,
+			input:   `{ "a": `,
+			wantErr: ErrParsingInput,
+		},
+		{
+			name:    "unterminated string",
+			query:   `parse-json | where "a" == 'b`,
+			input:   users,
+			wantErr: ErrInvalidQuery,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Run(context.Background(), tt.query, tt.input)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got.Data)
+			if tt.columns != nil {
+				require.Equal(t, tt.columns, got.Columns)
+			}
+		})
+	}
+}
+
+func TestResultToFrame(t *testing.T) {
+	result, err := Run(context.Background(), `parse-json | extend "dob"=todatetime("dob")`, `[{ "name": "foo", "age": 20, "dob": "2000-01-05", "tags": ["a"], "active": true }, { "name": "bar", "age": "unknown" }]`)
+	require.NoError(t, err)
+	frame := result.ToFrame("A")
+	require.Equal(t, "A", frame.Name)
+	names := []string{}
+	for _, f := range frame.Fields {
+		names = append(names, f.Name)
+	}
+	require.Equal(t, []string{"name", "age", "dob", "tags", "active"}, names)
+	require.Equal(t, "foo", *frame.Fields[0].At(0).(*string))
+	require.Equal(t, "unknown", *frame.Fields[1].At(1).(*string))
+	require.Equal(t, time.Date(2000, 1, 5, 0, 0, 0, 0, time.UTC), *frame.Fields[2].At(0).(*time.Time))
+	require.Nil(t, frame.Fields[2].At(1))
+	require.Equal(t, true, *frame.Fields[4].At(0).(*bool))
+
+	result, err = Run(context.Background(), `parse-json | count`, `[1,2,3]`)
+	require.NoError(t, err)
+	frame = result.ToFrame("B")
+	require.Equal(t, "result", frame.Fields[0].Name)
+	require.Equal(t, 3.0, *frame.Fields[0].At(0).(*float64))
+}
@@ -20,6 +20,10 @@ export const IsValidInfinityQuery = (query: InfinityQuery): boolean => {
       return query.referenceName !== undefined && query.referenceName !== '';
     } else if (query.source === 'azure-blob') {
       return query.azBlobName === '' || query.azContainerName === '';
+    } else if (query.source === 's3') {
+      return !!query.s3Bucket && !!query.s3Key;
+    } else if (query.source === 'gcs') {
+      return !!query.gcsBucket;
     } else {
       return query.data !== undefined && query.data !== '';
     }
@@ -1,5 +1,5 @@
 import { FieldType, MutableDataFrame } from '@grafana/data';
-import { toTimeSeriesLong, toTimeSeriesMulti } from '@/app/utils';
+import { getStreamData, getStreamPath, toTimeSeriesLong, toTimeSeriesMulti } from '@/app/utils';
 
 describe('utils', () => {
   describe('Time Series Transformations', () => {
@@ -176,4 +176,18 @@ describe('utils', () => {
       });
     });
   });
+  describe('getStreamPath', () => {
+    // same values are used in the tests of getStreamHash in the backend
+    const query = { refId: 'A', type: 'json', source: 'url', url: 'https://allowed.com/events', stream_mode: 'sse' } as any;
+    it('should hash the user login along with the query', () => {
+      expect(getStreamPath(query, 'admin')).toEqual('stream/A/23f14445');
+      expect(getStreamPath(query, 'viewer')).toEqual('stream/A/43cb0632');
+      expect(getStreamPath({ refId: 'A', url: 'https://allowed.com/ü' } as any, 'admin')).toEqual('stream/A/aea77c2f');
+    });
+    it('should ignore the datasource and the key of the query', () => {
+      const data = getStreamData({ ...query, datasource: { uid: 'foo' }, key: 'bar' });
+      expect(JSON.stringify(data)).toEqual(JSON.stringify(query));
+      expect(getStreamPath({ ...query, datasource: { uid: 'foo' }, key: 'bar' }, 'admin')).toEqual('stream/A/23f14445');
+    });
+  });
 });
@@ -10,6 +10,7 @@ import type {
   InfinityXMLQuery,
 } from '@/types';
 import { DataFrame, DataFrameType, Field, FieldType, Labels, MutableDataFrame } from '@grafana/data';
+import { config } from '@grafana/runtime';
 
 export const isCSVQuery = (query: InfinityQuery): query is InfinityCSVQuery => query.type === 'csv';
 export const isJSONQuery = (query: InfinityQuery): query is InfinityJSONQuery => query.type === 'json';
@@ -19,21 +20,33 @@ export const isHTMLQuery = (query: InfinityQuery): query is InfinityHTMLQuery =>
 
 export const isBackendQuerySupported = (
   query: InfinityQuery
-): query is Extract<InfinityQuery, { type: 'json' } | { type: 'csv' } | { type: 'tsv' } | { type: 'xml' } | { type: 'graphql' } | { type: 'html' }> =>
-  query.type === 'json' || query.type === 'csv' || query.type === 'tsv' || query.type === 'graphql' || query.type === 'xml' || query.type === 'html';
+): query is Extract<InfinityQuery, { type: 'json' } | { type: 'ndjson' } | { type: 'yaml' } | { type: 'prometheus' } | { type: 'parquet' } | { type: 'arrow' } | { type: 'xlsx' } | { type: 'csv' } | { type: 'tsv' } | { type: 'xml' } | { type: 'graphql' } | { type: 'html' }> =>
+  query.type === 'json' || query.type === 'ndjson' || query.type === 'yaml' || query.type === 'prometheus' || query.type === 'parquet' || query.type === 'arrow' || query.type === 'xlsx' || query.type === 'csv' || query.type === 'tsv' || query.type === 'graphql' || query.type === 'xml' || query.type === 'html';
 export const isBackendQuery = (
   query: InfinityQuery
 ): query is Extract<
   InfinityQuery,
+  | { type: 'ndjson' }
+  | { type: 'yaml' }
+  | { type: 'prometheus' }
+  | { type: 'parquet' }
+  | { type: 'arrow' }
+  | { type: 'xlsx' }
   | (({ type: 'json' } | { type: 'csv' } | { type: 'tsv' } | { type: 'xml' } | { type: 'graphql' } | { type: 'html' }) & { parser: 'backend' })
   | (({ type: 'json' } | { type: 'xml' } | { type: 'graphql' } | { type: 'html' }) & { parser: 'jq-backend' })
-> => query.type === 'transformations' || (isBackendQuerySupported(query) && (query.parser === 'backend' || query.parser === 'jq-backend'));
+> => query.type === 'transformations' || query.type === 'ndjson' || query.type === 'yaml' || (isBackendQuerySupported(query) && (query.parser === 'backend' || query.parser === 'jq-backend'));
 
 export const isDataQuery = (query: InfinityQuery): query is InfinityQueryWithDataSource<any> => {
   switch (query.type) {
     case 'csv':
     case 'tsv':
     case 'json':
+    case 'ndjson':
+    case 'yaml':
+    case 'prometheus':
+    case 'parquet':
+    case 'arrow':
+    case 'xlsx':
     case 'xml':
     case 'graphql':
     case 'html':
@@ -45,6 +58,29 @@ export const isDataQuery = (query: InfinityQuery): query is InfinityQueryWithDat
   }
 };
 
+export const isStreamingQuery = (query: InfinityQuery): boolean => {
+  if (!isInfinityQueryWithUrlSource(query) || !query.stream_mode || query.stream_mode === 'none') {
+    return false;
+  }
+  return isBackendQuery(query as InfinityQuery) || query.type === 'uql' || query.type === 'groq';
+};
+
+// getStreamData returns the query sent to the backend when subscribing to the stream
+export const getStreamData = (query: InfinityQuery) => ({ ...query, datasource: undefined, key: undefined });
+
+// getStreamPath returns the live channel path of the query. Identical queries of the same user share the same path and so the same stream.
+// The user login is part of the hash as the stream may use the user's credentials. The backend computes the same hash from the
+// subscription data and the user, and rejects the subscription when the path doesn't match
+export const getStreamPath = (query: InfinityQuery, login: string = config.bootData.user?.login ?? ''): string => {
+  const input = `${login}\n${JSON.stringify(getStreamData(query))}`;
+  let hash = 0x811c9dc5;
+  for (let i = 0; i < input.length; i++) {
+    hash ^= input.charCodeAt(i);
+    hash = Math.imul(hash, 0x01000193);
+  }
+  return `stream/${query.refId}/${(hash >>> 0).toString(16)}`;
+};
+
 // We have to have query: unknown here as InfinityQuery and InfinityQueryWithURLSource<InfinityQueryType> are not compatible according to TypeScript
 export const isInfinityQueryWithUrlSource = (query: unknown): query is InfinityQueryWithURLSource<InfinityQueryType> => {
   // We do a basic check to ensure that query is an object and has a type property
@@ -0,0 +1,36 @@
+import { Input } from '@grafana/ui';
+import React from 'react';
+import { EditorField } from '@/components/extended/EditorField';
+import type { InfinityQuery } from '@/types';
+
+export const FileOptionsEditor = (props: { query: InfinityQuery; onChange: (value: InfinityQuery) => void; onRunQuery: () => void }) => {
+  const { query, onChange, onRunQuery } = props;
+  if (query.type === 'xlsx') {
+    return (
+      <>
+        <EditorField label="Sheet Name" optional={true} tooltip="Name of the sheet to read. Leave empty to read the first sheet">
+          <Input width={20} value={query.sheetName} placeholder="First sheet" onChange={(e) => onChange({ ...query, sheetName: e.currentTarget.value })} onBlur={onRunQuery} />
+        </EditorField>
+        <EditorField label="Range" optional={true} tooltip="Range of the cells in A1 notation such as A1:D10 or A:D. First row of the range is used as the header. Leave empty to read the entire sheet">
+          <Input width={20} value={query.sheetRange} placeholder="A1:D10" onChange={(e) => onChange({ ...query, sheetRange: e.currentTarget.value })} onBlur={onRunQuery} />
+        </EditorField>
+      </>
+    );
+  }
+  if (!(query.type === 'parquet' || query.type === 'arrow')) {
+    return <></>;
+  }
+  return (
+    <EditorField label="Max rows" optional={true} tooltip="Stops reading the file after the given number of rows. Leave empty to read all the rows">
+      <Input
+        type="number"
+        min={0}
+        width={20}
+        value={query.max_rows}
+        placeholder="All rows"
+        onChange={(e) => onChange({ ...query, max_rows: e.currentTarget.valueAsNumber || undefined })}
+        onBlur={onRunQuery}
+      />
+    </EditorField>
+  );
+};
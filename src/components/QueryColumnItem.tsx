@@ -40,8 +40,8 @@ export const QueryColumnItem = (props: QueryColumnItemProps) => {
   };
   return (
     <div style={{ display: 'flex' }}>
-      <InlineFormLabel width={8}>{query.type === 'csv' ? 'Column Name' : 'Selector'}</InlineFormLabel>
-      <Input width={20} value={selector} placeholder={query.type === 'csv' ? 'Column Name' : 'Selector'} onChange={(e) => setSelector(e.currentTarget.value)} onBlur={onSelectorChange} />
+      <InlineFormLabel width={8}>{query.type === 'csv' || query.type === 'parquet' || query.type === 'arrow' || query.type === 'xlsx' ? 'Column Name' : 'Selector'}</InlineFormLabel>
+      <Input width={20} value={selector} placeholder={query.type === 'csv' || query.type === 'parquet' || query.type === 'arrow' || query.type === 'xlsx' ? 'Column Name' : 'Selector'} onChange={(e) => setSelector(e.currentTarget.value)} onBlur={onSelectorChange} />
       <InlineFormLabel width={2}>as</InlineFormLabel>
       <Input value={text} width={20} placeholder="Title" onChange={(e) => setText(e.currentTarget.value)} onBlur={onTextChange}></Input>
       <InlineFormLabel width={5}>format as</InlineFormLabel>
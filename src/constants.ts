@@ -1,5 +1,5 @@
 import type { ComboboxOption } from '@grafana/ui';
-import type { InfinityQuery, InfinityQueryType, InfinityQueryFormat, InfinityColumnFormat, ScrapQuerySources, VariableQueryType, AzureBlobCloudType } from '@/types';
+import type { InfinityQuery, InfinityQueryType, InfinityQueryFormat, InfinityColumnFormat, ScrapQuerySources, VariableQueryType, AzureBlobCloudType, AzureBlobAuthType, AzureADAuthType, AWSAuthType } from '@/types';
 
 export const DefaultInfinityQuery: InfinityQuery = {
   refId: '',
@@ -15,6 +15,12 @@ export const DefaultInfinityQuery: InfinityQuery = {
 
 export const SCRAP_QUERY_TYPES: Array<ComboboxOption<InfinityQueryType>> = [
   { label: 'JSON', value: 'json' },
+  { label: 'NDJSON', value: 'ndjson' },
+  { label: 'YAML', value: 'yaml' },
+  { label: 'Prometheus metrics', value: 'prometheus' },
+  { label: 'Parquet', value: 'parquet' },
+  { label: 'Arrow', value: 'arrow' },
+  { label: 'Excel (XLSX)', value: 'xlsx' },
   { label: 'CSV', value: 'csv' },
   { label: 'TSV', value: 'tsv' },
   { label: 'GraphQL', value: 'graphql' },
@@ -38,10 +44,12 @@ export const INFINITY_RESULT_FORMATS: Array<ComboboxOption<InfinityQueryFormat>>
   { label: 'As Is', value: 'as-is' },
 ];
 export const INFINITY_SOURCES: ScrapQuerySources[] = [
-  { label: 'URL', value: 'url', supported_types: ['csv', 'tsv', 'json', 'html', 'xml', 'graphql', 'uql', 'groq'] },
-  { label: 'Inline', value: 'inline', supported_types: ['csv', 'tsv', 'json', 'xml', 'uql', 'groq'] },
-  { label: 'Reference', value: 'reference', supported_types: ['csv', 'tsv', 'json', 'xml', 'uql', 'groq'] },
-  { label: 'Azure Blob', value: 'azure-blob', supported_types: ['csv', 'tsv', 'json', 'xml', 'uql', 'groq'] },
+  { label: 'URL', value: 'url', supported_types: ['csv', 'tsv', 'json', 'ndjson', 'yaml', 'prometheus', 'parquet', 'arrow', 'xlsx', 'html', 'xml', 'graphql', 'uql', 'groq'] },
+  { label: 'Inline', value: 'inline', supported_types: ['csv', 'tsv', 'json', 'ndjson', 'yaml', 'prometheus', 'xlsx', 'xml', 'uql', 'groq'] },
+  { label: 'Reference', value: 'reference', supported_types: ['csv', 'tsv', 'json', 'ndjson', 'prometheus', 'xml', 'uql', 'groq'] },
+  { label: 'Azure Blob', value: 'azure-blob', supported_types: ['csv', 'tsv', 'json', 'ndjson', 'prometheus', 'parquet', 'arrow', 'xlsx', 'xml', 'uql', 'groq'] },
+  { label: 'Amazon S3', value: 's3', supported_types: ['csv', 'tsv', 'json', 'ndjson', 'prometheus', 'parquet', 'arrow', 'xlsx', 'xml', 'uql', 'groq'] },
+  { label: 'Google Cloud Storage', value: 'gcs', supported_types: ['csv', 'tsv', 'json', 'ndjson', 'prometheus', 'parquet', 'arrow', 'xlsx', 'xml', 'uql', 'groq'] },
   { label: 'Random Walk', value: 'random-walk', supported_types: ['series'] },
   { label: 'Expression', value: 'expression', supported_types: ['series'] },
 ];
@@ -132,3 +140,24 @@ export const AzureBlobRegions: Array<ComboboxOption<AzureBlobCloudType>> = [
   { value: 'AzureUSGovernment', label: 'Azure US Government' },
   { value: 'AzureChinaCloud', label: 'Azure China' },
 ];
+export const AWSAuthTypes: Array<ComboboxOption<AWSAuthType>> = [
+  { value: 'keys', label: 'Access and secret key' },
+  { value: 'default', label: 'AWS SDK default' },
+  { value: 'credentials', label: 'Credentials file' },
+  { value: 'ec2_iam_role', label: 'EC2 IAM role' },
+];
+
+export const AzureBlobAuthTypes: Array<ComboboxOption<AzureBlobAuthType>> = [
+  { value: 'sharedKey', label: 'Storage account key' },
+  { value: 'sasToken', label: 'SAS token' },
+  { value: 'clientSecret', label: 'Service principal' },
+  { value: 'managedIdentity', label: 'Managed identity' },
+  { value: 'workloadIdentity', label: 'Workload identity' },
+];
+
+export const AzureADAuthTypes: Array<ComboboxOption<AzureADAuthType>> = [
+  { value: 'clientSecret', label: 'Client secret' },
+  { value: 'clientCertificate', label: 'Client certificate' },
+  { value: 'managedIdentity', label: 'Managed identity' },
+  { value: 'workloadIdentity', label: 'Workload identity' },
+];
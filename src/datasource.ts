@@ -1,7 +1,7 @@
-import { LoadingState, toDataFrame, DataFrame, DataQueryRequest, DataQueryResponse, ScopedVars, TimeRange } from '@grafana/data';
-import { DataSourceWithBackend } from '@grafana/runtime';
+import { LoadingState, LiveChannelScope, toDataFrame, DataFrame, DataQueryRequest, DataQueryResponse, ScopedVars, TimeRange } from '@grafana/data';
+import { DataSourceWithBackend, getGrafanaLiveSrv } from '@grafana/runtime';
 import { flatten } from 'lodash';
-import { Observable } from 'rxjs';
+import { merge, Observable } from 'rxjs';
 import { applyGroq } from '@/app/GROQProvider';
 import { InfinityProvider } from '@/app/InfinityProvider';
 import { SeriesProvider } from '@/app/SeriesProvider';
@@ -10,7 +10,7 @@ import { getUpdatedDataRequest, interpolateVariablesInQueries } from '@/app/quer
 import { InfinityVariableSupport } from '@/app/variablesQuery';
 import { AnnotationsEditor } from '@/editors/annotation.editor';
 import { interpolateQuery } from '@/interpolate';
-import { isBackendQuery, isInfinityQueryWithUrlSource } from '@/app/utils';
+import { getStreamData, getStreamPath, isBackendQuery, isInfinityQueryWithUrlSource, isStreamingQuery } from '@/app/utils';
 import type { InfinityInstanceSettings, InfinityOptions, InfinityQuery } from '@/types';
 
 export class Datasource extends DataSourceWithBackend<InfinityQuery, InfinityOptions> {
@@ -22,8 +22,25 @@ export class Datasource extends DataSourceWithBackend<InfinityQuery, InfinityOpt
     };
   }
   query(options: DataQueryRequest<InfinityQuery>): Observable<DataQueryResponse> {
+    const request = getUpdatedDataRequest(options, this.instanceSettings);
+    const streamingTargets = request.targets.filter(isStreamingQuery);
+    if (streamingTargets.length === 0) {
+      return this.runQuery(request);
+    }
+    const streams: Array<Observable<DataQueryResponse>> = streamingTargets.map((target) =>
+      getGrafanaLiveSrv().getDataStream({
+        key: `${request.requestId}.${target.refId}`,
+        addr: { scope: LiveChannelScope.DataSource, namespace: this.uid, path: getStreamPath(target), data: getStreamData(target) },
+      })
+    );
+    const targets = request.targets.filter((t) => !isStreamingQuery(t));
+    if (targets.length > 0) {
+      streams.push(this.runQuery({ ...request, targets }));
+    }
+    return merge(...streams);
+  }
+  private runQuery(request: DataQueryRequest<InfinityQuery>): Observable<DataQueryResponse> {
     return new Observable<DataQueryResponse>((subscriber) => {
-      let request = getUpdatedDataRequest(options, this.instanceSettings);
       super
         .query(request)
         .toPromise()
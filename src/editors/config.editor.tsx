@@ -16,6 +16,9 @@ import { CustomHealthCheckEditor } from '@/editors/config/CustomHealthCheckEdito
 import type { DataSourcePluginOptionsEditorProps, DataSourceSettings } from '@grafana/data';
 import type { InfinityOptions } from '@/types';
 import { KeepCookiesEditor } from '@/editors/config/KeepCookies';
+import { ResponseCacheEditor } from '@/editors/config/ResponseCache';
+import { RetryEditor } from '@/editors/config/Retry';
+import { RateLimitEditor } from '@/editors/config/RateLimit';
 
 const Collapse = CollapseOriginal as any;
 
@@ -118,6 +121,15 @@ export const NetworkEditor = (props: DataSourcePluginOptionsEditorProps<Infinity
           ></Input>
         </Stack>
       </Collapse>
+      <Collapse isOpen={true} collapsible={true} label={'Response Cache'}>
+        <ResponseCacheEditor options={options} onOptionsChange={onOptionsChange} />
+      </Collapse>
+      <Collapse isOpen={true} collapsible={true} label={'Retries'}>
+        <RetryEditor options={options} onOptionsChange={onOptionsChange} />
+      </Collapse>
+      <Collapse isOpen={true} collapsible={true} label={'Rate Limit'}>
+        <RateLimitEditor options={options} onOptionsChange={onOptionsChange} />
+      </Collapse>
       <Collapse isOpen={true} collapsible={true} label={'TLS / SSL Settings'}>
         <TLSConfigEditor options={options} onOptionsChange={onOptionsChange} hideTile={true} />
       </Collapse>
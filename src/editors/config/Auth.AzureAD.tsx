@@ -0,0 +1,137 @@
+import React from 'react';
+import { Stack, InlineLabel, Input, SecretInput, SecretTextArea, Combobox } from '@grafana/ui';
+import { onUpdateDatasourceSecureJsonDataOption, DataSourcePluginOptionsEditorProps } from '@grafana/data';
+import { Components } from '@/selectors';
+import { AzureBlobRegions, AzureBlobCloudTypeDefault, AzureADAuthTypes } from '@/constants';
+import type { AzureADAuthType, AzureADProps, InfinityOptions, InfinitySecureOptions } from '@/types';
+
+export const AzureADAuthEditor = (
+  props: DataSourcePluginOptionsEditorProps<InfinityOptions> & {
+    onResetSecret: (key: keyof InfinitySecureOptions) => void;
+  }
+) => {
+  const { options, onOptionsChange, onResetSecret } = props;
+  const { secureJsonFields } = options;
+  const {
+    Region: RegionSelector,
+    AuthType: AuthTypeSelector,
+    TenantID: TenantIDSelector,
+    ClientID: ClientIDSelector,
+    ClientSecret: ClientSecretSelector,
+    ClientCertificate: ClientCertificateSelector,
+    Scope: ScopeSelector,
+  } = Components.ConfigEditor.Auth.AzureAD;
+  const azureAD = options.jsonData?.azureAD || {};
+  const authType: AzureADAuthType = azureAD.authType || 'clientSecret';
+  const allowedHosts = (options.jsonData?.allowedHosts || []).filter((host) => host.trim() !== '');
+  const onAzureADChange = (value: AzureADProps) => {
+    onOptionsChange({ ...options, jsonData: { ...options.jsonData, azureAD: { ...options.jsonData?.azureAD, ...value } } });
+  };
+  const onScopeChange = (host: string, scope: string) => {
+    onAzureADChange({ scopes: { ...azureAD.scopes, [host]: scope } });
+  };
+  if (options.jsonData.auth_method !== 'azureAD') {
+    return <></>;
+  }
+  return (
+    <Stack direction={'column'}>
+      <Stack>
+        <InlineLabel width={24} tooltip={RegionSelector.tooltip}>
+          {RegionSelector.label}
+        </InlineLabel>
+        <Combobox
+          width={24}
+          aria-label={RegionSelector.ariaLabel}
+          options={AzureBlobRegions}
+          onChange={(e) => onAzureADChange({ cloudType: e.value })}
+          value={azureAD.cloudType || AzureBlobCloudTypeDefault}
+        />
+      </Stack>
+      <Stack>
+        <InlineLabel width={24} tooltip={AuthTypeSelector.tooltip}>
+          {AuthTypeSelector.label}
+        </InlineLabel>
+        <Combobox width={24} aria-label={AuthTypeSelector.ariaLabel} options={AzureADAuthTypes} onChange={(e) => onAzureADChange({ authType: e.value })} value={authType} />
+      </Stack>
+      {authType !== 'managedIdentity' && (
+        <Stack>
+          <InlineLabel width={24} tooltip={TenantIDSelector.tooltip}>
+            {TenantIDSelector.label}
+          </InlineLabel>
+          <Input
+            required={authType !== 'workloadIdentity'}
+            role="input"
+            aria-label={TenantIDSelector.ariaLabel}
+            placeholder={TenantIDSelector.placeholder}
+            width={48}
+            value={azureAD.tenantId || ''}
+            onChange={(e) => onAzureADChange({ tenantId: e.currentTarget.value })}
+          ></Input>
+        </Stack>
+      )}
+      <Stack>
+        <InlineLabel width={24} tooltip={ClientIDSelector.tooltip}>
+          {ClientIDSelector.label}
+        </InlineLabel>
+        <Input
+          required={authType === 'clientSecret' || authType === 'clientCertificate'}
+          role="input"
+          aria-label={ClientIDSelector.ariaLabel}
+          placeholder={ClientIDSelector.placeholder}
+          width={48}
+          value={azureAD.clientId || ''}
+          onChange={(e) => onAzureADChange({ clientId: e.currentTarget.value })}
+        ></Input>
+      </Stack>
+      {authType === 'clientSecret' && (
+        <Stack>
+          <InlineLabel width={24} tooltip={ClientSecretSelector.tooltip}>
+            {ClientSecretSelector.label}
+          </InlineLabel>
+          <SecretInput
+            required
+            role="input"
+            aria-label={ClientSecretSelector.ariaLabel}
+            placeholder={ClientSecretSelector.placeholder}
+            width={48}
+            isConfigured={(secureJsonFields && secureJsonFields.azureADClientSecret) as boolean}
+            onChange={onUpdateDatasourceSecureJsonDataOption(props, 'azureADClientSecret')}
+            onReset={() => onResetSecret('azureADClientSecret')}
+          />
+        </Stack>
+      )}
+      {authType === 'clientCertificate' && (
+        <Stack>
+          <InlineLabel width={24} tooltip={ClientCertificateSelector.tooltip}>
+            {ClientCertificateSelector.label}
+          </InlineLabel>
+          <SecretTextArea
+            cols={48}
+            rows={6}
+            aria-label={ClientCertificateSelector.ariaLabel}
+            placeholder={ClientCertificateSelector.placeholder}
+            isConfigured={(secureJsonFields && secureJsonFields.azureADClientCertificate) as boolean}
+            value={(options.secureJsonData as InfinitySecureOptions)?.azureADClientCertificate || ''}
+            onChange={onUpdateDatasourceSecureJsonDataOption(props, 'azureADClientCertificate')}
+            onReset={() => onResetSecret('azureADClientCertificate')}
+          />
+        </Stack>
+      )}
+      {allowedHosts.map((host) => (
+        <Stack key={host}>
+          <InlineLabel width={24} tooltip={ScopeSelector.tooltip}>
+            {`${ScopeSelector.label} · ${host}`}
+          </InlineLabel>
+          <Input
+            role="input"
+            aria-label={`${ScopeSelector.ariaLabel} ${host}`}
+            placeholder="Derived from the host"
+            width={48}
+            value={azureAD.scopes?.[host] || ''}
+            onChange={(e) => onScopeChange(host, e.currentTarget.value)}
+          ></Input>
+        </Stack>
+      ))}
+    </Stack>
+  );
+};
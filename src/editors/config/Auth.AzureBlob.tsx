@@ -2,8 +2,8 @@ import React from 'react';
 import { Stack, InlineLabel, Input, SecretInput, Combobox } from '@grafana/ui';
 import { onUpdateDatasourceSecureJsonDataOption, DataSourcePluginOptionsEditorProps } from '@grafana/data';
 import { Components } from '@/selectors';
-import { AzureBlobRegions, AzureBlobCloudTypeDefault } from '@/constants';
-import type { InfinityOptions, InfinitySecureOptions } from '@/types';
+import { AzureBlobRegions, AzureBlobCloudTypeDefault, AzureBlobAuthTypes } from '@/constants';
+import type { AzureBlobAuthType, InfinityOptions, InfinitySecureOptions } from '@/types';
 
 export const AzureBlobAuthEditor = (
   props: DataSourcePluginOptionsEditorProps<InfinityOptions> & {
@@ -12,13 +12,32 @@ export const AzureBlobAuthEditor = (
 ) => {
   const { options, onOptionsChange, onResetSecret } = props;
   const { secureJsonFields } = options;
-  const { Region: RegionSelector, StorageAccountName: StorageAccountNameSelector, StorageAccountKey: StorageAccountKeySelector } = Components.ConfigEditor.Auth.AzureBlob;
+  const {
+    Region: RegionSelector,
+    AuthType: AuthTypeSelector,
+    StorageAccountName: StorageAccountNameSelector,
+    StorageAccountKey: StorageAccountKeySelector,
+    SASToken: SASTokenSelector,
+    TenantID: TenantIDSelector,
+    ClientID: ClientIDSelector,
+    ClientSecret: ClientSecretSelector,
+  } = Components.ConfigEditor.Auth.AzureBlob;
+  const authType: AzureBlobAuthType = options.jsonData?.azureBlobAuthType || 'sharedKey';
   const onAzureBlobUrlChange = (azureBlobCloudType = AzureBlobCloudTypeDefault) => {
     onOptionsChange({ ...options, jsonData: { ...options.jsonData, azureBlobCloudType } });
   };
+  const onAzureBlobAuthTypeChange = (azureBlobAuthType: AzureBlobAuthType) => {
+    onOptionsChange({ ...options, jsonData: { ...options.jsonData, azureBlobAuthType } });
+  };
   const onAzureBlobAccountChange = (azureBlobAccountName: string) => {
     onOptionsChange({ ...options, jsonData: { ...options.jsonData, azureBlobAccountName } });
   };
+  const onAzureBlobTenantIdChange = (azureBlobTenantId: string) => {
+    onOptionsChange({ ...options, jsonData: { ...options.jsonData, azureBlobTenantId } });
+  };
+  const onAzureBlobClientIdChange = (azureBlobClientId: string) => {
+    onOptionsChange({ ...options, jsonData: { ...options.jsonData, azureBlobClientId } });
+  };
   if (options.jsonData.auth_method !== 'azureBlob') {
     return <></>;
   }
@@ -36,12 +55,18 @@ export const AzureBlobAuthEditor = (
           value={props.options.jsonData?.azureBlobCloudType || AzureBlobCloudTypeDefault}
         />
       </Stack>
+      <Stack>
+        <InlineLabel width={24} tooltip={AuthTypeSelector.tooltip}>
+          {AuthTypeSelector.label}
+        </InlineLabel>
+        <Combobox width={24} aria-label={AuthTypeSelector.ariaLabel} options={AzureBlobAuthTypes} onChange={(e) => onAzureBlobAuthTypeChange(e.value)} value={authType} />
+      </Stack>
       <Stack>
         <InlineLabel width={24} tooltip={StorageAccountNameSelector.tooltip}>
           {StorageAccountNameSelector.label}
         </InlineLabel>
         <Input
-          required
+          required={authType !== 'sasToken'}
           role="input"
           aria-label={StorageAccountNameSelector.ariaLabel}
           placeholder={StorageAccountNameSelector.placeholder}
@@ -50,21 +75,89 @@ export const AzureBlobAuthEditor = (
           onChange={(e) => onAzureBlobAccountChange(e.currentTarget.value)}
         ></Input>
       </Stack>
-      <Stack>
-        <InlineLabel width={24} tooltip={StorageAccountKeySelector.tooltip}>
-          {StorageAccountKeySelector.label}
-        </InlineLabel>
-        <SecretInput
-          required
-          role="input"
-          aria-label={StorageAccountKeySelector.ariaLabel}
-          placeholder={StorageAccountKeySelector.placeholder}
-          width={24}
-          isConfigured={(secureJsonFields && secureJsonFields.azureBlobAccountKey) as boolean}
-          onChange={onUpdateDatasourceSecureJsonDataOption(props, 'azureBlobAccountKey')}
-          onReset={() => onResetSecret('azureBlobAccountKey')}
-        />
-      </Stack>
+      {authType === 'sharedKey' && (
+        <Stack>
+          <InlineLabel width={24} tooltip={StorageAccountKeySelector.tooltip}>
+            {StorageAccountKeySelector.label}
+          </InlineLabel>
+          <SecretInput
+            required
+            role="input"
+            aria-label={StorageAccountKeySelector.ariaLabel}
+            placeholder={StorageAccountKeySelector.placeholder}
+            width={24}
+            isConfigured={(secureJsonFields && secureJsonFields.azureBlobAccountKey) as boolean}
+            onChange={onUpdateDatasourceSecureJsonDataOption(props, 'azureBlobAccountKey')}
+            onReset={() => onResetSecret('azureBlobAccountKey')}
+          />
+        </Stack>
+      )}
+      {authType === 'sasToken' && (
+        <Stack>
+          <InlineLabel width={24} tooltip={SASTokenSelector.tooltip}>
+            {SASTokenSelector.label}
+          </InlineLabel>
+          <SecretInput
+            required
+            role="input"
+            aria-label={SASTokenSelector.ariaLabel}
+            placeholder={SASTokenSelector.placeholder}
+            width={48}
+            isConfigured={(secureJsonFields && secureJsonFields.azureBlobSASToken) as boolean}
+            onChange={onUpdateDatasourceSecureJsonDataOption(props, 'azureBlobSASToken')}
+            onReset={() => onResetSecret('azureBlobSASToken')}
+          />
+        </Stack>
+      )}
+      {(authType === 'clientSecret' || authType === 'workloadIdentity') && (
+        <Stack>
+          <InlineLabel width={24} tooltip={TenantIDSelector.tooltip}>
+            {TenantIDSelector.label}
+          </InlineLabel>
+          <Input
+            required={authType === 'clientSecret'}
+            role="input"
+            aria-label={TenantIDSelector.ariaLabel}
+            placeholder={TenantIDSelector.placeholder}
+            width={48}
+            value={props.options.jsonData?.azureBlobTenantId || ''}
+            onChange={(e) => onAzureBlobTenantIdChange(e.currentTarget.value)}
+          ></Input>
+        </Stack>
+      )}
+      {(authType === 'clientSecret' || authType === 'managedIdentity' || authType === 'workloadIdentity') && (
+        <Stack>
+          <InlineLabel width={24} tooltip={ClientIDSelector.tooltip}>
+            {ClientIDSelector.label}
+          </InlineLabel>
+          <Input
+            required={authType === 'clientSecret'}
+            role="input"
+            aria-label={ClientIDSelector.ariaLabel}
+            placeholder={ClientIDSelector.placeholder}
+            width={48}
+            value={props.options.jsonData?.azureBlobClientId || ''}
+            onChange={(e) => onAzureBlobClientIdChange(e.currentTarget.value)}
+          ></Input>
+        </Stack>
+      )}
+      {authType === 'clientSecret' && (
+        <Stack>
+          <InlineLabel width={24} tooltip={ClientSecretSelector.tooltip}>
+            {ClientSecretSelector.label}
+          </InlineLabel>
+          <SecretInput
+            required
+            role="input"
+            aria-label={ClientSecretSelector.ariaLabel}
+            placeholder={ClientSecretSelector.placeholder}
+            width={48}
+            isConfigured={(secureJsonFields && secureJsonFields.azureBlobClientSecret) as boolean}
+            onChange={onUpdateDatasourceSecureJsonDataOption(props, 'azureBlobClientSecret')}
+            onReset={() => onResetSecret('azureBlobClientSecret')}
+          />
+        </Stack>
+      )}
     </Stack>
   );
 };
@@ -0,0 +1,110 @@
+import React from 'react';
+import { Stack, InlineLabel, Input, SecretInput, SecretTextArea, RadioButtonGroup, FileDropzone } from '@grafana/ui';
+import { onUpdateDatasourceSecureJsonDataOption, DataSourcePluginOptionsEditorProps } from '@grafana/data';
+import { Components } from '@/selectors';
+import type { GoogleAuthProps, InfinityOptions, InfinitySecureOptions } from '@/types';
+
+export const GoogleAuthEditor = (
+  props: DataSourcePluginOptionsEditorProps<InfinityOptions> & {
+    onResetSecret: (key: keyof InfinitySecureOptions) => void;
+  }
+) => {
+  const { options, onOptionsChange, onResetSecret } = props;
+  const { secureJsonFields } = options;
+  const { AuthType: AuthTypeSelector, ServiceAccountKey: ServiceAccountKeySelector, APIKey: APIKeySelector, HealthCheckSpreadsheetID: SpreadsheetSelector, HealthCheckBucket: BucketSelector } = Components.ConfigEditor.Auth.Google;
+  const google = options.jsonData?.google || {};
+  const authType = google.authType || 'serviceAccount';
+  const onGoogleChange = (value: GoogleAuthProps) => {
+    onOptionsChange({ ...options, jsonData: { ...options.jsonData, google: { ...options.jsonData?.google, ...value } } });
+  };
+  const onServiceAccountKeyLoad = (googleServiceAccountKey: string) => {
+    onOptionsChange({ ...options, secureJsonData: { ...options.secureJsonData, googleServiceAccountKey } });
+  };
+  if (options.jsonData.auth_method !== 'google') {
+    return <></>;
+  }
+  return (
+    <Stack direction={'column'}>
+      <Stack>
+        <InlineLabel width={24} tooltip={AuthTypeSelector.tooltip}>
+          {AuthTypeSelector.label}
+        </InlineLabel>
+        <RadioButtonGroup<NonNullable<GoogleAuthProps['authType']>>
+          options={[
+            { value: 'serviceAccount', label: 'Service account' },
+            { value: 'apiKey', label: 'API key' },
+          ]}
+          value={authType}
+          onChange={(authType) => onGoogleChange({ authType })}
+        />
+      </Stack>
+      {authType === 'serviceAccount' && (
+        <Stack>
+          <InlineLabel width={24} tooltip={ServiceAccountKeySelector.tooltip}>
+            {ServiceAccountKeySelector.label}
+          </InlineLabel>
+          <Stack direction={'column'}>
+            <SecretTextArea
+              cols={48}
+              rows={6}
+              aria-label={ServiceAccountKeySelector.ariaLabel}
+              placeholder={ServiceAccountKeySelector.placeholder}
+              isConfigured={(secureJsonFields && secureJsonFields.googleServiceAccountKey) as boolean}
+              value={(options.secureJsonData as InfinitySecureOptions)?.googleServiceAccountKey || ''}
+              onChange={onUpdateDatasourceSecureJsonDataOption(props, 'googleServiceAccountKey')}
+              onReset={() => onResetSecret('googleServiceAccountKey')}
+            />
+            {!secureJsonFields?.googleServiceAccountKey && (
+              <FileDropzone options={{ multiple: false, accept: { 'application/json': ['.json'] } }} onLoad={(result) => onServiceAccountKeyLoad(result as string)} />
+            )}
+          </Stack>
+        </Stack>
+      )}
+      {authType === 'apiKey' && (
+        <Stack>
+          <InlineLabel width={24} tooltip={APIKeySelector.tooltip}>
+            {APIKeySelector.label}
+          </InlineLabel>
+          <SecretInput
+            required
+            role="input"
+            aria-label={APIKeySelector.ariaLabel}
+            placeholder={APIKeySelector.placeholder}
+            width={48}
+            isConfigured={(secureJsonFields && secureJsonFields.googleApiKey) as boolean}
+            onChange={onUpdateDatasourceSecureJsonDataOption(props, 'googleApiKey')}
+            onReset={() => onResetSecret('googleApiKey')}
+          />
+        </Stack>
+      )}
+      <Stack>
+        <InlineLabel width={24} tooltip={SpreadsheetSelector.tooltip}>
+          {SpreadsheetSelector.label}
+        </InlineLabel>
+        <Input
+          role="input"
+          aria-label={SpreadsheetSelector.ariaLabel}
+          placeholder={SpreadsheetSelector.placeholder}
+          width={48}
+          value={google.healthCheckSpreadsheetId || ''}
+          onChange={(e) => onGoogleChange({ healthCheckSpreadsheetId: e.currentTarget.value })}
+        ></Input>
+      </Stack>
+      {authType === 'serviceAccount' && (
+        <Stack>
+          <InlineLabel width={24} tooltip={BucketSelector.tooltip}>
+            {BucketSelector.label}
+          </InlineLabel>
+          <Input
+            role="input"
+            aria-label={BucketSelector.ariaLabel}
+            placeholder={BucketSelector.placeholder}
+            width={48}
+            value={google.healthCheckBucket || ''}
+            onChange={(e) => onGoogleChange({ healthCheckBucket: e.currentTarget.value })}
+          ></Input>
+        </Stack>
+      )}
+    </Stack>
+  );
+};
@@ -0,0 +1,140 @@
+import React from 'react';
+import { Stack, InlineLabel, Input, SecretInput, RadioButtonGroup } from '@grafana/ui';
+import { onUpdateDatasourceSecureJsonDataOption, DataSourcePluginOptionsEditorProps } from '@grafana/data';
+import { Components } from '@/selectors';
+import type { InfinityOptions, InfinitySecureOptions, TokenExchangeProps, TokenExchangeType } from '@/types';
+
+export const TokenExchangeEditor = (
+  props: DataSourcePluginOptionsEditorProps<InfinityOptions> & {
+    onResetSecret: (key: keyof InfinitySecureOptions) => void;
+  }
+) => {
+  const { options, onOptionsChange, onResetSecret } = props;
+  const { secureJsonFields } = options;
+  const {
+    Type: TypeSelector,
+    TokenURL: TokenURLSelector,
+    ClientID: ClientIDSelector,
+    ClientSecret: ClientSecretSelector,
+    Scopes: ScopesSelector,
+    Audience: AudienceSelector,
+    Resource: ResourceSelector,
+    SubjectTokenType: SubjectTokenTypeSelector,
+  } = Components.ConfigEditor.Auth.TokenExchange;
+  const tokenExchange = options.jsonData?.tokenExchange || {};
+  const onTokenExchangeChange = (value: TokenExchangeProps) => {
+    onOptionsChange({ ...options, jsonData: { ...options.jsonData, tokenExchange: { ...options.jsonData?.tokenExchange, ...value } } });
+  };
+  return (
+    <Stack direction={'column'}>
+      <Stack>
+        <InlineLabel width={24} tooltip={TypeSelector.tooltip}>
+          {TypeSelector.label}
+        </InlineLabel>
+        <RadioButtonGroup<TokenExchangeType | ''>
+          options={[
+            { value: '', label: 'Forward as is' },
+            { value: 'rfc8693', label: 'Token exchange (RFC 8693)' },
+            { value: 'on_behalf_of', label: 'Entra ID on-behalf-of' },
+          ]}
+          value={tokenExchange.type || ''}
+          onChange={(type) => onTokenExchangeChange({ type: type || undefined })}
+        />
+      </Stack>
+      {tokenExchange.type && (
+        <>
+          <Stack>
+            <InlineLabel width={24} tooltip={TokenURLSelector.tooltip}>
+              {TokenURLSelector.label}
+            </InlineLabel>
+            <Input
+              aria-label={TokenURLSelector.ariaLabel}
+              placeholder={TokenURLSelector.placeholder}
+              width={48}
+              value={tokenExchange.tokenUrl || ''}
+              onChange={(e) => onTokenExchangeChange({ tokenUrl: e.currentTarget.value })}
+            />
+          </Stack>
+          <Stack>
+            <InlineLabel width={24} tooltip={ClientIDSelector.tooltip}>
+              {ClientIDSelector.label}
+            </InlineLabel>
+            <Input
+              aria-label={ClientIDSelector.ariaLabel}
+              placeholder={ClientIDSelector.placeholder}
+              width={48}
+              value={tokenExchange.clientId || ''}
+              onChange={(e) => onTokenExchangeChange({ clientId: e.currentTarget.value })}
+            />
+          </Stack>
+          <Stack>
+            <InlineLabel width={24} tooltip={ClientSecretSelector.tooltip}>
+              {ClientSecretSelector.label}
+            </InlineLabel>
+            <SecretInput
+              aria-label={ClientSecretSelector.ariaLabel}
+              placeholder={ClientSecretSelector.placeholder}
+              width={48}
+              isConfigured={(secureJsonFields && secureJsonFields.tokenExchangeClientSecret) as boolean}
+              onChange={onUpdateDatasourceSecureJsonDataOption(props, 'tokenExchangeClientSecret')}
+              onReset={() => onResetSecret('tokenExchangeClientSecret')}
+            />
+          </Stack>
+          <Stack>
+            <InlineLabel width={24} tooltip={ScopesSelector.tooltip}>
+              {ScopesSelector.label}
+            </InlineLabel>
+            <Input
+              aria-label={ScopesSelector.ariaLabel}
+              placeholder={ScopesSelector.placeholder}
+              width={48}
+              value={(tokenExchange.scopes || []).join(',')}
+              onChange={(e) => onTokenExchangeChange({ scopes: (e.currentTarget.value || '').split(',') })}
+            />
+          </Stack>
+          {tokenExchange.type === 'rfc8693' && (
+            <>
+              <Stack>
+                <InlineLabel width={24} tooltip={AudienceSelector.tooltip}>
+                  {AudienceSelector.label}
+                </InlineLabel>
+                <Input
+                  aria-label={AudienceSelector.ariaLabel}
+                  placeholder={AudienceSelector.placeholder}
+                  width={48}
+                  value={tokenExchange.audience || ''}
+                  onChange={(e) => onTokenExchangeChange({ audience: e.currentTarget.value })}
+                />
+              </Stack>
+              <Stack>
+                <InlineLabel width={24} tooltip={ResourceSelector.tooltip}>
+                  {ResourceSelector.label}
+                </InlineLabel>
+                <Input
+                  aria-label={ResourceSelector.ariaLabel}
+                  placeholder={ResourceSelector.placeholder}
+                  width={48}
+                  value={tokenExchange.resource || ''}
+                  onChange={(e) => onTokenExchangeChange({ resource: e.currentTarget.value })}
+                />
+              </Stack>
+              <Stack>
+                <InlineLabel width={24} tooltip={SubjectTokenTypeSelector.tooltip}>
+                  {SubjectTokenTypeSelector.label}
+                </InlineLabel>
+                <RadioButtonGroup<NonNullable<TokenExchangeProps['subjectTokenType']>>
+                  options={[
+                    { value: 'urn:ietf:params:oauth:token-type:access_token', label: 'Access token' },
+                    { value: 'urn:ietf:params:oauth:token-type:id_token', label: 'ID token' },
+                  ]}
+                  value={tokenExchange.subjectTokenType || 'urn:ietf:params:oauth:token-type:access_token'}
+                  onChange={(subjectTokenType) => onTokenExchangeChange({ subjectTokenType })}
+                />
+              </Stack>
+            </>
+          )}
+        </>
+      )}
+    </Stack>
+  );
+};
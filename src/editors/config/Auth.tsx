@@ -1,13 +1,16 @@
 import { css } from '@emotion/css';
 import { onUpdateDatasourceSecureJsonDataOption, DataSourcePluginOptionsEditorProps, SelectableValue } from '@grafana/data';
-import { Icon, InlineFormLabel, LegacyForms, RadioButtonGroup, Combobox, Grid, Link, useTheme2 } from '@grafana/ui';
+import { Icon, InlineFormLabel, InlineSwitch, LegacyForms, RadioButtonGroup, Combobox, Grid, Link, useTheme2 } from '@grafana/ui';
 import React, { useState } from 'react';
 import { AllowedHostsEditor } from '@/editors/config/AllowedHosts';
+import { AzureADAuthEditor } from '@/editors/config/Auth.AzureAD';
 import { AzureBlobAuthEditor } from '@/editors/config/Auth.AzureBlob';
+import { GoogleAuthEditor } from '@/editors/config/Auth.Google';
+import { TokenExchangeEditor } from '@/editors/config/Auth.TokenExchange';
 import { OAuthInputsEditor } from '@/editors/config/OAuthInput';
 import { OthersAuthentication } from '@/editors/config/OtherAuthProviders';
-import { AWSRegions } from '@/constants';
-import type { APIKeyType, AuthType, InfinityOptions, InfinitySecureOptions } from '@/types';
+import { AWSAuthTypes, AWSRegions } from '@/constants';
+import type { APIKeyType, AuthType, AWSAuthProps, AWSAuthType, InfinityOptions, InfinitySecureOptions } from '@/types';
 
 const authTypes: Array<SelectableValue<AuthType | 'others'> & { logo?: string }> = [
   { value: 'none', label: 'No Auth' },
@@ -19,6 +22,8 @@ const authTypes: Array<SelectableValue<AuthType | 'others'> & { logo?: string }>
   { value: 'oauth2', label: 'OAuth2', logo: '/public/plugins/yesoreyeram-infinity-datasource/img/oauth-2-sm.png' },
   { value: 'aws', label: 'AWS', logo: '/public/plugins/yesoreyeram-infinity-datasource/img/aws.jpg' },
   { value: 'azureBlob', label: 'Azure Blob' },
+  { value: 'google', label: 'Google' },
+  { value: 'azureAD', label: 'Azure AD' },
   { value: 'others', label: 'Other Auth Providers' },
 ];
 
@@ -77,7 +82,15 @@ export const AuthEditor = (props: DataSourcePluginOptionsEditorProps<InfinityOpt
       case 'apiKey':
       case 'bearerToken':
       case 'aws':
+        onOptionsChange({
+          ...options,
+          basicAuth: false,
+          jsonData: { ...options.jsonData, oauthPassThru: false, auth_method: authMethod, aws: { ...options.jsonData?.aws, authType: options.jsonData?.aws?.authType || 'keys' } },
+        });
+        break;
       case 'azureBlob':
+      case 'google':
+      case 'azureAD':
       case 'oauth2':
       case 'none':
       default:
@@ -90,11 +103,16 @@ export const AuthEditor = (props: DataSourcePluginOptionsEditorProps<InfinityOpt
   const onAPIKeyKeyChange = (apiKeyKey: string) => {
     onOptionsChange({ ...options, jsonData: { ...options.jsonData, apiKeyKey } });
   };
+  const awsAuthType: AWSAuthType = options.jsonData?.aws?.authType || 'keys';
+  // auth type is always saved so that the data sources without the auth type can be told apart as legacy
+  const onAwsChange = (aws: AWSAuthProps) => {
+    onOptionsChange({ ...options, jsonData: { ...options.jsonData, aws: { ...options.jsonData?.aws, authType: awsAuthType, ...aws } } });
+  };
   const onAwsRegionChange = (region: string) => {
-    onOptionsChange({ ...options, jsonData: { ...options.jsonData, aws: { ...options.jsonData?.aws, region } } });
+    onAwsChange({ region });
   };
   const onAwsServiceChange = (service: string) => {
-    onOptionsChange({ ...options, jsonData: { ...options.jsonData, aws: { ...options.jsonData?.aws, service } } });
+    onAwsChange({ service });
   };
   const onResetSecret = (key: keyof InfinitySecureOptions) => {
     onOptionsChange({
@@ -226,55 +244,130 @@ export const AuthEditor = (props: DataSourcePluginOptionsEditorProps<InfinityOpt
             {authType === 'aws' && (
               <>
                 <div className="gf-form">
-                  <InlineFormLabel>Region</InlineFormLabel>
-                  <Combobox width={24} options={AWSRegions} placeholder="us-east-2" onChange={(e) => onAwsRegionChange(e.value!)} value={props.options.jsonData?.aws?.region || ''} />
+                  <InlineFormLabel tooltip="Default uses the AWS SDK credential chain of the Grafana host such as environment variables, web identity token (EKS IRSA), ECS task role and EC2 instance role">Authentication</InlineFormLabel>
+                  <Combobox width={24} options={AWSAuthTypes} onChange={(e) => onAwsChange({ authType: e.value as AWSAuthType })} value={awsAuthType} />
+                </div>
+                <div className="gf-form">
+                  <InlineFormLabel tooltip="Region used to sign the requests. When empty, the AWS_REGION environment variable of the Grafana host is used. Required except for the access and secret key authentication, which defaults to us-east-2">Region</InlineFormLabel>
+                  <Combobox width={24} options={AWSRegions} placeholder="AWS_REGION" onChange={(e) => onAwsRegionChange(e.value!)} value={props.options.jsonData?.aws?.region || ''} />
                 </div>
                 <div className="gf-form">
                   <FormField
                     label="Service"
-                    placeholder="monitoring"
+                    placeholder="execute-api"
+                    tooltip="Service used to sign the requests. For example execute-api for API Gateway, monitoring for CloudWatch and s3 for S3. Required except for the access and secret key authentication, which defaults to monitoring"
                     labelWidth={10}
+                    required={awsAuthType !== 'keys'}
                     value={props.options.jsonData?.aws?.service || ''}
                     onChange={(e) => onAwsServiceChange(e.currentTarget.value)}
                   ></FormField>
                 </div>
+                {awsAuthType === 'keys' && (
+                  <>
+                    <div className="gf-form">
+                      <SecretFormField
+                        labelWidth={10}
+                        inputWidth={12}
+                        required
+                        value={secureJsonData.awsAccessKey || ''}
+                        isConfigured={(secureJsonFields && secureJsonFields.awsAccessKey) as boolean}
+                        onReset={() => onResetSecret('awsAccessKey')}
+                        onChange={onUpdateDatasourceSecureJsonDataOption(props, 'awsAccessKey')}
+                        label="Access Key"
+                        aria-label="aws access key"
+                        placeholder="aws access key"
+                        tooltip="aws access key"
+                      />
+                    </div>
+                    <div className="gf-form">
+                      <SecretFormField
+                        labelWidth={10}
+                        inputWidth={12}
+                        required
+                        value={secureJsonData.awsSecretKey || ''}
+                        isConfigured={(secureJsonFields && secureJsonFields.awsSecretKey) as boolean}
+                        onReset={() => onResetSecret('awsSecretKey')}
+                        onChange={onUpdateDatasourceSecureJsonDataOption(props, 'awsSecretKey')}
+                        label="Secret Key"
+                        aria-label="aws secret key"
+                        placeholder="aws secret key"
+                        tooltip="aws secret key"
+                      />
+                    </div>
+                  </>
+                )}
+                {awsAuthType === 'credentials' && (
+                  <div className="gf-form">
+                    <FormField
+                      label="Profile"
+                      placeholder="default"
+                      tooltip="Profile of the shared credentials file of the Grafana host"
+                      labelWidth={10}
+                      value={props.options.jsonData?.aws?.profile || ''}
+                      onChange={(e) => onAwsChange({ profile: e.currentTarget.value })}
+                    ></FormField>
+                  </div>
+                )}
                 <div className="gf-form">
-                  <SecretFormField
+                  <FormField
+                    label="Assume role ARN"
+                    placeholder="arn:aws:iam::123456789012:role/grafana"
+                    tooltip="Optional. IAM role assumed using STS after resolving the credentials"
                     labelWidth={10}
-                    inputWidth={12}
-                    required
-                    value={secureJsonData.awsAccessKey || ''}
-                    isConfigured={(secureJsonFields && secureJsonFields.awsAccessKey) as boolean}
-                    onReset={() => onResetSecret('awsAccessKey')}
-                    onChange={onUpdateDatasourceSecureJsonDataOption(props, 'awsAccessKey')}
-                    label="Access Key"
-                    aria-label="aws access key"
-                    placeholder="aws access key"
-                    tooltip="aws access key"
-                  />
+                    inputWidth={20}
+                    value={props.options.jsonData?.aws?.assumeRoleArn || ''}
+                    onChange={(e) => onAwsChange({ assumeRoleArn: e.currentTarget.value })}
+                  ></FormField>
                 </div>
                 <div className="gf-form">
-                  <SecretFormField
+                  <FormField
+                    label="External ID"
+                    placeholder="External ID"
+                    tooltip="Optional. External ID passed to STS when assuming the role"
                     labelWidth={10}
-                    inputWidth={12}
-                    required
-                    value={secureJsonData.awsSecretKey || ''}
-                    isConfigured={(secureJsonFields && secureJsonFields.awsSecretKey) as boolean}
-                    onReset={() => onResetSecret('awsSecretKey')}
-                    onChange={onUpdateDatasourceSecureJsonDataOption(props, 'awsSecretKey')}
-                    label="Secret Key"
-                    aria-label="aws secret key"
-                    placeholder="aws secret key"
-                    tooltip="aws secret key"
-                  />
+                    value={props.options.jsonData?.aws?.externalId || ''}
+                    onChange={(e) => onAwsChange({ externalId: e.currentTarget.value })}
+                  ></FormField>
+                </div>
+                <div className="gf-form">
+                  <FormField
+                    label="S3 Endpoint"
+                    placeholder="Amazon S3"
+                    tooltip="Endpoint of the S3 compatible object storage such as MinIO. Leave empty for Amazon S3"
+                    labelWidth={10}
+                    value={props.options.jsonData?.aws?.endpoint || ''}
+                    onChange={(e) => onAwsChange({ endpoint: e.currentTarget.value })}
+                  ></FormField>
+                </div>
+                <div className="gf-form">
+                  <InlineFormLabel tooltip="Address the bucket in the path instead of the host name. Required by most of the S3 compatible storages">Path style</InlineFormLabel>
+                  <InlineSwitch value={props.options.jsonData?.aws?.usePathStyle || false} onChange={(e) => onAwsChange({ usePathStyle: e.currentTarget.checked })} />
+                </div>
+                <div className="gf-form">
+                  <FormField
+                    label="Health check bucket"
+                    placeholder="my-bucket"
+                    tooltip="S3 bucket to verify during the health check"
+                    labelWidth={10}
+                    value={props.options.jsonData?.aws?.healthCheckBucket || ''}
+                    onChange={(e) => onAwsChange({ healthCheckBucket: e.currentTarget.value })}
+                  ></FormField>
                 </div>
               </>
             )}
             {authType === 'oauth2' && <OAuthInputsEditor {...props} />}
             {authType === 'azureBlob' && <AzureBlobAuthEditor {...props} onResetSecret={onResetSecret} />}
+            {authType === 'google' && <GoogleAuthEditor {...props} onResetSecret={onResetSecret} />}
+            {authType === 'azureAD' && <AzureADAuthEditor {...props} onResetSecret={onResetSecret} />}
           </div>
         </>
       )}
+      {authType === 'oauthPassThru' && !othersOpen && (
+        <>
+          <h5 className={styles.subheading}>Token exchange</h5>
+          <TokenExchangeEditor {...props} onResetSecret={onResetSecret} />
+        </>
+      )}
       {authType !== 'none' && authType !== 'azureBlob' && !othersOpen && (
         <>
           <h5 className={styles.subheading}>Allowed hosts</h5>
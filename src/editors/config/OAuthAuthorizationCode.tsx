@@ -0,0 +1,100 @@
+import React, { useEffect, useRef, useState } from 'react';
+import { Alert, Button, InlineFormLabel, Input, Stack } from '@grafana/ui';
+import { config, getBackendSrv } from '@grafana/runtime';
+import { DataSourcePluginOptionsEditorProps } from '@grafana/data';
+import { Components } from '@/selectors';
+import type { InfinityOptions } from '@/types';
+
+type AuthorizeResponse = { authorizeUrl: string; redirectUrl: string; state: string };
+type TokenResponse = { status: 'pending' | 'completed'; refreshToken?: string };
+type StatusResponse = { warnings?: string[] };
+
+const pollIntervalInMs = 2000;
+const pollTimeoutInMs = 10 * 60 * 1000;
+
+export const OAuthAuthorizationCodeEditor = (props: DataSourcePluginOptionsEditorProps<InfinityOptions>) => {
+  const { options, onOptionsChange } = props;
+  const { Authorize: AuthorizeSelector, RedirectURL: RedirectURLSelector } = Components.ConfigEditor.Auth.OAuth2;
+  const [status, setStatus] = useState<'idle' | 'pending' | 'completed'>('idle');
+  const [error, setError] = useState('');
+  const timer = useRef<ReturnType<typeof setTimeout>>(undefined);
+  const resourceUrl = `/api/datasources/uid/${options.uid}/resources/oauth2`;
+  const redirectUrl = `${window.location.origin}${config.appSubUrl}${resourceUrl}/callback`;
+  const [warnings, setWarnings] = useState<string[]>([]);
+  useEffect(() => () => clearTimeout(timer.current), []);
+  // warnings about the rotated refresh tokens which are not saved. available only after the data source is saved and authorized
+  const isAuthorized = !!options.secureJsonFields?.oauth2RefreshToken;
+  useEffect(() => {
+    if (!options.uid || !isAuthorized) {
+      return;
+    }
+    getBackendSrv()
+      .get<StatusResponse>(`${resourceUrl}/status`, undefined, undefined, { showErrorAlert: false })
+      .then((res) => setWarnings(res.warnings || []))
+      .catch(() => setWarnings([]));
+  }, [options.uid, isAuthorized, resourceUrl]);
+  const onRefreshToken = (oauth2RefreshToken: string) => {
+    onOptionsChange({
+      ...options,
+      secureJsonFields: { ...options.secureJsonFields, oauth2RefreshToken: false },
+      secureJsonData: { ...options.secureJsonData, oauth2RefreshToken },
+    });
+  };
+  const poll = (state: string, startedAt: number) => {
+    getBackendSrv()
+      .get<TokenResponse>(`${resourceUrl}/token`, { state }, undefined, { showErrorAlert: false })
+      .then((res) => {
+        if (res.status === 'completed' && res.refreshToken) {
+          onRefreshToken(res.refreshToken);
+          setStatus('completed');
+          return;
+        }
+        if (Date.now() - startedAt > pollTimeoutInMs) {
+          setStatus('idle');
+          setError('Authorization timed out. Try again.');
+          return;
+        }
+        timer.current = setTimeout(() => poll(state, startedAt), pollIntervalInMs);
+      })
+      .catch((ex) => {
+        setStatus('idle');
+        setError(ex?.data?.error || ex?.message || 'Authorization failed');
+      });
+  };
+  const onAuthorize = () => {
+    setError('');
+    clearTimeout(timer.current);
+    getBackendSrv()
+      .get<AuthorizeResponse>(`${resourceUrl}/authorize`, undefined, undefined, { showErrorAlert: false })
+      .then((res) => {
+        window.open(res.authorizeUrl, 'infinity-oauth2', 'width=600,height=800');
+        setStatus('pending');
+        poll(res.state, Date.now());
+      })
+      .catch((ex) => setError(ex?.data?.error || ex?.message || 'Authorization failed'));
+  };
+  return (
+    <Stack direction={'column'}>
+      <Stack gap={0.5}>
+        <InlineFormLabel width={10} tooltip={RedirectURLSelector.tooltip}>
+          {RedirectURLSelector.label}
+        </InlineFormLabel>
+        <Input value={redirectUrl} readOnly width={60} />
+      </Stack>
+      <Stack gap={0.5}>
+        <InlineFormLabel width={10} tooltip={AuthorizeSelector.tooltip}>
+          {AuthorizeSelector.label}
+        </InlineFormLabel>
+        <Button variant="secondary" icon={status === 'pending' ? 'spinner' : 'key-skeleton-alt'} disabled={!options.uid || status === 'pending'} onClick={onAuthorize}>
+          {options.secureJsonFields?.oauth2RefreshToken || status === 'completed' ? 'Authorize again' : 'Authorize'}
+        </Button>
+      </Stack>
+      {!options.secureJsonFields?.oauth2RefreshToken && status === 'idle' && !error && <Alert severity="info" title="Save the data source and authorize the data source with the authorization server" />}
+      {status === 'completed' && <Alert severity="success" title="Authorization completed. Save the data source to store the refresh token" />}
+      {error && <Alert severity="error" title={error} />}
+      {warnings.map((warning) => (
+        <Alert key={warning} severity="warning" title={warning} />
+      ))}
+    </Stack>
+  );
+};
@@ -1,22 +1,38 @@
 import { css } from '@emotion/css';
 import { onUpdateDatasourceSecureJsonDataOption, DataSourcePluginOptionsEditorProps, SelectableValue } from '@grafana/data';
-import { InlineFormLabel, Input, LegacyForms, LinkButton, RadioButtonGroup, Stack } from '@grafana/ui';
+import { Alert, InlineFormLabel, Input, LegacyForms, LinkButton, RadioButtonGroup, Stack } from '@grafana/ui';
 import React from 'react';
 import { Components } from '@/selectors';
 import { SecureFieldsEditor } from '@/components/config/SecureFieldsEditor';
-import type { InfinityOptions, InfinitySecureOptions, OAuth2Props, OAuth2Type } from '@/types';
+import { OAuthAuthorizationCodeEditor } from '@/editors/config/OAuthAuthorizationCode';
+import type { InfinityOptions, InfinitySecureOptions, OAuth2ClientAuthMethod, OAuth2Props, OAuth2Type } from '@/types';
 
 const oAuthTypes: Array<SelectableValue<OAuth2Type>> = [
   { value: 'client_credentials', label: 'Client Credentials' },
   { value: 'jwt', label: 'JWT' },
+  { value: 'authorization_code', label: 'Authorization Code' },
   { value: 'others', label: 'Others' },
 ];
 
+const oAuthClientAuthMethods: Array<SelectableValue<OAuth2ClientAuthMethod>> = [
+  { value: 'client_secret', label: 'Client Secret' },
+  { value: 'private_key_jwt', label: 'Private Key JWT' },
+  { value: 'tls_client_auth', label: 'Mutual TLS' },
+];
+
 export const OAuthInputsEditor = (props: DataSourcePluginOptionsEditorProps<InfinityOptions>) => {
   const { options, onOptionsChange } = props;
   const { secureJsonFields } = options;
   const secureJsonData = (options.secureJsonData || {}) as InfinitySecureOptions;
   let oauth2: OAuth2Props = options?.jsonData?.oauth2 || {};
+  const {
+    AuthURL: AuthURLSelector,
+    ClientAuthMethod: ClientAuthMethodSelector,
+    PrivateKey: PrivateKeySelector,
+    PrivateKeyID: PrivateKeyIDSelector,
+    ClientAssertionAudience: ClientAssertionAudienceSelector,
+  } = Components.ConfigEditor.Auth.OAuth2;
+  const clientAuthMethod = oauth2.client_auth_method || 'client_secret';
   const onOAuth2PropsChange = <T extends keyof OAuth2Props, V extends OAuth2Props[T]>(key: T, value: V) => {
     onOptionsChange({ ...options, jsonData: { ...options.jsonData, oauth2: { ...oauth2, [key]: value } } });
   };
@@ -45,51 +61,104 @@ export const OAuthInputsEditor = (props: DataSourcePluginOptionsEditorProps<Infi
       {(oauth2.oauth2_type === 'client_credentials' || !oauth2?.oauth2_type) && (
         <>
           <div className="gf-form">
-            <InlineFormLabel
-              width={10}
-              tooltip={
-                <>
-                  {`AuthStyleAutoDetect means to auto-detect which authentication style the provider wants by trying both ways and caching the successful way for the future.`}
-                  <br />
-                  <br />
-                  {`AuthStyleInParams sends the "client_id" and "client_secret" in the POST body as application/x-www-form-urlencoded parameters.`}
-                  <br />
-                  <br />
-                  {`AuthStyleInHeader sends the client_id and client_password using HTTP Basic Authorization. This is an optional style described in the OAuth2 RFC 6749 section 2.3.1.`}
-                </>
-              }
-              {...{ interactive: true }}
-            >
-              Auth Style
+            <InlineFormLabel width={10} tooltip={ClientAuthMethodSelector.tooltip} interactive={true}>
+              {ClientAuthMethodSelector.label}
             </InlineFormLabel>
-            <RadioButtonGroup
-              options={[
-                { value: 0, label: 'Auto' },
-                { value: 1, label: 'In Params' },
-                { value: 2, label: 'In Header' },
-              ]}
-              onChange={(v) => onOAuth2PropsChange('authStyle', v || 0)}
-              value={oauth2.authStyle || 0}
+            <RadioButtonGroup<OAuth2ClientAuthMethod>
+              options={oAuthClientAuthMethods}
+              onChange={(v) => onOAuth2PropsChange('client_auth_method', v)}
+              value={clientAuthMethod}
             ></RadioButtonGroup>
           </div>
+          {clientAuthMethod === 'client_secret' && (
+            <div className="gf-form">
+              <InlineFormLabel
+                width={10}
+                tooltip={
+                  <>
+                    {`AuthStyleAutoDetect means to auto-detect which authentication style the provider wants by trying both ways and caching the successful way for the future.`}
+                    <br />
+                    <br />
+                    {`AuthStyleInParams sends the "client_id" and "client_secret" in the POST body as application/x-www-form-urlencoded parameters.`}
+                    <br />
+                    <br />
+                    {`AuthStyleInHeader sends the client_id and client_password using HTTP Basic Authorization. This is an optional style described in the OAuth2 RFC 6749 section 2.3.1.`}
+                  </>
+                }
+                {...{ interactive: true }}
+              >
+                Auth Style
+              </InlineFormLabel>
+              <RadioButtonGroup
+                options={[
+                  { value: 0, label: 'Auto' },
+                  { value: 1, label: 'In Params' },
+                  { value: 2, label: 'In Header' },
+                ]}
+                onChange={(v) => onOAuth2PropsChange('authStyle', v || 0)}
+                value={oauth2.authStyle || 0}
+              ></RadioButtonGroup>
+            </div>
+          )}
           <div className="gf-form">
             <InlineFormLabel width={10}>Client ID</InlineFormLabel>
             <Input onChange={(v) => onOAuth2PropsChange('client_id', v.currentTarget.value)} value={oauth2.client_id} width={30} placeholder={'Client ID'} />
           </div>
-          <div className="gf-form">
-            <LegacyForms.SecretFormField
-              labelWidth={10}
-              inputWidth={15}
-              required
-              value={secureJsonData.oauth2ClientSecret || ''}
-              isConfigured={(secureJsonFields && secureJsonFields.oauth2ClientSecret) as boolean}
-              onReset={onResetClientSecret}
-              onChange={onUpdateDatasourceSecureJsonDataOption(props, 'oauth2ClientSecret')}
-              label="Client Secret"
-              aria-label="client secret"
-              placeholder="Client secret"
-            />
-          </div>
+          {clientAuthMethod === 'client_secret' && (
+            <div className="gf-form">
+              <LegacyForms.SecretFormField
+                labelWidth={10}
+                inputWidth={15}
+                required
+                value={secureJsonData.oauth2ClientSecret || ''}
+                isConfigured={(secureJsonFields && secureJsonFields.oauth2ClientSecret) as boolean}
+                onReset={onResetClientSecret}
+                onChange={onUpdateDatasourceSecureJsonDataOption(props, 'oauth2ClientSecret')}
+                label="Client Secret"
+                aria-label="client secret"
+                placeholder="Client secret"
+              />
+            </div>
+          )}
+          {clientAuthMethod === 'private_key_jwt' && (
+            <>
+              <div className="gf-form">
+                <LegacyForms.SecretFormField
+                  labelWidth={10}
+                  inputWidth={15}
+                  required
+                  value={secureJsonData.oauth2JWTPrivateKey || ''}
+                  tooltip={PrivateKeySelector.tooltip}
+                  isConfigured={(secureJsonFields && secureJsonFields.oauth2JWTPrivateKey) as boolean}
+                  onReset={onResetJWTPrivateKey}
+                  onChange={onUpdateDatasourceSecureJsonDataOption(props, 'oauth2JWTPrivateKey')}
+                  label={PrivateKeySelector.label}
+                  aria-label="Private Key"
+                  placeholder={PrivateKeySelector.placeholder}
+                />
+              </div>
+              <div className="gf-form">
+                <InlineFormLabel width={10} tooltip={PrivateKeyIDSelector.tooltip}>
+                  {PrivateKeyIDSelector.label}
+                </InlineFormLabel>
+                <Input onChange={(v) => onOAuth2PropsChange('private_key_id', v.currentTarget.value)} value={oauth2.private_key_id} width={30} placeholder={PrivateKeyIDSelector.placeholder} />
+              </div>
+              <div className="gf-form">
+                <InlineFormLabel width={10} tooltip={ClientAssertionAudienceSelector.tooltip}>
+                  {ClientAssertionAudienceSelector.label}
+                </InlineFormLabel>
+                <Input
+                  onChange={(v) => onOAuth2PropsChange('client_assertion_audience', v.currentTarget.value)}
+                  value={oauth2.client_assertion_audience}
+                  width={30}
+                  placeholder={ClientAssertionAudienceSelector.placeholder}
+                />
+              </div>
+            </>
+          )}
+          {clientAuthMethod === 'tls_client_auth' && !options.jsonData.tlsAuth && (
+            <Alert severity="info" title="Mutual TLS client authentication uses the TLS client certificate. Enable TLS client authentication in the TLS settings" />
+          )}
           <div className="gf-form">
             <InlineFormLabel width={10}>Token URL</InlineFormLabel>
             <Input onChange={(v) => onOAuth2PropsChange('token_url', v.currentTarget.value)} value={oauth2.token_url} width={30} placeholder={'Token URL'} />
@@ -173,6 +242,50 @@ export const OAuthInputsEditor = (props: DataSourcePluginOptionsEditorProps<Infi
           <TokenCustomization options={options} onOptionsChange={onOptionsChange} />
         </>
       )}
+      {oauth2.oauth2_type === 'authorization_code' && (
+        <>
+          <div className="gf-form">
+            <InlineFormLabel width={10}>Client ID</InlineFormLabel>
+            <Input onChange={(v) => onOAuth2PropsChange('client_id', v.currentTarget.value)} value={oauth2.client_id} width={30} placeholder={'Client ID'} />
+          </div>
+          <div className="gf-form">
+            <LegacyForms.SecretFormField
+              labelWidth={10}
+              inputWidth={15}
+              value={secureJsonData.oauth2ClientSecret || ''}
+              isConfigured={(secureJsonFields && secureJsonFields.oauth2ClientSecret) as boolean}
+              onReset={onResetClientSecret}
+              onChange={onUpdateDatasourceSecureJsonDataOption(props, 'oauth2ClientSecret')}
+              label="Client Secret"
+              aria-label="client secret"
+              placeholder="Client secret"
+            />
+          </div>
+          <div className="gf-form">
+            <InlineFormLabel width={10} tooltip={AuthURLSelector.tooltip}>
+              {AuthURLSelector.label}
+            </InlineFormLabel>
+            <Input onChange={(v) => onOAuth2PropsChange('auth_url', v.currentTarget.value)} value={oauth2.auth_url} width={30} placeholder={AuthURLSelector.placeholder} />
+          </div>
+          <div className="gf-form">
+            <InlineFormLabel width={10}>Token URL</InlineFormLabel>
+            <Input onChange={(v) => onOAuth2PropsChange('token_url', v.currentTarget.value)} value={oauth2.token_url} width={30} placeholder={'Token URL'} />
+          </div>
+          <div className="gf-form">
+            <InlineFormLabel width={10} tooltip="Include the scope which issues the refresh token. Example: offline_access. Enter comma separated values">
+              Scopes
+            </InlineFormLabel>
+            <Input
+              onChange={(v) => onOAuth2PropsChange('scopes', (v.currentTarget.value || '').split(','))}
+              value={(oauth2.scopes || []).join(',')}
+              width={30}
+              placeholder={'Comma separated values of scopes'}
+            />
+          </div>
+          <OAuthAuthorizationCodeEditor {...props} />
+          <TokenCustomization options={options} onOptionsChange={onOptionsChange} />
+        </>
+      )}
       {oauth2.oauth2_type === 'others' && (
         <div style={{ margin: '15px', marginInline: '45px', textAlign: 'center' }}>
           <p>
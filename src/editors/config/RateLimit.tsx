@@ -0,0 +1,40 @@
+import React from 'react';
+import { InlineField, Input, Stack } from '@grafana/ui';
+import type { DataSourcePluginOptionsEditorProps } from '@grafana/data';
+import type { InfinityOptions } from '@/types';
+
+type RateLimitEditorProps = DataSourcePluginOptionsEditorProps<InfinityOptions>;
+
+export const RateLimitEditor = ({ options, onOptionsChange }: RateLimitEditorProps) => {
+  const { jsonData } = options;
+  const onNumberChange = (key: 'rateLimitRequestsPerSecond' | 'rateLimitBurst', value: number) => {
+    onOptionsChange({ ...options, jsonData: { ...jsonData, [key]: isNaN(value) ? undefined : value } });
+  };
+  return (
+    <Stack gap={0.5} direction={'column'}>
+      <InlineField
+        label="Rate limit"
+        labelWidth={30}
+        tooltip="Maximum number of requests per second sent by the data source. Use fractions for per-minute limits, for example 0.5 for 30 requests per minute. 0 disables the rate limit"
+      >
+        <Input
+          type="number"
+          width={20}
+          min={0}
+          step={0.1}
+          placeholder="0"
+          value={jsonData.rateLimitRequestsPerSecond ?? ''}
+          onChange={(e) => onNumberChange('rateLimitRequestsPerSecond', e.currentTarget.valueAsNumber)}
+        />
+      </InlineField>
+      <InlineField
+        label="Rate limit burst"
+        labelWidth={30}
+        tooltip="Number of requests that can be sent at once before the limit applies. Defaults to the requests per second, rounded up"
+        disabled={!jsonData.rateLimitRequestsPerSecond}
+      >
+        <Input type="number" width={20} min={1} value={jsonData.rateLimitBurst ?? ''} onChange={(e) => onNumberChange('rateLimitBurst', e.currentTarget.valueAsNumber)} />
+      </InlineField>
+    </Stack>
+  );
+};
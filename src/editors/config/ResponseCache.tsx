@@ -0,0 +1,37 @@
+import React from 'react';
+import { InlineField, Input, Stack } from '@grafana/ui';
+import type { DataSourcePluginOptionsEditorProps } from '@grafana/data';
+import type { InfinityOptions } from '@/types';
+
+type ResponseCacheEditorProps = DataSourcePluginOptionsEditorProps<InfinityOptions>;
+
+export const ResponseCacheEditor = ({ options, onOptionsChange }: ResponseCacheEditorProps) => {
+  const { jsonData } = options;
+  const onNumberChange = (key: 'responseCacheTTLInSeconds' | 'responseCacheMaxEntries', value: number) => {
+    onOptionsChange({ ...options, jsonData: { ...jsonData, [key]: isNaN(value) ? undefined : value } });
+  };
+  return (
+    <Stack gap={0.5} direction={'column'}>
+      <InlineField label="Response cache TTL" labelWidth={30} tooltip="How long the responses of GET and POST requests are cached, in seconds. 0 disables the cache">
+        <Input
+          type="number"
+          width={20}
+          min={0}
+          placeholder="0"
+          value={jsonData.responseCacheTTLInSeconds ?? ''}
+          onChange={(e) => onNumberChange('responseCacheTTLInSeconds', e.currentTarget.valueAsNumber)}
+        />
+      </InlineField>
+      <InlineField label="Response cache size" labelWidth={30} tooltip="The maximum number of cached responses" disabled={!jsonData.responseCacheTTLInSeconds}>
+        <Input
+          type="number"
+          width={20}
+          min={1}
+          placeholder="100"
+          value={jsonData.responseCacheMaxEntries ?? ''}
+          onChange={(e) => onNumberChange('responseCacheMaxEntries', e.currentTarget.valueAsNumber)}
+        />
+      </InlineField>
+    </Stack>
+  );
+};
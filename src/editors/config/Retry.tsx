@@ -0,0 +1,59 @@
+import React from 'react';
+import { InlineField, InlineSwitch, Input, Stack, TagsInput } from '@grafana/ui';
+import type { DataSourcePluginOptionsEditorProps } from '@grafana/data';
+import type { InfinityOptions } from '@/types';
+
+type RetryEditorProps = DataSourcePluginOptionsEditorProps<InfinityOptions>;
+
+export const RetryEditor = ({ options, onOptionsChange }: RetryEditorProps) => {
+  const { jsonData } = options;
+  const disabled = (jsonData.retryMaxAttempts || 0) <= 1;
+  const onNumberChange = (key: 'retryMaxAttempts' | 'retryInitialBackoffInMs' | 'retryMaxBackoffInMs', value: number) => {
+    onOptionsChange({ ...options, jsonData: { ...jsonData, [key]: isNaN(value) ? undefined : value } });
+  };
+  const onStatusCodesChange = (tags: string[] = []) => {
+    const retryStatusCodes = tags.map((tag) => parseInt(tag, 10)).filter((code) => code >= 100 && code <= 599);
+    onOptionsChange({ ...options, jsonData: { ...jsonData, retryStatusCodes: retryStatusCodes.length > 0 ? retryStatusCodes : undefined } });
+  };
+  return (
+    <Stack gap={0.5} direction={'column'}>
+      <InlineField label="Retry max attempts" labelWidth={30} tooltip="Maximum number of attempts, including the first request. 0 disables the retries">
+        <Input type="number" width={20} min={0} placeholder="0" value={jsonData.retryMaxAttempts ?? ''} onChange={(e) => onNumberChange('retryMaxAttempts', e.currentTarget.valueAsNumber)} />
+      </InlineField>
+      <InlineField label="Retry initial backoff" labelWidth={30} tooltip="Wait time before the first retry, in milliseconds. It doubles with each attempt" disabled={disabled}>
+        <Input
+          type="number"
+          width={20}
+          min={0}
+          placeholder="500"
+          value={jsonData.retryInitialBackoffInMs ?? ''}
+          onChange={(e) => onNumberChange('retryInitialBackoffInMs', e.currentTarget.valueAsNumber)}
+        />
+      </InlineField>
+      <InlineField
+        label="Retry max backoff"
+        labelWidth={30}
+        tooltip="Maximum wait time between attempts, in milliseconds. Requests with a longer Retry-After aren't retried"
+        disabled={disabled}
+      >
+        <Input
+          type="number"
+          width={20}
+          min={0}
+          placeholder="10000"
+          value={jsonData.retryMaxBackoffInMs ?? ''}
+          onChange={(e) => onNumberChange('retryMaxBackoffInMs', e.currentTarget.valueAsNumber)}
+        />
+      </InlineField>
+      <InlineField label="Retry status codes" labelWidth={30} tooltip="Status codes to retry. Defaults to 429, 500, 502, 503 and 504" disabled={disabled}>
+        <TagsInput placeholder="Enter the status codes (enter key to add)" tags={(jsonData.retryStatusCodes || []).map(String)} onChange={onStatusCodesChange} />
+      </InlineField>
+      <InlineField label="Retry non-idempotent methods" labelWidth={30} tooltip="Also retry POST and PATCH requests" disabled={disabled}>
+        <InlineSwitch
+          value={jsonData.retryNonIdempotentMethods || false}
+          onChange={(e) => onOptionsChange({ ...options, jsonData: { ...jsonData, retryNonIdempotentMethods: e.currentTarget.checked } })}
+        />
+      </InlineField>
+    </Stack>
+  );
+};
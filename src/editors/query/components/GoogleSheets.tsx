@@ -12,7 +12,7 @@ export const GoogleSheetsEditor = ({ query, onChange, onRunQuery }: { query: Inf
       <EditorField label="Sheet Name" optional={true} horizontal={true}>
         <Input value={query.sheetName} width={20} onChange={(e) => onChange({ ...query, sheetName: e.currentTarget.value })} onBlur={onRunQuery} />
       </EditorField>
-      <EditorField label="Range" horizontal={true}>
+      <EditorField label="Range" horizontal={true} tooltip="Range of the cells in A1 notation such as A1:D10. Separate multiple ranges with commas to get a frame for each range">
         <Input value={query.sheetRange} width={20} onChange={(e) => onChange({ ...query, sheetRange: e.currentTarget.value })} onBlur={onRunQuery} />
       </EditorField>
     </>
@@ -13,9 +13,12 @@ import { UQLEditor } from '@/editors/query//query.uql';
 import { URLEditor } from '@/editors/query/query.url';
 import { ExperimentalFeatures } from '@/editors/query/query.experimental';
 import { AzureBlobEditor } from '@/editors/query/query.azureBlob';
-import { isDataQuery } from '@/app/utils';
+import { S3Editor } from '@/editors/query/query.s3';
+import { GCSEditor } from '@/editors/query/query.gcs';
+import { isBackendQuery, isDataQuery } from '@/app/utils';
 import { Datasource } from '@/datasource';
 import { PaginationEditor } from '@/editors/query/query.pagination';
+import { StreamEditor } from '@/editors/query/query.stream';
 import { TransformationsEditor } from '@/editors/query/query.transformations';
 import { QueryWarning } from '@/editors/query/query.warning';
 import type { PanelData } from '@grafana/data';
@@ -37,12 +40,18 @@ export const InfinityQueryEditor = (props: InfinityEditorProps) => {
   const [showHelp, setShowHelp] = useState(false);
   let query: InfinityQuery = defaultsDeep(cloneDeep(props.query), DefaultInfinityQuery) as InfinityQuery;
   query = migrateQuery(query);
-  let canShowColumnsEditor = ['csv', 'tsv', 'html', 'json', 'graphql', 'xml', 'google-sheets'].includes(query.type);
+  let canShowColumnsEditor = ['csv', 'tsv', 'html', 'json', 'ndjson', 'yaml', 'parquet', 'arrow', 'xlsx', 'graphql', 'xml', 'google-sheets'].includes(query.type);
   let canShowFilterEditor =
     query.type !== 'series' &&
     query.type !== 'global' &&
     query.type !== 'google-sheets' &&
     query.type !== 'transformations' &&
+    query.type !== 'ndjson' &&
+    query.type !== 'yaml' &&
+    query.type !== 'prometheus' &&
+    query.type !== 'parquet' &&
+    query.type !== 'arrow' &&
+    query.type !== 'xlsx' &&
     !(query.type === 'json' && query.parser === 'backend') &&
     !(query.type === 'graphql' && query.parser === 'backend') &&
     !(query.type === 'csv' && query.parser === 'backend') &&
@@ -78,6 +87,8 @@ export const InfinityQueryEditor = (props: InfinityEditorProps) => {
         {query.type === 'series' && <SeriesEditor {...{ query, onChange }} />}
         {isDataQuery(query) && query.source !== 'inline' && showUrlOptions && <URLEditor {...{ mode, query, onChange, onRunQuery }} />}
         {isDataQuery(query) && query.source === 'azure-blob' && <AzureBlobEditor query={query} onChange={onChange} />}
+        {isDataQuery(query) && query.source === 's3' && <S3Editor query={query} onChange={onChange} />}
+        {isDataQuery(query) && query.source === 'gcs' && <GCSEditor query={query} onChange={onChange} />}
         {canShowColumnsEditor && <QueryColumnsEditor {...{ mode, query, onChange, onRunQuery, datasourceUid: datasource?.uid, data }} />}
         {canShowFilterEditor && <TableFilter {...{ query, onChange, onRunQuery }} />}
         {query.type === 'uql' && (
@@ -92,11 +103,13 @@ export const InfinityQueryEditor = (props: InfinityEditorProps) => {
             <GROQEditor {...{ query, onChange, onRunQuery, mode }} />
           </EditorRow>
         )}
+        {(query.type === 'ndjson' || query.type === 'yaml' || query.type === 'prometheus' || query.type === 'parquet' || query.type === 'arrow' || query.type === 'xlsx') && <ExperimentalFeatures query={query} onChange={onChange} onRunQuery={onRunQuery} />}
         {(query.type === 'json' || query.type === 'graphql' || query.type === 'csv' || query.type === 'tsv' || query.type === 'xml') &&
           (query.parser === 'backend' || query.parser === 'jq-backend') && <ExperimentalFeatures query={query} onChange={onChange} onRunQuery={onRunQuery} />}
         {query.type === 'json' && (query.parser === 'backend' || query.parser === 'jq-backend') && query.source === 'url' && (
           <PaginationEditor query={query} onChange={onChange} onRunQuery={onRunQuery} />
         )}
+        {(isBackendQuery(query) || query.type === 'uql' || query.type === 'groq') && query.source === 'url' && <StreamEditor query={query} onChange={onChange} onRunQuery={onRunQuery} />}
         {query.type === 'transformations' && <TransformationsEditor query={query} onChange={onChange} onRunQuery={onRunQuery} />}
         <QueryWarning query={query} />
       </EditorRows>
@@ -7,6 +7,7 @@ import { isBackendQuery, isDataQuery } from '@/app/utils';
 import { QueryColumnItem } from '@/components/QueryColumnItem';
 import { JSONOptionsEditor } from '@/components/JSONOptionsEditor';
 import { CSVOptionsEditor } from '@/components/CSVOptionsEditor';
+import { FileOptionsEditor } from '@/components/FileOptionsEditor';
 import { RootSelectorAssistant } from '@/editors/query/components/RootSelectorAssistant';
 import { UQLEditor } from '@/editors/query/query.uql';
 import { GROQEditor } from '@/editors/query/query.groq';
@@ -65,6 +66,7 @@ export const QueryColumnsEditor = (props: { query: InfinityQuery; onChange: (val
               <RootSelector {...props} />
               {query.type === 'json' && <JSONOptionsEditor {...props} />}
               {(query.type === 'csv' || query.type === 'tsv') && <CSVOptionsEditor {...props} />}
+              {(query.type === 'parquet' || query.type === 'arrow' || query.type === 'xlsx') && <FileOptionsEditor {...props} />}
             </Stack>
             <EditorField label="Columns" optional={true}>
               <>
@@ -126,7 +128,7 @@ const RootSelector = (props: { query: InfinityQuery; onChange: (value: any) => v
     onChange({ ...query, root_selector });
     onRunQuery();
   };
-  return ['html', 'json', 'xml', 'graphql'].indexOf(props.query.type) > -1 ? (
+  return ['html', 'json', 'ndjson', 'yaml', 'xml', 'graphql'].indexOf(props.query.type) > -1 ? (
     <EditorField label="Rows/Root" optional={true}>
       <Stack direction="column" gap={2} alignItems="flex-start">
         <TextArea
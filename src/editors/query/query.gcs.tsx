@@ -0,0 +1,31 @@
+import React from 'react';
+import { Input, Stack } from '@grafana/ui';
+import { EditorRow } from '@/components/extended/EditorRow';
+import { EditorField } from '@/components/extended/EditorField';
+import { isDataQuery } from '@/app/utils';
+import type { InfinityQuery } from '@/types';
+
+type GCSEditorProps = { query: InfinityQuery; onChange: (query: InfinityQuery) => void };
+
+export const GCSEditor = (props: GCSEditorProps) => {
+  const { query, onChange } = props;
+  if (isDataQuery(query) && query.source === 'gcs') {
+    return (
+      <EditorRow label="Google Cloud Storage details" collapsible={false} collapsed={true} title={() => ''}>
+        <Stack gap={1} direction="row" wrap={'wrap'}>
+          <EditorField label="Bucket" horizontal={true}>
+            <Input value={query.gcsBucket} width={39} placeholder="my-bucket" onChange={(e) => onChange({ ...query, gcsBucket: e.currentTarget.value })} />
+          </EditorField>
+          <EditorField
+            label="Object"
+            horizontal={true}
+            tooltip="Name of the object. Leave empty or end with / to list the objects with the JSON type, for example in variable queries"
+          >
+            <Input value={query.gcsObject} width={49} placeholder="path/to/file.csv" onChange={(e) => onChange({ ...query, gcsObject: e.currentTarget.value })} />
+          </EditorField>
+        </Stack>
+      </EditorRow>
+    );
+  }
+  return <></>;
+};
@@ -11,13 +11,14 @@ const paginationTypes: Array<ComboboxOption<PaginationType>> = [
   { value: 'page', label: 'Page number' },
   { value: 'cursor', label: 'Cursor' },
   { value: 'list', label: 'List of values' },
+  { value: 'next_link', label: 'Next link' },
 ];
 
 const paginationParamTypes: Array<ComboboxOption<PaginationParamType>> = [
   { value: 'query', label: 'Query param' },
   { value: 'header', label: 'Header' },
   { value: 'body_data', label: 'Body form' },
-  // { value: 'body_json', label: 'Body JSON' },
+  { value: 'body_json', label: 'Body JSON' },
   { value: 'replace', label: 'Replace URL' },
 ];
 
@@ -140,6 +141,28 @@ export const PaginationEditor = (props: PaginationEditorProps) => {
                   </Stack>
                 </EditorField>
               )}
+              {(query.pagination_mode === 'offset' || query.pagination_mode === 'page') && (
+                <EditorField label="Total count" tooltip={'Optional. When the total number of items is available, only the pages required are requested.'}>
+                  <Stack>
+                    <InlineLabel width={12}>Header</InlineLabel>
+                    <Input
+                      width={30}
+                      value={query.pagination_total_count_header || ''}
+                      onChange={(e) => onChange({ ...query, pagination_total_count_header: e.currentTarget.value })}
+                      placeholder="X-Total-Count"
+                    />
+                    <InlineLabel width={20} tooltip="selector to extract the total count from the response body. Used when the header is not set">
+                      Extraction path
+                    </InlineLabel>
+                    <Input
+                      width={20}
+                      value={query.pagination_total_count_extraction_path || ''}
+                      onChange={(e) => onChange({ ...query, pagination_total_count_extraction_path: e.currentTarget.value })}
+                      placeholder="total"
+                    ></Input>
+                  </Stack>
+                </EditorField>
+              )}
               {query.pagination_mode === 'cursor' && (
                 <EditorField label="Cursor field">
                   <Stack>
@@ -205,6 +228,21 @@ export const PaginationEditor = (props: PaginationEditorProps) => {
             </Stack>
           </>
         )}
+        {query.pagination_mode === 'next_link' && (
+          <Stack gap={1} wrap={'nowrap'} direction="column">
+            <EditorField
+              label="Next link"
+              tooltip={'Selector to extract the next page URL from the response body. When empty, the next page URL is read from the Link response header with rel="next".'}
+            >
+              <Input
+                width={40}
+                value={query.pagination_param_next_link_extraction_path || ''}
+                onChange={(e) => onChange({ ...query, pagination_param_next_link_extraction_path: e.currentTarget.value || '' })}
+                placeholder="Link header"
+              />
+            </EditorField>
+          </Stack>
+        )}
       </Stack>
     </EditorRow>
   );
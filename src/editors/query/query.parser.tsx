@@ -5,7 +5,7 @@ import type { InfinityQuery, InfinityQueryType, InfinityParserType } from '@/typ
 
 export const ParseTypeEditor = (props: { query: InfinityQuery; onChange: (value: any) => void; onRunQuery: () => void }) => {
   const { query, onChange, onRunQuery } = props;
-  if (query.type === 'json' || query.type === 'graphql' || query.type === 'csv' || query.type === 'tsv' || query.type === 'xml' || query.type === 'html') {
+  if (query.type === 'json' || query.type === 'ndjson' || query.type === 'yaml' || query.type === 'graphql' || query.type === 'csv' || query.type === 'tsv' || query.type === 'xml' || query.type === 'html') {
     return (
       <EditorField label="Parser" horizontal={true}>
         <Combobox
@@ -51,6 +51,12 @@ const getParserOptions = (queryType: InfinityQueryType): Array<ComboboxOption<In
         { value: 'groq', label: 'GROQ', group: 'Frontend' },
         { value: 'simple', label: 'Frontend', group: 'Frontend' },
       ];
+    case 'ndjson':
+    case 'yaml':
+      return [
+        { value: 'backend', label: 'JSONata', group: 'Backend' },
+        { value: 'jq-backend', label: 'JQ', group: 'Backend' },
+      ];
     case 'csv':
     case 'tsv':
       return [
@@ -0,0 +1,27 @@
+import React from 'react';
+import { Input, Stack } from '@grafana/ui';
+import { EditorRow } from '@/components/extended/EditorRow';
+import { EditorField } from '@/components/extended/EditorField';
+import { isDataQuery } from '@/app/utils';
+import type { InfinityQuery } from '@/types';
+
+type S3EditorProps = { query: InfinityQuery; onChange: (query: InfinityQuery) => void };
+
+export const S3Editor = (props: S3EditorProps) => {
+  const { query, onChange } = props;
+  if (isDataQuery(query) && query.source === 's3') {
+    return (
+      <EditorRow label="Amazon S3 details" collapsible={false} collapsed={true} title={() => ''}>
+        <Stack gap={1} direction="row" wrap={'wrap'}>
+          <EditorField label="Bucket" horizontal={true}>
+            <Input value={query.s3Bucket} width={39} placeholder="my-bucket" onChange={(e) => onChange({ ...query, s3Bucket: e.currentTarget.value })} />
+          </EditorField>
+          <EditorField label="Key" horizontal={true}>
+            <Input value={query.s3Key} width={49} placeholder="path/to/file.csv" onChange={(e) => onChange({ ...query, s3Key: e.currentTarget.value })} />
+          </EditorField>
+        </Stack>
+      </EditorRow>
+    );
+  }
+  return <></>;
+};
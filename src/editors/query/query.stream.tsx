@@ -0,0 +1,57 @@
+import React from 'react';
+import { FeatureBadge, Input, Combobox, Stack, type ComboboxOption } from '@grafana/ui';
+import { FeatureState } from '@grafana/data';
+import { EditorField } from '@/components/extended/EditorField';
+import { EditorRow } from '@/components/extended/EditorRow';
+import { isInfinityQueryWithUrlSource } from '@/app/utils';
+import type { InfinityQuery, StreamMode } from '@/types';
+
+const streamModes: Array<ComboboxOption<StreamMode>> = [
+  { value: 'none', label: 'None', description: 'Run the query once' },
+  { value: 'polling', label: 'Polling', description: 'Run the query on an interval and push the results to the panel' },
+  { value: 'sse', label: 'Server-sent events', description: 'Subscribe to a text/event-stream endpoint. Data of each event is parsed as a separate response' },
+  { value: 'ndjson', label: 'NDJSON', description: 'Read a chunked newline delimited JSON response. Each line is parsed as a separate response' },
+];
+
+type StreamEditorProps = {
+  query: InfinityQuery;
+  onChange: (query: InfinityQuery) => void;
+  onRunQuery: () => void;
+};
+
+export const StreamEditor = (props: StreamEditorProps) => {
+  const { query, onChange, onRunQuery } = props;
+  if (!isInfinityQueryWithUrlSource(query)) {
+    return <></>;
+  }
+  return (
+    <EditorRow label={'Streaming'} collapsible={true} collapsed={!query.stream_mode || query.stream_mode === 'none'} title={() => <FeatureBadge featureState={FeatureState.experimental} />}>
+      <Stack direction="row" wrap={'wrap'}>
+        <EditorField label="Stream mode" tooltip={'Results are streamed to the panel through Grafana Live'}>
+          <Combobox
+            width={30}
+            value={query.stream_mode || 'none'}
+            options={streamModes}
+            onChange={(e) => {
+              onChange({ ...query, stream_mode: (e.value as StreamMode) || 'none' });
+              onRunQuery();
+            }}
+          />
+        </EditorField>
+        {query.stream_mode === 'polling' && (
+          <EditorField label="Interval (ms)" tooltip={'Polling interval in milliseconds. Minimum 1000. Defaults to 10000'}>
+            <Input
+              type={'number'}
+              min={1000}
+              width={30}
+              value={query.stream_interval_ms}
+              onChange={(e) => onChange({ ...query, stream_interval_ms: e.currentTarget.valueAsNumber || undefined })}
+              onBlur={onRunQuery}
+              placeholder="10000"
+            />
+          </EditorField>
+        )}
+      </Stack>
+    </EditorRow>
+  );
+};
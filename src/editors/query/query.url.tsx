@@ -34,7 +34,7 @@ export const Method = ({
   if (!(isDataQuery(query) || query.type === 'uql' || query.type === 'groq')) {
     return <></>;
   }
-  if (query.source === 'inline' || query.source === 'azure-blob') {
+  if (query.source === 'inline' || query.source === 'azure-blob' || query.source === 's3' || query.source === 'gcs') {
     return <></>;
   }
   const SAFE_URL_METHODS: Array<ComboboxOption<InfinityURLMethod>> = [
@@ -139,7 +139,7 @@ const Headers = ({ query, onChange }: { query: InfinityQuery; onChange: (value:
   if (!(isDataQuery(query) || query.type === 'uql' || query.type === 'groq')) {
     return <></>;
   }
-  if (query.source === 'inline' || query.source === 'reference' || query.source === 'azure-blob') {
+  if (query.source === 'inline' || query.source === 'reference' || query.source === 'azure-blob' || query.source === 's3' || query.source === 'gcs') {
     return <></>;
   }
   const defaultHeader = {
@@ -162,7 +162,7 @@ const QueryParams = ({ query, onChange, onRunQuery }: { query: InfinityQuery; on
   if (!(isDataQuery(query) || query.type === 'uql' || query.type === 'groq')) {
     return <></>;
   }
-  if (query.source === 'inline' || query.source === 'reference' || query.source === 'azure-blob') {
+  if (query.source === 'inline' || query.source === 'reference' || query.source === 'azure-blob' || query.source === 's3' || query.source === 'gcs') {
     return <></>;
   }
   const defaultParam = {
@@ -185,7 +185,7 @@ const Body = ({ query, onChange, onRunQuery }: { query: InfinityQuery; onChange:
   if (!(isDataQuery(query) || query.type === 'uql' || query.type === 'groq')) {
     return <></>;
   }
-  if (query.source === 'inline' || query.source === 'reference' || query.source === 'azure-blob') {
+  if (query.source === 'inline' || query.source === 'reference' || query.source === 'azure-blob' || query.source === 's3' || query.source === 'gcs') {
     return <></>;
   }
   const placeholderGraphQLQuery = `{ query : { }}`;
@@ -42,6 +42,14 @@ export const interpolateQuery = (query: InfinityQuery, scopedVars: ScopedVars):
       newQuery.azBlobName = replaceVariable(newQuery.azBlobName, scopedVars);
       newQuery.azContainerName = replaceVariable(newQuery.azContainerName, scopedVars);
     }
+    if (newQuery.source === 's3') {
+      newQuery.s3Bucket = replaceVariable(newQuery.s3Bucket, scopedVars);
+      newQuery.s3Key = replaceVariable(newQuery.s3Key, scopedVars);
+    }
+    if (newQuery.source === 'gcs') {
+      newQuery.gcsBucket = replaceVariable(newQuery.gcsBucket, scopedVars);
+      newQuery.gcsObject = replaceVariable(newQuery.gcsObject, scopedVars);
+    }
     if (isDataQuery(newQuery)) {
       newQuery.filters = (newQuery.filters || []).map((filter) => {
         const value = (filter.value || []).map((val) => {
@@ -71,6 +79,10 @@ export const interpolateQuery = (query: InfinityQuery, scopedVars: ScopedVars):
       newQuery.summarizeExpression = replaceVariable(newQuery.summarizeExpression || '', scopedVars);
     }
   }
+  if (newQuery.type === 'xlsx') {
+    newQuery.sheetName = replaceVariable(newQuery.sheetName, scopedVars);
+    newQuery.sheetRange = replaceVariable(newQuery.sheetRange, scopedVars);
+  }
   if (newQuery.type === 'google-sheets') {
     newQuery.spreadsheet = replaceVariable(newQuery.spreadsheet, scopedVars);
     newQuery.sheetName = replaceVariable(newQuery.sheetName, scopedVars);
@@ -19,6 +19,39 @@ export const Components = {
           tooltip: `Token Template allows you to customize the token value using the template. This will be Authorization header value. String \${__oauth2.access_token} will be replaced with actual access token`,
           placeholder: `Bearer \${__oauth2.access_token}`,
         },
+        ClientAuthMethod: {
+          label: 'Client Auth',
+          tooltip:
+            'How the client authenticates with the token endpoint. Private Key JWT signs a client assertion with the private key of the client (RFC 7523). Mutual TLS uses the TLS client certificate configured in the TLS settings (RFC 8705)',
+        },
+        PrivateKey: {
+          label: 'Private Key',
+          tooltip: 'PEM encoded RSA, EC or Ed25519 private key of the client used to sign the client assertion',
+          placeholder: 'Private Key',
+        },
+        PrivateKeyID: {
+          label: 'Key ID',
+          tooltip: 'Optional key identifier sent in the kid header of the client assertion',
+          placeholder: '(optional) key identifier',
+        },
+        ClientAssertionAudience: {
+          label: 'Audience',
+          tooltip: 'Audience of the client assertion. Defaults to the token URL. Some authorization servers expect the issuer URL instead',
+          placeholder: '(optional) defaults to token URL',
+        },
+        AuthURL: {
+          label: 'Auth URL',
+          tooltip: 'Authorization endpoint of the authorization server where the admin consents the access',
+          placeholder: 'Authorization URL',
+        },
+        RedirectURL: {
+          label: 'Redirect URL',
+          tooltip: 'Register this URL as the redirect URL of the client in the authorization server',
+        },
+        Authorize: {
+          label: 'Authorization',
+          tooltip: 'Opens the consent page of the authorization server. Once authorized, the refresh token is stored in the secure JSON data of the data source and the access tokens are refreshed automatically',
+        },
       },
       AzureBlob: {
         Region: {
@@ -38,6 +71,153 @@ export const Components = {
           placeholder: 'Azure blob storage account key',
           ariaLabel: 'Azure blob storage account key',
         },
+        AuthType: {
+          label: 'Authentication type',
+          tooltip: 'Managed identity and workload identity must be enabled in the azure section of the Grafana configuration and forwarded to the plugin',
+          ariaLabel: 'Azure blob authentication type',
+        },
+        SASToken: {
+          label: 'SAS token',
+          tooltip: 'SAS token or SAS URL of the storage account or the container',
+          placeholder: 'sv=...&sig=... or https://account.blob.core.windows.net/?sv=...',
+          ariaLabel: 'Azure blob SAS token',
+        },
+        TenantID: {
+          label: 'Tenant ID',
+          tooltip: 'Microsoft Entra ID tenant ID. Optional for workload identity',
+          placeholder: 'Tenant ID',
+          ariaLabel: 'Azure blob tenant ID',
+        },
+        ClientID: {
+          label: 'Client ID',
+          tooltip: 'Client ID of the app registration. Optional client ID of the user assigned managed identity or the workload identity',
+          placeholder: 'Client ID',
+          ariaLabel: 'Azure blob client ID',
+        },
+        ClientSecret: {
+          label: 'Client secret',
+          tooltip: 'Client secret of the app registration',
+          placeholder: 'Client secret',
+          ariaLabel: 'Azure blob client secret',
+        },
+      },
+      AzureAD: {
+        Region: {
+          label: 'Azure cloud',
+          tooltip: 'Azure cloud of the Microsoft Entra ID tenant',
+          ariaLabel: 'Azure AD cloud type',
+        },
+        AuthType: {
+          label: 'Authentication type',
+          tooltip: 'Managed identity and workload identity must be enabled in the azure section of the Grafana configuration and forwarded to the plugin',
+          ariaLabel: 'Azure AD authentication type',
+        },
+        TenantID: {
+          label: 'Tenant ID',
+          tooltip: 'Microsoft Entra ID tenant ID. Optional for workload identity',
+          placeholder: 'Tenant ID',
+          ariaLabel: 'Azure AD tenant ID',
+        },
+        ClientID: {
+          label: 'Client ID',
+          tooltip: 'Client ID of the app registration. Optional client ID of the user assigned managed identity or the workload identity',
+          placeholder: 'Client ID',
+          ariaLabel: 'Azure AD client ID',
+        },
+        ClientSecret: {
+          label: 'Client secret',
+          tooltip: 'Client secret of the app registration',
+          placeholder: 'Client secret',
+          ariaLabel: 'Azure AD client secret',
+        },
+        ClientCertificate: {
+          label: 'Client certificate',
+          tooltip: 'PEM encoded certificate and RSA private key of the app registration',
+          placeholder: '-----BEGIN CERTIFICATE-----',
+          ariaLabel: 'Azure AD client certificate',
+        },
+        Scope: {
+          label: 'Scope',
+          tooltip:
+            'Scope of the token requested for the allowed host. Defaults to the scope of Azure Resource Manager, Microsoft Graph, Log Analytics and Key Vault for their hosts and to https://<host>/.default for other hosts. For the APIs registered in Entra ID, use the application ID URI. For example api://<application-id>/.default',
+          ariaLabel: 'Azure AD scope',
+        },
+      },
+      Google: {
+        AuthType: {
+          label: 'Authentication type',
+          tooltip: 'Service account key to access the private spreadsheets or API key to access the public spreadsheets',
+        },
+        ServiceAccountKey: {
+          label: 'Service account key',
+          tooltip: 'JSON key file of the Google service account. Scopes are derived from the allowed hosts',
+          placeholder: 'Paste or upload the JSON key file',
+          ariaLabel: 'Google service account key',
+        },
+        APIKey: {
+          label: 'API key',
+          tooltip: 'Google API key with access to the Google Sheets API',
+          placeholder: 'Google API key',
+          ariaLabel: 'Google API key',
+        },
+        HealthCheckSpreadsheetID: {
+          label: 'Health check spreadsheet',
+          tooltip: 'ID of the spreadsheet to fetch during the health check to verify the access',
+          placeholder: 'Spreadsheet ID',
+          ariaLabel: 'Google health check spreadsheet ID',
+        },
+        HealthCheckBucket: {
+          label: 'Health check bucket',
+          tooltip: 'Google Cloud Storage bucket to list during the health check to verify the access. Requires service account authentication',
+          placeholder: 'Bucket name',
+          ariaLabel: 'Google health check bucket',
+        },
+      },
+      TokenExchange: {
+        Type: {
+          label: 'Token exchange',
+          tooltip: 'Exchange the forwarded OAuth identity of the user for a token of the API. Forward as is sends the token of the user to the API without exchanging',
+        },
+        TokenURL: {
+          label: 'Token URL',
+          tooltip: 'Token endpoint where the forwarded token is exchanged',
+          placeholder: 'https://login.microsoftonline.com/<tenant-id>/oauth2/v2.0/token',
+          ariaLabel: 'Token exchange token URL',
+        },
+        ClientID: {
+          label: 'Client ID',
+          tooltip: 'Client ID used to authenticate with the token endpoint',
+          placeholder: 'Client ID',
+          ariaLabel: 'Token exchange client ID',
+        },
+        ClientSecret: {
+          label: 'Client secret',
+          tooltip: 'Client secret used to authenticate with the token endpoint',
+          placeholder: 'Client secret',
+          ariaLabel: 'Token exchange client secret',
+        },
+        Scopes: {
+          label: 'Scopes',
+          tooltip: 'Comma separated scopes of the exchanged token. Example: api://<application-id>/.default for Microsoft Entra ID',
+          placeholder: 'Comma separated values of scopes',
+          ariaLabel: 'Token exchange scopes',
+        },
+        Audience: {
+          label: 'Audience',
+          tooltip: 'Logical name of the API the exchanged token is requested for',
+          placeholder: '(optional) Audience',
+          ariaLabel: 'Token exchange audience',
+        },
+        Resource: {
+          label: 'Resource',
+          tooltip: 'URI of the API the exchanged token is requested for',
+          placeholder: '(optional) Resource',
+          ariaLabel: 'Token exchange resource',
+        },
+        SubjectTokenType: {
+          label: 'Subject token',
+          tooltip: 'Forwarded token of the user to exchange. Access token uses the token from the Authorization header and ID token uses the X-ID-Token header',
+        },
       },
     },
     URL: {
@@ -7,8 +7,9 @@ export interface GlobalInfinityQuery {
   id: string;
   query: InfinityQuery;
 }
-export type AuthType = 'none' | 'basicAuth' | 'apiKey' | 'bearerToken' | 'oauthPassThru' | 'digestAuth' | 'aws' | 'azureBlob' | 'oauth2';
-export type OAuth2Type = 'client_credentials' | 'jwt' | 'others';
+export type AuthType = 'none' | 'basicAuth' | 'apiKey' | 'bearerToken' | 'oauthPassThru' | 'digestAuth' | 'aws' | 'azureBlob' | 'oauth2' | 'google' | 'azureAD';
+export type OAuth2Type = 'client_credentials' | 'jwt' | 'authorization_code' | 'others';
+export type OAuth2ClientAuthMethod = 'client_secret' | 'private_key_jwt' | 'tls_client_auth';
 export type APIKeyType = 'header' | 'query';
 export type OAuth2Props = {
   oauth2_type?: OAuth2Type;
@@ -17,26 +18,64 @@ export type OAuth2Props = {
   private_key_id?: string;
   subject?: string;
   token_url?: string;
+  auth_url?: string;
   scopes?: string[];
   authStyle?: number;
   authHeader?: string;
   tokenTemplate?: string;
+  client_auth_method?: OAuth2ClientAuthMethod;
+  client_assertion_audience?: string;
 };
+export type AWSAuthType = 'keys' | 'default' | 'credentials' | 'ec2_iam_role';
 export type AWSAuthProps = {
-  authType?: 'keys';
+  authType?: AWSAuthType;
   region?: string;
   service?: string;
+  profile?: string;
+  assumeRoleArn?: string;
+  externalId?: string;
+  endpoint?: string;
+  usePathStyle?: boolean;
+  healthCheckBucket?: string;
+};
+export type GoogleAuthProps = {
+  authType?: 'serviceAccount' | 'apiKey';
+  healthCheckSpreadsheetId?: string;
+  healthCheckBucket?: string;
+};
+export type TokenExchangeType = 'rfc8693' | 'on_behalf_of';
+export type TokenExchangeProps = {
+  type?: TokenExchangeType;
+  tokenUrl?: string;
+  clientId?: string;
+  authStyle?: number;
+  scopes?: string[];
+  audience?: string;
+  resource?: string;
+  subjectTokenType?: 'urn:ietf:params:oauth:token-type:access_token' | 'urn:ietf:params:oauth:token-type:id_token';
 };
 export type InfinityReferenceData = { name: string; data: string };
 export type ProxyType = 'none' | 'env' | 'url';
 export type UnsecureQueryHandling = 'warn' | 'allow' | 'deny';
 export type AzureBlobCloudType = 'AzureCloud' | 'AzureUSGovernment' | 'AzureChinaCloud';
+export type AzureBlobAuthType = 'sharedKey' | 'sasToken' | 'clientSecret' | 'managedIdentity' | 'workloadIdentity';
+export type AzureADAuthType = 'clientSecret' | 'clientCertificate' | 'managedIdentity' | 'workloadIdentity';
+export type AzureADProps = {
+  authType?: AzureADAuthType;
+  cloudType?: AzureBlobCloudType;
+  tenantId?: string;
+  clientId?: string;
+  scopes?: Record<string, string>;
+};
 export interface InfinityOptions extends DataSourceJsonData {
   auth_method?: AuthType;
   apiKeyKey?: string;
   apiKeyType?: APIKeyType;
   oauth2?: OAuth2Props;
   aws?: AWSAuthProps;
+  google?: GoogleAuthProps;
+  azureAD?: AzureADProps;
+  tokenExchange?: TokenExchangeProps;
   tlsSkipVerify?: boolean;
   tlsAuth?: boolean;
   serverName?: string;
@@ -55,12 +94,24 @@ export interface InfinityOptions extends DataSourceJsonData {
   azureBlobCloudType?: AzureBlobCloudType;
   azureBlobAccountUrl?: string;
   azureBlobAccountName?: string;
+  azureBlobAuthType?: AzureBlobAuthType;
+  azureBlobTenantId?: string;
+  azureBlobClientId?: string;
   unsecuredQueryHandling?: UnsecureQueryHandling;
   enableSecureSocksProxy?: boolean;
   pathEncodedUrlsEnabled?: boolean;
   ignoreStatusCodeCheck?: boolean;
   allowDangerousHTTPMethods?: boolean;
   keepCookies?: string[];
+  responseCacheTTLInSeconds?: number;
+  responseCacheMaxEntries?: number;
+  retryMaxAttempts?: number;
+  retryInitialBackoffInMs?: number;
+  retryMaxBackoffInMs?: number;
+  retryStatusCodes?: number[];
+  retryNonIdempotentMethods?: boolean;
+  rateLimitRequestsPerSecond?: number;
+  rateLimitBurst?: number;
 }
 
 export interface InfinitySecureOptions {
@@ -72,9 +123,17 @@ export interface InfinitySecureOptions {
   bearerToken?: string;
   awsAccessKey?: string;
   awsSecretKey?: string;
+  googleServiceAccountKey?: string;
+  googleApiKey?: string;
   oauth2ClientSecret?: string;
   oauth2JWTPrivateKey?: string;
+  oauth2RefreshToken?: string;
+  tokenExchangeClientSecret?: string;
   azureBlobAccountKey?: string;
+  azureBlobClientSecret?: string;
+  azureBlobSASToken?: string;
+  azureADClientSecret?: string;
+  azureADClientCertificate?: string;
   proxyUserPassword?: string;
 }
 export interface SecureField {
@@ -3,9 +3,9 @@ import type { ComboboxOption } from '@grafana/ui';
 import type { DataQuery } from '@grafana/schema';
 
 //#region Query
-export type InfinityQueryType = 'json' | 'graphql' | 'csv' | 'tsv' | 'xml' | 'html' | 'uql' | 'groq' | 'global' | 'google-sheets' | 'transformations' | 'series';
+export type InfinityQueryType = 'json' | 'ndjson' | 'yaml' | 'prometheus' | 'parquet' | 'arrow' | 'xlsx' | 'graphql' | 'csv' | 'tsv' | 'xml' | 'html' | 'uql' | 'groq' | 'global' | 'google-sheets' | 'transformations' | 'series';
 export type InfinityParserType = 'simple' | 'backend' | 'jq-backend' | 'uql' | 'groq';
-export type InfinityQuerySources = 'url' | 'inline' | 'azure-blob' | 'reference' | 'random-walk' | 'expression';
+export type InfinityQuerySources = 'url' | 'inline' | 'azure-blob' | 's3' | 'gcs' | 'reference' | 'random-walk' | 'expression';
 export type InfinityColumnFormat = 'string' | 'number' | 'timestamp' | 'timestamp_epoch' | 'timestamp_epoch_s' | 'boolean';
 export type InfinityQueryFormat = 'table' | 'timeseries' | 'logs' | 'trace' | 'node-graph-nodes' | 'node-graph-edges' | 'dataframe' | 'as-is';
 export type QueryBodyType = 'none' | 'form-data' | 'x-www-form-urlencoded' | 'raw' | 'graphql';
@@ -29,9 +29,12 @@ export type InfinityQueryWithReferenceSource<T extends InfinityQueryType> = {
   referenceName: string;
 } & InfinityQueryWithSource<'reference'> &
   InfinityQueryBase<T>;
+export type StreamMode = 'none' | 'polling' | 'sse' | 'ndjson';
 export type InfinityQueryWithURLSource<T extends InfinityQueryType> = {
   url: string;
   url_options: InfinityURLOptions;
+  stream_mode?: StreamMode;
+  stream_interval_ms?: number;
 } & InfinityQueryWithSource<'url'> &
   InfinityQueryBase<T>;
 export type InfinityQueryWithAzureBlobSource<T extends InfinityQueryType> = {
@@ -39,6 +42,16 @@ export type InfinityQueryWithAzureBlobSource<T extends InfinityQueryType> = {
   azBlobName: string;
 } & InfinityQueryWithSource<'azure-blob'> &
   InfinityQueryBase<T>;
+export type InfinityQueryWithS3Source<T extends InfinityQueryType> = {
+  s3Bucket: string;
+  s3Key: string;
+} & InfinityQueryWithSource<'s3'> &
+  InfinityQueryBase<T>;
+export type InfinityQueryWithGCSSource<T extends InfinityQueryType> = {
+  gcsBucket: string;
+  gcsObject: string;
+} & InfinityQueryWithSource<'gcs'> &
+  InfinityQueryBase<T>;
 export type InfinityQueryWithInlineSource<T extends InfinityQueryType> = {
   data: string;
 } & InfinityQueryWithSource<'inline'> &
@@ -50,7 +63,7 @@ export type InfinityQueryWithDataSource<T extends InfinityQueryType> = {
   columns: InfinityColumn[];
   filters?: InfinityFilter[];
   format: InfinityQueryFormat;
-} & (InfinityQueryWithURLSource<T> | InfinityQueryWithInlineSource<T> | InfinityQueryWithReferenceSource<T> | InfinityQueryWithAzureBlobSource<T>) &
+} & (InfinityQueryWithURLSource<T> | InfinityQueryWithInlineSource<T> | InfinityQueryWithReferenceSource<T> | InfinityQueryWithAzureBlobSource<T> | InfinityQueryWithS3Source<T> | InfinityQueryWithGCSSource<T>) &
   InfinityQueryBase<T>;
 export type InfinityJSONQueryOptions = {
   root_is_not_array?: boolean;
@@ -65,6 +78,12 @@ export type InfinityJSONQuery = (
   | { parser: 'groq'; groq?: string }
 ) &
   InfinityQueryWithDataSource<'json'>;
+export type InfinityNDJSONQuery = (({ parser?: 'backend' } & BackendParserOptions) | ({ parser: 'jq-backend' } & BackendParserOptions)) & InfinityQueryWithDataSource<'ndjson'>;
+export type InfinityYAMLQuery = (({ parser?: 'backend' } & BackendParserOptions) | ({ parser: 'jq-backend' } & BackendParserOptions)) & InfinityQueryWithDataSource<'yaml'>;
+export type InfinityPrometheusQuery = { parser?: 'backend' } & BackendParserOptions & InfinityQueryWithDataSource<'prometheus'>;
+export type InfinityParquetQuery = { parser?: 'backend'; max_rows?: number } & BackendParserOptions & InfinityQueryWithDataSource<'parquet'>;
+export type InfinityArrowQuery = { parser?: 'backend'; max_rows?: number } & BackendParserOptions & InfinityQueryWithDataSource<'arrow'>;
+export type InfinityXLSXQuery = { parser?: 'backend'; sheetName?: string; sheetRange?: string } & BackendParserOptions & InfinityQueryWithDataSource<'xlsx'>;
 export type InfinityCSVQueryOptions = {
   delimiter?: string;
   skip_empty_lines?: boolean;
@@ -101,7 +120,7 @@ export type InfinitySeriesQueryRandomWalk = {} & InfinitySeriesQueryBase<'random
 export type InfinitySeriesQueryExpression = { expression?: string } & InfinitySeriesQueryBase<'expression'>;
 export type InfinitySeriesQuery = InfinitySeriesQueryRandomWalk | InfinitySeriesQueryExpression;
 export type InfinityGlobalQuery = { global_query_id: string } & InfinityQueryBase<'global'>;
-export type InfinityDataQuery = InfinityJSONQuery | InfinityCSVQuery | InfinityTSVQuery | InfinityXMLQuery | InfinityGraphQLQuery | InfinityHTMLQuery;
+export type InfinityDataQuery = InfinityJSONQuery | InfinityNDJSONQuery | InfinityYAMLQuery | InfinityPrometheusQuery | InfinityParquetQuery | InfinityArrowQuery | InfinityXLSXQuery | InfinityCSVQuery | InfinityTSVQuery | InfinityXMLQuery | InfinityGraphQLQuery | InfinityHTMLQuery;
 export type InfinityDestinationQuery = InfinityDataQuery | InfinitySeriesQuery;
 export type InfinityLegacyQuery = InfinityDestinationQuery | InfinityGlobalQuery;
 export type InfinityUQLQuerySource = InfinityQueryWithURLSource<'uql'> | InfinityQueryWithInlineSource<'uql'>;
@@ -109,7 +128,7 @@ export type InfinityUQLQuery = { uql: string; format: InfinityQueryFormat } & In
 export type InfinityGROQQuerySource = InfinityQueryWithURLSource<'groq'> | InfinityQueryWithInlineSource<'groq'>;
 export type InfinityGROQQuery = { groq: string; format: InfinityQueryFormat } & InfinityGROQQuerySource & InfinityQueryBase<'groq'>;
 export type InfinityGSheetsQuery = { spreadsheet: string; sheetName?: string; sheetRange: string; columns: InfinityColumn[] } & InfinityQueryBase<'google-sheets'>;
-export type PaginationType = 'none' | 'offset' | 'page' | 'cursor' | 'list';
+export type PaginationType = 'none' | 'offset' | 'page' | 'cursor' | 'list' | 'next_link';
 export type PaginationParamType = 'query' | 'header' | 'body_data' | 'body_json' | 'replace';
 export type PaginationBase<T extends PaginationType> = { pagination_mode?: T; pagination_max_pages?: number };
 export type PaginationNone = {} & PaginationBase<'none'>;
@@ -120,6 +139,8 @@ export type PaginationOffset = {
   pagination_param_offset_field_name?: string;
   pagination_param_offset_field_type?: PaginationParamType;
   pagination_param_offset_value?: number;
+  pagination_total_count_extraction_path?: string;
+  pagination_total_count_header?: string;
 } & PaginationBase<'offset'>;
 export type PaginationPage = {
   pagination_param_size_field_name?: string;
@@ -128,6 +149,8 @@ export type PaginationPage = {
   pagination_param_page_field_name?: string;
   pagination_param_page_field_type?: PaginationParamType;
   pagination_param_page_value?: number;
+  pagination_total_count_extraction_path?: string;
+  pagination_total_count_header?: string;
 } & PaginationBase<'page'>;
 export type PaginationCursor = {
   pagination_param_size_field_name?: string;
@@ -142,7 +165,10 @@ export type PaginationList = {
   pagination_param_list_field_type?: PaginationParamType;
   pagination_param_list_value?: string;
 } & PaginationBase<'list'>;
-export type Pagination = PaginationNone | PaginationOffset | PaginationPage | PaginationCursor | PaginationList;
+export type PaginationNextLink = {
+  pagination_param_next_link_extraction_path?: string;
+} & PaginationBase<'next_link'>;
+export type Pagination = PaginationNone | PaginationOffset | PaginationPage | PaginationCursor | PaginationList | PaginationNextLink;
 export type Transformation = 'limit' | 'filterExpression' | 'summarize' | 'computedColumn';
 export type TransformationItem = {
   type: Transformation;